@@ -2,30 +2,116 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
+	"github.com/Gu1llaum-3/sshm/internal/config"
 	"github.com/Gu1llaum-3/sshm/internal/ui"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	addTemplate string
+	addEnv      string
+	addFlags    hostFlags
+)
+
 var addCmd = &cobra.Command{
 	Use:   "add [hostname]",
 	Short: "Add a new SSH host configuration",
-	Long:  `Add a new SSH host configuration with an interactive form.`,
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Add a new SSH host configuration with an interactive form.
+
+Given a host name with --template or any of the host flags, the host is
+written without opening the form. Templates come from config.json; flags
+replace the values of the template. Without a host name, the form starts
+from the template.
+
+` + hostExitCodes + `
+
+Examples:
+  sshm add                                      # Open the add form
+  sshm add web1                                 # Open the add form for web1
+  sshm add web1 --hostname 10.0.0.5 --user deploy --tag web --tag prod
+  sshm add web1 --hostname web1.example.com --option ServerAliveInterval=60
+  sshm add web1 --hostname 10.0.0.5 --file ~/.ssh/config.d/work.conf
+  sshm add db3 --template prod-db               # Add db3 from the prod-db template
+  sshm add db3 --template prod-db --env staging # Set {{env}} to staging
+  sshm add --template prod-db                   # Open the form from the template`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		var hostname string
 		if len(args) > 0 {
 			hostname = args[0]
 		}
 
-		err := ui.RunAddForm(hostname, configFile)
+		if addTemplate != "" || addEnv != "" || addFlags.changed(cmd) {
+			if hostname != "" {
+				os.Exit(runAdd(cmd, hostname))
+			}
+			if addEnv != "" || addFlags.changed(cmd) {
+				os.Exit(usageError("a host name is required with flags"))
+			}
+		}
+
+		err := ui.RunAddFormFromTemplate(hostname, addTemplate, configFile)
 		if err != nil {
 			fmt.Printf("Error adding host: %v\n", err)
 		}
 	},
 }
 
+// runAdd writes a host built from a template and the flags, and returns the
+// exit code
+func runAdd(cmd *cobra.Command, name string) int {
+	host := config.SSHHost{Name: name, Metadata: config.HostMetadata{Environment: addEnv}}
+	if addTemplate != "" {
+		template, err := config.GetHostTemplate(addTemplate)
+		if err != nil {
+			return usageError("%v", err)
+		}
+		if host, err = template.NewHost(name, addEnv); err != nil {
+			return usageError("template '%s': %v", addTemplate, err)
+		}
+	}
+	if err := addFlags.apply(cmd, &host); err != nil {
+		return usageError("%v", err)
+	}
+	if host.Hostname == "" {
+		return usageError("--hostname is required")
+	}
+	if err := validateHostFlags(host); err != nil {
+		return usageError("%v", err)
+	}
+
+	// The host must be new to the whole tree, not only to the target file
+	if _, err := findConfigHost(name); err == nil {
+		return hostExitCode("adding host", fmt.Errorf("host '%s' %w", name, config.ErrHostExists))
+	}
+
+	target := configFile
+	if addFlags.file != "" {
+		var err error
+		if target, err = resolveTargetFile(addFlags.file); err != nil {
+			return usageError("%v", err)
+		}
+	}
+
+	var err error
+	if target != "" {
+		err = config.AddSSHHostToFile(host, target)
+	} else {
+		err = config.AddSSHHost(host)
+	}
+	if err != nil {
+		return hostExitCode("adding host", err)
+	}
+	fmt.Printf("Added host '%s'\n", name)
+	return hostExitOK
+}
+
 func init() {
+	addCmd.Flags().StringVarP(&addTemplate, "template", "t", "", "Start from a host template of config.json")
+	addCmd.Flags().StringVar(&addEnv, "env", "", "Environment of the host, also replacing {{env}} in templates")
+	addFlags.register(addCmd, "Config file to add the host to, among the included files")
 	RootCmd.AddCommand(addCmd)
 }
@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"bytes"
+	"os"
+	"strings"
 	"testing"
 
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
 	"github.com/spf13/cobra"
 )
 
@@ -70,6 +74,44 @@ func TestAddCommandHelp(t *testing.T) {
 	}
 }
 
+func TestAddHostFromTemplate(t *testing.T) {
+	path := setupHostCommandConfig(t, "")
+
+	if err := config.SaveHostTemplate("prod-db", config.HostTemplate{HostName: "{{name}}.{{env}}.example.com", User: "postgres", Environment: "prod"}); err != nil {
+		t.Fatalf("SaveHostTemplate() error = %v", err)
+	}
+	if err := config.SaveHostTemplate("bare", config.HostTemplate{User: "root"}); err != nil {
+		t.Fatalf("SaveHostTemplate() error = %v", err)
+	}
+
+	addTemplate, addEnv = "prod-db", "staging"
+	defer func() { addTemplate, addEnv = "", "" }()
+	if code := runAdd(newHostFlagsCommand(t, &addFlags, nil), "db3"); code != hostExitOK {
+		t.Fatalf("runAdd() = %d, want %d", code, hostExitOK)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if !strings.Contains(string(data), "HostName db3.staging.example.com") || !strings.Contains(string(data), "User postgres") {
+		t.Errorf("Config does not hold the new host:\n%s", data)
+	}
+
+	// Flags replace the values of the template
+	addTemplate, addEnv = "bare", ""
+	if code := runAdd(newHostFlagsCommand(t, &addFlags, nil), "db4"); code != hostExitUsage {
+		t.Errorf("runAdd() without a hostname = %d, want %d", code, hostExitUsage)
+	}
+	cmd := newHostFlagsCommand(t, &addFlags, map[string]string{"hostname": "10.0.0.4", "user": "admin"})
+	if code := runAdd(cmd, "db4"); code != hostExitOK {
+		t.Errorf("runAdd() with --hostname = %d, want %d", code, hostExitOK)
+	}
+	host, err := config.GetSSHHostFromFile("db4", path)
+	if err != nil || host.User != "admin" {
+		t.Errorf("db4 = %+v, %v, want the user of --user", host, err)
+	}
+}
+
 // Helper function to check if string contains substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// applyYes skips the confirmation of sshm apply
+var applyYes bool
+
+var applyCmd = &cobra.Command{
+	Use:   "apply <manifest>",
+	Short: "Make the SSH config match a host manifest",
+	Long: `Add, change, move and remove the hosts of a manifest so that the SSH config
+matches it. The plan is shown and confirmed first; --yes skips the
+confirmation, and is required when sshm is not run from a terminal.
+
+Every file involved is backed up and written as a single change: either all
+hosts are updated or none is, and "u" in the TUI undoes the whole change.
+
+` + manifestHelp + `
+
+` + hostExitCodes + `
+
+Examples:
+  sshm apply hosts.yaml        # Show the plan and apply it after a confirmation
+  sshm apply hosts.json --yes  # Apply without confirmation, e.g. from CI`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runApply(args[0]))
+	},
+}
+
+// runApply applies a manifest and returns the exit code
+func runApply(path string) int {
+	plan, code := loadPlan(path)
+	if plan == nil {
+		return code
+	}
+
+	printPlan(os.Stdout, plan)
+	if plan.IsEmpty() {
+		return hostExitOK
+	}
+
+	if !applyYes {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return usageError("not running in a terminal, use --yes to apply the manifest")
+		}
+		if !confirm("Apply these changes?") {
+			fmt.Println("Cancelled.")
+			return hostExitOK
+		}
+	}
+
+	if err := config.ApplyPlan(plan); err != nil {
+		return hostExitCode("applying manifest", err)
+	}
+	fmt.Printf("Applied manifest '%s'\n", plan.Manifest)
+	return hostExitOK
+}
+
+func init() {
+	applyCmd.Flags().BoolVarP(&applyYes, "yes", "y", false, "Apply without confirmation")
+	RootCmd.AddCommand(applyCmd)
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "List, compare and restore config backups",
+	Long: `SSHM saves a timestamped snapshot of every config file before changing it.
+Snapshots are kept per file, including included files, according to the
+retention policy in config.json.
+
+Examples:
+  sshm backup list                       # List every snapshot, newest first
+  sshm backup list ~/.ssh/config         # List snapshots of one file
+  sshm backup diff config-1a2b3c4d/2025  # Show changes made since a snapshot
+  sshm backup restore config-1a2b3c4d/2025`,
+}
+
+var backupListCmd = &cobra.Command{
+	Use:   "list [file]",
+	Short: "List config snapshots, newest first",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var sourceFile string
+		if len(args) > 0 {
+			sourceFile = args[0]
+		}
+
+		backups, err := config.ListBackups(sourceFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing backups: %v\n", err)
+			os.Exit(1)
+		}
+		if len(backups) == 0 {
+			fmt.Println("No backups found.")
+			return
+		}
+
+		idWidth := 2 // "ID"
+		for _, backup := range backups {
+			if len(backup.ID) > idWidth {
+				idWidth = len(backup.ID)
+			}
+		}
+
+		fmt.Printf("%-*s  %-19s  %8s  %s\n", idWidth, "ID", "Date", "Size", "File")
+		for _, backup := range backups {
+			size := strconv.FormatInt(backup.Size, 10)
+			if backup.Absent {
+				size = "absent"
+			}
+			fmt.Printf("%-*s  %-19s  %8s  %s\n", idWidth, backup.ID,
+				backup.Time.Local().Format("2006-01-02 15:04:05"), size, backup.SourceFile)
+		}
+	},
+}
+
+var backupDiffCmd = &cobra.Command{
+	Use:   "diff <id>",
+	Short: "Show a unified diff from a snapshot to the current file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		backup := findBackupOrExit(args[0])
+
+		diff, err := config.DiffBackup(*backup)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error comparing backup: %v\n", err)
+			os.Exit(1)
+		}
+		if diff == "" {
+			fmt.Printf("%s is identical to backup %s\n", backup.SourceFile, backup.ID)
+			return
+		}
+		fmt.Print(diff)
+	},
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Restore a config file from a snapshot",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		backup := findBackupOrExit(args[0])
+
+		if err := config.RestoreBackup(*backup); err != nil {
+			fmt.Fprintf(os.Stderr, "Error restoring backup: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Restored %s from backup %s\n", backup.SourceFile, backup.ID)
+	},
+}
+
+// findBackupOrExit looks up a snapshot by ID, exiting with an error if it can't be found
+func findBackupOrExit(id string) *config.Backup {
+	backup, err := config.FindBackup(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return backup
+}
+
+func init() {
+	backupCmd.AddCommand(backupListCmd)
+	backupCmd.AddCommand(backupDiffCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+	RootCmd.AddCommand(backupCmd)
+}
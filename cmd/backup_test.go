@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestBackupCommandSubcommands(t *testing.T) {
+	expected := map[string]bool{"list": false, "diff": false, "restore": false}
+	for _, cmd := range backupCmd.Commands() {
+		if _, ok := expected[cmd.Name()]; ok {
+			expected[cmd.Name()] = true
+		}
+	}
+	for name, found := range expected {
+		if !found {
+			t.Errorf("Expected backup subcommand '%s' not found", name)
+		}
+	}
+}
+
+func TestBackupCommandArgs(t *testing.T) {
+	if err := backupListCmd.Args(backupListCmd, []string{}); err != nil {
+		t.Errorf("Expected no error for list without arguments, got %v", err)
+	}
+	if err := backupDiffCmd.Args(backupDiffCmd, []string{}); err == nil {
+		t.Error("Expected error for diff without a backup ID")
+	}
+	if err := backupRestoreCmd.Args(backupRestoreCmd, []string{"id", "other"}); err == nil {
+		t.Error("Expected error for restore with too many arguments")
+	}
+}
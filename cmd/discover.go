@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/discover"
+	"github.com/Gu1llaum-3/sshm/internal/importer"
+	"github.com/Gu1llaum-3/sshm/internal/ui"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	discoverFile     string
+	discoverTags     []string
+	discoverMinCount int
+	discoverLimit    int
+	discoverYes      bool
+	discoverDryRun   bool
+)
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Find hosts connected to that are missing from the config",
+	Long: `Scan known_hosts and shell histories for hosts that were connected to but
+are missing from the SSH config, and suggest them as new hosts, the most
+frequent first.
+
+Files scanned:
+  ~/.ssh/known_hosts and ~/.ssh/known_hosts2
+  ~/.bash_history, ~/.zsh_history, ~/.zhistory, the fish history and $HISTFILE
+
+The user, port, key and jump host of "ssh user@host -p N -i key -J jump"
+commands are carried over, keeping the values used most often. Hashed
+known_hosts entries can't be read back; they are matched against the hosts
+found in histories. Hosts reached through an alias of the config, or whose
+hostname and port a host of the config already uses, are left out.
+
+The suggestions open in a checklist to pick the hosts to add, their names and
+tags, and the file they are added to. --yes adds every suggestion without the
+checklist, and is required when sshm is not run from a terminal.
+
+` + hostExitCodes + `
+
+Examples:
+  sshm discover
+  sshm discover --dry-run                       # Only list the suggestions
+  sshm discover --min-count 3 --tag discovered
+  sshm discover --limit 10 --yes --file ~/.ssh/config.d/discovered.conf`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runDiscover())
+	},
+}
+
+// runDiscover suggests the hosts found in known_hosts and histories, adds
+// the ones picked and returns the exit code
+func runDiscover() int {
+	if discoverMinCount < 1 || discoverLimit < 0 {
+		return usageError("--min-count must be at least 1 and --limit can't be negative")
+	}
+	var tags []string
+	for _, flag := range discoverTags {
+		for _, tag := range strings.Split(flag, ",") {
+			if tag = strings.TrimSpace(tag); tag == "" {
+				continue
+			}
+			if err := config.ValidateTag(tag); err != nil {
+				return usageError("%v", err)
+			}
+			tags = append(tags, tag)
+		}
+	}
+
+	target := configFile
+	var err error
+	if discoverFile != "" {
+		if target, err = resolveTargetFile(discoverFile); err != nil {
+			return usageError("%v", err)
+		}
+	} else if target == "" {
+		if target, err = config.GetDefaultSSHConfigPath(); err != nil {
+			return hostExitCode("discovering hosts", err)
+		}
+	}
+
+	parsed, err := config.ParseConfigLayers(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
+		return hostExitFailure
+	}
+	sources, err := discover.DefaultSources()
+	if err != nil {
+		return hostExitCode("discovering hosts", err)
+	}
+
+	result := discover.Discover(sources, parsed.Hosts)
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	var suggestions []discover.Suggestion
+	for _, s := range result.Suggestions {
+		if s.Count < discoverMinCount || (discoverLimit > 0 && len(suggestions) == discoverLimit) {
+			continue
+		}
+		s.Host.Tags = tags
+		suggestions = append(suggestions, s)
+	}
+	if len(suggestions) == 0 {
+		fmt.Printf("No new hosts found in %d file(s)\n", len(result.Scanned))
+		return hostExitOK
+	}
+
+	if discoverDryRun {
+		printSuggestions(os.Stdout, suggestions)
+		return hostExitOK
+	}
+
+	var hosts []config.SSHHost
+	if discoverYes {
+		printSuggestions(os.Stdout, suggestions)
+		for _, s := range suggestions {
+			hosts = append(hosts, s.Host)
+		}
+	} else {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return usageError("not running in a terminal, use --yes to add the hosts without the checklist")
+		}
+		var knownTags []string
+		for _, tc := range config.CountTags(parsed.Hosts) {
+			knownTags = append(knownTags, tc.Tag)
+		}
+		var confirmed bool
+		hosts, target, confirmed, err = ui.RunDiscoverChecklist(suggestions, parsed.Hosts, discoverTargets(target), knownTags)
+		if err != nil {
+			return hostExitCode("discovering hosts", err)
+		}
+		if !confirmed {
+			fmt.Println("Cancelled.")
+			return hostExitOK
+		}
+	}
+
+	if err := config.ImportHosts(hosts, nil, nil, target); err != nil {
+		return hostExitCode("adding hosts", err)
+	}
+	fmt.Printf("Added %d host(s) to %s\n", len(hosts), target)
+	return hostExitOK
+}
+
+// discoverTargets lists the files hosts can be added to, the default target
+// first and the other files of the config after it
+func discoverTargets(target string) []string {
+	files, _ := config.GetAllConfigFilesFromBase(configFile)
+	sort.Strings(files)
+	targets := []string{target}
+	for _, file := range files {
+		if file != target {
+			targets = append(targets, file)
+		}
+	}
+	return targets
+}
+
+// printSuggestions lists the discovered hosts with where they were found
+func printSuggestions(w io.Writer, suggestions []discover.Suggestion) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tENDPOINT\tSEEN\tFOUND IN")
+	for _, s := range suggestions {
+		var files []string
+		for _, file := range s.Files {
+			files = append(files, shortenHome(file))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", s.Host.Name, importer.Endpoint(s.Host), s.Count, strings.Join(files, ", "))
+	}
+	tw.Flush()
+}
+
+func init() {
+	discoverCmd.Flags().StringVar(&discoverFile, "file", "", "Config file to add the hosts to, among the included files")
+	discoverCmd.Flags().StringArrayVar(&discoverTags, "tag", nil, "Tag to give the discovered hosts (repeatable, or comma-separated)")
+	discoverCmd.Flags().IntVar(&discoverMinCount, "min-count", 1, "Only suggest hosts seen at least this many times")
+	discoverCmd.Flags().IntVar(&discoverLimit, "limit", 0, "Suggest at most this many hosts, the most frequent (0 for all)")
+	discoverCmd.Flags().BoolVarP(&discoverYes, "yes", "y", false, "Add every suggestion without the checklist")
+	discoverCmd.Flags().BoolVar(&discoverDryRun, "dry-run", false, "Only list the suggestions")
+	RootCmd.AddCommand(discoverCmd)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+func TestDiscoverCommand(t *testing.T) {
+	path := setupHostCommandConfig(t, "Host web\n    HostName web.example.com\n")
+	t.Setenv("HISTFILE", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	home, _ := os.UserHomeDir()
+	history := "ssh web\nssh web.example.com\nssh -p 2222 ops@db.example.com\nssh ops@db.example.com -p 2222\nssh api.example.com\n"
+	if err := os.WriteFile(filepath.Join(home, ".bash_history"), []byte(history), 0600); err != nil {
+		t.Fatalf("Failed to write history: %v", err)
+	}
+
+	discoverYes, discoverMinCount, discoverTags = true, 2, []string{"found"}
+	defer func() { discoverYes, discoverMinCount, discoverTags = false, 1, nil }()
+	if code := runDiscover(); code != hostExitOK {
+		t.Fatalf("runDiscover() = %d", code)
+	}
+
+	// web is in the config and api was seen once
+	db, err := config.GetSSHHostFromFile("db", path)
+	if err != nil || db.Hostname != "db.example.com" || db.User != "ops" || db.Port != "2222" || len(db.Tags) != 1 || db.Tags[0] != "found" {
+		t.Errorf("db = %+v, %v, want it added", db, err)
+	}
+	if _, err := config.GetSSHHostFromFile("api", path); err == nil {
+		t.Error("api was seen once and should not be added with --min-count 2")
+	}
+
+	discoverMinCount = 0
+	if code := runDiscover(); code != hostExitUsage {
+		t.Errorf("runDiscover(--min-count 0) = %d, want %d", code, hostExitUsage)
+	}
+}
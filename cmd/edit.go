@@ -2,20 +2,41 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
+	"github.com/Gu1llaum-3/sshm/internal/config"
 	"github.com/Gu1llaum-3/sshm/internal/ui"
 
 	"github.com/spf13/cobra"
 )
 
+var editFlags hostFlags
+
 var editCmd = &cobra.Command{
 	Use:   "edit <hostname>",
 	Short: "Edit an existing SSH host configuration",
-	Long:  `Edit an existing SSH host configuration with an interactive form.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Edit an existing SSH host configuration with an interactive form.
+
+With any of the host flags, only the given values are changed and the form is
+not opened. Other directives, comments and metadata are kept. An empty value,
+such as --user "", removes the directive.
+
+` + hostExitCodes + `
+
+Examples:
+  sshm edit web1                                # Open the edit form
+  sshm edit web1 --user deploy --port 2222
+  sshm edit web1 --option Compression=yes --option ForwardAgent=
+  sshm edit web1 --tag web,prod                 # Replace the tags
+  sshm edit web1 --file ~/.ssh/config.d/old.conf # Move the host to another file`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		hostname := args[0]
 
+		if editFlags.changed(cmd) {
+			os.Exit(runEdit(cmd, hostname))
+		}
+
 		err := ui.RunEditForm(hostname, configFile)
 		if err != nil {
 			fmt.Printf("Error editing host: %v\n", err)
@@ -23,6 +44,45 @@ var editCmd = &cobra.Command{
 	},
 }
 
+// runEdit changes the values of a host given as flags and returns the exit
+// code
+func runEdit(cmd *cobra.Command, name string) int {
+	existing, err := findConfigHost(name)
+	if err != nil {
+		return hostExitCode("editing host", err)
+	}
+
+	host := *existing
+	host.Directives = append([]config.Directive(nil), existing.Directives...)
+	if err := editFlags.apply(cmd, &host); err != nil {
+		return usageError("%v", err)
+	}
+	if err := validateHostFlags(host); err != nil {
+		return usageError("%v", err)
+	}
+
+	var target string
+	if editFlags.file != "" {
+		if target, err = resolveTargetFile(editFlags.file); err != nil {
+			return usageError("%v", err)
+		}
+	}
+
+	if target != "" && target != existing.SourceFile {
+		if err := config.UpdateAndMoveHost(existing.Name, host, existing.SourceFile, target); err != nil {
+			return hostExitCode("editing host", err)
+		}
+		fmt.Printf("Updated host '%s' and moved it to %s\n", host.Name, target)
+		return hostExitOK
+	}
+	if err := config.UpdateSSHHostInFile(existing.Name, host, existing.SourceFile); err != nil {
+		return hostExitCode("editing host", err)
+	}
+	fmt.Printf("Updated host '%s'\n", host.Name)
+	return hostExitOK
+}
+
 func init() {
+	editFlags.register(editCmd, "Config file to move the host to, among the included files")
 	RootCmd.AddCommand(editCmd)
 }
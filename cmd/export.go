@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/ansible"
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportOutput string
+)
+
+// exportFormats render hosts for other tools, by format name
+var exportFormats = map[string]func([]config.SSHHost) ([]byte, error){
+	"ansible-ini":  ansible.ExportINI,
+	"ansible-yaml": ansible.ExportYAML,
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export --format <format> [host...]",
+	Short: "Export hosts for other tools",
+	Long: `Export SSH hosts, all of them or the given ones, for use by other tools.
+
+Formats:
+  ansible-ini   an Ansible inventory in the INI format
+  ansible-yaml  an Ansible inventory in the YAML format
+
+Inventories have a group per tag, hosts without tags being listed under
+ungrouped. Each host has its ansible_host, ansible_user, ansible_port and
+ansible_ssh_private_key_file, and its ProxyJump in ansible_ssh_common_args.
+Characters Ansible doesn't accept in group names are replaced with "_".
+"sshm import --from ansible" reads such inventories back.
+
+` + hostExitCodes + `
+
+Examples:
+  sshm export --format ansible-ini > inventory.ini
+  sshm export --format ansible-yaml -o inventory.yml
+  sshm export --format ansible-ini web1 web2`,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runExport(args))
+	},
+}
+
+// runExport writes the hosts in the requested format and returns the exit
+// code
+func runExport(names []string) int {
+	export, ok := exportFormats[exportFormat]
+	if !ok {
+		return usageError("unsupported format '%s' (use %s)", exportFormat, strings.Join(exportFormatNames(), ", "))
+	}
+
+	parsed, err := config.ParseConfigTree(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
+		return hostExitFailure
+	}
+	printDiagnostics(parsed.Diagnostics)
+
+	hosts := parsed.Hosts
+	if len(names) > 0 {
+		hosts = nil
+		for _, name := range names {
+			host, found := findHost(parsed.Hosts, name)
+			if !found {
+				return hostExitCode("exporting hosts", fmt.Errorf("host '%s' %w", name, config.ErrHostNotFound))
+			}
+			hosts = append(hosts, host)
+		}
+	}
+
+	data, err := export(hosts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error exporting hosts: %v\n", err)
+		return hostExitFailure
+	}
+	if exportOutput == "" || exportOutput == "-" {
+		os.Stdout.Write(data)
+		return hostExitOK
+	}
+	if err := os.WriteFile(exportOutput, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", exportOutput, err)
+		return hostExitFailure
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d host(s) to %s\n", len(hosts), exportOutput)
+	return hostExitOK
+}
+
+// findHost returns the host one of whose aliases is name
+func findHost(hosts []config.SSHHost, name string) (config.SSHHost, bool) {
+	for _, host := range hosts {
+		if host.HasAlias(name) {
+			return host, true
+		}
+	}
+	return config.SSHHost{}, false
+}
+
+// exportFormatNames returns the names of the export formats, sorted
+func exportFormatNames() []string {
+	names := make([]string, 0, len(exportFormats))
+	for name := range exportFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", "", "Output format ("+strings.Join(exportFormatNames(), ", ")+")")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "File to write to instead of the standard output")
+	exportCmd.MarkFlagRequired("format")
+	RootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+func TestExportAndImportAnsible(t *testing.T) {
+	path := setupHostCommandConfig(t, "# Tags: web\nHost web1\n    HostName 10.0.0.1\n    User deploy\n\nHost db1\n    HostName 10.0.1.1\n")
+	inventory := filepath.Join(t.TempDir(), "inventory.ini")
+
+	exportFormat, exportOutput = "ansible-ini", inventory
+	defer func() { exportFormat, exportOutput = "", "" }()
+	if code := runExport([]string{"web1"}); code != hostExitOK {
+		t.Fatalf("runExport() = %d", code)
+	}
+	data, err := os.ReadFile(inventory)
+	if err != nil {
+		t.Fatalf("Failed to read inventory: %v", err)
+	}
+	if want := "[web]\nweb1 ansible_host=10.0.0.1 ansible_user=deploy\n"; string(data) != want {
+		t.Errorf("inventory = %q, want %q", data, want)
+	}
+
+	if code := runExport([]string{"missing"}); code != hostExitNotFound {
+		t.Errorf("runExport(missing) = %d, want %d", code, hostExitNotFound)
+	}
+	exportFormat = "ansible-json"
+	if code := runExport(nil); code != hostExitUsage {
+		t.Errorf("runExport(unknown format) = %d, want %d", code, hostExitUsage)
+	}
+
+	// Existing hosts are updated by default, new ones created
+	changed := "[prod]\nweb1 ansible_host=10.0.0.5 ansible_port=2222\napi ansible_host=10.0.2.1\n"
+	if err := os.WriteFile(inventory, []byte(changed), 0600); err != nil {
+		t.Fatalf("Failed to write inventory: %v", err)
+	}
+	importFrom, importYes = "ansible", true
+	defer func() { importFrom, importYes = "", false }()
+	if code := runImport(inventory); code != hostExitOK {
+		t.Fatalf("runImport() = %d", code)
+	}
+	web, err := config.GetSSHHostFromFile("web1", path)
+	if err != nil || web.Hostname != "10.0.0.5" || web.Port != "2222" || web.User != "deploy" || len(web.Tags) != 1 || web.Tags[0] != "prod" {
+		t.Errorf("web1 = %+v, %v, want it updated", web, err)
+	}
+	if api, err := config.GetSSHHostFromFile("api", path); err != nil || api.Hostname != "10.0.2.1" || api.Tags[0] != "prod" {
+		t.Errorf("api = %+v, %v, want it created", api, err)
+	}
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	fmtCheck bool
+	fmtDiff  bool
+	fmtSort  bool
+)
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [file...]",
+	Short: "Format SSH config files in a consistent style",
+	Long: `Format the SSH config and every file it includes, or only the given files:
+directives are indented by four spaces inside Host and Match blocks, keywords
+use their documented casing, blocks are separated by one blank line and
+"# Tags:" comments sit directly above their Host line. Comments are kept.
+
+With --sort, consecutive Host blocks naming concrete hosts are sorted by name.
+Blocks are never moved past a wildcard pattern or Match block, so the values
+ssh applies to each host don't change.
+
+Files are backed up before they are rewritten. With --check, nothing is
+written and the command exits with status 1 if a file is not formatted.
+
+Examples:
+  sshm fmt                   # Format ~/.ssh/config and its includes
+  sshm fmt --diff            # Show what would change
+  sshm fmt --check           # Fail if a file is not formatted, for CI
+  sshm fmt --sort work.conf  # Format one file and sort its hosts`,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runFmt(args))
+	},
+}
+
+// runFmt formats the requested files and returns the exit code
+func runFmt(args []string) int {
+	paths := args
+	if len(paths) == 0 {
+		var err error
+		paths, err = config.ConfigTreeFiles(configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
+			return 2
+		}
+	}
+
+	opts := config.FormatOptions{SortHosts: fmtSort}
+	files, err := config.FormatFiles(paths, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error formatting SSH config: %v\n", err)
+		return 2
+	}
+
+	var changed []string
+	for _, file := range files {
+		if !file.Changed() {
+			continue
+		}
+		changed = append(changed, file.Path)
+		if fmtDiff {
+			fmt.Print(config.UnifiedDiff(file.Path, file.Path, file.Original, file.Formatted))
+		}
+	}
+
+	if fmtCheck || fmtDiff {
+		if fmtCheck {
+			for _, path := range changed {
+				fmt.Fprintf(os.Stderr, "%s is not formatted\n", path)
+			}
+			if len(changed) > 0 {
+				return 1
+			}
+		}
+		return 0
+	}
+
+	if len(changed) == 0 {
+		return 0
+	}
+	if err := config.WriteFormattedFiles(changed, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing SSH config: %v\n", err)
+		return 2
+	}
+	for _, path := range changed {
+		fmt.Printf("Formatted %s\n", path)
+	}
+	return 0
+}
+
+func init() {
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "Don't write, exit with status 1 if a file is not formatted")
+	fmtCmd.Flags().BoolVar(&fmtDiff, "diff", false, "Don't write, show a diff of the changes")
+	fmtCmd.Flags().BoolVar(&fmtSort, "sort", false, "Sort Host blocks by name")
+	RootCmd.AddCommand(fmtCmd)
+}
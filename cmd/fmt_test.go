@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestFmtCommand(t *testing.T) {
+	if fmtCmd.Use != "fmt [file...]" {
+		t.Errorf("Expected Use 'fmt [file...]', got '%s'", fmtCmd.Use)
+	}
+
+	for _, flag := range []string{"check", "diff", "sort"} {
+		if fmtCmd.Flags().Lookup(flag) == nil {
+			t.Errorf("Expected --%s flag to be defined", flag)
+		}
+	}
+
+	found := false
+	for _, cmd := range RootCmd.Commands() {
+		if cmd.Name() == "fmt" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Fmt command not found in root command")
+	}
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/validation"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of the commands managing hosts without a form, meant for scripts
+const (
+	hostExitOK       = 0 // Also when a confirmation is declined
+	hostExitFailure  = 1 // The config could not be read or written, or changed on disk meanwhile
+	hostExitUsage    = 2 // Invalid arguments or values
+	hostExitNotFound = 3 // The host does not exist
+	hostExitConflict = 4 // The host already exists
+)
+
+// hostExitCodes documents the exit codes in the help of the commands
+const hostExitCodes = `Exit codes: 0 on success or when a confirmation is declined, 1 when the config
+could not be read or written or was changed by another program meanwhile, 2 for
+invalid arguments or values, 3 when the host does not exist and 4 when it
+already exists.`
+
+// hostFlags holds the values of a host given on the command line. Only the
+// flags that are set change the host.
+type hostFlags struct {
+	hostname  string
+	user      string
+	port      string
+	identity  string
+	proxyJump string
+	options   []string
+	tags      []string
+	file      string
+}
+
+// register adds the host flags to a command
+func (f *hostFlags) register(cmd *cobra.Command, fileUsage string) {
+	cmd.Flags().StringVar(&f.hostname, "hostname", "", "Hostname or IP address")
+	cmd.Flags().StringVar(&f.user, "user", "", "User to log in as")
+	cmd.Flags().StringVar(&f.port, "port", "", "Port to connect to")
+	cmd.Flags().StringVar(&f.identity, "identity", "", "Private key file")
+	cmd.Flags().StringVar(&f.proxyJump, "proxy-jump", "", "Jump host, as for ssh -J")
+	cmd.Flags().StringArrayVar(&f.options, "option", nil, `Other directive as Key=Value, repeatable; "Key=" removes it`)
+	cmd.Flags().StringArrayVar(&f.tags, "tag", nil, "Tag, repeatable or comma-separated; replaces the host's tags")
+	cmd.Flags().StringVar(&f.file, "file", "", fileUsage)
+}
+
+// hostFlagNames lists the flags changing a host
+var hostFlagNames = []string{"hostname", "user", "port", "identity", "proxy-jump", "option", "tag", "file"}
+
+// changed reports whether any host flag is set
+func (f *hostFlags) changed(cmd *cobra.Command) bool {
+	for _, name := range hostFlagNames {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply sets the values of the flags that are set on a host
+func (f *hostFlags) apply(cmd *cobra.Command, host *config.SSHHost) error {
+	changed := cmd.Flags().Changed
+	if changed("hostname") {
+		host.Set("HostName", f.hostname)
+	}
+	if changed("user") {
+		host.Set("User", f.user)
+	}
+	if changed("port") {
+		host.Set("Port", f.port)
+	}
+	if changed("identity") {
+		identity := f.identity
+		if identity != "" {
+			// Paths with spaces, common on Windows, must be quoted
+			identity = config.QuoteArg(identity)
+		}
+		host.Set("IdentityFile", identity)
+	}
+	if changed("proxy-jump") {
+		host.Set("ProxyJump", f.proxyJump)
+	}
+
+	if changed("option") {
+		// Values given for the same keyword replace all of its occurrences
+		var keys []string
+		values := make(map[string][]string)
+		for _, option := range f.options {
+			key, value, err := parseOptionFlag(option)
+			if err != nil {
+				return err
+			}
+			name := config.LookupKeyword(key).Name
+			if _, seen := values[name]; !seen {
+				keys = append(keys, name)
+				values[name] = nil
+			}
+			if value != "" {
+				values[name] = append(values[name], value)
+			}
+		}
+		for _, key := range keys {
+			host.SetAll(key, values[key])
+		}
+	}
+
+	if changed("tag") {
+		var tags []string
+		for _, flag := range f.tags {
+			for _, tag := range strings.Split(flag, ",") {
+				if tag = strings.TrimSpace(tag); tag == "" {
+					continue
+				}
+				if err := config.ValidateTag(tag); err != nil {
+					return err
+				}
+				tags = append(tags, tag)
+			}
+		}
+		host.Tags = tags
+	}
+	return nil
+}
+
+// parseOptionFlag splits an --option value written as Key=Value or "Key Value"
+func parseOptionFlag(option string) (string, string, error) {
+	key, value := config.SplitDirective(option)
+	if value == "" {
+		// "Key=" removes the directive, only the keyword is checked
+		if key == "" || strings.ContainsAny(key, "\"") {
+			return "", "", fmt.Errorf("invalid option '%s', use Key=Value", option)
+		}
+		return key, "", nil
+	}
+	if err := config.ValidateDirective(key, value); err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+// validateHostFlags checks a host built from flags the way the forms do
+func validateHostFlags(host config.SSHHost) error {
+	return validation.ValidateHost(host.Name, host.Hostname, host.Port, config.UnquoteArg(host.Identity))
+}
+
+// resolveTargetFile returns the absolute path of a --file value, which must
+// be part of the config tree so that ssh reads the hosts written to it
+func resolveTargetFile(file string) (string, error) {
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	files, err := config.ConfigTreeFiles(configFile)
+	if err != nil {
+		return "", err
+	}
+	for _, treeFile := range files {
+		if abs, err := filepath.Abs(treeFile); err == nil && abs == path {
+			return treeFile, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not part of the SSH config; include it first", file)
+}
+
+// findConfigHost looks up a host of the config tree by name or alias
+func findConfigHost(name string) (*config.SSHHost, error) {
+	parsed, err := config.ParseConfigTree(configFile)
+	if err != nil {
+		return nil, err
+	}
+	for i := range parsed.Hosts {
+		if parsed.Hosts[i].HasAlias(name) {
+			return &parsed.Hosts[i], nil
+		}
+	}
+	return nil, fmt.Errorf("host '%s' %w", name, config.ErrHostNotFound)
+}
+
+// hostExitCode reports an error on stderr and returns the matching exit code
+func hostExitCode(action string, err error) int {
+	fmt.Fprintf(os.Stderr, "Error %s: %v\n", action, err)
+	switch {
+	case errors.Is(err, config.ErrHostNotFound):
+		return hostExitNotFound
+	case errors.Is(err, config.ErrHostExists):
+		return hostExitConflict
+	default:
+		return hostExitFailure
+	}
+}
+
+// usageError reports invalid arguments or values and returns hostExitUsage
+func usageError(format string, args ...interface{}) int {
+	fmt.Fprintf(os.Stderr, "Error: "+format+"\n", args...)
+	return hostExitUsage
+}
+
+// confirm asks a yes or no question on the terminal, no being the default
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
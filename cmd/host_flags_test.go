@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// setupHostCommandConfig points the commands at a temporary config holding
+// content, with the app config in a temporary home
+func setupHostCommandConfig(t *testing.T, content string) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	original := configFile
+	configFile = path
+	t.Cleanup(func() { configFile = original })
+	return path
+}
+
+// newHostFlagsCommand returns a command with fresh host flags bound to flags,
+// set to the given values
+func newHostFlagsCommand(t *testing.T, flags *hostFlags, values map[string]string) *cobra.Command {
+	t.Helper()
+	*flags = hostFlags{}
+	cmd := &cobra.Command{}
+	flags.register(cmd, "")
+	for name, value := range values {
+		if err := cmd.Flags().Set(name, value); err != nil {
+			t.Fatalf("Failed to set --%s: %v", name, err)
+		}
+	}
+	return cmd
+}
+
+func TestHostFlagsApply(t *testing.T) {
+	host := config.SSHHost{Name: "web", Tags: []string{"old"}}
+	host.Set("HostName", "web.example.com")
+	host.Set("User", "root")
+	host.Set("ForwardAgent", "yes")
+
+	var flags hostFlags
+	cmd := newHostFlagsCommand(t, &flags, map[string]string{
+		"user":   "",
+		"port":   "2222",
+		"option": "ForwardAgent=",
+		"tag":    "web, prod",
+	})
+	if err := cmd.Flags().Set("option", "serveraliveinterval=60"); err != nil {
+		t.Fatalf("Failed to set --option: %v", err)
+	}
+	if err := flags.apply(cmd, &host); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+
+	if host.Hostname != "web.example.com" {
+		t.Errorf("Hostname = %q, want it unchanged", host.Hostname)
+	}
+	if host.User != "" || host.Port != "2222" {
+		t.Errorf("User = %q, Port = %q, want the user removed and port 2222", host.User, host.Port)
+	}
+	if host.Get("ForwardAgent") != "" || host.Get("ServerAliveInterval") != "60" {
+		t.Errorf("Directives = %+v", host.Directives)
+	}
+	if strings.Join(host.Tags, ",") != "web,prod" {
+		t.Errorf("Tags = %v, want [web prod]", host.Tags)
+	}
+
+	cmd = newHostFlagsCommand(t, &flags, map[string]string{"option": "Port=notaport"})
+	if err := flags.apply(cmd, &host); err == nil {
+		t.Error("Expected an error for an invalid option")
+	}
+}
+
+func TestParseOptionFlag(t *testing.T) {
+	tests := []struct {
+		option string
+		key    string
+		value  string
+	}{
+		{"User=bob", "User", "bob"},
+		{"User bob", "User", "bob"},
+		{"User = bob", "User", "bob"},
+		{" User\t=\tbob ", "User", "bob"},
+		{"SetEnv FOO=bar", "SetEnv", "FOO=bar"},
+		{"ForwardAgent =", "ForwardAgent", ""},
+	}
+	for _, tt := range tests {
+		key, value, err := parseOptionFlag(tt.option)
+		if err != nil || key != tt.key || value != tt.value {
+			t.Errorf("parseOptionFlag(%q) = %q, %q, %v, want %q, %q", tt.option, key, value, err, tt.key, tt.value)
+		}
+	}
+	if _, _, err := parseOptionFlag("Port = = 22"); err == nil {
+		t.Error("expected an error for a doubled separator")
+	}
+}
+
+func TestHostCommandsExitCodes(t *testing.T) {
+	path := setupHostCommandConfig(t, "Host web\n    HostName web.example.com\n\nHost db\n    HostName db.example.com\n")
+
+	cmd := newHostFlagsCommand(t, &addFlags, map[string]string{"hostname": "10.0.0.1"})
+	if code := runAdd(cmd, "web"); code != hostExitConflict {
+		t.Errorf("runAdd(existing) = %d, want %d", code, hostExitConflict)
+	}
+	cmd = newHostFlagsCommand(t, &addFlags, map[string]string{"hostname": "not a host"})
+	if code := runAdd(cmd, "api"); code != hostExitUsage {
+		t.Errorf("runAdd(invalid hostname) = %d, want %d", code, hostExitUsage)
+	}
+	cmd = newHostFlagsCommand(t, &addFlags, map[string]string{"hostname": "10.0.0.1", "file": filepath.Join(t.TempDir(), "other")})
+	if code := runAdd(cmd, "api"); code != hostExitUsage {
+		t.Errorf("runAdd(file outside the config) = %d, want %d", code, hostExitUsage)
+	}
+
+	cmd = newHostFlagsCommand(t, &editFlags, map[string]string{"user": "deploy"})
+	if code := runEdit(cmd, "missing"); code != hostExitNotFound {
+		t.Errorf("runEdit(missing) = %d, want %d", code, hostExitNotFound)
+	}
+	if code := runEdit(cmd, "web"); code != hostExitOK {
+		t.Errorf("runEdit(web) = %d, want %d", code, hostExitOK)
+	}
+	host, err := config.GetSSHHostFromFile("web", path)
+	if err != nil || host.User != "deploy" || host.Hostname != "web.example.com" {
+		t.Errorf("web = %+v, %v, want only the user changed", host, err)
+	}
+
+	rmYes = true
+	defer func() { rmYes = false }()
+	if code := runRm([]string{"db", "missing"}); code != hostExitNotFound {
+		t.Errorf("runRm(missing) = %d, want %d", code, hostExitNotFound)
+	}
+	if code := runRm([]string{"db"}); code != hostExitOK {
+		t.Errorf("runRm(db) = %d, want %d", code, hostExitOK)
+	}
+	if _, err := config.GetSSHHostFromFile("db", path); err == nil {
+		t.Error("db should have been removed")
+	}
+
+	// The first web is the one of the included file, the config has another.
+	// Moving it to the config is a conflict that leaves both files unchanged.
+	other := filepath.Join(filepath.Dir(path), "other")
+	if err := os.WriteFile(other, []byte("Host web\n    HostName 10.0.0.9\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if err := os.WriteFile(path, []byte("Include other\n\n"+string(content)+"\nHost api\n    HostName api.example.com\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cmd = newHostFlagsCommand(t, &editFlags, map[string]string{"user": "admin", "file": path})
+	if code := runEdit(cmd, "web"); code != hostExitConflict {
+		t.Errorf("runEdit(web --file) = %d, want %d", code, hostExitConflict)
+	}
+	if data, _ := os.ReadFile(other); string(data) != "Host web\n    HostName 10.0.0.9\n" {
+		t.Errorf("other = %q, want it unchanged", data)
+	}
+	cmd = newHostFlagsCommand(t, &editFlags, map[string]string{"user": "admin", "file": other})
+	if code := runEdit(cmd, "api"); code != hostExitOK {
+		t.Errorf("runEdit(api --file) = %d, want %d", code, hostExitOK)
+	}
+	if host, err := config.GetSSHHostFromFile("api", other); err != nil || host.User != "admin" {
+		t.Errorf("api = %+v, %v, want it moved with the user changed", host, err)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "Host api") {
+		t.Error("api should have been moved out of the config")
+	}
+}
+
+func TestWriteHostsFormats(t *testing.T) {
+	host := config.SSHHost{Name: "web", Aliases: []string{"web", "www"}, Patterns: []string{"web", "www"}, Tags: []string{"prod", "web"}, SourceFile: "/tmp/config"}
+	host.Set("HostName", "web.example.com")
+	host.Set("Compression", "yes")
+	hosts := []config.SSHHost{host, {Name: "bare"}}
+
+	var buf bytes.Buffer
+	if err := writeHosts(&buf, "json", hosts); err != nil {
+		t.Fatalf("writeHosts(json) error = %v", err)
+	}
+	var records []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(records) != 2 || records[0]["hostname"] != "web.example.com" {
+		t.Errorf("records = %v", records)
+	}
+	if tags, ok := records[1]["tags"].([]interface{}); !ok || len(tags) != 0 {
+		t.Errorf("tags of a host without tags = %v, want []", records[1]["tags"])
+	}
+	if options := records[0]["options"].([]interface{}); len(options) != 1 {
+		t.Errorf("options = %v, want Compression", options)
+	}
+
+	buf.Reset()
+	if err := writeHosts(&buf, "yaml", hosts); err != nil {
+		t.Fatalf("writeHosts(yaml) error = %v", err)
+	}
+	var yamlRecords []config.HostRecord
+	if err := yaml.Unmarshal(buf.Bytes(), &yamlRecords); err != nil {
+		t.Fatalf("Invalid YAML: %v\n%s", err, buf.String())
+	}
+	if len(yamlRecords) != 2 || yamlRecords[0].Aliases[0] != "www" {
+		t.Errorf("YAML records = %+v", yamlRecords)
+	}
+
+	buf.Reset()
+	if err := writeHosts(&buf, "csv", hosts); err != nil {
+		t.Fatalf("writeHosts(csv) error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "name,aliases,hostname") || !strings.HasPrefix(lines[1], "web,www,web.example.com,,,,,prod;web,") {
+		t.Errorf("CSV = %q", buf.String())
+	}
+}
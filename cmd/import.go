@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/importer"
+	"github.com/Gu1llaum-3/sshm/internal/ui"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	importFrom       string
+	importFile       string
+	importOnConflict string
+	importFolders    string
+	importYes        bool
+	importDryRun     bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import --from <format> <file>",
+	Short: "Import hosts from other SSH clients",
+	Long: `Import the sessions saved by other SSH clients as SSH hosts. Hostnames,
+users, ports, keys and jump hosts are carried over; folders become groups, or
+tags with --folders tag. Sessions that don't use SSH are left out.
+
+Formats:
+  ansible        an Ansible inventory in the INI or the YAML format; groups,
+                 direct or through children, become tags
+  putty-reg      PuTTY sessions exported with
+                 reg export HKCU\Software\SimonTatham\PuTTY\Sessions putty.reg
+  termius-csv    a CSV export of Termius hosts
+  mobaxterm-ini  MobaXterm.ini or a .mxtsessions export of MobaXterm bookmarks
+  csv            a CSV file with a header line, such as the output of
+                 "sshm list --format csv"; lists are separated by ";"
+
+The hosts are shown before anything is written. Hosts whose name is already
+used can be skipped, renamed, overwritten, the existing host then being
+removed from its file, or updated where they are with the imported values and
+tags. --on-conflict picks the action to start from: update for Ansible
+inventories, skip for the other formats.
+--yes writes without the preview, and is required when sshm is not run from a
+terminal. Every file involved is backed up and written as a single change.
+
+` + hostExitCodes + `
+
+Examples:
+  sshm import --from putty-reg putty.reg
+  sshm import --from ansible inventory.ini --yes             # Create or update hosts
+  sshm import --from mobaxterm-ini MobaXterm.ini --folders tag
+  sshm import --from termius-csv hosts.csv --file ~/.ssh/config.d/termius.conf
+  sshm import --from csv hosts.csv --dry-run                 # Only show the hosts
+  sshm import --from csv hosts.csv --on-conflict rename --yes`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runImport(args[0]))
+	},
+}
+
+// runImport imports the hosts of an export and returns the exit code
+func runImport(path string) int {
+	folders, err := importer.ParseFolderMode(importFolders)
+	if err != nil {
+		return usageError("%v", err)
+	}
+	conflictAction := importOnConflict
+	if conflictAction == "" {
+		// Inventories describe hosts that are kept in sync with them
+		conflictAction = "skip"
+		if importFrom == "ansible" {
+			conflictAction = "update"
+		}
+	}
+	onConflict, err := importer.ParseConflictAction(conflictAction)
+	if err != nil {
+		return usageError("%v", err)
+	}
+
+	var data []byte
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", path, err)
+		return hostExitFailure
+	}
+
+	result, err := importer.Parse(importFrom, data, importer.Options{Folders: folders})
+	if err != nil {
+		return usageError("%v", err)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	if len(result.Hosts) == 0 {
+		fmt.Printf("No hosts found in %s\n", path)
+		return hostExitOK
+	}
+
+	target := configFile
+	if importFile != "" {
+		if target, err = resolveTargetFile(importFile); err != nil {
+			return usageError("%v", err)
+		}
+	} else if target == "" {
+		if target, err = config.GetDefaultSSHConfigPath(); err != nil {
+			return hostExitCode("importing hosts", err)
+		}
+	}
+
+	parsed, err := config.ParseConfigLayers(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
+		return hostExitFailure
+	}
+	candidates := importer.Resolve(result.Hosts, parsed.Hosts, onConflict)
+
+	if importDryRun {
+		printImportPreview(os.Stdout, candidates)
+		return hostExitOK
+	}
+
+	if importYes {
+		printImportPreview(os.Stdout, candidates)
+	} else {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return usageError("not running in a terminal, use --yes to import without the preview")
+		}
+		var confirmed bool
+		if candidates, confirmed, err = ui.RunImportPreview(candidates, parsed.Hosts, target); err != nil {
+			return hostExitCode("importing hosts", err)
+		}
+		if !confirmed {
+			fmt.Println("Cancelled.")
+			return hostExitOK
+		}
+	}
+
+	written, err := importer.Write(candidates, target)
+	if err != nil {
+		return hostExitCode("importing hosts", err)
+	}
+	fmt.Printf("Imported %d host(s) into %s\n", written, target)
+	return hostExitOK
+}
+
+// printImportPreview lists what an import does with each host
+func printImportPreview(w io.Writer, candidates []importer.Candidate) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range candidates {
+		name := c.Host.Name
+		if c.Name != c.Host.Name {
+			name += " -> " + c.Name
+		}
+		var conflict string
+		if c.Conflict() {
+			conflict = "exists in " + shortenHome(c.Existing.SourceFile)
+		}
+		fmt.Fprintf(tw, "  [%s]\t%s\t%s\t%s\n", c.Action, name, importer.Endpoint(c.Host), conflict)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\n%s\n", importer.Summary(candidates))
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importFrom, "from", "", "Format of the export ("+strings.Join(importer.Formats(), ", ")+")")
+	importCmd.Flags().StringVar(&importFile, "file", "", "Config file to add the hosts to, among the included files")
+	importCmd.Flags().StringVar(&importOnConflict, "on-conflict", "", "Action for hosts that already exist (skip, rename, overwrite, update)")
+	importCmd.Flags().StringVar(&importFolders, "folders", "group", "Turn folders into a group or into tags (group, tag)")
+	importCmd.Flags().BoolVarP(&importYes, "yes", "y", false, "Import without the preview")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Only show the hosts that would be imported")
+	importCmd.MarkFlagRequired("from")
+	RootCmd.AddCommand(importCmd)
+}
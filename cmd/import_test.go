@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+func TestImportCommand(t *testing.T) {
+	path := setupHostCommandConfig(t, "Host web\n    HostName 10.0.9.9\n")
+	export := filepath.Join(t.TempDir(), "hosts.csv")
+	data := "name,hostname,user,group\nweb,10.0.0.1,deploy,prod\napi,10.0.0.2,,prod\n"
+	if err := os.WriteFile(export, []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write export: %v", err)
+	}
+
+	importFrom, importOnConflict, importFolders = "csv", "rename", "group"
+	importYes = true
+	defer func() { importFrom, importOnConflict, importFolders, importYes = "", "", "group", false }()
+
+	if code := runImport(export); code != hostExitOK {
+		t.Fatalf("runImport() = %d", code)
+	}
+	renamed, err := config.GetSSHHostFromFile("web-2", path)
+	if err != nil || renamed.Hostname != "10.0.0.1" || renamed.User != "deploy" || renamed.Group != "prod" {
+		t.Errorf("web-2 = %+v, %v, want the imported web renamed", renamed, err)
+	}
+	if original, err := config.GetSSHHostFromFile("web", path); err != nil || original.Hostname != "10.0.9.9" {
+		t.Errorf("web = %+v, %v, want it untouched", original, err)
+	}
+
+	importFrom = "securecrt"
+	if code := runImport(export); code != hostExitUsage {
+		t.Errorf("runImport(unknown format) = %d, want %d", code, hostExitUsage)
+	}
+}
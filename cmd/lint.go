@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of the lint command, meant for CI and pre-commit hooks
+const (
+	lintExitClean    = 0 // No finding, or only warnings without --strict
+	lintExitFindings = 1 // Errors, or warnings with --strict
+	lintExitFailure  = 2 // The config could not be checked
+)
+
+var (
+	lintFormat string
+	lintFix    bool
+	lintStrict bool
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check the SSH config and its includes for mistakes",
+	Long: `Check the SSH config and every file it includes for mistakes, reported
+with the file and line they appear on:
+
+  duplicate-host        a host defined more than once (ssh uses the first one)
+  shadowed              a directive ignored because an earlier block sets it
+  unknown-keyword       a keyword ssh doesn't know, with a suggested spelling
+  invalid-value         a value that doesn't suit its keyword, such as a bad port
+  identity-missing      an IdentityFile that doesn't exist
+  identity-permissions  an IdentityFile readable by other users
+  proxyjump-unknown     a ProxyJump target that is not a defined host
+  proxyjump-cycle       hosts that jump through each other
+  include-no-match      an Include that matches no file
+  include-unreadable    an included file that can't be read
+  byte-order-mark       a file starting with a UTF-8 byte order mark
+
+With --fix, misspelled keywords are corrected, byte order marks removed and
+identity files made private; config files are backed up first.
+
+Exit codes: 0 when there is no error (warnings only fail with --strict),
+1 when there are findings, 2 when the config could not be checked.
+
+Examples:
+  sshm lint                   # Check ~/.ssh/config and its includes
+  sshm lint --format json     # Machine-readable output
+  sshm lint --fix             # Apply safe automatic repairs
+  sshm lint --strict          # Fail on warnings too, for CI`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runLint())
+	},
+}
+
+// runLint checks the config, prints the findings and returns the exit code
+func runLint() int {
+	if lintFormat != "text" && lintFormat != "json" {
+		fmt.Fprintf(os.Stderr, "Error: unsupported format '%s' (use text or json)\n", lintFormat)
+		return lintExitFailure
+	}
+
+	findings, err := config.LintConfig(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error checking SSH config: %v\n", err)
+		return lintExitFailure
+	}
+
+	if lintFix {
+		fixed, err := config.FixLintFindings(findings)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fixing SSH config: %v\n", err)
+			return lintExitFailure
+		}
+		if fixed > 0 {
+			fmt.Fprintf(os.Stderr, "Fixed %d problem(s)\n", fixed)
+			if findings, err = config.LintConfig(configFile); err != nil {
+				fmt.Fprintf(os.Stderr, "Error checking SSH config: %v\n", err)
+				return lintExitFailure
+			}
+		}
+	}
+
+	if lintFormat == "json" {
+		if findings == nil {
+			findings = []config.LintFinding{}
+		}
+		data, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding findings: %v\n", err)
+			return lintExitFailure
+		}
+		fmt.Println(string(data))
+	} else {
+		outputLintText(findings)
+	}
+
+	return lintExitCode(findings, lintStrict)
+}
+
+// outputLintText prints one finding per line followed by a summary
+func outputLintText(findings []config.LintFinding) {
+	if len(findings) == 0 {
+		fmt.Println("No problems found.")
+		return
+	}
+
+	errors, fixable := 0, 0
+	for _, finding := range findings {
+		fmt.Println(finding.String())
+		if finding.Severity == config.SeverityError {
+			errors++
+		}
+		if finding.Fixable {
+			fixable++
+		}
+	}
+
+	fmt.Printf("\n%d problem(s): %d error(s), %d warning(s)", len(findings), errors, len(findings)-errors)
+	if fixable > 0 && !lintFix {
+		fmt.Printf(", %d fixable with --fix", fixable)
+	}
+	fmt.Println()
+}
+
+// lintExitCode returns the exit code for a set of findings
+func lintExitCode(findings []config.LintFinding, strict bool) int {
+	for _, finding := range findings {
+		if finding.Severity == config.SeverityError || strict {
+			return lintExitFindings
+		}
+	}
+	return lintExitClean
+}
+
+func init() {
+	lintCmd.Flags().StringVarP(&lintFormat, "format", "f", "text", "Output format (text, json)")
+	lintCmd.Flags().BoolVar(&lintFix, "fix", false, "Apply safe automatic repairs")
+	lintCmd.Flags().BoolVar(&lintStrict, "strict", false, "Exit with an error on warnings too")
+	RootCmd.AddCommand(lintCmd)
+}
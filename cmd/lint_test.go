@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+func TestLintCommand(t *testing.T) {
+	if lintCmd.Use != "lint" {
+		t.Errorf("Expected Use 'lint', got '%s'", lintCmd.Use)
+	}
+	if err := lintCmd.Args(lintCmd, []string{"extra"}); err == nil {
+		t.Error("Expected error for arguments")
+	}
+
+	for _, flag := range []string{"format", "fix", "strict"} {
+		if lintCmd.Flags().Lookup(flag) == nil {
+			t.Errorf("Expected --%s flag to be defined", flag)
+		}
+	}
+
+	found := false
+	for _, cmd := range RootCmd.Commands() {
+		if cmd.Name() == "lint" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Lint command not found in root command")
+	}
+}
+
+func TestLintExitCode(t *testing.T) {
+	warning := config.LintFinding{Severity: config.SeverityWarning}
+	lintError := config.LintFinding{Severity: config.SeverityError}
+
+	tests := []struct {
+		name     string
+		findings []config.LintFinding
+		strict   bool
+		want     int
+	}{
+		{"clean", nil, false, lintExitClean},
+		{"clean strict", nil, true, lintExitClean},
+		{"warnings", []config.LintFinding{warning}, false, lintExitClean},
+		{"warnings strict", []config.LintFinding{warning}, true, lintExitFindings},
+		{"errors", []config.LintFinding{warning, lintError}, false, lintExitFindings},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lintExitCode(tt.findings, tt.strict); got != tt.want {
+				t.Errorf("lintExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// listFormat is the output format of sshm list
+var listFormat string
+
+// csvColumns are the columns of the CSV output of sshm list. Lists are joined
+// with ";"; options and notes are only written in JSON and YAML.
+var csvColumns = []string{
+	"name", "aliases", "hostname", "user", "port", "identity_files", "proxy_jump",
+	"tags", "group", "description", "owner", "environment", "links", "file", "system",
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List SSH hosts",
+	Long: `List the SSH hosts of the config, its includes and the system-wide config.
+
+Formats:
+  table  aligned columns for reading (default)
+  json   an array of hosts
+  yaml   a list of hosts
+  csv    one host per line after a header; lists are joined with ";"
+
+JSON and YAML hosts have the fields name, aliases, hostname, user, port,
+identity_files, proxy_jump, options (a list of key and value), tags, group,
+description, owner, environment, links, notes, file and system. Fields are
+never renamed or removed; lists are empty rather than null.
+
+` + hostExitCodes + `
+
+Examples:
+  sshm list                      # Show the hosts in a table
+  sshm list --format json | jq -r '.[] | select(.tags | index("prod")) | .name'
+  sshm list --format csv > hosts.csv`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runList())
+	},
+}
+
+// runList prints the hosts in the requested format and returns the exit code
+func runList() int {
+	if !isRecordFormat(listFormat) && listFormat != "table" && listFormat != "csv" {
+		return usageError("unsupported format '%s' (use table, json, yaml or csv)", listFormat)
+	}
+
+	parsed, err := config.ParseConfigLayers(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
+		return hostExitFailure
+	}
+	printDiagnostics(parsed.Diagnostics)
+
+	if err := writeHosts(os.Stdout, listFormat, parsed.Hosts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing hosts: %v\n", err)
+		return hostExitFailure
+	}
+	return hostExitOK
+}
+
+// isRecordFormat reports whether a format writes hosts as HostRecord
+func isRecordFormat(format string) bool {
+	return format == "json" || format == "yaml"
+}
+
+// writeHosts writes hosts in one of the formats of sshm list
+func writeHosts(w io.Writer, format string, hosts []config.SSHHost) error {
+	records := make([]config.HostRecord, 0, len(hosts))
+	for _, host := range hosts {
+		records = append(records, config.NewHostRecord(host))
+	}
+
+	switch format {
+	case "json", "yaml":
+		return writeRecords(w, format, records)
+	case "csv":
+		return writeHostsCSV(w, records)
+	default:
+		return writeHostsTable(w, records)
+	}
+}
+
+// writeRecords encodes a value made of HostRecord in JSON or YAML
+func writeRecords(w io.Writer, format string, value interface{}) error {
+	if format == "yaml" {
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// writeHostsCSV writes hosts as CSV with a header line
+func writeHostsCSV(w io.Writer, records []config.HostRecord) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvColumns); err != nil {
+		return err
+	}
+	for _, r := range records {
+		row := []string{
+			r.Name, strings.Join(r.Aliases, ";"), r.Hostname, r.User, r.Port,
+			strings.Join(r.IdentityFiles, ";"), r.ProxyJump, strings.Join(r.Tags, ";"),
+			r.Group, r.Description, r.Owner, r.Environment, strings.Join(r.Links, ";"),
+			r.File, strconv.FormatBool(r.System),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeHostsTable writes hosts in aligned columns
+func writeHostsTable(w io.Writer, records []config.HostRecord) error {
+	if len(records) == 0 {
+		_, err := fmt.Fprintln(w, "No hosts found.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tHOSTNAME\tUSER\tPORT\tTAGS\tFILE")
+	for _, r := range records {
+		file := formatListFile(r)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Name, orDash(r.Hostname), orDash(r.User), orDash(r.Port), orDash(strings.Join(r.Tags, ", ")), file)
+	}
+	return tw.Flush()
+}
+
+// formatListFile shortens the file of a host for the table, marking the
+// hosts of the system-wide config
+func formatListFile(r config.HostRecord) string {
+	file := shortenHome(r.File)
+	if r.System {
+		file += " [system]"
+	}
+	return file
+}
+
+// shortenHome writes paths under the home directory with "~"
+func shortenHome(path string) string {
+	if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(path, home) {
+		return "~" + strings.TrimPrefix(path, home)
+	}
+	return path
+}
+
+// orDash shows empty values as "-" in tables
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func init() {
+	listCmd.Flags().StringVarP(&listFormat, "format", "f", "table", "Output format (table, json, yaml, csv)")
+	RootCmd.AddCommand(listCmd)
+}
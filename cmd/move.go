@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/Gu1llaum-3/sshm/internal/ui"
 
@@ -18,7 +19,8 @@ var moveCmd = &cobra.Command{
 
 		err := ui.RunMoveForm(hostname, configFile)
 		if err != nil {
-			fmt.Printf("Error moving host: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error moving host: %v\n", err)
+			os.Exit(1)
 		}
 	},
 }
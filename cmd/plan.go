@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// planDiff shows the changes of sshm plan as a diff of the config files
+var planDiff bool
+
+// manifestHelp describes manifests in the help of plan and apply
+const manifestHelp = `A manifest is a YAML or JSON file declaring hosts with the fields of
+"sshm list --format yaml": name, aliases, hostname, user, port,
+identity_files, proxy_jump, options, tags, group, description, owner,
+environment, links, notes and file. Only name is required. The optional top
+level name marks the hosts the manifest manages and defaults to the name of
+the manifest file; file is the config file of the hosts that don't name one
+and defaults to the main config. Relative paths are relative to the manifest.
+
+Hosts written by a manifest carry a "# sshm: managed-by: <name>" comment.
+Only those hosts are changed, moved or removed; a declared host that already
+exists without the comment is a conflict, so hand-written hosts are never
+overwritten.`
+
+var planCmd = &cobra.Command{
+	Use:   "plan <manifest>",
+	Short: "Show what applying a host manifest would change",
+	Long: `Compare a host manifest with the SSH config and list the hosts to add,
+change, move to another file and remove. Nothing is written.
+
+` + manifestHelp + `
+
+` + hostExitCodes + `
+
+Examples:
+  sshm plan hosts.yaml         # List the changes
+  sshm plan hosts.yaml --diff  # Show them as a diff of the config files`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runPlan(args[0]))
+	},
+}
+
+// runPlan prints the plan of a manifest and returns the exit code
+func runPlan(path string) int {
+	plan, code := loadPlan(path)
+	if plan == nil {
+		return code
+	}
+
+	printPlan(os.Stdout, plan)
+	if planDiff && !plan.IsEmpty() {
+		diff, err := plan.Diff()
+		if err != nil {
+			return hostExitCode("planning manifest", err)
+		}
+		fmt.Printf("\n%s", diff)
+	}
+	return hostExitOK
+}
+
+// loadPlan reads a manifest and compares it with the config. The plan is nil
+// when it could not be made, with the exit code to return.
+func loadPlan(path string) (*config.Plan, int) {
+	manifest, err := config.LoadManifest(path)
+	if err != nil {
+		return nil, usageError("%v", err)
+	}
+	plan, err := config.PlanManifest(manifest, configFile)
+	if err != nil {
+		return nil, hostExitCode("planning manifest", err)
+	}
+	return plan, hostExitOK
+}
+
+// printPlan lists the steps of a plan with their changes
+func printPlan(w io.Writer, plan *config.Plan) {
+	if plan.IsEmpty() {
+		fmt.Fprintf(w, "No changes: the config matches manifest '%s'.\n", plan.Manifest)
+		return
+	}
+
+	symbols := map[config.PlanAction]string{
+		config.PlanAdd:    "+",
+		config.PlanChange: "~",
+		config.PlanMove:   ">",
+		config.PlanRemove: "-",
+	}
+	for _, step := range plan.Steps {
+		if step.Action == config.PlanMove {
+			fmt.Fprintf(w, "  %s %s (%s -> %s)\n", symbols[step.Action], step.Name, shortenHome(step.From), shortenHome(step.File))
+		} else {
+			fmt.Fprintf(w, "  %s %s (%s)\n", symbols[step.Action], step.Name, shortenHome(step.File))
+		}
+		for _, change := range step.Changes {
+			fmt.Fprintf(w, "      %s\n", change)
+		}
+	}
+	fmt.Fprintf(w, "\nManifest '%s': %d to add, %d to change, %d to move, %d to remove.\n", plan.Manifest,
+		plan.Count(config.PlanAdd), plan.Count(config.PlanChange), plan.Count(config.PlanMove), plan.Count(config.PlanRemove))
+}
+
+func init() {
+	planCmd.Flags().BoolVar(&planDiff, "diff", false, "Show the changes as a diff of the config files")
+	RootCmd.AddCommand(planCmd)
+}
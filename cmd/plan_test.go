@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+func TestPlanAndApplyCommands(t *testing.T) {
+	path := setupHostCommandConfig(t, "Host manual\n    HostName 10.0.9.9\n")
+	manifest := filepath.Join(filepath.Dir(path), "fleet.yaml")
+	if err := os.WriteFile(manifest, []byte("hosts:\n  - name: web1\n    hostname: 10.0.0.1\n"), 0600); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	plan, code := loadPlan(manifest)
+	if code != hostExitOK {
+		t.Fatalf("loadPlan() = %d", code)
+	}
+	var out bytes.Buffer
+	printPlan(&out, plan)
+	if !strings.Contains(out.String(), "  + web1 (") || !strings.Contains(out.String(), "1 to add, 0 to change, 0 to move, 0 to remove") {
+		t.Errorf("printPlan() = %q", out.String())
+	}
+
+	applyYes = true
+	defer func() { applyYes = false }()
+	if code := runApply(manifest); code != hostExitOK {
+		t.Fatalf("runApply() = %d", code)
+	}
+	host, err := config.GetSSHHostFromFile("web1", path)
+	if err != nil || host.Hostname != "10.0.0.1" || host.ManagedBy() != "fleet" {
+		t.Errorf("web1 = %+v, %v, want a host managed by fleet", host, err)
+	}
+
+	if err := os.WriteFile(manifest, []byte("hosts:\n  - name: manual\n"), 0600); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	if code := runPlan(manifest); code != hostExitConflict {
+		t.Errorf("runPlan(hand-written host) = %d, want %d", code, hostExitConflict)
+	}
+	if err := os.WriteFile(manifest, []byte("hosts:\n  - nme: web1\n"), 0600); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	if code := runPlan(manifest); code != hostExitUsage {
+		t.Errorf("runPlan(invalid manifest) = %d, want %d", code, hostExitUsage)
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// rmYes skips the confirmation of sshm rm
+var rmYes bool
+
+var rmCmd = &cobra.Command{
+	Use:   "rm <hostname>...",
+	Short: "Remove SSH hosts",
+	Long: `Remove SSH hosts from the config files they are defined in. Every file
+involved is backed up and written as a single change that "u" in the TUI can
+undo.
+
+The removal is confirmed first; --yes skips the confirmation, and is required
+when sshm is not run from a terminal.
+
+` + hostExitCodes + `
+
+Examples:
+  sshm rm web1               # Remove web1 after a confirmation
+  sshm rm web1 web2 --yes    # Remove two hosts without confirmation`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runRm(args))
+	},
+}
+
+// runRm removes hosts and returns the exit code
+func runRm(names []string) int {
+	var hosts []config.SSHHost
+	for _, name := range names {
+		host, err := findConfigHost(name)
+		if err != nil {
+			return hostExitCode("removing host", err)
+		}
+		hosts = append(hosts, *host)
+	}
+
+	if !rmYes {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return usageError("not running in a terminal, use --yes to remove hosts")
+		}
+		for _, host := range hosts {
+			fmt.Printf("  %s (%s)\n", host.Name, host.SourceFile)
+		}
+		if !confirm(fmt.Sprintf("Remove %d host(s)?", len(hosts))) {
+			fmt.Println("Cancelled.")
+			return hostExitOK
+		}
+	}
+
+	if err := config.DeleteHosts(hosts); err != nil {
+		return hostExitCode("removing host", err)
+	}
+	fmt.Printf("Removed %d host(s)\n", len(hosts))
+	return hostExitOK
+}
+
+func init() {
+	rmCmd.Flags().BoolVarP(&rmYes, "yes", "y", false, "Remove without confirmation")
+	RootCmd.AddCommand(rmCmd)
+}
@@ -55,17 +55,19 @@ Hosts are read from your ~/.ssh/config file by default.`,
 	},
 }
 
-func runInteractiveMode() {
-	// Parse SSH configurations
-	var hosts []config.SSHHost
-	var err error
-
-	if configFile != "" {
-		hosts, err = config.ParseSSHConfigFile(configFile)
-	} else {
-		hosts, err = config.ParseSSHConfig()
+// loadHosts reads the hosts of the SSH config, followed by the read-only hosts
+// of the system-wide config when it is enabled
+func loadHosts() ([]config.SSHHost, error) {
+	parsed, err := config.ParseConfigLayers(configFile)
+	if err != nil {
+		return nil, err
 	}
+	return parsed.Hosts, nil
+}
 
+func runInteractiveMode() {
+	// Parse SSH configurations
+	hosts, err := loadHosts()
 	if err != nil {
 		log.Fatalf("Error reading SSH config file: %v", err)
 	}
@@ -81,11 +83,7 @@ func runInteractiveMode() {
 				fmt.Printf("Error adding host: %v\n", err)
 			}
 			// After adding, try to reload hosts and continue if any exist
-			if configFile != "" {
-				hosts, err = config.ParseSSHConfigFile(configFile)
-			} else {
-				hosts, err = config.ParseSSHConfig()
-			}
+			hosts, err = loadHosts()
 			if err != nil || len(hosts) == 0 {
 				fmt.Println("No hosts available, exiting.")
 				os.Exit(1)
@@ -104,15 +102,7 @@ func runInteractiveMode() {
 
 func connectToHost(hostName string) {
 	// Parse SSH configurations to verify host exists
-	var hosts []config.SSHHost
-	var err error
-
-	if configFile != "" {
-		hosts, err = config.ParseSSHConfigFile(configFile)
-	} else {
-		hosts, err = config.ParseSSHConfig()
-	}
-
+	hosts, err := loadHosts()
 	if err != nil {
 		log.Fatalf("Error reading SSH config file: %v", err)
 	}
@@ -120,7 +110,7 @@ func connectToHost(hostName string) {
 	// Check if host exists
 	var hostFound bool
 	for _, host := range hosts {
-		if host.Name == hostName {
+		if host.HasAlias(hostName) {
 			hostFound = true
 			break
 		}
@@ -22,13 +22,18 @@ var (
 var searchCmd = &cobra.Command{
 	Use:   "search [query]",
 	Short: "Search SSH hosts by name, hostname, or tags",
-	Long: `Search through your SSH hosts configuration by name, hostname, or tags.
-The search is case-insensitive and will match partial strings.
+	Long: `Search through your SSH hosts configuration by name, hostname, tags or
+metadata (description, owner, environment, links and notes).
+The search is case-insensitive and will match partial strings. A query of the
+form keyword=value matches the values a host sets for an ssh_config keyword or
+a metadata entry, such as owner=platform or env=prod.
 
 Examples:
   sshm search web          # Search for hosts containing "web"
   sshm search --tags dev   # Search only in tags for "dev"
   sshm search --names prod # Search only in host names for "prod"
+  sshm search user=deploy  # Search for hosts logging in as "deploy"
+  sshm search env=staging  # Search for hosts of the staging environment
   sshm search --format json server # Output results in JSON format`,
 	Args: cobra.MaximumNArgs(1),
 	Run:  runSearch,
@@ -36,19 +41,13 @@ Examples:
 
 func runSearch(cmd *cobra.Command, args []string) {
 	// Parse SSH configurations
-	var hosts []config.SSHHost
-	var err error
-
-	if configFile != "" {
-		hosts, err = config.ParseSSHConfigFile(configFile)
-	} else {
-		hosts, err = config.ParseSSHConfig()
-	}
-
+	parsed, err := config.ParseConfigLayers(configFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
 		os.Exit(1)
 	}
+	printDiagnostics(parsed.Diagnostics)
+	hosts := parsed.Hosts
 
 	if len(hosts) == 0 {
 		fmt.Println("No SSH hosts found in your configuration file.")
@@ -85,6 +84,14 @@ func runSearch(cmd *cobra.Command, args []string) {
 	}
 }
 
+// printDiagnostics reports problems met while reading the config on stderr,
+// so that they don't mix with the results
+func printDiagnostics(diagnostics []config.Diagnostic) {
+	for _, diagnostic := range diagnostics {
+		fmt.Fprintf(os.Stderr, "%s\n", diagnostic)
+	}
+}
+
 // filterHosts filters hosts according to the search query and options
 func filterHosts(hosts []config.SSHHost, query string, tagsOnly, namesOnly bool) []config.SSHHost {
 	var filtered []config.SSHHost
@@ -96,14 +103,35 @@ func filterHosts(hosts []config.SSHHost, query string, tagsOnly, namesOnly bool)
 	query = strings.ToLower(query)
 
 	for _, host := range hosts {
+		// Queries such as "user=deploy" match the values of a directive
+		if !tagsOnly && !namesOnly {
+			if matched, ok := host.MatchMetadataQuery(query); ok {
+				if matched {
+					filtered = append(filtered, host)
+				}
+				continue
+			}
+			if matched, ok := host.MatchDirectiveQuery(query); ok {
+				if matched {
+					filtered = append(filtered, host)
+				}
+				continue
+			}
+		}
+
 		matched := false
 
 		// Search in names if not tags-only
 		if !tagsOnly {
-			// Check the host name
+			// Check the host name and its aliases
 			if strings.Contains(strings.ToLower(host.Name), query) {
 				matched = true
 			}
+			for _, alias := range host.Aliases {
+				if !matched && strings.Contains(strings.ToLower(alias), query) {
+					matched = true
+				}
+			}
 
 			// Check the hostname if not names-only
 			if !namesOnly && !matched && strings.Contains(strings.ToLower(host.Hostname), query) {
@@ -121,6 +149,11 @@ func filterHosts(hosts []config.SSHHost, query string, tagsOnly, namesOnly bool)
 			}
 		}
 
+		// Search in the metadata if neither option restricts the search
+		if !namesOnly && !tagsOnly && !matched {
+			matched = host.Metadata.Matches(query)
+		}
+
 		if matched {
 			filtered = append(filtered, host)
 		}
@@ -200,12 +233,51 @@ func outputJSON(hosts []config.SSHHost) {
 	for i, host := range hosts {
 		fmt.Printf("  {\n")
 		fmt.Printf("    \"name\": \"%s\",\n", escapeJSON(host.Name))
+		aliases := host.OtherAliases()
+		fmt.Printf("    \"aliases\": [")
+		for j, alias := range aliases {
+			fmt.Printf("\"%s\"", escapeJSON(alias))
+			if j < len(aliases)-1 {
+				fmt.Printf(", ")
+			}
+		}
+		fmt.Printf("],\n")
 		fmt.Printf("    \"hostname\": \"%s\",\n", escapeJSON(host.Hostname))
 		fmt.Printf("    \"user\": \"%s\",\n", escapeJSON(host.User))
 		fmt.Printf("    \"port\": \"%s\",\n", escapeJSON(host.Port))
 		fmt.Printf("    \"identity\": \"%s\",\n", escapeJSON(host.Identity))
+		identities := host.GetAll("IdentityFile")
+		fmt.Printf("    \"identity_files\": [")
+		for j, identity := range identities {
+			fmt.Printf("\"%s\"", escapeJSON(identity))
+			if j < len(identities)-1 {
+				fmt.Printf(", ")
+			}
+		}
+		fmt.Printf("],\n")
 		fmt.Printf("    \"proxy_jump\": \"%s\",\n", escapeJSON(host.ProxyJump))
 		fmt.Printf("    \"options\": \"%s\",\n", escapeJSON(host.Options))
+		fmt.Printf("    \"system\": %t,\n", host.System)
+		fmt.Printf("    \"directives\": [")
+		for j, d := range host.Directives {
+			fmt.Printf("{\"key\": \"%s\", \"value\": \"%s\"}", escapeJSON(config.LookupKeyword(d.Key).Name), escapeJSON(d.Value))
+			if j < len(host.Directives)-1 {
+				fmt.Printf(", ")
+			}
+		}
+		fmt.Printf("],\n")
+		fmt.Printf("    \"description\": \"%s\",\n", escapeJSON(host.Metadata.Description))
+		fmt.Printf("    \"owner\": \"%s\",\n", escapeJSON(host.Metadata.Owner))
+		fmt.Printf("    \"environment\": \"%s\",\n", escapeJSON(host.Metadata.Environment))
+		fmt.Printf("    \"links\": [")
+		for j, link := range host.Metadata.Links {
+			fmt.Printf("\"%s\"", escapeJSON(link))
+			if j < len(host.Metadata.Links)-1 {
+				fmt.Printf(", ")
+			}
+		}
+		fmt.Printf("],\n")
+		fmt.Printf("    \"notes\": \"%s\",\n", escapeJSON(host.Metadata.Notes))
 		fmt.Printf("    \"tags\": [")
 		for j, tag := range host.Tags {
 			fmt.Printf("\"%s\"", escapeJSON(tag))
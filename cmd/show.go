@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// showEffective prints the resolved configuration instead of the host block
+	showEffective bool
+	// showFormat is the output format of the host block (text, json, yaml)
+	showFormat string
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show <hostname>",
+	Short: "Show the configuration of an SSH host",
+	Long: `Show the configuration of an SSH host as written in its Host block.
+
+With --effective, show the configuration ssh would actually use, like "ssh -G",
+including values inherited from wildcard Host blocks, Match blocks and the
+system config, along with the block and file each value comes from. A name
+that is not a host of the config and that no Host or Match block applies to is
+reported as not found.
+
+With --format json or yaml, the host is written with the schema of
+"sshm list --format json".
+
+` + hostExitCodes + `
+
+Examples:
+  sshm show web              # Show the Host block of "web"
+  sshm show --effective web  # Show the resolved configuration of "web"
+  sshm show --format json web`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runShow(args[0]))
+	},
+}
+
+// runShow prints a host and returns the exit code
+func runShow(hostName string) int {
+	if showFormat != "text" && !isRecordFormat(showFormat) {
+		return usageError("unsupported format '%s' (use text, json or yaml)", showFormat)
+	}
+
+	if showEffective {
+		if showFormat != "text" {
+			return usageError("--effective only supports the text format")
+		}
+		effective, err := config.ResolveEffectiveConfig(hostName, configFile)
+		if err != nil {
+			return hostExitCode("resolving configuration", err)
+		}
+		// Only defaults and global values apply to a mistyped name
+		if !effectiveMatched(effective) {
+			if _, err := findConfigHost(hostName); err != nil {
+				return hostExitCode("resolving configuration", err)
+			}
+		}
+		outputEffective(effective)
+		return hostExitOK
+	}
+
+	var host *config.SSHHost
+	var err error
+	if configFile != "" {
+		host, err = config.GetSSHHostFromFile(hostName, configFile)
+	} else {
+		host, err = config.GetSSHHost(hostName)
+	}
+	if err != nil {
+		return hostExitCode("showing host", err)
+	}
+
+	if isRecordFormat(showFormat) {
+		if err := writeRecords(os.Stdout, showFormat, config.NewHostRecord(*host)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing host: %v\n", err)
+			return hostExitFailure
+		}
+		return hostExitOK
+	}
+	outputHost(host)
+	return hostExitOK
+}
+
+// outputHost prints the values written in a host's own block
+func outputHost(host *config.SSHHost) {
+	fields := []struct {
+		label string
+		value string
+	}{
+		{"Host", strings.Join(host.Patterns, " ")},
+		{"HostName", host.Hostname},
+		{"User", host.User},
+		{"Port", host.Port},
+		{"IdentityFile", strings.Join(host.GetAll("IdentityFile"), ", ")},
+		{"ProxyJump", host.ProxyJump},
+		{"Tags", strings.Join(host.Tags, ", ")},
+		{"Description", host.Metadata.Description},
+		{"Owner", host.Metadata.Owner},
+		{"Environment", host.Metadata.Environment},
+		{"Links", strings.Join(host.Metadata.Links, ", ")},
+		{"File", host.SourceFile},
+	}
+	for _, field := range fields {
+		if field.value != "" {
+			fmt.Printf("%-13s %s\n", field.label+":", field.value)
+		}
+	}
+	if host.Metadata.Notes != "" {
+		fmt.Println("Notes:")
+		for _, line := range strings.Split(host.Metadata.Notes, "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+	var options []config.Directive
+	for _, d := range host.OtherDirectives() {
+		if !strings.EqualFold(d.Key, "IdentityFile") {
+			options = append(options, d)
+		}
+	}
+	if len(options) > 0 {
+		fmt.Println("Options:")
+		for _, d := range options {
+			fmt.Printf("  %s %s\n", config.LookupKeyword(d.Key).Name, d.Value)
+		}
+	}
+}
+
+// effectiveMatched reports whether a Host or Match block applies to the
+// resolved host, or may apply
+func effectiveMatched(effective *config.EffectiveConfig) bool {
+	if len(effective.Conditional) > 0 {
+		return true
+	}
+	for _, value := range effective.Values {
+		if value.Source != config.SourceDefault && value.Source != config.SourceGlobal {
+			return true
+		}
+	}
+	return false
+}
+
+// outputEffective prints every effective value with the block it comes from
+func outputEffective(effective *config.EffectiveConfig) {
+	keyWidth := 0
+	for _, value := range effective.Values {
+		if len(value.Key) > keyWidth {
+			keyWidth = len(value.Key)
+		}
+	}
+
+	for _, value := range effective.Values {
+		source := value.Source
+		if value.SourceFile != "" {
+			source = fmt.Sprintf("%s (%s:%d)", value.Source, value.SourceFile, value.Line)
+		}
+		fmt.Printf("%-*s %s  # %s\n", keyWidth, strings.ToLower(value.Key), value.Value, source)
+	}
+
+	for _, match := range effective.Conditional {
+		fmt.Printf("# Match %s (%s:%d) %s and was not applied\n",
+			match.Block.Condition, match.Block.SourceFile, match.Block.Line, match.Status)
+	}
+}
+
+func init() {
+	showCmd.Flags().BoolVar(&showEffective, "effective", false, "Show the resolved configuration ssh would use")
+	showCmd.Flags().StringVarP(&showFormat, "format", "f", "text", "Output format (text, json, yaml)")
+	RootCmd.AddCommand(showCmd)
+}
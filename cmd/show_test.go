@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestShowCommand(t *testing.T) {
+	if showCmd.Use != "show <hostname>" {
+		t.Errorf("Expected Use 'show <hostname>', got '%s'", showCmd.Use)
+	}
+
+	// Test that it requires exactly 1 argument
+	if err := showCmd.Args(showCmd, []string{}); err == nil {
+		t.Error("Expected error for no arguments")
+	}
+	if err := showCmd.Args(showCmd, []string{"host"}); err != nil {
+		t.Errorf("Expected no error for 1 argument, got %v", err)
+	}
+
+	if showCmd.Flags().Lookup("effective") == nil {
+		t.Error("Expected --effective flag to be defined")
+	}
+}
+
+func TestShowCommandRegistration(t *testing.T) {
+	found := false
+	for _, cmd := range RootCmd.Commands() {
+		if cmd.Name() == "show" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("Show command not found in root command")
+	}
+}
+
+func TestShowEffectiveExitCodes(t *testing.T) {
+	setupHostCommandConfig(t, "User admin\n\nHost web\n\nHost *.prod\n    Port 2222\n")
+	showEffective = true
+	defer func() { showEffective = false }()
+
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"web", hostExitOK},     // A host of the config, even without values
+		{"db.prod", hostExitOK}, // Matched by a wildcard block
+		{"mistyped", hostExitNotFound},
+	}
+	for _, tt := range tests {
+		if code := runShow(tt.name); code != tt.want {
+			t.Errorf("runShow(%q) = %d, want %d", tt.name, code, tt.want)
+		}
+	}
+}
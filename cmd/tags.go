@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "List, rename, merge and delete host tags",
+	Long: `Manage the tags kept in "# Tags:" comments above Host blocks. Changes are
+applied to every host of the SSH config and the files it includes, backed up
+and written as a single change that "u" in the TUI can undo.
+
+Examples:
+  sshm tags                            # List tags with the number of hosts using them
+  sshm tags rename prod production     # Rename a tag everywhere
+  sshm tags merge prd Prod production  # Merge prd and Prod into production
+  sshm tags delete legacy              # Remove a tag from every host`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTagsList()
+	},
+}
+
+var tagsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List tags with the number of hosts using them",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTagsList()
+	},
+}
+
+var tagsRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a tag on every host",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		changed := renameTagsOrExit(args[:1], args[1])
+		fmt.Printf("Renamed tag '%s' to '%s' on %d host(s)\n", args[0], args[1], changed)
+	},
+}
+
+var tagsMergeCmd = &cobra.Command{
+	Use:   "merge <tag>... <target>",
+	Short: "Merge tags into a target tag on every host",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		sources, target := args[:len(args)-1], args[len(args)-1]
+		changed := renameTagsOrExit(sources, target)
+		fmt.Printf("Merged %d tag(s) into '%s' on %d host(s)\n", len(sources), target, changed)
+	},
+}
+
+var tagsDeleteCmd = &cobra.Command{
+	Use:   "delete <tag>...",
+	Short: "Remove tags from every host",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		changed, err := config.DeleteTags(configFile, args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error deleting tags: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Removed %d tag(s) from %d host(s)\n", len(args), changed)
+	},
+}
+
+// runTagsList prints every tag with the number of hosts using it
+func runTagsList() {
+	parsed, err := config.ParseConfigLayers(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading SSH config file: %v\n", err)
+		os.Exit(1)
+	}
+
+	tags := config.CountTags(parsed.Hosts)
+	if len(tags) == 0 {
+		fmt.Println("No tags found.")
+		return
+	}
+
+	tagWidth := 3 // "Tag"
+	for _, tag := range tags {
+		if len(tag.Tag) > tagWidth {
+			tagWidth = len(tag.Tag)
+		}
+	}
+
+	fmt.Printf("%-*s  %s\n", tagWidth, "Tag", "Hosts")
+	for _, tag := range tags {
+		fmt.Printf("%-*s  %d\n", tagWidth, tag.Tag, tag.Count)
+	}
+}
+
+// renameTagsOrExit renames tags on every host, exiting with an error if the
+// change can't be applied
+func renameTagsOrExit(from []string, to string) int {
+	changed, err := config.RenameTags(configFile, from, to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error renaming tags: %v\n", err)
+		os.Exit(1)
+	}
+	return changed
+}
+
+func init() {
+	tagsCmd.AddCommand(tagsListCmd)
+	tagsCmd.AddCommand(tagsRenameCmd)
+	tagsCmd.AddCommand(tagsMergeCmd)
+	tagsCmd.AddCommand(tagsDeleteCmd)
+	RootCmd.AddCommand(tagsCmd)
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestTagsCommandSubcommands(t *testing.T) {
+	expected := map[string]bool{"list": false, "rename": false, "merge": false, "delete": false}
+	for _, cmd := range tagsCmd.Commands() {
+		if _, ok := expected[cmd.Name()]; ok {
+			expected[cmd.Name()] = true
+		}
+	}
+	for name, found := range expected {
+		if !found {
+			t.Errorf("Expected tags subcommand '%s' not found", name)
+		}
+	}
+}
+
+func TestTagsCommandArgs(t *testing.T) {
+	if err := tagsRenameCmd.Args(tagsRenameCmd, []string{"prod"}); err == nil {
+		t.Error("Expected error for rename without a new name")
+	}
+	if err := tagsMergeCmd.Args(tagsMergeCmd, []string{"prd"}); err == nil {
+		t.Error("Expected error for merge without a target tag")
+	}
+	if err := tagsMergeCmd.Args(tagsMergeCmd, []string{"prd", "Prod", "production"}); err != nil {
+		t.Errorf("Expected merge to accept several tags, got %v", err)
+	}
+	if err := tagsDeleteCmd.Args(tagsDeleteCmd, []string{}); err == nil {
+		t.Error("Expected error for delete without a tag")
+	}
+}
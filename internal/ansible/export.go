@@ -0,0 +1,174 @@
+package ansible
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// invalidGroupChars matches the runs of characters Ansible doesn't accept in
+// group names
+var invalidGroupChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// GroupName turns a tag into an Ansible group name
+func GroupName(tag string) string {
+	return strings.Trim(invalidGroupChars.ReplaceAllString(strings.TrimSpace(tag), "_"), "_")
+}
+
+// HostVars returns the connection variables of a host, with the values of
+// its HostName, User, Port, IdentityFile and ProxyJump
+func HostVars(host config.SSHHost) map[string]string {
+	vars := make(map[string]string)
+	if host.Hostname != "" {
+		vars["ansible_host"] = host.Hostname
+	}
+	if host.User != "" {
+		vars["ansible_user"] = host.User
+	}
+	if host.Port != "" && host.Port != "22" {
+		vars["ansible_port"] = host.Port
+	}
+	if host.Identity != "" {
+		vars["ansible_ssh_private_key_file"] = config.UnquoteArg(host.Identity)
+	}
+	if host.ProxyJump != "" {
+		vars["ansible_ssh_common_args"] = "-o ProxyJump=" + host.ProxyJump
+	}
+	return vars
+}
+
+// exportGroup is a group of an exported inventory with its hosts
+type exportGroup struct {
+	name  string
+	hosts []config.SSHHost
+}
+
+// groupHosts sorts hosts into groups named after their tags. Groups are
+// sorted by name; hosts without tags go into the ungrouped group, last.
+func groupHosts(hosts []config.SSHHost) []exportGroup {
+	byName := make(map[string]*exportGroup)
+	var names []string
+	var ungrouped []config.SSHHost
+	for _, host := range hosts {
+		added := make(map[string]bool)
+		for _, tag := range host.Tags {
+			name := GroupName(tag)
+			if name == "" || name == allGroup || name == ungroupedGroup || added[name] {
+				continue
+			}
+			added[name] = true
+			g, ok := byName[name]
+			if !ok {
+				g = &exportGroup{name: name}
+				byName[name] = g
+				names = append(names, name)
+			}
+			g.hosts = append(g.hosts, host)
+		}
+		if len(added) == 0 {
+			ungrouped = append(ungrouped, host)
+		}
+	}
+
+	sort.Strings(names)
+	groups := make([]exportGroup, 0, len(names)+1)
+	for _, name := range names {
+		groups = append(groups, *byName[name])
+	}
+	if len(ungrouped) > 0 {
+		groups = append(groups, exportGroup{name: ungroupedGroup, hosts: ungrouped})
+	}
+	return groups
+}
+
+// sortedKeys returns the keys of variables, sorted
+func sortedKeys(vars map[string]string) []string {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ExportINI renders hosts as an inventory in the INI format, with a group per
+// tag. The variables of a host are repeated in each of its groups.
+func ExportINI(hosts []config.SSHHost) ([]byte, error) {
+	var b bytes.Buffer
+	for i, g := range groupHosts(hosts) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "[%s]\n", g.name)
+		for _, host := range g.hosts {
+			b.WriteString(host.Name)
+			vars := HostVars(host)
+			for _, key := range sortedKeys(vars) {
+				fmt.Fprintf(&b, " %s=%s", key, quoteINIValue(vars[key]))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// quoteINIValue quotes a host variable that holds spaces or quotes
+func quoteINIValue(value string) string {
+	if !strings.ContainsAny(value, " \t\"'#") {
+		return value
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
+
+// ExportYAML renders hosts as an inventory in the YAML format, with a child
+// group of all per tag
+func ExportYAML(hosts []config.SSHHost) ([]byte, error) {
+	children := &yaml.Node{Kind: yaml.MappingNode}
+	for _, g := range groupHosts(hosts) {
+		members := &yaml.Node{Kind: yaml.MappingNode}
+		for _, host := range g.hosts {
+			vars := &yaml.Node{Kind: yaml.MappingNode}
+			hostVars := HostVars(host)
+			for _, key := range sortedKeys(hostVars) {
+				vars.Content = append(vars.Content, yamlString(key), &yaml.Node{Kind: yaml.ScalarNode, Value: hostVars[key]})
+			}
+			if len(vars.Content) == 0 {
+				vars = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+			}
+			members.Content = append(members.Content, yamlString(host.Name), vars)
+		}
+		group := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{yamlString("hosts"), members}}
+		children.Content = append(children.Content, yamlString(g.name), group)
+	}
+
+	all := &yaml.Node{Kind: yaml.MappingNode}
+	if len(children.Content) > 0 {
+		all.Content = []*yaml.Node{yamlString("children"), children}
+	}
+	root := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{yamlString(allGroup), all}}
+
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// yamlString is a YAML string node, quoted when it would read as another type
+func yamlString(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
@@ -0,0 +1,92 @@
+package ansible
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// exportTestHosts returns hosts with and without tags
+func exportTestHosts() []config.SSHHost {
+	return []config.SSHHost{
+		{Name: "web1", Hostname: "10.0.0.1", User: "deploy", Port: "2222", Identity: `"~/.ssh/my key"`, ProxyJump: "bastion", Tags: []string{"web", "prod eu"}},
+		{Name: "db1", Hostname: "10.0.1.1", Port: "22", Tags: []string{"prod eu"}},
+		{Name: "solo"},
+	}
+}
+
+func TestExportINI(t *testing.T) {
+	data, err := ExportINI(exportTestHosts())
+	if err != nil {
+		t.Fatalf("ExportINI() error = %v", err)
+	}
+	want := `[prod_eu]
+web1 ansible_host=10.0.0.1 ansible_port=2222 ansible_ssh_common_args='-o ProxyJump=bastion' ansible_ssh_private_key_file='~/.ssh/my key' ansible_user=deploy
+db1 ansible_host=10.0.1.1
+
+[web]
+web1 ansible_host=10.0.0.1 ansible_port=2222 ansible_ssh_common_args='-o ProxyJump=bastion' ansible_ssh_private_key_file='~/.ssh/my key' ansible_user=deploy
+
+[ungrouped]
+solo
+`
+	if string(data) != want {
+		t.Errorf("ExportINI() = %q, want %q", data, want)
+	}
+}
+
+func TestExportYAML(t *testing.T) {
+	data, err := ExportYAML(exportTestHosts())
+	if err != nil {
+		t.Fatalf("ExportYAML() error = %v", err)
+	}
+	want := `all:
+  children:
+    prod_eu:
+      hosts:
+        web1:
+          ansible_host: 10.0.0.1
+          ansible_port: 2222
+          ansible_ssh_common_args: -o ProxyJump=bastion
+          ansible_ssh_private_key_file: ~/.ssh/my key
+          ansible_user: deploy
+        db1:
+          ansible_host: 10.0.1.1
+    web:
+      hosts:
+        web1:
+          ansible_host: 10.0.0.1
+          ansible_port: 2222
+          ansible_ssh_common_args: -o ProxyJump=bastion
+          ansible_ssh_private_key_file: ~/.ssh/my key
+          ansible_user: deploy
+    ungrouped:
+      hosts:
+        solo:
+`
+	if string(data) != want {
+		t.Errorf("ExportYAML() = %q, want %q", data, want)
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	for name, export := range map[string]func([]config.SSHHost) ([]byte, error){"ini": ExportINI, "yaml": ExportYAML} {
+		data, err := export(exportTestHosts())
+		if err != nil {
+			t.Fatalf("%s: export error = %v", name, err)
+		}
+		inv, err := ParseInventory(data)
+		if err != nil {
+			t.Fatalf("%s: ParseInventory() error = %v", name, err)
+		}
+		web := inventoryHost(t, inv, "web1")
+		if !reflect.DeepEqual(web.Groups, []string{"prod_eu", "web"}) || web.ProxyJump() != "bastion" ||
+			web.Var("ansible_ssh_private_key_file") != "~/.ssh/my key" || web.Var("ansible_port") != "2222" {
+			t.Errorf("%s: web1 = %+v", name, web)
+		}
+		if solo := inventoryHost(t, inv, "solo"); len(solo.Groups) != 0 {
+			t.Errorf("%s: solo groups = %v, want none", name, solo.Groups)
+		}
+	}
+}
@@ -0,0 +1,493 @@
+// Package ansible reads and writes Ansible inventories, whose groups match
+// the tags of SSH hosts.
+package ansible
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Groups every inventory has, which are not turned into tags
+const (
+	allGroup       = "all"
+	ungroupedGroup = "ungrouped"
+)
+
+// Host is a host of an inventory with the variables that apply to it
+type Host struct {
+	Name string
+	// Groups lists every group the host belongs to, directly or through
+	// children, in the order they appear in the inventory
+	Groups []string
+	// Vars merges the variables of all, of the groups of the host and of the
+	// host itself, the most specific taking precedence. Legacy names such as
+	// ansible_ssh_host are stored under their current one.
+	Vars map[string]string
+}
+
+// Inventory is a parsed Ansible inventory
+type Inventory struct {
+	groups     map[string]*group
+	groupOrder []string
+	hostVars   map[string]map[string]string
+	hostOrder  []string
+}
+
+// group is a group of an inventory
+type group struct {
+	name     string
+	hosts    []string
+	children []string
+	vars     map[string]string
+}
+
+// newInventory creates an empty inventory with the all group
+func newInventory() *Inventory {
+	inv := &Inventory{groups: make(map[string]*group), hostVars: make(map[string]map[string]string)}
+	inv.group(allGroup)
+	return inv
+}
+
+// group returns a group, creating it when needed
+func (inv *Inventory) group(name string) *group {
+	g, ok := inv.groups[name]
+	if !ok {
+		g = &group{name: name, vars: make(map[string]string)}
+		inv.groups[name] = g
+		inv.groupOrder = append(inv.groupOrder, name)
+	}
+	return g
+}
+
+// addHost adds a host to a group, merging its variables
+func (inv *Inventory) addHost(groupName, name string, vars map[string]string) {
+	hostVars, ok := inv.hostVars[name]
+	if !ok {
+		hostVars = make(map[string]string)
+		inv.hostVars[name] = hostVars
+		inv.hostOrder = append(inv.hostOrder, name)
+	}
+	for key, value := range vars {
+		hostVars[key] = value
+	}
+	g := inv.group(groupName)
+	for _, host := range g.hosts {
+		if host == name {
+			return
+		}
+	}
+	g.hosts = append(g.hosts, name)
+}
+
+// ParseInventory reads an inventory in the INI or the YAML format, which is
+// recognized from its content
+func ParseInventory(data []byte) (*Inventory, error) {
+	if isYAMLInventory(data) {
+		return parseYAMLInventory(data)
+	}
+	return parseINIInventory(data)
+}
+
+// isYAMLInventory reports whether an inventory is written in YAML, in which
+// it starts with a document marker or a "group:" key, rather than in INI
+func isYAMLInventory(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		return line == "---" || strings.HasPrefix(line, "{") ||
+			(strings.HasSuffix(line, ":") && !strings.ContainsAny(line, "= "))
+	}
+	return false
+}
+
+// parseINIInventory reads an inventory in the INI format
+func parseINIInventory(data []byte) (*Inventory, error) {
+	inv := newInventory()
+	section, kind := ungroupedGroup, ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section, kind, _ = strings.Cut(line[1:len(line)-1], ":")
+			if kind != "" && kind != "vars" && kind != "children" {
+				return nil, fmt.Errorf("line %d: unknown section type '%s'", number, kind)
+			}
+			inv.group(section)
+			continue
+		}
+
+		fields, err := config.SplitArgs(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+		switch kind {
+		case "vars":
+			key, value, found := strings.Cut(line, "=")
+			if !found {
+				return nil, fmt.Errorf("line %d: expected key=value", number)
+			}
+			inv.group(section).vars[strings.TrimSpace(key)] = unquoteINIValue(strings.TrimSpace(value))
+		case "children":
+			g := inv.group(section)
+			inv.group(fields[0])
+			g.children = append(g.children, fields[0])
+		default:
+			vars := make(map[string]string)
+			for _, field := range fields[1:] {
+				key, value, found := strings.Cut(field, "=")
+				if !found {
+					return nil, fmt.Errorf("line %d: expected key=value, got '%s'", number, field)
+				}
+				vars[key] = value
+			}
+			if err := inv.addHostPattern(section, fields[0], vars); err != nil {
+				return nil, fmt.Errorf("line %d: %w", number, err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// unquoteINIValue removes the quotes around a group variable
+func unquoteINIValue(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// yamlGroup is a group of an inventory in the YAML format
+type yamlGroup struct {
+	Hosts    map[string]map[string]interface{} `yaml:"hosts"`
+	Vars     map[string]interface{}            `yaml:"vars"`
+	Children map[string]*yamlGroup             `yaml:"children"`
+}
+
+// parseYAMLInventory reads an inventory in the YAML format
+func parseYAMLInventory(data []byte) (*Inventory, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid YAML inventory: %w", err)
+	}
+	inv := newInventory()
+	if len(root.Content) == 0 {
+		return inv, nil
+	}
+
+	// Groups are read in order from the nodes, as maps lose it
+	var walk func(name string, node *yaml.Node) error
+	walk = func(name string, node *yaml.Node) error {
+		g := inv.group(name)
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var decoded yamlGroup
+		if err := node.Decode(&decoded); err != nil {
+			return fmt.Errorf("group '%s': %w", name, err)
+		}
+		for key, value := range decoded.Vars {
+			g.vars[key] = yamlScalar(value)
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			section, value := node.Content[i].Value, node.Content[i+1]
+			if value.Kind != yaml.MappingNode {
+				continue
+			}
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				key := value.Content[j].Value
+				switch section {
+				case "hosts":
+					vars := make(map[string]string)
+					for k, v := range decoded.Hosts[key] {
+						vars[k] = yamlScalar(v)
+					}
+					if err := inv.addHostPattern(name, key, vars); err != nil {
+						return err
+					}
+				case "children":
+					g.children = append(g.children, key)
+					if err := walk(key, value.Content[j+1]); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	top := root.Content[0]
+	if top.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("invalid YAML inventory: expected groups at the top level")
+	}
+	for i := 0; i+1 < len(top.Content); i += 2 {
+		if err := walk(top.Content[i].Value, top.Content[i+1]); err != nil {
+			return nil, err
+		}
+	}
+	return inv, nil
+}
+
+// yamlScalar renders a variable value read from YAML as a string
+func yamlScalar(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// hostPortPattern matches a host followed by a port, as in "db:2222"
+var hostPortPattern = regexp.MustCompile(`^(.+):(\d+)$`)
+
+// addHostPattern adds the hosts of a pattern such as "web[01:03]:2222" to a
+// group. The port of the pattern is the ansible_port of the hosts.
+func (inv *Inventory) addHostPattern(groupName, pattern string, vars map[string]string) error {
+	if m := hostPortPattern.FindStringSubmatch(pattern); m != nil && !strings.Contains(m[1], ":") {
+		pattern = m[1]
+		if _, ok := vars["ansible_port"]; !ok {
+			vars["ansible_port"] = m[2]
+		}
+	}
+	names, err := expandHostPattern(pattern)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		inv.addHost(groupName, name, vars)
+	}
+	return nil
+}
+
+// rangePattern matches the first range of a host pattern, such as [01:10],
+// [a:f] or [1:10:2]
+var rangePattern = regexp.MustCompile(`\[([0-9]+|[a-zA-Z]):([0-9]+|[a-zA-Z])(?::([0-9]+))?\]`)
+
+// maxExpandedHosts caps the number of hosts a host pattern expands to, so
+// that a mistyped range can't exhaust memory
+const maxExpandedHosts = 10000
+
+// expandHostPattern expands the numeric and alphabetic ranges of a host
+// pattern. Numeric ranges keep the width of their start, as Ansible does.
+func expandHostPattern(pattern string) ([]string, error) {
+	loc := rangePattern.FindStringSubmatchIndex(pattern)
+	if loc == nil {
+		return []string{pattern}, nil
+	}
+	m := rangePattern.FindStringSubmatch(pattern)
+	prefix, suffix := pattern[:loc[0]], pattern[loc[1]:]
+
+	var start, end int
+	format := "%d"
+	startLetter, endLetter := isLetter(m[1][0]), isLetter(m[2][0])
+	switch {
+	case !startLetter && !endLetter:
+		var errStart, errEnd error
+		start, errStart = strconv.Atoi(m[1])
+		end, errEnd = strconv.Atoi(m[2])
+		if errStart != nil || errEnd != nil {
+			return nil, fmt.Errorf("invalid range in '%s'", pattern)
+		}
+		if len(m[1]) > 1 && m[1][0] == '0' {
+			format = "%0" + strconv.Itoa(len(m[1])) + "d"
+		}
+	case startLetter && endLetter && (m[1][0] >= 'a') == (m[2][0] >= 'a'):
+		// Letters of the same case, so the range holds no punctuation
+		format = "%c"
+		start, end = int(m[1][0]), int(m[2][0])
+	default:
+		return nil, fmt.Errorf("invalid range in '%s'", pattern)
+	}
+	if end < start {
+		return nil, fmt.Errorf("empty range in '%s'", pattern)
+	}
+
+	step := 1
+	if m[3] != "" {
+		var err error
+		step, err = strconv.Atoi(m[3])
+		if err != nil || step < 1 || (end > start && step > end-start) {
+			return nil, fmt.Errorf("invalid range step in '%s'", pattern)
+		}
+	}
+	if (end-start)/step+1 > maxExpandedHosts {
+		return nil, fmt.Errorf("range in '%s' expands to more than %d hosts", pattern, maxExpandedHosts)
+	}
+
+	rest, err := expandHostPattern(suffix)
+	if err != nil {
+		return nil, err
+	}
+	if ((end-start)/step+1)*len(rest) > maxExpandedHosts {
+		return nil, fmt.Errorf("'%s' expands to more than %d hosts", pattern, maxExpandedHosts)
+	}
+
+	var names []string
+	for i := start; i <= end; i += step {
+		item := fmt.Sprintf(format, i)
+		for _, r := range rest {
+			names = append(names, prefix+item+r)
+		}
+	}
+	return names, nil
+}
+
+// isLetter reports whether a range bound is a letter rather than a digit
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// Hosts returns the hosts of the inventory with their groups and variables
+func (inv *Inventory) Hosts() []Host {
+	parents := make(map[string][]string)
+	for _, name := range inv.groupOrder {
+		for _, child := range inv.groups[name].children {
+			parents[child] = append(parents[child], name)
+		}
+	}
+
+	// Depth of each group below all, which orders the precedence of vars
+	depths := make(map[string]int)
+	var depth func(name string, visiting map[string]bool) int
+	depth = func(name string, visiting map[string]bool) int {
+		if d, ok := depths[name]; ok {
+			return d
+		}
+		if name == allGroup || visiting[name] {
+			return 0
+		}
+		visiting[name] = true
+		d := 1
+		for _, parent := range parents[name] {
+			if pd := depth(parent, visiting) + 1; pd > d {
+				d = pd
+			}
+		}
+		delete(visiting, name)
+		depths[name] = d
+		return d
+	}
+
+	var hosts []Host
+	for _, name := range inv.hostOrder {
+		// Groups holding the host, then their ancestors
+		member := make(map[string]bool)
+		var groups []string
+		var add func(g string)
+		add = func(g string) {
+			if member[g] {
+				return
+			}
+			member[g] = true
+			groups = append(groups, g)
+			for _, parent := range parents[g] {
+				add(parent)
+			}
+		}
+		for _, g := range inv.groupOrder {
+			for _, host := range inv.groups[g].hosts {
+				if host == name {
+					add(g)
+				}
+			}
+		}
+
+		ordered := append([]string{allGroup}, groups...)
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return depth(ordered[i], map[string]bool{}) < depth(ordered[j], map[string]bool{})
+		})
+		vars := make(map[string]string)
+		for _, g := range ordered {
+			for key, value := range inv.groups[g].vars {
+				vars[canonicalVar(key)] = value
+			}
+		}
+		for key, value := range inv.hostVars[name] {
+			vars[canonicalVar(key)] = value
+		}
+
+		host := Host{Name: name, Vars: vars}
+		for _, g := range groups {
+			if g != allGroup && g != ungroupedGroup {
+				host.Groups = append(host.Groups, g)
+			}
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// legacyVars maps the names older Ansible releases used for connection
+// variables to the current ones
+var legacyVars = map[string]string{
+	"ansible_ssh_host":         "ansible_host",
+	"ansible_ssh_user":         "ansible_user",
+	"ansible_ssh_port":         "ansible_port",
+	"ansible_private_key_file": "ansible_ssh_private_key_file",
+}
+
+// canonicalVar returns the current name of a variable
+func canonicalVar(name string) string {
+	if current, ok := legacyVars[name]; ok {
+		return current
+	}
+	return name
+}
+
+// Var returns a variable of the host, set under its current or its legacy
+// name
+func (h Host) Var(name string) string {
+	return h.Vars[canonicalVar(name)]
+}
+
+// ProxyJump returns the jump host set with -J or -o ProxyJump= in the extra
+// SSH arguments of the host
+func (h Host) ProxyJump() string {
+	for _, name := range []string{"ansible_ssh_common_args", "ansible_ssh_extra_args"} {
+		args, err := config.SplitArgs(h.Vars[name])
+		if err != nil {
+			continue
+		}
+		for i, arg := range args {
+			var option string
+			switch {
+			case arg == "-J" && i+1 < len(args):
+				return args[i+1]
+			case strings.HasPrefix(arg, "-J"):
+				return arg[2:]
+			case arg == "-o" && i+1 < len(args):
+				option = args[i+1]
+			case strings.HasPrefix(arg, "-o"):
+				option = arg[2:]
+			}
+			key, value, found := strings.Cut(option, "=")
+			if !found {
+				key, value, found = strings.Cut(option, " ")
+			}
+			if found && strings.EqualFold(strings.TrimSpace(key), "ProxyJump") {
+				return strings.TrimSpace(value)
+			}
+		}
+	}
+	return ""
+}
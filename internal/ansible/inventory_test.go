@@ -0,0 +1,174 @@
+package ansible
+
+import (
+	"reflect"
+	"testing"
+)
+
+// inventoryHost returns a host of an inventory by name
+func inventoryHost(t *testing.T, inv *Inventory, name string) Host {
+	t.Helper()
+	for _, host := range inv.Hosts() {
+		if host.Name == name {
+			return host
+		}
+	}
+	t.Fatalf("host %s not found", name)
+	return Host{}
+}
+
+func TestParseINIInventory(t *testing.T) {
+	data := `# Hosts before any section are ungrouped
+jump.example.com ansible_user=admin
+
+[web]
+web[01:02].example.com ansible_user=deploy
+api.example.com:2222 ansible_ssh_common_args='-o ProxyJump=jump.example.com'
+
+[db]
+db1 ansible_ssh_host=10.0.1.1 ansible_ssh_user=postgres
+
+[prod:children]
+web
+db
+
+[prod:vars]
+ansible_user=ops
+ansible_ssh_private_key_file="~/.ssh/prod key"
+
+[all:vars]
+ansible_port=2200
+`
+	inv, err := ParseInventory([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseInventory() error = %v", err)
+	}
+
+	var names []string
+	for _, host := range inv.Hosts() {
+		names = append(names, host.Name)
+	}
+	want := []string{"jump.example.com", "web01.example.com", "web02.example.com", "api.example.com", "db1"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("hosts = %v, want %v", names, want)
+	}
+
+	jump := inventoryHost(t, inv, "jump.example.com")
+	if len(jump.Groups) != 0 || jump.Var("ansible_user") != "admin" || jump.Var("ansible_port") != "2200" {
+		t.Errorf("jump = %+v, want ungrouped with its own user and the port of all", jump)
+	}
+
+	// Host vars win over the vars of prod, which win over those of all
+	web := inventoryHost(t, inv, "web01.example.com")
+	if !reflect.DeepEqual(web.Groups, []string{"web", "prod"}) {
+		t.Errorf("web01 groups = %v, want [web prod]", web.Groups)
+	}
+	if web.Var("ansible_user") != "deploy" || web.Var("ansible_ssh_private_key_file") != "~/.ssh/prod key" {
+		t.Errorf("web01 vars = %v", web.Vars)
+	}
+
+	api := inventoryHost(t, inv, "api.example.com")
+	if api.Var("ansible_port") != "2222" || api.ProxyJump() != "jump.example.com" || api.Var("ansible_user") != "ops" {
+		t.Errorf("api = %+v, want port 2222, jump.example.com and the user of prod", api)
+	}
+
+	db := inventoryHost(t, inv, "db1")
+	if db.Var("ansible_host") != "10.0.1.1" || db.Var("ansible_user") != "postgres" {
+		t.Errorf("db1 vars = %v, want the legacy names read", db.Vars)
+	}
+}
+
+func TestParseYAMLInventory(t *testing.T) {
+	data := `all:
+  vars:
+    ansible_user: root
+  hosts:
+    solo:
+      ansible_host: 10.0.0.9
+  children:
+    prod:
+      vars:
+        ansible_user: ops
+      children:
+        web:
+          vars:
+            ansible_user: deploy
+          hosts:
+            web[a:b]:
+              ansible_port: 2222
+        db:
+          hosts:
+            db1:
+              ansible_ssh_extra_args: -J bastion
+`
+	inv, err := ParseInventory([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseInventory() error = %v", err)
+	}
+
+	solo := inventoryHost(t, inv, "solo")
+	if len(solo.Groups) != 0 || solo.Var("ansible_host") != "10.0.0.9" || solo.Var("ansible_user") != "root" {
+		t.Errorf("solo = %+v", solo)
+	}
+
+	// The deeper web group wins over prod
+	webb := inventoryHost(t, inv, "webb")
+	if !reflect.DeepEqual(webb.Groups, []string{"web", "prod"}) || webb.Var("ansible_user") != "deploy" || webb.Var("ansible_port") != "2222" {
+		t.Errorf("webb = %+v", webb)
+	}
+
+	db := inventoryHost(t, inv, "db1")
+	if !reflect.DeepEqual(db.Groups, []string{"db", "prod"}) || db.Var("ansible_user") != "ops" || db.ProxyJump() != "bastion" {
+		t.Errorf("db1 = %+v", db)
+	}
+}
+
+func TestExpandHostPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"web", []string{"web"}},
+		{"web[1:3]", []string{"web1", "web2", "web3"}},
+		{"web[08:10].lan", []string{"web08.lan", "web09.lan", "web10.lan"}},
+		{"db-[a:c]", []string{"db-a", "db-b", "db-c"}},
+		{"n[0:4:2]", []string{"n0", "n2", "n4"}},
+		{"db-[a:e:2]", []string{"db-a", "db-c", "db-e"}},
+		{"r[1:2]-[a:b]", []string{"r1-a", "r1-b", "r2-a", "r2-b"}},
+	}
+	for _, tt := range tests {
+		got, err := expandHostPattern(tt.pattern)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandHostPattern(%q) = %v, %v, want %v", tt.pattern, got, err, tt.want)
+		}
+	}
+	for _, pattern := range []string{
+		"web[1:a]",
+		"web[A:z]",     // Would include punctuation
+		"web[a:z:256]", // A step the byte counter wrapped on
+		"web[a:z:200]", // A step larger than the range
+		"web[1:10:0]",  // A step that never advances
+		"web[1:10:99999999999999999999]",
+		"web[10:1]",
+		"web[1:99999999]",    // Too many hosts
+		"web[1:200]-[1:200]", // Too many hosts once combined
+		"web[1:99999999999999999999]",
+	} {
+		if got, err := expandHostPattern(pattern); err == nil {
+			t.Errorf("expandHostPattern(%s) = %d hosts, should fail", pattern, len(got))
+		}
+	}
+}
+
+func TestParseInventoryErrors(t *testing.T) {
+	for _, data := range []string{
+		"[web:hosts]\nweb1\n",
+		"[web]\nweb1 ansible_user\n",
+		"[web]\nweb1 ansible_user='deploy\n",
+		"all:\n  hosts: [web1\n",
+	} {
+		if _, err := ParseInventory([]byte(data)); err == nil {
+			t.Errorf("ParseInventory(%q) should fail", data)
+		}
+	}
+}
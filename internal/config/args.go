@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitArgs splits a directive value into arguments the way OpenSSH does.
+// Arguments are separated by spaces or tabs. Double or single quotes group
+// text containing whitespace into one argument and are removed. A backslash
+// escapes a quote, a backslash or, outside quotes, a space; other backslashes
+// are kept, so Windows paths read as written. An unterminated quote is an
+// error, returned with the arguments read so far.
+func SplitArgs(value string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote byte
+	inArg := false
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == '\\' && i+1 < len(value) && isEscapable(value[i+1], quote):
+			i++
+			current.WriteByte(value[i])
+			inArg = true
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteByte(c)
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inArg = true
+		default:
+			current.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	if quote != 0 {
+		return args, fmt.Errorf("unterminated quote in '%s'", value)
+	}
+	return args, nil
+}
+
+// isEscapable reports whether a backslash escapes the character after it
+func isEscapable(c, quote byte) bool {
+	switch c {
+	case '\\', '"', '\'':
+		return true
+	case ' ':
+		return quote == 0
+	}
+	return false
+}
+
+// splitQuotedArgs splits a value into arguments, ignoring an unterminated
+// quote at the end
+func splitQuotedArgs(value string) []string {
+	args, _ := SplitArgs(value)
+	return args
+}
+
+// QuoteArg returns an argument written so that SplitArgs reads it back as a
+// single argument: as is when possible, otherwise in double quotes. A trailing
+// backslash is quoted too, as it would escape a space written after it.
+func QuoteArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'#") && !strings.Contains(arg, `\\`) && !strings.HasSuffix(arg, `\`) {
+		return arg
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg)
+	return `"` + escaped + `"`
+}
+
+// UnquoteArg returns the argument held by a single-argument value, such as an
+// IdentityFile path, or the value as written if it holds several
+func UnquoteArg(value string) string {
+	args, err := SplitArgs(value)
+	if err != nil || len(args) != 1 {
+		return value
+	}
+	return args[0]
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{"a b\tc", []string{"a", "b", "c"}, false},
+		{`"a b.conf" c.conf`, []string{"a b.conf", "c.conf"}, false},
+		{`'single quoted' x`, []string{"single quoted", "x"}, false},
+		{`a\ b`, []string{"a b"}, false},
+		{`"say \"hi\""`, []string{`say "hi"`}, false},
+		{`C:\Users\me\id`, []string{`C:\Users\me\id`}, false},
+		{`pre"fix mid"post`, []string{"prefix midpost"}, false},
+		{`""`, []string{""}, false},
+		{`"open`, []string{"open"}, true},
+	}
+	for _, tt := range tests {
+		got, err := SplitArgs(tt.value)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitArgs(%q) = %q, %v, want %q (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestQuoteArg(t *testing.T) {
+	for _, arg := range []string{"plain", "C:/My Keys/id", `C:\Users\id`, `a"b`, `ends with \`, "#hash", ""} {
+		quoted := QuoteArg(arg)
+		if got := UnquoteArg(quoted); got != arg {
+			t.Errorf("UnquoteArg(QuoteArg(%q)) = %q (quoted as %q)", arg, got, quoted)
+		}
+	}
+	if got := QuoteArg("~/.ssh/id"); got != "~/.ssh/id" {
+		t.Errorf("QuoteArg() quoted a plain path: %q", got)
+	}
+}
+
+func TestIncludeSeveralQuotedFiles(t *testing.T) {
+	path := writeTestConfig(t, "Include \"work hosts.conf\" home.conf\n")
+	dir := filepath.Dir(path)
+	for name, host := range map[string]string{"work hosts.conf": "work", "home.conf": "home"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("Host "+host+"\n"), 0600); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+
+	parsed, err := ParseConfigTree(path)
+	if err != nil {
+		t.Fatalf("ParseConfigTree() error = %v", err)
+	}
+	if len(parsed.Hosts) != 2 || parsed.Hosts[0].Name != "work" || parsed.Hosts[1].Name != "home" {
+		t.Errorf("expected hosts from both included files, got %+v", parsed.Hosts)
+	}
+	if len(parsed.Diagnostics) != 0 {
+		t.Errorf("unexpected diagnostics: %v", parsed.Diagnostics)
+	}
+}
@@ -0,0 +1,565 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LineKind identifies what a physical line of an ssh_config file holds
+type LineKind int
+
+const (
+	LineBlank LineKind = iota
+	LineComment
+	LineDirective
+)
+
+// ConfigLine is a single physical line of an ssh_config file.
+// Lines that are not modified are written back exactly as they were read.
+type ConfigLine struct {
+	Kind LineKind
+
+	Indent   string // Leading whitespace
+	Key      string // Keyword as written (e.g. "HostName"), directives only
+	Sep      string // Separator between keyword and value as written (" ", "=", " = ")
+	Value    string // Value as written, without the trailing comment
+	Trailing string // Trailing whitespace and comment after the value
+	Comment  string // Comment text starting with '#', comment lines only
+	Number   int    // 1-based line number in the file as read, 0 for new lines
+
+	raw   string // Original text without the line terminator
+	eol   string // Line terminator as read, empty for new lines
+	dirty bool   // Set when the line must be re-rendered from its fields
+}
+
+// ConfigBlock is a Host or Match section, or the global section at the top of a file
+type ConfigBlock struct {
+	Leading []*ConfigLine // Comment lines directly above the header (e.g. "# Tags:")
+	Header  *ConfigLine   // Host or Match line, nil for the global section
+	Body    []*ConfigLine // Everything up to the next block, including comments and blank lines
+}
+
+// ConfigFile is a lossless syntax tree of a single ssh_config file.
+// Includes are not followed; each file is its own tree.
+type ConfigFile struct {
+	Path   string
+	Blocks []*ConfigBlock // Blocks[0] is always the global section
+
+	finalNewline bool
+	crlf         bool // New lines end with CRLF, as most lines of the file do
+	bom          bool // The file starts with a UTF-8 byte order mark
+}
+
+// utf8BOM is the byte order mark some Windows editors write at the start of
+// UTF-8 files
+const utf8BOM = "\ufeff"
+
+// LoadConfigFile reads and parses a config file into a syntax tree, and
+// records its version to start a change. Reads that don't lead to a write
+// use readConfigFile without recording, so that outside edits still show.
+// A missing file yields an empty tree so that it can be created on save.
+func LoadConfigFile(path string) (*ConfigFile, error) {
+	return readConfigFile(path, true)
+}
+
+// readConfigFile reads and parses a config file. When record is set, the
+// content is remembered to detect changes made on disk before the next write.
+func readConfigFile(path string, record bool) (*ConfigFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if record {
+		recordVersion(path, data)
+	}
+	return ParseConfigBytes(path, data), nil
+}
+
+// loadConfigFileForUpdate reads a config file that is about to be changed,
+// failing with ErrConfigChanged if it changed since sshm last read it
+func loadConfigFileForUpdate(path string) (*ConfigFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := checkVersion(path, data); err != nil {
+		return nil, err
+	}
+	return ParseConfigBytes(path, data), nil
+}
+
+// ParseConfigBytes parses the content of an ssh_config file into a syntax tree
+func ParseConfigBytes(path string, data []byte) *ConfigFile {
+	cfg := &ConfigFile{
+		Path:   path,
+		Blocks: []*ConfigBlock{{}},
+	}
+
+	content := string(data)
+	if strings.HasPrefix(content, utf8BOM) {
+		content = content[len(utf8BOM):]
+		cfg.bom = true
+	}
+	if content == "" {
+		return cfg
+	}
+
+	rawLines := strings.SplitAfter(content, "\n")
+	if rawLines[len(rawLines)-1] == "" {
+		rawLines = rawLines[:len(rawLines)-1]
+		cfg.finalNewline = true
+	}
+
+	crlfLines := 0
+	for _, raw := range rawLines {
+		if strings.HasSuffix(raw, "\r\n") {
+			crlfLines++
+		}
+	}
+	cfg.crlf = crlfLines*2 > len(rawLines)
+
+	current := cfg.Blocks[0]
+	for i, raw := range rawLines {
+		text := strings.TrimSuffix(strings.TrimSuffix(raw, "\n"), "\r")
+		line := parseConfigLine(text)
+		line.eol = raw[len(text):]
+		line.Number = i + 1
+
+		if line.Kind == LineDirective && isBlockKeyword(line.Key) {
+			block := &ConfigBlock{Header: line}
+			block.Leading = detachLeadingComments(current, line.Indent)
+			cfg.Blocks = append(cfg.Blocks, block)
+			current = block
+			continue
+		}
+
+		current.Body = append(current.Body, line)
+	}
+
+	return cfg
+}
+
+// parseConfigLine splits a raw line into its components
+func parseConfigLine(raw string) *ConfigLine {
+	line := &ConfigLine{raw: raw}
+
+	rest := strings.TrimLeft(raw, " \t")
+	line.Indent = raw[:len(raw)-len(rest)]
+
+	switch {
+	case strings.TrimSpace(rest) == "":
+		line.Kind = LineBlank
+		return line
+	case strings.HasPrefix(rest, "#"):
+		line.Kind = LineComment
+		line.Comment = rest
+		return line
+	}
+
+	line.Kind = LineDirective
+
+	// Keyword ends at the first whitespace or '='
+	end := strings.IndexAny(rest, " \t=")
+	if end < 0 {
+		line.Key = rest
+		return line
+	}
+	line.Key = rest[:end]
+	rest = rest[end:]
+
+	// Separator: whitespace, at most one '=', whitespace
+	sepLen := len(rest) - len(strings.TrimLeft(rest, " \t"))
+	if sepLen < len(rest) && rest[sepLen] == '=' {
+		sepLen++
+		sepLen += len(rest[sepLen:]) - len(strings.TrimLeft(rest[sepLen:], " \t"))
+	}
+	line.Sep = rest[:sepLen]
+	rest = rest[sepLen:]
+
+	line.Value, line.Trailing = splitTrailingComment(rest)
+	return line
+}
+
+// splitTrailingComment separates a value from trailing whitespace and an
+// end-of-line comment. A '#' only starts a comment at the start of an
+// argument: after unescaped whitespace and outside quotes.
+func splitTrailingComment(s string) (string, string) {
+	var quote byte
+	afterSpace := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isEscapable(s[i+1], quote):
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == ' ' || c == '\t':
+			afterSpace = true
+			continue
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && afterSpace:
+			value := strings.TrimRight(s[:i], " \t")
+			return value, s[len(value):]
+		}
+		afterSpace = false
+	}
+	value := strings.TrimRight(s, " \t")
+	return value, s[len(value):]
+}
+
+// isBlockKeyword reports whether a keyword starts a new section
+func isBlockKeyword(key string) bool {
+	switch strings.ToLower(key) {
+	case "host", "match":
+		return true
+	}
+	return false
+}
+
+// detachLeadingComments moves the comment lines sitting directly above a new
+// header out of the previous block, so that they travel with the header.
+// Comments indented deeper than the header stay with the previous block.
+func detachLeadingComments(prev *ConfigBlock, headerIndent string) []*ConfigLine {
+	start := len(prev.Body)
+	for start > 0 {
+		line := prev.Body[start-1]
+		if line.Kind != LineComment || len(line.Indent) > len(headerIndent) {
+			break
+		}
+		start--
+	}
+
+	leading := append([]*ConfigLine(nil), prev.Body[start:]...)
+	prev.Body = prev.Body[:start]
+	return leading
+}
+
+// String renders the line, reusing the original text when it was not modified
+func (l *ConfigLine) String() string {
+	if !l.dirty {
+		return l.raw
+	}
+	switch l.Kind {
+	case LineComment:
+		return l.Indent + l.Comment
+	case LineDirective:
+		return l.Indent + l.Key + l.Sep + l.Value + l.Trailing
+	default:
+		return l.Indent
+	}
+}
+
+// SetValue changes the value of a directive, keeping keyword casing,
+// indentation, separator and trailing comment
+func (l *ConfigLine) SetValue(value string) {
+	if l.Value == value {
+		return
+	}
+	l.Value = value
+	l.dirty = true
+}
+
+// SetKey changes the keyword of a directive, keeping everything else
+func (l *ConfigLine) SetKey(key string) {
+	if l.Key == key {
+		return
+	}
+	l.Key = key
+	l.dirty = true
+}
+
+// SetComment changes the text of a comment line
+func (l *ConfigLine) SetComment(comment string) {
+	if l.Comment == comment {
+		return
+	}
+	l.Comment = comment
+	l.dirty = true
+}
+
+// newDirectiveLine creates a directive line in the style written by sshm
+func newDirectiveLine(indent, key, value string) *ConfigLine {
+	return &ConfigLine{Kind: LineDirective, Indent: indent, Key: key, Sep: " ", Value: value, dirty: true}
+}
+
+// newCommentLine creates a comment line
+func newCommentLine(indent, comment string) *ConfigLine {
+	return &ConfigLine{Kind: LineComment, Indent: indent, Comment: comment, dirty: true}
+}
+
+// newBlankLine creates an empty line
+func newBlankLine() *ConfigLine {
+	return &ConfigLine{Kind: LineBlank, dirty: true}
+}
+
+// Lines returns every line of the block in file order
+func (b *ConfigBlock) Lines() []*ConfigLine {
+	lines := make([]*ConfigLine, 0, len(b.Leading)+len(b.Body)+1)
+	lines = append(lines, b.Leading...)
+	if b.Header != nil {
+		lines = append(lines, b.Header)
+	}
+	return append(lines, b.Body...)
+}
+
+// IsHost reports whether the block is a Host section
+func (b *ConfigBlock) IsHost() bool {
+	return b.Header != nil && strings.EqualFold(b.Header.Key, "host")
+}
+
+// IsMatch reports whether the block is a Match section
+func (b *ConfigBlock) IsMatch() bool {
+	return b.Header != nil && strings.EqualFold(b.Header.Key, "match")
+}
+
+// Patterns returns the patterns listed on a Host line
+func (b *ConfigBlock) Patterns() []string {
+	if !b.IsHost() {
+		return nil
+	}
+	return SplitHostPatterns(b.Header.Value)
+}
+
+// Directives returns the directive lines in the block body
+func (b *ConfigBlock) Directives() []*ConfigLine {
+	var directives []*ConfigLine
+	for _, line := range b.Body {
+		if line.Kind == LineDirective {
+			directives = append(directives, line)
+		}
+	}
+	return directives
+}
+
+// Tags returns the tags declared in "# Tags:" comments above the header
+func (b *ConfigBlock) Tags() []string {
+	var tags []string
+	for _, line := range b.Leading {
+		if tagLine, ok := parseTagsComment(line.Comment); ok {
+			tags = append(tags, tagLine...)
+		}
+	}
+	return tags
+}
+
+// parseTagsComment extracts tags from a "# Tags:" comment
+func parseTagsComment(comment string) ([]string, bool) {
+	if !strings.HasPrefix(comment, "# Tags:") {
+		return nil, false
+	}
+
+	var tags []string
+	for _, tag := range strings.Split(strings.TrimPrefix(comment, "# Tags:"), ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, true
+}
+
+// formatTagsComment renders tags as a "# Tags:" comment
+func formatTagsComment(tags []string) string {
+	return "# Tags: " + strings.Join(tags, ", ")
+}
+
+// Group returns the group path declared in a "# Group:" comment above the
+// header, or "" if there is none
+func (b *ConfigBlock) Group() string {
+	for _, line := range b.Leading {
+		if group, ok := parseGroupComment(line.Comment); ok {
+			return group
+		}
+	}
+	return ""
+}
+
+// parseGroupComment extracts the group path from a "# Group:" comment
+func parseGroupComment(comment string) (string, bool) {
+	if !strings.HasPrefix(comment, "# Group:") {
+		return "", false
+	}
+	return NormalizeGroupPath(strings.TrimPrefix(comment, "# Group:")), true
+}
+
+// formatGroupComment renders a group path as a "# Group:" comment
+func formatGroupComment(group string) string {
+	return "# Group: " + group
+}
+
+// NormalizeGroupPath cleans a slash-separated group path such as
+// "prod / eu/db/", dropping empty segments and the spaces around them
+func NormalizeGroupPath(group string) string {
+	var segments []string
+	for _, segment := range strings.Split(group, "/") {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// bodyIndent returns the indentation used by directives in the block,
+// falling back to the four spaces sshm writes by default
+func (b *ConfigBlock) bodyIndent() string {
+	for _, line := range b.Body {
+		if line.Kind == LineDirective && line.Indent != "" {
+			return line.Indent
+		}
+	}
+	return "    "
+}
+
+// lastDirectiveIndex returns the position after which new directives should be
+// inserted: after the last directive, before trailing comments and blank lines
+func (b *ConfigBlock) lastDirectiveIndex() int {
+	for i := len(b.Body) - 1; i >= 0; i-- {
+		if b.Body[i].Kind == LineDirective {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertBody inserts a line into the body at the given position
+func (b *ConfigBlock) insertBody(pos int, line *ConfigLine) {
+	b.Body = append(b.Body, nil)
+	copy(b.Body[pos+1:], b.Body[pos:])
+	b.Body[pos] = line
+}
+
+// removeBody removes a line from the body
+func (b *ConfigBlock) removeBody(line *ConfigLine) {
+	for i, l := range b.Body {
+		if l == line {
+			b.Body = append(b.Body[:i], b.Body[i+1:]...)
+			return
+		}
+	}
+}
+
+// FindHostBlock returns the first Host block declaring the given name as a
+// concrete alias, or nil
+func (f *ConfigFile) FindHostBlock(name string) *ConfigBlock {
+	for _, block := range f.Blocks {
+		if !block.IsHost() {
+			continue
+		}
+		for _, alias := range ConcreteAliases(block.Patterns()) {
+			if alias == name {
+				return block
+			}
+		}
+	}
+	return nil
+}
+
+// FindPatternBlock returns the Host block made only of wildcards and negations
+// whose patterns are exactly the given ones, or nil
+func (f *ConfigFile) FindPatternBlock(pattern string) *ConfigBlock {
+	want := strings.Join(SplitHostPatterns(pattern), " ")
+	for _, block := range f.Blocks {
+		if !block.IsHost() || len(ConcreteAliases(block.Patterns())) > 0 {
+			continue
+		}
+		if strings.Join(block.Patterns(), " ") == want {
+			return block
+		}
+	}
+	return nil
+}
+
+// AppendBlock adds a block at the end of the file, separated by a blank line.
+// Lines moved from another file take the line endings of this one.
+func (f *ConfigFile) AppendBlock(block *ConfigBlock) {
+	for _, line := range block.Lines() {
+		line.eol = ""
+	}
+
+	last := f.Blocks[len(f.Blocks)-1]
+	lines := last.Lines()
+	if len(lines) > 0 && lines[len(lines)-1].Kind != LineBlank {
+		last.Body = append(last.Body, newBlankLine())
+	}
+	for len(block.Body) > 0 && block.Body[len(block.Body)-1].Kind == LineBlank {
+		block.Body = block.Body[:len(block.Body)-1]
+	}
+
+	f.Blocks = append(f.Blocks, block)
+	f.finalNewline = true
+}
+
+// RemoveBlock removes a block together with its leading comments
+func (f *ConfigFile) RemoveBlock(block *ConfigBlock) {
+	for i, b := range f.Blocks {
+		if b != block || i == 0 {
+			continue
+		}
+		f.Blocks = append(f.Blocks[:i], f.Blocks[i+1:]...)
+
+		// Avoid leaving blank lines dangling at the end of the file
+		if i == len(f.Blocks) {
+			last := f.Blocks[len(f.Blocks)-1]
+			for len(last.Body) > 0 && last.Body[len(last.Body)-1].Kind == LineBlank {
+				last.Body = last.Body[:len(last.Body)-1]
+			}
+		}
+		return
+	}
+}
+
+// Bytes renders the syntax tree back to file content. Lines keep the line
+// endings they were read with, new lines use the ones most of the file uses.
+func (f *ConfigFile) Bytes() []byte {
+	var lines []*ConfigLine
+	for _, block := range f.Blocks {
+		lines = append(lines, block.Lines()...)
+	}
+
+	var b strings.Builder
+	if f.bom {
+		b.WriteString(utf8BOM)
+	}
+	for i, line := range lines {
+		b.WriteString(line.String())
+		switch {
+		case i < len(lines)-1 || f.finalNewline:
+			b.WriteString(f.lineEnding(line))
+		case strings.HasSuffix(line.eol, "\r"):
+			// Last line ending with a lone carriage return
+			b.WriteString(line.eol)
+		}
+	}
+	return []byte(b.String())
+}
+
+// lineEnding returns the terminator written after a line
+func (f *ConfigFile) lineEnding(line *ConfigLine) string {
+	if strings.HasSuffix(line.eol, "\n") {
+		return line.eol
+	}
+	return f.newline()
+}
+
+// newline returns the line terminator most lines of the file use
+func (f *ConfigFile) newline() string {
+	if f.crlf {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// WriteFile writes the syntax tree back to its file atomically, keeping the
+// mode of an existing file and the symlink it may be reached through
+func (f *ConfigFile) WriteFile() error {
+	data := f.Bytes()
+	if err := writeFileAtomic(f.Path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.Path, err)
+	}
+	recordVersion(f.Path, data)
+	return nil
+}
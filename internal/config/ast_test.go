@@ -0,0 +1,328 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConfigBytesRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty", ""},
+		{"no trailing newline", "Host a\n    HostName a.example.com"},
+		{"comments and blanks", "# global\n\nHost a\n  # inline comment\n  HostName a.example.com\n\n\n"},
+		{"key=value forms", "Host a\n\tPort=2222\n\tUser = admin\n\tHostName\ta.example.com\n"},
+		{"trailing comments", "Host a # main box\n    User root   # admin user\n"},
+		{"adjacent blocks", "Host a\n    HostName a\nHost b\n    HostName b\n"},
+		{"match blocks", "Host a\n    HostName a\nMatch host a exec \"true\"\n    User matched\n"},
+		{"mixed casing", "HOST a\n    hostname a\n    USER root\n"},
+		{"crlf", "Host a\r\n    HostName a\r\n"},
+		{"mixed line endings", "Host a\r\n    HostName a\n    User root\r\n"},
+		{"byte order mark", "\ufeffHost a\r\n    HostName a\r\n"},
+		{"quotes and escapes", "Host a\n    IdentityFile \"C:/My Keys/id\" # quoted\n    RemoteCommand echo \\\"#not a comment\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := ParseConfigBytes("config", []byte(tt.content))
+			if got := string(cfg.Bytes()); got != tt.content {
+				t.Errorf("round trip mismatch:\ngot:  %q\nwant: %q", got, tt.content)
+			}
+		})
+	}
+}
+
+func TestParseConfigLine(t *testing.T) {
+	tests := []struct {
+		raw      string
+		kind     LineKind
+		key      string
+		sep      string
+		value    string
+		trailing string
+	}{
+		{"", LineBlank, "", "", "", ""},
+		{"   ", LineBlank, "", "", "", ""},
+		{"# comment", LineComment, "", "", "", ""},
+		{"    HostName example.com", LineDirective, "HostName", " ", "example.com", ""},
+		{"Port=2222", LineDirective, "Port", "=", "2222", ""},
+		{"User = admin", LineDirective, "User", " = ", "admin", ""},
+		{"User admin  # the admin", LineDirective, "User", " ", "admin", "  # the admin"},
+		{"LocalCommand echo a#b", LineDirective, "LocalCommand", " ", "echo a#b", ""},
+		{`IdentityFile "a #b"`, LineDirective, "IdentityFile", " ", `"a #b"`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			line := parseConfigLine(tt.raw)
+			if line.Kind != tt.kind || line.Key != tt.key || line.Sep != tt.sep || line.Value != tt.value || line.Trailing != tt.trailing {
+				t.Errorf("parseConfigLine(%q) = {%v %q %q %q %q}, want {%v %q %q %q %q}",
+					tt.raw, line.Kind, line.Key, line.Sep, line.Value, line.Trailing,
+					tt.kind, tt.key, tt.sep, tt.value, tt.trailing)
+			}
+		})
+	}
+}
+
+func TestParseConfigBytesLeadingComments(t *testing.T) {
+	content := `# file header
+
+Host a
+    HostName a
+    # belongs to a
+# Tags: web, prod
+Host b
+    HostName b
+`
+	cfg := ParseConfigBytes("config", []byte(content))
+	if len(cfg.Blocks) != 3 {
+		t.Fatalf("expected 3 blocks, got %d", len(cfg.Blocks))
+	}
+
+	a, b := cfg.Blocks[1], cfg.Blocks[2]
+	if len(a.Leading) != 0 {
+		t.Errorf("host a should have no leading comments, got %d", len(a.Leading))
+	}
+	if last := a.Body[len(a.Body)-1]; last.Comment != "# belongs to a" {
+		t.Errorf("indented comment should stay in host a, got %q", last.String())
+	}
+	if got := strings.Join(b.Tags(), ","); got != "web,prod" {
+		t.Errorf("host b tags = %q, want %q", got, "web,prod")
+	}
+}
+
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func readTestConfig(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	return string(data)
+}
+
+func TestUpdateSSHHostInFileNoOpIsByteIdentical(t *testing.T) {
+	content := `# My hosts
+Host *
+  ServerAliveInterval 60
+
+# Tags: web,  prod
+Host web
+	HostName=web.example.com   # primary
+	user deploy
+	# keep this comment
+	Compression yes
+Host db
+    HostName db.example.com
+Match host db exec "test -f /tmp/x"
+    User special
+`
+	path := writeTestConfig(t, content)
+
+	hosts, err := ParseSSHConfigFile(path)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+
+	for _, host := range hosts {
+		if err := UpdateSSHHostInFile(host.Name, host, path); err != nil {
+			t.Fatalf("UpdateSSHHostInFile(%s) error = %v", host.Name, err)
+		}
+	}
+
+	if got := readTestConfig(t, path); got != content {
+		t.Errorf("no-op update changed the file:\ngot:\n%s\nwant:\n%s", got, content)
+	}
+}
+
+func TestUpdateSSHHostInFileKeepsFormatting(t *testing.T) {
+	content := `Host web
+	HostName=web.example.com   # primary
+	# keep this comment
+	Compression yes
+Host db
+    HostName db.example.com
+`
+	path := writeTestConfig(t, content)
+
+	host := SSHHost{
+		Name:     "web",
+		Hostname: "web2.example.com",
+		User:     "deploy",
+		Port:     "22",
+		Options:  "Compression yes",
+		Tags:     []string{"web"},
+	}
+	if err := UpdateSSHHostInFile("web", host, path); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() error = %v", err)
+	}
+
+	want := `# Tags: web
+Host web
+	HostName=web2.example.com   # primary
+	User deploy
+	# keep this comment
+	Compression yes
+Host db
+    HostName db.example.com
+`
+	if got := readTestConfig(t, path); got != want {
+		t.Errorf("unexpected result:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDeleteSSHHostFromFileKeepsNeighbours(t *testing.T) {
+	content := `Host a
+    HostName a
+# Tags: b
+Host b
+    HostName b
+    # comment in b
+Host c
+    HostName c
+`
+	path := writeTestConfig(t, content)
+
+	if err := DeleteSSHHostFromFile("b", path); err != nil {
+		t.Fatalf("DeleteSSHHostFromFile() error = %v", err)
+	}
+
+	want := `Host a
+    HostName a
+Host c
+    HostName c
+`
+	if got := readTestConfig(t, path); got != want {
+		t.Errorf("unexpected result:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestAddSSHHostToFileAppendsBlock(t *testing.T) {
+	content := "Host a\n    HostName a"
+	path := writeTestConfig(t, content)
+
+	host := SSHHost{
+		Name:     "b",
+		Hostname: "b.example.com",
+		User:     "admin",
+		Port:     "2222",
+		Options:  "Compression yes",
+		Tags:     []string{"x", "y"},
+	}
+	if err := AddSSHHostToFile(host, path); err != nil {
+		t.Fatalf("AddSSHHostToFile() error = %v", err)
+	}
+
+	want := `Host a
+    HostName a
+
+# Tags: x, y
+Host b
+    HostName b.example.com
+    User admin
+    Port 2222
+    Compression yes
+`
+	if got := readTestConfig(t, path); got != want {
+		t.Errorf("unexpected result:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	if err := AddSSHHostToFile(host, path); err == nil {
+		t.Error("AddSSHHostToFile() should fail for an existing host")
+	}
+}
+
+func TestParseConfigLineQuotes(t *testing.T) {
+	tests := []struct {
+		raw      string
+		value    string
+		trailing string
+	}{
+		{`IdentityFile "C:/My Keys/id" # key`, `"C:/My Keys/id"`, " # key"},
+		{`RemoteCommand echo "a # b"`, `echo "a # b"`, ""},
+		{`RemoteCommand echo 'a # b' #c`, `echo 'a # b'`, " #c"},
+		{`SetEnv A=\" #c`, `A=\"`, " #c"},
+		{`SendEnv LANG#x`, `LANG#x`, ""},
+	}
+	for _, tt := range tests {
+		line := parseConfigLine(tt.raw)
+		if line.Value != tt.value || line.Trailing != tt.trailing {
+			t.Errorf("parseConfigLine(%q) value=%q trailing=%q, want %q, %q", tt.raw, line.Value, line.Trailing, tt.value, tt.trailing)
+		}
+	}
+}
+
+func TestUpdateKeepsLineEndings(t *testing.T) {
+	content := "\ufeffHost web\r\n    HostName web.example.com\r\n    Port=2222\r\n"
+	path := writeTestConfig(t, content)
+
+	host, err := GetSSHHostFromFile("web", path)
+	if err != nil {
+		t.Fatalf("GetSSHHostFromFile() error = %v", err)
+	}
+	if host.Port != "2222" || host.Hostname != "web.example.com" {
+		t.Fatalf("unexpected host read from a CRLF file: %+v", host)
+	}
+
+	host.User = "deploy"
+	host.Port = "2200"
+	if err := UpdateSSHHostInFile("web", *host, path); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() error = %v", err)
+	}
+	if err := AddSSHHostToFile(SSHHost{Name: "db", Hostname: "db.example.com"}, path); err != nil {
+		t.Fatalf("AddSSHHostToFile() error = %v", err)
+	}
+
+	want := "\ufeffHost web\r\n    HostName web.example.com\r\n    Port=2200\r\n    User deploy\r\n\r\nHost db\r\n    HostName db.example.com\r\n"
+	if got := readTestConfig(t, path); got != want {
+		t.Errorf("config = %q, want %q", got, want)
+	}
+}
+
+func TestHostGroupComment(t *testing.T) {
+	path := writeTestConfig(t, "# Group: prod / eu/db/\n# Tags: db\nHost db1\n\n# Tags: web\nHost web1\n")
+
+	host, err := GetSSHHostFromFile("db1", path)
+	if err != nil {
+		t.Fatalf("GetSSHHostFromFile() error = %v", err)
+	}
+	if host.Group != "prod/eu/db" {
+		t.Errorf("Group = %q, want prod/eu/db", host.Group)
+	}
+
+	// An unchanged group keeps the comment as written
+	if err := UpdateSSHHostInFile("db1", *host, path); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() error = %v", err)
+	}
+
+	web, err := GetSSHHostFromFile("web1", path)
+	if err != nil {
+		t.Fatalf("GetSSHHostFromFile() error = %v", err)
+	}
+	web.Group = "prod/eu/web"
+	if err := UpdateSSHHostInFile("web1", *web, path); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() error = %v", err)
+	}
+	host.Group = ""
+	if err := UpdateSSHHostInFile("db1", *host, path); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() error = %v", err)
+	}
+
+	want := "# Tags: db\nHost db1\n\n# Group: prod/eu/web\n# Tags: web\nHost web1\n"
+	if got := readTestConfig(t, path); got != want {
+		t.Errorf("config = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,365 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNoBackups is returned when there is no snapshot to restore
+var ErrNoBackups = errors.New("no backups found")
+
+// backupTimeFormat names snapshot files so that they sort chronologically
+const backupTimeFormat = "20060102-150405.000000"
+
+// backupSourceFile is the file, in each per-config directory, holding the
+// path of the config file the snapshots belong to
+const backupSourceFile = "source"
+
+// backupAbsentSuffix marks the empty snapshot of a file that did not exist
+// yet, so that undoing the change deletes the file again
+const backupAbsentSuffix = ".absent"
+
+// backupUndoSuffix marks the snapshot of a file taken before an undo restored
+// it, which later undos skip so that they keep walking back through history
+const backupUndoSuffix = ".undo"
+
+// Backup is a snapshot of a config file taken before sshm changed it
+type Backup struct {
+	ID         string // "<file key>/<timestamp>", used to select a snapshot
+	SourceFile string // Config file the snapshot was taken from
+	Path       string // Location of the snapshot
+	Time       time.Time
+	Size       int64
+	Absent     bool // The file did not exist when the snapshot was taken
+	Undo       bool // Taken before an undo, to recover edits made outside sshm
+}
+
+// backupConfig saves the current content of a config file to the backup history
+func backupConfig(configPath string) error {
+	return snapshotFiles([]string{configPath})
+}
+
+// snapshotFiles saves the current content of config files to the backup
+// history under a single timestamp, so that they are undone together.
+// Files that don't exist yet get an empty snapshot marked absent.
+func snapshotFiles(paths []string) error {
+	return takeSnapshots(paths, "")
+}
+
+// takeSnapshots saves the current content of config files under a single
+// timestamp, adding suffix to the names of the snapshots
+func takeSnapshots(paths []string, suffix string) error {
+	backupDir, err := GetSSHMBackupDir()
+	if err != nil {
+		return fmt.Errorf("failed to get backup directory: %w", err)
+	}
+
+	now := time.Now().UTC()
+	retention := loadBackupRetention()
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		absent := os.IsNotExist(err)
+		if err != nil && !absent {
+			return err
+		}
+
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve absolute path for %s: %w", path, err)
+		}
+
+		dir := filepath.Join(backupDir, backupKey(absPath))
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		if err := writeFileAtomic(filepath.Join(dir, backupSourceFile), []byte(absPath+"\n"), 0600); err != nil {
+			return err
+		}
+
+		snapshot := filepath.Join(dir, now.Format(backupTimeFormat)+suffix)
+		if absent {
+			snapshot += backupAbsentSuffix
+		}
+		if err := writeFileAtomic(snapshot, data, 0600); err != nil {
+			return err
+		}
+
+		// Snapshots taken before an undo wait for the next change to be
+		// pruned, so that the one being restored is never removed first
+		if suffix == "" {
+			pruneBackups(dir, retention)
+		}
+	}
+
+	return nil
+}
+
+// backupKey names the backup directory of a config file: its base name, to
+// stay readable, and a hash of its path, to keep same-named files apart
+func backupKey(absPath string) string {
+	sum := sha256.Sum256([]byte(absPath))
+	return filepath.Base(absPath) + "-" + hex.EncodeToString(sum[:4])
+}
+
+// loadBackupRetention returns the retention policy from the app config, or
+// the default one if the app config can't be read
+func loadBackupRetention() BackupSettings {
+	appConfig, err := LoadAppConfig()
+	if err != nil {
+		return GetDefaultBackupSettings()
+	}
+	return appConfig.Backups
+}
+
+// pruneBackups removes the snapshots of a directory that exceed the retention
+// policy. The most recent snapshot is always kept.
+func pruneBackups(dir string, retention BackupSettings) {
+	backups, err := readBackupDir(dir)
+	if err != nil {
+		return
+	}
+
+	cutoff := time.Now().Add(-time.Duration(retention.MaxAgeDays) * 24 * time.Hour)
+	for i, backup := range backups {
+		if i == 0 {
+			continue
+		}
+		tooMany := retention.MaxCount > 0 && i >= retention.MaxCount
+		tooOld := retention.MaxAgeDays > 0 && backup.Time.Before(cutoff)
+		if tooMany || tooOld {
+			_ = os.Remove(backup.Path)
+		}
+	}
+}
+
+// ListBackups returns the snapshots of a config file, or of every config file
+// when sourceFile is empty, newest first
+func ListBackups(sourceFile string) ([]Backup, error) {
+	backupDir, err := GetSSHMBackupDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backup directory: %w", err)
+	}
+
+	var dirs []string
+	if sourceFile != "" {
+		absPath, err := filepath.Abs(sourceFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve absolute path for %s: %w", sourceFile, err)
+		}
+		dirs = []string{filepath.Join(backupDir, backupKey(absPath))}
+	} else {
+		entries, err := os.ReadDir(backupDir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				dirs = append(dirs, filepath.Join(backupDir, entry.Name()))
+			}
+		}
+	}
+
+	var backups []Backup
+	for _, dir := range dirs {
+		dirBackups, err := readBackupDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, dirBackups...)
+	}
+
+	sortBackups(backups)
+	return backups, nil
+}
+
+// readBackupDir lists the snapshots of one config file, newest first
+func readBackupDir(dir string) ([]Backup, error) {
+	source, err := os.ReadFile(filepath.Join(dir, backupSourceFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []Backup
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), backupAbsentSuffix)
+		stamp := strings.TrimSuffix(name, backupUndoSuffix)
+		taken, err := time.Parse(backupTimeFormat, stamp)
+		if err != nil || entry.IsDir() {
+			continue // Not a snapshot (source file, temporary file, ...)
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, Backup{
+			ID:         filepath.Base(dir) + "/" + entry.Name(),
+			SourceFile: strings.TrimSpace(string(source)),
+			Path:       filepath.Join(dir, entry.Name()),
+			Time:       taken,
+			Size:       info.Size(),
+			Absent:     name != entry.Name(),
+			Undo:       stamp != name,
+		})
+	}
+
+	sortBackups(backups)
+	return backups, nil
+}
+
+// sortBackups orders snapshots newest first
+func sortBackups(backups []Backup) {
+	sort.SliceStable(backups, func(i, j int) bool {
+		if !backups[i].Time.Equal(backups[j].Time) {
+			return backups[i].Time.After(backups[j].Time)
+		}
+		return backups[i].ID < backups[j].ID
+	})
+}
+
+// FindBackup returns the snapshot with the given ID. A unique prefix of the
+// ID, or a timestamp shared by a single snapshot, is accepted as well.
+func FindBackup(id string) (*Backup, error) {
+	backups, err := ListBackups("")
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Backup
+	for _, backup := range backups {
+		if backup.ID == id {
+			return &backup, nil
+		}
+		if strings.HasPrefix(backup.ID, id) || strings.HasPrefix(filepath.Base(backup.Path), id) {
+			matches = append(matches, backup)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("backup '%s' not found", id)
+	case 1:
+		return &matches[0], nil
+	default:
+		return nil, fmt.Errorf("backup '%s' is ambiguous, %d backups match", id, len(matches))
+	}
+}
+
+// DiffBackup returns a unified diff from a snapshot to the current content of its file
+func DiffBackup(backup Backup) (string, error) {
+	snapshot, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read backup: %w", err)
+	}
+	current, err := os.ReadFile(backup.SourceFile)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	return UnifiedDiff("backup "+backup.ID, backup.SourceFile, snapshot, current), nil
+}
+
+// RestoreBackup atomically replaces a config file with a snapshot. The current
+// content is backed up first, so that a restore can be undone as well.
+func RestoreBackup(backup Backup) error {
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := backupConfig(backup.SourceFile); err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	return restoreSnapshot(backup)
+}
+
+// UndoLastChange restores the files changed by the most recent sshm write and
+// removes the snapshots used, so that repeated calls walk back through history.
+// The current content of the files is saved first, so that edits made outside
+// sshm since that write can still be restored from the backups.
+func UndoLastChange() ([]Backup, error) {
+	unlock, err := lockConfig()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	all, err := ListBackups("")
+	if err != nil {
+		return nil, err
+	}
+	var backups []Backup
+	for _, backup := range all {
+		if !backup.Undo {
+			backups = append(backups, backup)
+		}
+	}
+	if len(backups) == 0 {
+		return nil, ErrNoBackups
+	}
+
+	// Files changed together share the timestamp of their snapshots
+	var last []Backup
+	var paths []string
+	for _, backup := range backups {
+		if !backup.Time.Equal(backups[0].Time) {
+			break
+		}
+		last = append(last, backup)
+		paths = append(paths, backup.SourceFile)
+	}
+	if err := takeSnapshots(paths, backupUndoSuffix); err != nil {
+		return nil, fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	var restored []Backup
+	for _, backup := range last {
+		if err := restoreSnapshot(backup); err != nil {
+			return restored, err
+		}
+		_ = os.Remove(backup.Path)
+		restored = append(restored, backup)
+	}
+
+	return restored, nil
+}
+
+// restoreSnapshot writes a snapshot back to its config file, or deletes the
+// file if it did not exist when the snapshot was taken
+func restoreSnapshot(backup Backup) error {
+	if backup.Absent {
+		if err := os.Remove(backup.SourceFile); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", backup.SourceFile, err)
+		}
+		recordVersion(backup.SourceFile, nil)
+		return nil
+	}
+
+	data, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+	if err := writeFileAtomic(backup.SourceFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", backup.SourceFile, err)
+	}
+	recordVersion(backup.SourceFile, data)
+	return nil
+}
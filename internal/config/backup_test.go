@@ -0,0 +1,200 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUndoLastChangeWalksBackThroughHistory(t *testing.T) {
+	original := "Host a\n    HostName a\n"
+	path := writeTestConfig(t, original)
+
+	if err := AddSSHHostToFile(SSHHost{Name: "b", Hostname: "b"}, path); err != nil {
+		t.Fatalf("AddSSHHostToFile() error = %v", err)
+	}
+	afterAdd := readTestConfig(t, path)
+	if err := DeleteSSHHostFromFile("a", path); err != nil {
+		t.Fatalf("DeleteSSHHostFromFile() error = %v", err)
+	}
+
+	backups, err := ListBackups(path)
+	if err != nil {
+		t.Fatalf("ListBackups() error = %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups, got %d", len(backups))
+	}
+
+	if _, err := UndoLastChange(); err != nil {
+		t.Fatalf("UndoLastChange() error = %v", err)
+	}
+	if got := readTestConfig(t, path); got != afterAdd {
+		t.Errorf("first undo:\ngot:\n%s\nwant:\n%s", got, afterAdd)
+	}
+
+	if _, err := UndoLastChange(); err != nil {
+		t.Fatalf("UndoLastChange() error = %v", err)
+	}
+	if got := readTestConfig(t, path); got != original {
+		t.Errorf("second undo:\ngot:\n%s\nwant:\n%s", got, original)
+	}
+
+	if _, err := UndoLastChange(); !errors.Is(err, ErrNoBackups) {
+		t.Errorf("UndoLastChange() without history error = %v, want ErrNoBackups", err)
+	}
+}
+
+func TestUndoLastChangeKeepsOutsideEdits(t *testing.T) {
+	original := "Host a\n    HostName a\n"
+	path := writeTestConfig(t, original)
+	if err := AddSSHHostToFile(SSHHost{Name: "b", Hostname: "b"}, path); err != nil {
+		t.Fatalf("AddSSHHostToFile() error = %v", err)
+	}
+
+	// Another program edits the file after the last sshm write
+	external := readTestConfig(t, path) + "\nHost c\n    HostName c\n"
+	if err := os.WriteFile(path, []byte(external), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, err := UndoLastChange(); err != nil {
+		t.Fatalf("UndoLastChange() error = %v", err)
+	}
+	if got := readTestConfig(t, path); got != original {
+		t.Errorf("undo:\ngot:\n%s\nwant:\n%s", got, original)
+	}
+
+	// The outside edit was saved before the undo and can be restored
+	backups, err := ListBackups(path)
+	if err != nil {
+		t.Fatalf("ListBackups() error = %v", err)
+	}
+	if len(backups) != 1 || !backups[0].Undo {
+		t.Fatalf("expected the snapshot taken before the undo, got %+v", backups)
+	}
+	if data, _ := os.ReadFile(backups[0].Path); string(data) != external {
+		t.Errorf("snapshot = %q, want %q", data, external)
+	}
+
+	// It is not undone in turn
+	if _, err := UndoLastChange(); !errors.Is(err, ErrNoBackups) {
+		t.Errorf("UndoLastChange() error = %v, want ErrNoBackups", err)
+	}
+	if err := RestoreBackup(backups[0]); err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	if got := readTestConfig(t, path); got != external {
+		t.Errorf("restore:\ngot:\n%s\nwant:\n%s", got, external)
+	}
+}
+
+func TestUndoMoveIntoNewFile(t *testing.T) {
+	original := "Include conf.d/*\n\nHost a\n    HostName a\n"
+	path := writeTestConfig(t, original)
+	target := filepath.Join(filepath.Dir(path), "conf.d", "moved")
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	host, err := GetSSHHostFromFile("a", path)
+	if err != nil {
+		t.Fatalf("GetSSHHostFromFile() error = %v", err)
+	}
+	if err := MoveHostsToFile([]SSHHost{*host}, target); err != nil {
+		t.Fatalf("MoveHostsToFile() error = %v", err)
+	}
+	if _, err := os.Stat(target); err != nil {
+		t.Fatalf("expected %s to be created: %v", target, err)
+	}
+
+	restored, err := UndoLastChange()
+	if err != nil {
+		t.Fatalf("UndoLastChange() error = %v", err)
+	}
+	if len(restored) != 2 {
+		t.Errorf("expected both files to be restored, got %d", len(restored))
+	}
+	if got := readTestConfig(t, path); got != original {
+		t.Errorf("config:\ngot:\n%s\nwant:\n%s", got, original)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, stat error = %v", target, err)
+	}
+
+	// The file can be created again without a conflict
+	if err := MoveHostsToFile([]SSHHost{*host}, target); err != nil {
+		t.Errorf("MoveHostsToFile() after undo error = %v", err)
+	}
+}
+
+func TestRestoreBackupAndDiff(t *testing.T) {
+	original := "Host a\n    HostName a\n"
+	path := writeTestConfig(t, original)
+
+	if err := UpdateSSHHostInFile("a", SSHHost{Name: "a", Hostname: "a2", Port: "22"}, path); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() error = %v", err)
+	}
+
+	backups, err := ListBackups("")
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("ListBackups() = %d backups, error = %v", len(backups), err)
+	}
+
+	found, err := FindBackup(backups[0].ID)
+	if err != nil {
+		t.Fatalf("FindBackup() error = %v", err)
+	}
+
+	diff, err := DiffBackup(*found)
+	if err != nil {
+		t.Fatalf("DiffBackup() error = %v", err)
+	}
+	want := "--- backup " + found.ID + "\n+++ " + path + "\n@@ -1,2 +1,2 @@\n Host a\n-    HostName a\n+    HostName a2\n"
+	if diff != want {
+		t.Errorf("DiffBackup() =\n%s\nwant:\n%s", diff, want)
+	}
+
+	if err := RestoreBackup(*found); err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	if got := readTestConfig(t, path); got != original {
+		t.Errorf("restored content:\ngot:\n%s\nwant:\n%s", got, original)
+	}
+
+	// The content replaced by the restore is kept as well
+	if backups, _ := ListBackups(path); len(backups) != 2 {
+		t.Errorf("expected 2 backups after restore, got %d", len(backups))
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	path := writeTestConfig(t, "Host a\n")
+
+	for i := 0; i < 4; i++ {
+		if err := backupConfig(path); err != nil {
+			t.Fatalf("backupConfig() error = %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	backups, _ := ListBackups(path)
+	if len(backups) != 4 {
+		t.Fatalf("expected 4 backups, got %d", len(backups))
+	}
+
+	// Age the oldest snapshot past the limit
+	oldest := backups[len(backups)-1]
+	dir := filepath.Dir(oldest.Path)
+	if err := os.Rename(oldest.Path, filepath.Join(dir, "20000101-000000.000000")); err != nil {
+		t.Fatalf("Failed to age backup: %v", err)
+	}
+
+	pruneBackups(dir, BackupSettings{MaxCount: 2, MaxAgeDays: 30})
+
+	if backups, _ := ListBackups(path); len(backups) != 2 {
+		t.Errorf("expected 2 backups after pruning, got %d", len(backups))
+	}
+}
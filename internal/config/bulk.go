@@ -0,0 +1,302 @@
+package config
+
+import "fmt"
+
+// TagAction is how a bulk tag change combines the given tags with the tags
+// of each host
+type TagAction int
+
+const (
+	TagsAdd     TagAction = iota // Add the tags the host doesn't have yet
+	TagsRemove                   // Remove the tags from the host
+	TagsReplace                  // Replace the tags of the host
+)
+
+func (a TagAction) String() string {
+	switch a {
+	case TagsRemove:
+		return "remove"
+	case TagsReplace:
+		return "replace"
+	default:
+		return "add"
+	}
+}
+
+// Apply returns the tags of a host once the action is applied
+func (a TagAction) Apply(current, tags []string) []string {
+	switch a {
+	case TagsReplace:
+		return append([]string(nil), tags...)
+	case TagsRemove:
+		var kept []string
+		for _, tag := range current {
+			if !containsString(tags, tag) {
+				kept = append(kept, tag)
+			}
+		}
+		return kept
+	default:
+		result := append([]string(nil), current...)
+		for _, tag := range tags {
+			if !containsString(result, tag) {
+				result = append(result, tag)
+			}
+		}
+		return result
+	}
+}
+
+// containsString reports whether a list holds a value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// hostBlock is the block defining a host in a file loaded for a bulk change
+type hostBlock struct {
+	file  *ConfigFile
+	block *ConfigBlock
+}
+
+// hostSourceFiles lists the files defining the given hosts, followed by the
+// extra files, each once
+func hostSourceFiles(hosts []SSHHost, extra ...string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if key := versionKey(path); !seen[key] {
+			seen[key] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, host := range hosts {
+		if host.System {
+			return nil, fmt.Errorf("host '%s' is defined in the system-wide SSH config and is read-only", host.Name)
+		}
+		if host.SourceFile == "" {
+			return nil, fmt.Errorf("host '%s' has no source file", host.Name)
+		}
+		add(host.SourceFile)
+	}
+	for _, path := range extra {
+		add(path)
+	}
+	return files, nil
+}
+
+// findHostBlocks finds the blocks defining the given hosts in files loaded by
+// a transaction. A block declaring several of the hosts is returned once.
+func findHostBlocks(paths []string, files []*ConfigFile, hosts []SSHHost) ([]hostBlock, error) {
+	byPath := make(map[string]*ConfigFile, len(paths))
+	for i, path := range paths {
+		byPath[versionKey(path)] = files[i]
+	}
+
+	var blocks []hostBlock
+	seen := make(map[*ConfigBlock]bool)
+	for _, host := range hosts {
+		file := byPath[versionKey(host.SourceFile)]
+		block := file.FindHostBlock(host.Name)
+		if block == nil {
+			return nil, fmt.Errorf("host '%s' %w in %s", host.Name, ErrHostNotFound, host.SourceFile)
+		}
+		if !seen[block] {
+			seen[block] = true
+			blocks = append(blocks, hostBlock{file: file, block: block})
+		}
+	}
+	return blocks, nil
+}
+
+// UpdateHostsTags changes the tags of several hosts. All the files involved
+// are backed up and written as a single change.
+func UpdateHostsTags(hosts []SSHHost, action TagAction, tags []string) error {
+	paths, err := hostSourceFiles(hosts)
+	if err != nil {
+		return err
+	}
+
+	return modifyConfigFiles(paths, func(files []*ConfigFile) error {
+		blocks, err := findHostBlocks(paths, files, hosts)
+		if err != nil {
+			return err
+		}
+		for _, hb := range blocks {
+			applyTagsToBlock(hb.block, action.Apply(hb.block.Tags(), tags))
+		}
+		return nil
+	})
+}
+
+// DeleteHosts removes several hosts. All the files involved are backed up and
+// written as a single change.
+func DeleteHosts(hosts []SSHHost) error {
+	paths, err := hostSourceFiles(hosts)
+	if err != nil {
+		return err
+	}
+
+	return modifyConfigFiles(paths, func(files []*ConfigFile) error {
+		blocks, err := findHostBlocks(paths, files, hosts)
+		if err != nil {
+			return err
+		}
+		for _, hb := range blocks {
+			hb.file.RemoveBlock(hb.block)
+		}
+		return nil
+	})
+}
+
+// MoveHostsToFile moves several hosts to a target config file. Hosts already
+// in the target file are left where they are. All the files involved are
+// backed up and written as a single change.
+func MoveHostsToFile(hosts []SSHHost, targetConfigFile string) error {
+	var toMove []SSHHost
+	for _, host := range hosts {
+		if versionKey(host.SourceFile) != versionKey(targetConfigFile) {
+			toMove = append(toMove, host)
+		}
+	}
+	if len(toMove) == 0 {
+		return fmt.Errorf("the hosts are already in the target config file '%s'", targetConfigFile)
+	}
+
+	paths, err := hostSourceFiles(toMove, targetConfigFile)
+	if err != nil {
+		return err
+	}
+
+	return modifyConfigFiles(paths, func(files []*ConfigFile) error {
+		// No host to move comes from the target, so it is listed last
+		target := files[len(files)-1]
+
+		blocks, err := findHostBlocks(paths, files, toMove)
+		if err != nil {
+			return err
+		}
+		for _, hb := range blocks {
+			for _, alias := range ConcreteAliases(hb.block.Patterns()) {
+				if target.FindHostBlock(alias) != nil {
+					return fmt.Errorf("host '%s' %w in %s", alias, ErrHostExists, targetConfigFile)
+				}
+			}
+		}
+
+		// Blocks are moved as-is so that comments and unmapped directives
+		// travel with them
+		for _, hb := range blocks {
+			hb.file.RemoveBlock(hb.block)
+			target.AppendBlock(hb.block)
+		}
+		return nil
+	})
+}
+
+// UpdateAndMoveHost applies the values of a host to its block and moves the
+// block from sourceFile to a target config file. Both files are backed up and
+// written as a single change.
+func UpdateAndMoveHost(oldName string, newHost SSHHost, sourceFile, targetConfigFile string) error {
+	if versionKey(sourceFile) == versionKey(targetConfigFile) {
+		return UpdateSSHHostInFile(oldName, newHost, sourceFile)
+	}
+
+	return modifyConfigFiles([]string{sourceFile, targetConfigFile}, func(files []*ConfigFile) error {
+		source, target := files[0], files[1]
+		block := source.FindHostBlock(oldName)
+		if block == nil {
+			return fmt.Errorf("host '%s' %w", oldName, ErrHostNotFound)
+		}
+
+		updateHostBlock(block, oldName, newHost)
+		for _, alias := range ConcreteAliases(block.Patterns()) {
+			if target.FindHostBlock(alias) != nil {
+				return fmt.Errorf("host '%s' %w in %s", alias, ErrHostExists, targetConfigFile)
+			}
+		}
+		source.RemoveBlock(block)
+		target.AppendBlock(block)
+		return nil
+	})
+}
+
+// ImportHosts adds hosts to a config file, removes the hosts they replace
+// and applies the updated hosts to their blocks in place. All the files
+// involved are backed up and written as a single change.
+func ImportHosts(hosts, replace, update []SSHHost, targetConfigFile string) error {
+	paths, err := hostSourceFiles(append(append([]SSHHost(nil), replace...), update...), targetConfigFile)
+	if err != nil {
+		return err
+	}
+
+	return modifyConfigFiles(paths, func(files []*ConfigFile) error {
+		blocks, err := findHostBlocks(paths, files, replace)
+		if err != nil {
+			return err
+		}
+		for _, host := range update {
+			updated, err := findHostBlocks(paths, files, []SSHHost{host})
+			if err != nil {
+				return err
+			}
+			block := updated[0].block
+			applyMetadataToBlock(block, host.Metadata)
+			applyGroupToBlock(block, host.Group)
+			applyTagsToBlock(block, host.Tags)
+			applyHostToBlock(block, host)
+		}
+		for _, hb := range blocks {
+			hb.file.RemoveBlock(hb.block)
+		}
+
+		var target *ConfigFile
+		for i, path := range paths {
+			if versionKey(path) == versionKey(targetConfigFile) {
+				target = files[i]
+			}
+		}
+		for _, host := range hosts {
+			if target.FindHostBlock(host.Name) != nil {
+				return fmt.Errorf("host '%s' %w in %s", host.Name, ErrHostExists, targetConfigFile)
+			}
+			target.AppendBlock(newHostBlock(host))
+		}
+		return nil
+	})
+}
+
+// ExportHosts renders the blocks defining the given hosts, comments included,
+// as the content of a standalone config file
+func ExportHosts(hosts []SSHHost) ([]byte, error) {
+	var paths []string
+	var files []*ConfigFile
+	seen := make(map[string]bool)
+	for _, host := range hosts {
+		if key := versionKey(host.SourceFile); !seen[key] {
+			seen[key] = true
+			file, err := readConfigFile(host.SourceFile, false)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", host.SourceFile, err)
+			}
+			paths = append(paths, host.SourceFile)
+			files = append(files, file)
+		}
+	}
+
+	blocks, err := findHostBlocks(paths, files, hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	out := ParseConfigBytes("", nil)
+	for _, hb := range blocks {
+		out.AppendBlock(hb.block)
+	}
+	return out.Bytes(), nil
+}
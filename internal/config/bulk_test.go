@@ -0,0 +1,200 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeBulkTestConfigs writes a main config including a work config and
+// returns the hosts of both, by name
+func writeBulkTestConfigs(t *testing.T) (string, string, map[string]SSHHost) {
+	t.Helper()
+	mainConfig := writeTestConfig(t, "Include work\n\n# Tags: web\nHost web1\n    HostName 10.0.0.1\n\n# Tags: web, old\nHost web2\n    HostName 10.0.0.2\n")
+	work := filepath.Join(filepath.Dir(mainConfig), "work")
+	if err := os.WriteFile(work, []byte("Host db1 db1.alias\n    HostName 10.0.1.1\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	parsed, err := ParseSSHConfigFile(mainConfig)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	hosts := make(map[string]SSHHost)
+	for _, host := range parsed {
+		hosts[host.Name] = host
+	}
+	return mainConfig, work, hosts
+}
+
+// bulkHosts returns the named hosts
+func bulkHosts(hosts map[string]SSHHost, names ...string) []SSHHost {
+	var list []SSHHost
+	for _, name := range names {
+		list = append(list, hosts[name])
+	}
+	return list
+}
+
+func TestTagActionApply(t *testing.T) {
+	current := []string{"web", "old"}
+	tests := []struct {
+		action TagAction
+		tags   []string
+		want   []string
+	}{
+		{TagsAdd, []string{"prod", "web"}, []string{"web", "old", "prod"}},
+		{TagsRemove, []string{"old"}, []string{"web"}},
+		{TagsReplace, []string{"prod"}, []string{"prod"}},
+	}
+	for _, tt := range tests {
+		if got := tt.action.Apply(current, tt.tags); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %v = %v, want %v", tt.action, tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateHostsTags(t *testing.T) {
+	mainConfig, work, hosts := writeBulkTestConfigs(t)
+
+	if err := UpdateHostsTags(bulkHosts(hosts, "web1", "web2", "db1"), TagsAdd, []string{"prod"}); err != nil {
+		t.Fatalf("UpdateHostsTags() error = %v", err)
+	}
+	want := "Include work\n\n# Tags: web, prod\nHost web1\n    HostName 10.0.0.1\n\n# Tags: web, old, prod\nHost web2\n    HostName 10.0.0.2\n"
+	if got := readTestConfig(t, mainConfig); got != want {
+		t.Errorf("config = %q, want %q", got, want)
+	}
+	if got, want := readTestConfig(t, work), "# Tags: prod\nHost db1 db1.alias\n    HostName 10.0.1.1\n"; got != want {
+		t.Errorf("work = %q, want %q", got, want)
+	}
+
+	// Both files were written as a single change
+	restored, err := UndoLastChange()
+	if err != nil {
+		t.Fatalf("UndoLastChange() error = %v", err)
+	}
+	if len(restored) != 2 {
+		t.Errorf("expected both files to be restored, got %d", len(restored))
+	}
+}
+
+func TestDeleteHosts(t *testing.T) {
+	mainConfig, work, hosts := writeBulkTestConfigs(t)
+
+	// Both aliases of db1 name the same block
+	alias := hosts["db1"]
+	alias.Name = "db1.alias"
+	if err := DeleteHosts(append(bulkHosts(hosts, "web2", "db1"), alias)); err != nil {
+		t.Fatalf("DeleteHosts() error = %v", err)
+	}
+	if got, want := readTestConfig(t, mainConfig), "Include work\n\n# Tags: web\nHost web1\n    HostName 10.0.0.1\n"; got != want {
+		t.Errorf("config = %q, want %q", got, want)
+	}
+	if got := readTestConfig(t, work); got != "" {
+		t.Errorf("work = %q, want it empty", got)
+	}
+
+	system := SSHHost{Name: "bastion", SourceFile: "/etc/ssh/ssh_config", System: true}
+	if err := DeleteHosts([]SSHHost{hosts["web1"], system}); err == nil {
+		t.Error("expected deleting a system host to fail")
+	}
+}
+
+func TestMoveHostsToFile(t *testing.T) {
+	mainConfig, work, hosts := writeBulkTestConfigs(t)
+
+	// db1 is already in the target and stays where it is
+	if err := MoveHostsToFile(bulkHosts(hosts, "web1", "db1", "web2"), work); err != nil {
+		t.Fatalf("MoveHostsToFile() error = %v", err)
+	}
+	if got, want := readTestConfig(t, mainConfig), "Include work\n"; got != want {
+		t.Errorf("config = %q, want %q", got, want)
+	}
+	want := "Host db1 db1.alias\n    HostName 10.0.1.1\n\n# Tags: web\nHost web1\n    HostName 10.0.0.1\n\n# Tags: web, old\nHost web2\n    HostName 10.0.0.2\n"
+	if got := readTestConfig(t, work); got != want {
+		t.Errorf("work = %q, want %q", got, want)
+	}
+}
+
+func TestUpdateAndMoveHost(t *testing.T) {
+	mainConfig, work, hosts := writeBulkTestConfigs(t)
+
+	// The update and the move are a single change
+	updated := hosts["web2"]
+	updated.Name = "web3"
+	updated.Set("User", "deploy")
+	if err := UpdateAndMoveHost("web2", updated, mainConfig, work); err != nil {
+		t.Fatalf("UpdateAndMoveHost() error = %v", err)
+	}
+	if got, want := readTestConfig(t, mainConfig), "Include work\n\n# Tags: web\nHost web1\n    HostName 10.0.0.1\n"; got != want {
+		t.Errorf("config = %q, want %q", got, want)
+	}
+	want := "Host db1 db1.alias\n    HostName 10.0.1.1\n\n# Tags: web, old\nHost web3\n    HostName 10.0.0.2\n    User deploy\n"
+	if got := readTestConfig(t, work); got != want {
+		t.Errorf("work = %q, want %q", got, want)
+	}
+	restored, err := UndoLastChange()
+	if err != nil {
+		t.Fatalf("UndoLastChange() error = %v", err)
+	}
+	if len(restored) != 2 {
+		t.Errorf("expected both files to be restored, got %d", len(restored))
+	}
+
+	// A name taken in the target leaves both files untouched
+	before := readTestConfig(t, mainConfig)
+	updated = hosts["web1"]
+	updated.Name = "db1.alias"
+	updated.Set("User", "deploy")
+	if err := UpdateAndMoveHost("web1", updated, mainConfig, work); !errors.Is(err, ErrHostExists) {
+		t.Errorf("UpdateAndMoveHost(taken name) error = %v, want ErrHostExists", err)
+	}
+	if got := readTestConfig(t, mainConfig); got != before {
+		t.Errorf("config = %q, want it unchanged", got)
+	}
+}
+
+func TestImportHosts(t *testing.T) {
+	mainConfig, work, hosts := writeBulkTestConfigs(t)
+
+	imported := []SSHHost{{Name: "db1"}, {Name: "api"}}
+	imported[0].Set("HostName", "10.0.2.1")
+	imported[1].Set("HostName", "10.0.2.2")
+
+	// web2 is updated where it is
+	updated := hosts["web2"]
+	updated.Set("User", "deploy")
+	updated.Tags = []string{"prod"}
+
+	// db1 of work is replaced by the imported one in the main config
+	if err := ImportHosts(imported, bulkHosts(hosts, "db1"), []SSHHost{updated}, mainConfig); err != nil {
+		t.Fatalf("ImportHosts() error = %v", err)
+	}
+	if got := readTestConfig(t, work); got != "" {
+		t.Errorf("work = %q, want db1 removed", got)
+	}
+	want := "# Tags: web\nHost web1\n    HostName 10.0.0.1\n\n# Tags: prod\nHost web2\n    HostName 10.0.0.2\n    User deploy\n\nHost db1\n    HostName 10.0.2.1\n\nHost api\n    HostName 10.0.2.2\n"
+	if got := readTestConfig(t, mainConfig); !strings.HasSuffix(got, want) {
+		t.Errorf("config = %q, want it to end with %q", got, want)
+	}
+
+	if err := ImportHosts(imported[1:], nil, nil, mainConfig); !errors.Is(err, ErrHostExists) {
+		t.Errorf("ImportHosts(existing) error = %v, want ErrHostExists", err)
+	}
+}
+
+func TestExportHosts(t *testing.T) {
+	_, _, hosts := writeBulkTestConfigs(t)
+
+	data, err := ExportHosts(bulkHosts(hosts, "web2", "db1"))
+	if err != nil {
+		t.Fatalf("ExportHosts() error = %v", err)
+	}
+	want := "# Tags: web, old\nHost web2\n    HostName 10.0.0.2\n\nHost db1 db1.alias\n    HostName 10.0.1.1\n"
+	if string(data) != want {
+		t.Errorf("ExportHosts() = %q, want %q", data, want)
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IsPatternName reports whether a Host value is made only of wildcards and
+// negations, such as "*" or "*.internal !bastion.internal"
+func IsPatternName(name string) bool {
+	patterns := SplitHostPatterns(name)
+	return len(patterns) > 0 && len(ConcreteAliases(patterns)) == 0
+}
+
+// GetPatternBlock retrieves a pattern block by its Host value from a config
+// file and its includes. An empty path selects the default SSH config file.
+func GetPatternBlock(pattern string, configPath string) (*SSHHost, error) {
+	parsed, err := ParseConfigTree(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	want := strings.Join(SplitHostPatterns(pattern), " ")
+	for _, block := range parsed.Patterns {
+		if block.Name == want {
+			return &block, nil
+		}
+	}
+	return nil, fmt.Errorf("pattern '%s' not found", pattern)
+}
+
+// AddPatternBlockToFile adds a new pattern block to a specific config file
+func AddPatternBlockToFile(block SSHHost, configPath string) error {
+	if !IsPatternName(block.Name) {
+		return fmt.Errorf("'%s' is not a wildcard pattern", block.Name)
+	}
+	block.Patterns = SplitHostPatterns(block.Name)
+
+	return modifyConfigFile(configPath, func(cfg *ConfigFile) error {
+		if cfg.FindPatternBlock(block.Name) != nil {
+			return fmt.Errorf("pattern '%s' already exists", block.Name)
+		}
+		cfg.AppendBlock(newHostBlock(block))
+		return nil
+	})
+}
+
+// UpdatePatternBlockInFile updates an existing pattern block in a specific config file
+func UpdatePatternBlockInFile(oldPattern string, block SSHHost, configPath string) error {
+	if !IsPatternName(block.Name) {
+		return fmt.Errorf("'%s' is not a wildcard pattern", block.Name)
+	}
+
+	return modifyConfigFile(configPath, func(cfg *ConfigFile) error {
+		existing := cfg.FindPatternBlock(oldPattern)
+		if existing == nil {
+			return fmt.Errorf("pattern '%s' not found", oldPattern)
+		}
+
+		newValue := strings.Join(SplitHostPatterns(block.Name), " ")
+		if newValue != strings.Join(existing.Patterns(), " ") {
+			if cfg.FindPatternBlock(newValue) != nil {
+				return fmt.Errorf("pattern '%s' already exists", newValue)
+			}
+			existing.Header.SetValue(newValue)
+		}
+		applyMetadataToBlock(existing, block.Metadata)
+		applyGroupToBlock(existing, block.Group)
+		applyTagsToBlock(existing, block.Tags)
+		applyHostToBlock(existing, block)
+		return nil
+	})
+}
+
+// DeletePatternBlockFromFile removes a pattern block from a specific config file
+func DeletePatternBlockFromFile(pattern string, configPath string) error {
+	return modifyConfigFile(configPath, func(cfg *ConfigFile) error {
+		block := cfg.FindPatternBlock(pattern)
+		if block == nil {
+			return fmt.Errorf("pattern '%s' not found", pattern)
+		}
+		cfg.RemoveBlock(block)
+		return nil
+	})
+}
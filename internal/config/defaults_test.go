@@ -0,0 +1,89 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConfigTreeLoadsPatternBlocks(t *testing.T) {
+	content := `# Tags: shared
+Host *
+    ServerAliveInterval 60
+
+Host *.internal !bastion.internal
+    User ops
+    IdentityFile ~/.ssh/internal
+
+Host web
+    HostName web.example.com
+`
+	path := writeTestConfig(t, content)
+
+	parsed, err := ParseConfigTree(path)
+	if err != nil {
+		t.Fatalf("ParseConfigTree() error = %v", err)
+	}
+
+	if len(parsed.Hosts) != 1 || parsed.Hosts[0].Name != "web" {
+		t.Fatalf("expected only host web, got %+v", parsed.Hosts)
+	}
+	if len(parsed.Patterns) != 2 {
+		t.Fatalf("expected 2 pattern blocks, got %d", len(parsed.Patterns))
+	}
+
+	all, internal := parsed.Patterns[0], parsed.Patterns[1]
+	if all.Name != "*" || strings.Join(all.Tags, ",") != "shared" || all.Options != "ServerAliveInterval 60" {
+		t.Errorf("unexpected '*' block: %+v", all)
+	}
+	if internal.Name != "*.internal !bastion.internal" || internal.User != "ops" || internal.Identity != "~/.ssh/internal" {
+		t.Errorf("unexpected '*.internal' block: %+v", internal)
+	}
+	if internal.Port != "" {
+		t.Errorf("pattern blocks should not get a default port, got %q", internal.Port)
+	}
+}
+
+func TestPatternBlockCRUD(t *testing.T) {
+	content := `Host *
+    ServerAliveInterval 60
+
+Host web
+    HostName web.example.com
+`
+	path := writeTestConfig(t, content)
+
+	if err := AddPatternBlockToFile(SSHHost{Name: "*.internal", User: "ops"}, path); err != nil {
+		t.Fatalf("AddPatternBlockToFile() error = %v", err)
+	}
+	if err := AddPatternBlockToFile(SSHHost{Name: "*.internal"}, path); err == nil {
+		t.Error("AddPatternBlockToFile() should fail for an existing pattern")
+	}
+	if err := AddPatternBlockToFile(SSHHost{Name: "db"}, path); err == nil {
+		t.Error("AddPatternBlockToFile() should reject a concrete host name")
+	}
+
+	block, err := GetPatternBlock("*", path)
+	if err != nil {
+		t.Fatalf("GetPatternBlock() error = %v", err)
+	}
+	block.Name = "* !web"
+	block.User = "admin"
+	if err := UpdatePatternBlockInFile("*", *block, path); err != nil {
+		t.Fatalf("UpdatePatternBlockInFile() error = %v", err)
+	}
+
+	if err := DeletePatternBlockFromFile("*.internal", path); err != nil {
+		t.Fatalf("DeletePatternBlockFromFile() error = %v", err)
+	}
+
+	want := `Host * !web
+    User admin
+    ServerAliveInterval 60
+
+Host web
+    HostName web.example.com
+`
+	if got := readTestConfig(t, path); got != want {
+		t.Errorf("unexpected result:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
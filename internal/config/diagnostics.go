@@ -0,0 +1,40 @@
+package config
+
+import "fmt"
+
+// Severity tells how serious a problem found in the config is
+type Severity string
+
+const (
+	// SeverityError is a problem that makes ssh fail or hosts go missing
+	SeverityError Severity = "error"
+	// SeverityWarning is a likely mistake that ssh accepts
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem met while reading the config tree, such as an
+// include that can't be read. Hosts are still read from the rest of the tree.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Message  string   `json:"message"`
+}
+
+// String formats the diagnostic like a compiler message
+func (d Diagnostic) String() string {
+	if d.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s", d.File, d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s: %s", d.File, d.Line, d.Severity, d.Message)
+}
+
+// addDiagnostic records a problem met while parsing
+func (p *ParsedConfig) addDiagnostic(severity Severity, file string, line int, format string, args ...interface{}) {
+	p.Diagnostics = append(p.Diagnostics, Diagnostic{
+		Severity: severity,
+		File:     file,
+		Line:     line,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
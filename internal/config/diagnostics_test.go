@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConfigTreeDiagnostics(t *testing.T) {
+	path := writeTestConfig(t, "Include loop\nInclude [bad\nInclude broken\n\nHost main\n    HostName main.example.com\n")
+	dir := filepath.Dir(path)
+
+	// loop includes the main config back
+	if err := os.WriteFile(filepath.Join(dir, "loop"), []byte("Include config\n\nHost looped\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "broken")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	parsed, err := ParseConfigTree(path)
+	if err != nil {
+		t.Fatalf("ParseConfigTree() error = %v", err)
+	}
+
+	// Hosts are still read from the rest of the tree
+	if len(parsed.Hosts) != 2 {
+		t.Errorf("expected 2 hosts, got %d", len(parsed.Hosts))
+	}
+
+	tests := []struct {
+		file     string
+		line     int
+		severity Severity
+		message  string
+	}{
+		{"loop", 1, SeverityWarning, "include cycle skipped: " + path + " -> " + filepath.Join(dir, "loop") + " -> " + path},
+		{"config", 2, SeverityError, "invalid Include pattern '[bad'"},
+		{"config", 3, SeverityError, "included file " + filepath.Join(dir, "broken") + " skipped"},
+	}
+	if len(parsed.Diagnostics) != len(tests) {
+		t.Fatalf("expected %d diagnostics, got %v", len(tests), parsed.Diagnostics)
+	}
+	for _, tt := range tests {
+		found := false
+		for _, d := range parsed.Diagnostics {
+			if filepath.Base(d.File) == tt.file && d.Line == tt.line && d.Severity == tt.severity &&
+				strings.Contains(d.Message, tt.message) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("missing %s diagnostic at %s:%d containing %q, got %v", tt.severity, tt.file, tt.line, tt.message, parsed.Diagnostics)
+		}
+	}
+}
+
+func TestParseConfigTreeRepeatedIncludeIsNotACycle(t *testing.T) {
+	path := writeTestConfig(t, "Include common\nInclude common\n")
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), "common"), []byte("Host shared\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	parsed, err := ParseConfigTree(path)
+	if err != nil {
+		t.Fatalf("ParseConfigTree() error = %v", err)
+	}
+	if len(parsed.Diagnostics) != 0 {
+		t.Errorf("expected no diagnostics, got %v", parsed.Diagnostics)
+	}
+}
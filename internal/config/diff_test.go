@@ -0,0 +1,30 @@
+package config
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	from := "Host a\n    HostName a\n    User old\n    Port 22\n"
+	to := "Host a\n    HostName a\n    User new\n    Port 22\n\nHost b\n"
+
+	want := `--- old
++++ new
+@@ -1,4 +1,6 @@
+ Host a
+     HostName a
+-    User old
++    User new
+     Port 22
++
++Host b
+`
+	if got := UnifiedDiff("old", "new", []byte(from), []byte(to)); got != want {
+		t.Errorf("UnifiedDiff() =\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := UnifiedDiff("old", "new", []byte(from), []byte(from)); got != "" {
+		t.Errorf("UnifiedDiff() of equal content = %q, want empty", got)
+	}
+	if got := UnifiedDiff("old", "new", nil, []byte("Host a\n")); got != "--- old\n+++ new\n@@ -0,0 +1 @@\n+Host a\n" {
+		t.Errorf("UnifiedDiff() from empty = %q", got)
+	}
+}
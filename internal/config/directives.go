@@ -0,0 +1,539 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DirectiveType is the kind of value an ssh_config keyword takes
+type DirectiveType string
+
+const (
+	DirectiveString   DirectiveType = "string"   // Free-form value
+	DirectiveYesNo    DirectiveType = "yes/no"   // yes or no
+	DirectiveChoice   DirectiveType = "choice"   // One of a fixed list of values
+	DirectiveInteger  DirectiveType = "integer"  // Non-negative number
+	DirectivePort     DirectiveType = "port"     // Port number between 1 and 65535
+	DirectiveDuration DirectiveType = "duration" // Time such as 30, 45s or 1h30m
+	DirectivePath     DirectiveType = "path"     // Single file path, tokens and ~ allowed
+	DirectiveForward  DirectiveType = "forward"  // Port forwarding specification
+)
+
+// KeywordSpec describes the value an ssh_config keyword takes
+type KeywordSpec struct {
+	Name   string // Canonical spelling
+	Type   DirectiveType
+	Multi  bool     // Every occurrence is used, in order, instead of only the first one
+	Values []string // Accepted values for choices, or extra words accepted besides the type
+}
+
+// keywordSpecs gives the type of the keywords whose values sshm checks. Other
+// known keywords take free-form strings.
+var keywordSpecs = map[string]KeywordSpec{
+	"addressfamily":                    {Type: DirectiveChoice, Values: []string{"any", "inet", "inet6"}},
+	"batchmode":                        {Type: DirectiveYesNo},
+	"canonicalizefallbacklocal":        {Type: DirectiveYesNo},
+	"canonicalizehostname":             {Type: DirectiveChoice, Values: []string{"yes", "no", "always", "none"}},
+	"canonicalizemaxdots":              {Type: DirectiveInteger},
+	"certificatefile":                  {Type: DirectivePath, Multi: true},
+	"challengeresponseauthentication":  {Type: DirectiveYesNo},
+	"checkhostip":                      {Type: DirectiveYesNo},
+	"clearallforwardings":              {Type: DirectiveYesNo},
+	"compression":                      {Type: DirectiveYesNo},
+	"connectionattempts":               {Type: DirectiveInteger},
+	"connecttimeout":                   {Type: DirectiveDuration, Values: []string{"none"}},
+	"controlmaster":                    {Type: DirectiveChoice, Values: []string{"yes", "no", "ask", "auto", "autoask"}},
+	"controlpath":                      {Type: DirectivePath, Values: []string{"none"}},
+	"controlpersist":                   {Type: DirectiveDuration, Values: []string{"yes", "no"}},
+	"dynamicforward":                   {Type: DirectiveForward, Multi: true},
+	"enableescapecommandline":          {Type: DirectiveYesNo},
+	"enablesshkeysign":                 {Type: DirectiveYesNo},
+	"exitonforwardfailure":             {Type: DirectiveYesNo},
+	"fingerprinthash":                  {Type: DirectiveChoice, Values: []string{"md5", "sha256"}},
+	"forkafterauthentication":          {Type: DirectiveYesNo},
+	"forwardx11":                       {Type: DirectiveYesNo},
+	"forwardx11timeout":                {Type: DirectiveDuration},
+	"forwardx11trusted":                {Type: DirectiveYesNo},
+	"gatewayports":                     {Type: DirectiveYesNo},
+	"gssapiauthentication":             {Type: DirectiveYesNo},
+	"gssapidelegatecredentials":        {Type: DirectiveYesNo},
+	"gssapikeyexchange":                {Type: DirectiveYesNo},
+	"gssapirenewalforcesrekey":         {Type: DirectiveYesNo},
+	"gssapitrustdns":                   {Type: DirectiveYesNo},
+	"hashknownhosts":                   {Type: DirectiveYesNo},
+	"hostbasedauthentication":          {Type: DirectiveYesNo},
+	"identitiesonly":                   {Type: DirectiveYesNo},
+	"identityagent":                    {Type: DirectivePath, Values: []string{"none", "SSH_AUTH_SOCK"}},
+	"identityfile":                     {Type: DirectivePath, Multi: true},
+	"kbdinteractiveauthentication":     {Type: DirectiveYesNo},
+	"localforward":                     {Type: DirectiveForward, Multi: true},
+	"loglevel":                         {Type: DirectiveChoice, Values: []string{"QUIET", "FATAL", "ERROR", "INFO", "VERBOSE", "DEBUG", "DEBUG1", "DEBUG2", "DEBUG3"}},
+	"nohostauthenticationforlocalhost": {Type: DirectiveYesNo},
+	"numberofpasswordprompts":          {Type: DirectiveInteger},
+	"passwordauthentication":           {Type: DirectiveYesNo},
+	"permitlocalcommand":               {Type: DirectiveYesNo},
+	"port":                             {Type: DirectivePort},
+	"proxyusefdpass":                   {Type: DirectiveYesNo},
+	"pubkeyauthentication":             {Type: DirectiveChoice, Values: []string{"yes", "no", "unbound", "host-bound"}},
+	"remoteforward":                    {Type: DirectiveForward, Multi: true},
+	"requesttty":                       {Type: DirectiveChoice, Values: []string{"yes", "no", "force", "auto"}},
+	"requiredrsasize":                  {Type: DirectiveInteger},
+	"revokedhostkeys":                  {Type: DirectivePath},
+	"sendenv":                          {Type: DirectiveString, Multi: true},
+	"serveralivecountmax":              {Type: DirectiveInteger},
+	"serveraliveinterval":              {Type: DirectiveDuration},
+	"sessiontype":                      {Type: DirectiveChoice, Values: []string{"none", "subsystem", "default"}},
+	"setenv":                           {Type: DirectiveString, Multi: true},
+	"stdinnull":                        {Type: DirectiveYesNo},
+	"streamlocalbindunlink":            {Type: DirectiveYesNo},
+	"stricthostkeychecking":            {Type: DirectiveChoice, Values: []string{"yes", "no", "ask", "accept-new", "off"}},
+	"tcpkeepalive":                     {Type: DirectiveYesNo},
+	"tunnel":                           {Type: DirectiveChoice, Values: []string{"yes", "no", "point-to-point", "ethernet"}},
+	"updatehostkeys":                   {Type: DirectiveChoice, Values: []string{"yes", "no", "ask"}},
+	"usekeychain":                      {Type: DirectiveYesNo},
+	"verifyhostkeydns":                 {Type: DirectiveChoice, Values: []string{"yes", "no", "ask"}},
+	"visualhostkey":                    {Type: DirectiveYesNo},
+	"xauthlocation":                    {Type: DirectivePath},
+}
+
+// durationPattern matches the time format of ssh_config(5): a number of
+// seconds, or numbers with s, m, h, d or w units run together such as 1h30m
+var durationPattern = regexp.MustCompile(`^([0-9]+[sSmMhHdDwW]?)+$`)
+
+// LookupKeyword returns the spec of a keyword. Unknown keywords are reported
+// as free-form strings under the spelling they were given.
+func LookupKeyword(key string) KeywordSpec {
+	spec := keywordSpecs[strings.ToLower(key)]
+	if spec.Type == "" {
+		spec.Type = DirectiveString
+	}
+	spec.Name = key
+	if canonical, ok := CanonicalKeyword(key); ok {
+		spec.Name = canonical
+	}
+	return spec
+}
+
+// IsMultiValueKeyword reports whether every occurrence of a keyword is used
+// instead of only the first one
+func IsMultiValueKeyword(key string) bool {
+	return keywordSpecs[strings.ToLower(key)].Multi
+}
+
+// ValidateDirective checks that a value suits the type of its keyword
+func ValidateDirective(key, value string) error {
+	if strings.TrimSpace(key) == "" {
+		return fmt.Errorf("option keyword is empty")
+	}
+	if strings.ContainsAny(key, " \t=\"") {
+		return fmt.Errorf("invalid option keyword '%s'", key)
+	}
+	if strings.ContainsAny(value, "\n\r\x00") {
+		return fmt.Errorf("%s: value must be on a single line", key)
+	}
+
+	spec := LookupKeyword(key)
+	if value == "" {
+		return fmt.Errorf("%s: value is required", spec.Name)
+	}
+	for _, accepted := range spec.Values {
+		if spec.Type != DirectiveChoice && strings.EqualFold(value, accepted) {
+			return nil
+		}
+	}
+
+	switch spec.Type {
+	case DirectiveYesNo:
+		if !strings.EqualFold(value, "yes") && !strings.EqualFold(value, "no") {
+			return fmt.Errorf("%s: expected yes or no, got '%s'", spec.Name, value)
+		}
+	case DirectiveChoice:
+		for _, accepted := range spec.Values {
+			if strings.EqualFold(value, accepted) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: expected one of %s, got '%s'", spec.Name, strings.Join(spec.Values, ", "), value)
+	case DirectiveInteger:
+		if _, err := strconv.ParseUint(value, 10, 31); err != nil {
+			return fmt.Errorf("%s: expected a number, got '%s'", spec.Name, value)
+		}
+	case DirectivePort:
+		if !isValidPortValue(value) {
+			return fmt.Errorf("%s: expected a port between 1 and 65535, got '%s'", spec.Name, value)
+		}
+	case DirectiveDuration:
+		if !durationPattern.MatchString(value) {
+			return fmt.Errorf("%s: expected a time such as 30, 45s or 1h30m, got '%s'", spec.Name, value)
+		}
+	case DirectivePath:
+		args, err := SplitArgs(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", spec.Name, err)
+		}
+		if len(args) != 1 {
+			return fmt.Errorf("%s: expected a single path, quote paths containing spaces", spec.Name)
+		}
+	case DirectiveForward:
+		return validateForward(spec, value)
+	}
+	return nil
+}
+
+// isValidPortValue reports whether a value is a port number between 1 and 65535
+func isValidPortValue(value string) bool {
+	port, err := strconv.Atoi(value)
+	return err == nil && port >= 1 && port <= 65535
+}
+
+// validateForward checks a LocalForward, RemoteForward or DynamicForward value:
+// a listen address followed, except for dynamic forwards, by a destination.
+// Either side may be a Unix socket path.
+func validateForward(spec KeywordSpec, value string) error {
+	args, err := SplitArgs(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", spec.Name, err)
+	}
+	want := "[bind_address:]port host:hostport"
+	min, max := 2, 2
+	switch strings.ToLower(spec.Name) {
+	case "dynamicforward":
+		want = "[bind_address:]port"
+		min, max = 1, 1
+	case "remoteforward":
+		// Without a destination the remote side acts as a SOCKS proxy
+		min = 1
+	}
+	if len(args) < min || len(args) > max {
+		return fmt.Errorf("%s: expected %s, got '%s'", spec.Name, want, value)
+	}
+
+	if !isForwardEndpoint(args[0], false) {
+		return fmt.Errorf("%s: invalid listen address '%s'", spec.Name, args[0])
+	}
+	if len(args) == 2 && !isForwardEndpoint(args[1], true) {
+		return fmt.Errorf("%s: invalid destination '%s', expected host:port", spec.Name, args[1])
+	}
+	return nil
+}
+
+// isForwardEndpoint reports whether a forwarding argument is a Unix socket
+// path or ends in a port. Destinations must also name a host.
+func isForwardEndpoint(arg string, destination bool) bool {
+	if strings.Contains(arg, "/") || strings.ContainsAny(arg, "%$") {
+		return true
+	}
+	colon := strings.LastIndex(arg, ":")
+	if colon < 0 {
+		return !destination && isValidPortValue(arg)
+	}
+	port := arg[colon+1:]
+	if !destination && port == "0" {
+		// A listen port of 0 lets the server pick one
+		return true
+	}
+	return colon > 0 && isValidPortValue(port)
+}
+
+// ParseCommandOptions parses options written as on the ssh command line,
+// such as "-o Compression=yes -o ServerAliveInterval=60", and validates them.
+// Values holding spaces or "-o" are quoted, as FormatCommandOptions writes them.
+func ParseCommandOptions(options string) ([]Directive, error) {
+	parts, err := splitCommandOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	var directives []Directive
+	for _, part := range parts {
+		key, value := SplitDirective(part)
+		if err := ValidateDirective(key, value); err != nil {
+			return nil, err
+		}
+		directives = append(directives, Directive{Key: LookupKeyword(key).Name, Value: value})
+	}
+	return directives, nil
+}
+
+// SplitDirective splits a directive written as "Key value", "Key=value" or
+// "Key = value" into its keyword and value, separated as on config lines:
+// whitespace, at most one '=', whitespace
+func SplitDirective(directive string) (string, string) {
+	directive = strings.TrimSpace(directive)
+	end := strings.IndexAny(directive, " \t=")
+	if end < 0 {
+		return directive, ""
+	}
+	rest := strings.TrimLeft(directive[end:], " \t")
+	rest = strings.TrimPrefix(rest, "=")
+	return directive[:end], strings.TrimSpace(rest)
+}
+
+// splitCommandOptions splits a command line options string into the
+// "Key=value" of its -o flags. Arguments following an option without a -o of
+// their own, as in "-o SendEnv=LANG LC_*", are part of its value.
+func splitCommandOptions(options string) ([]string, error) {
+	args, err := SplitArgs(options)
+	if err != nil {
+		return nil, err
+	}
+	var parts []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-o":
+			if i+1 < len(args) {
+				i++
+				parts = append(parts, args[i])
+			}
+		case strings.HasPrefix(arg, "-o"):
+			parts = append(parts, arg[2:])
+		case len(parts) > 0:
+			parts[len(parts)-1] += " " + QuoteArg(arg)
+		default:
+			parts = append(parts, arg)
+		}
+	}
+	return parts, nil
+}
+
+// FormatCommandOptions writes directives as ssh command line options, quoting
+// the values ParseCommandOptions would otherwise split
+func FormatCommandOptions(directives []Directive) string {
+	parts := make([]string, 0, len(directives))
+	for _, d := range directives {
+		value := d.Value
+		if value != "" {
+			value = QuoteArg(value)
+		}
+		parts = append(parts, fmt.Sprintf("-o %s=%s", d.Key, value))
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseConfigOptions parses options in config format, one "Key value" per line
+func parseConfigOptions(options string) []Directive {
+	var directives []Directive
+	for _, line := range strings.Split(options, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, " ", 2)
+		d := Directive{Key: parts[0]}
+		if len(parts) == 2 {
+			d.Value = strings.TrimSpace(parts[1])
+		}
+		directives = append(directives, d)
+	}
+	return directives
+}
+
+// formatConfigOptions writes directives in config format, one per line
+func formatConfigOptions(directives []Directive) string {
+	lines := make([]string, 0, len(directives))
+	for _, d := range directives {
+		lines = append(lines, d.Key+" "+d.Value)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Get returns the value ssh uses for a keyword in this host's block: the first
+// occurrence, or "" if the keyword is not set
+func (h *SSHHost) Get(key string) string {
+	for _, d := range h.Directives {
+		if strings.EqualFold(d.Key, key) {
+			return d.Value
+		}
+	}
+	return ""
+}
+
+// GetAll returns every value of a keyword in order, as used for IdentityFile,
+// LocalForward and other keywords that may be repeated
+func (h *SSHHost) GetAll(key string) []string {
+	var values []string
+	for _, d := range h.Directives {
+		if strings.EqualFold(d.Key, key) {
+			values = append(values, d.Value)
+		}
+	}
+	return values
+}
+
+// Set replaces the first occurrence of a keyword, or adds it at the end of the
+// block. An empty value removes the first occurrence.
+func (h *SSHHost) Set(key, value string) {
+	for i, d := range h.Directives {
+		if !strings.EqualFold(d.Key, key) {
+			continue
+		}
+		if value == "" {
+			h.Directives = append(h.Directives[:i:i], h.Directives[i+1:]...)
+		} else {
+			h.Directives[i].Value = value
+		}
+		h.syncField(key)
+		return
+	}
+	if value != "" {
+		h.Directives = append(h.Directives, Directive{Key: LookupKeyword(key).Name, Value: value})
+	}
+	h.syncField(key)
+}
+
+// SetAll replaces every occurrence of a keyword with the given values, kept
+// in order where the keyword first appeared
+func (h *SSHHost) SetAll(key string, values []string) {
+	name := LookupKeyword(key).Name
+	directives := make([]Directive, 0, len(h.Directives)+len(values))
+	inserted := false
+	insert := func() {
+		for _, value := range values {
+			if value != "" {
+				directives = append(directives, Directive{Key: name, Value: value})
+			}
+		}
+		inserted = true
+	}
+
+	for _, d := range h.Directives {
+		if strings.EqualFold(d.Key, key) {
+			if !inserted {
+				insert()
+			}
+			continue
+		}
+		directives = append(directives, d)
+	}
+	if !inserted {
+		insert()
+	}
+	h.Directives = directives
+	h.syncField(key)
+}
+
+// OtherDirectives returns the directives not shown in a dedicated field: every
+// keyword without a field, and the repeated occurrences of those with one
+func (h *SSHHost) OtherDirectives() []Directive {
+	var others []Directive
+	seen := make(map[string]bool)
+	for _, d := range h.Directives {
+		key := strings.ToLower(d.Key)
+		if isHostFieldKey(key) && !seen[key] {
+			seen[key] = true
+			continue
+		}
+		others = append(others, d)
+	}
+	return others
+}
+
+// SetOtherDirectives replaces the directives not shown in a dedicated field,
+// keeping the first occurrence of each field keyword
+func (h *SSHHost) SetOtherDirectives(directives []Directive) {
+	var kept []Directive
+	seen := make(map[string]bool)
+	for _, d := range h.Directives {
+		key := strings.ToLower(d.Key)
+		if isHostFieldKey(key) && !seen[key] {
+			seen[key] = true
+			kept = append(kept, d)
+		}
+	}
+	h.Directives = append(kept, directives...)
+	for _, field := range hostFields {
+		h.syncField(field.key)
+	}
+	h.Options = formatConfigOptions(h.OtherDirectives())
+}
+
+// ValidateDirectives checks the value of every directive of the host
+func (h *SSHHost) ValidateDirectives() error {
+	for _, d := range h.Directives {
+		if err := ValidateDirective(d.Key, d.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncField updates the dedicated field and the options string mirroring a
+// keyword after its directives changed
+func (h *SSHHost) syncField(key string) {
+	value := h.Get(key)
+	switch strings.ToLower(key) {
+	case "hostname":
+		h.Hostname = value
+	case "user":
+		h.User = value
+	case "port":
+		h.Port = value
+	case "identityfile":
+		h.Identity = value
+	case "proxyjump":
+		h.ProxyJump = value
+	}
+	h.Options = formatConfigOptions(h.OtherDirectives())
+}
+
+// directivesToWrite returns the directives a host's block should hold. Hosts
+// built without directives are written from their fields and options. For
+// hosts read from a config, fields and options changed directly take
+// precedence over the directives they mirror.
+func (h SSHHost) directivesToWrite() []Directive {
+	var directives []Directive
+	if h.Directives == nil {
+		for _, field := range hostFields {
+			if value := field.value(h); value != "" {
+				directives = append(directives, Directive{Key: field.key, Value: value})
+			}
+		}
+		return append(directives, parseConfigOptions(h.Options)...)
+	}
+
+	model := SSHHost{Directives: append([]Directive(nil), h.Directives...)}
+	if h.Options != formatConfigOptions(model.OtherDirectives()) {
+		model.SetOtherDirectives(parseConfigOptions(h.Options))
+	}
+	for _, field := range hostFields {
+		if value := field.value(h); value != model.Get(field.key) {
+			model.Set(field.key, value)
+		}
+	}
+	return model.Directives
+}
+
+// MatchDirectiveQuery matches a search query of the form keyword=value, such
+// as "user=deploy" or "localforward=8080", against every value the host sets
+// for the keyword, ignoring case. ok is false when the query doesn't name a
+// known keyword.
+func (h *SSHHost) MatchDirectiveQuery(query string) (matched, ok bool) {
+	key, want, found := strings.Cut(query, "=")
+	if !found {
+		return false, false
+	}
+	key = strings.TrimSpace(key)
+	if _, known := CanonicalKeyword(key); !known {
+		return false, false
+	}
+
+	values := h.GetAll(key)
+	if len(values) == 0 {
+		// Hosts built without directives only have their fields
+		for _, field := range hostFields {
+			if strings.EqualFold(field.key, key) && field.value(*h) != "" {
+				values = []string{field.value(*h)}
+			}
+		}
+	}
+
+	want = strings.ToLower(strings.TrimSpace(want))
+	for _, value := range values {
+		if strings.Contains(strings.ToLower(value), want) {
+			return true, true
+		}
+	}
+	return false, true
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeepsRepeatedDirectivesInOrder(t *testing.T) {
+	path := writeTestConfig(t, `Host web
+    HostName web.example.com
+    IdentityFile ~/.ssh/first
+    LocalForward 8080 localhost:80
+    User deploy
+    IdentityFile ~/.ssh/second
+    LocalForward 8443 localhost:443
+    User ignored
+`)
+
+	hosts, err := ParseSSHConfigFile(path)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	host := hosts[0]
+
+	if got, want := host.GetAll("IdentityFile"), []string{"~/.ssh/first", "~/.ssh/second"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAll(IdentityFile) = %v, want %v", got, want)
+	}
+	if got, want := host.GetAll("localforward"), []string{"8080 localhost:80", "8443 localhost:443"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAll(LocalForward) = %v, want %v", got, want)
+	}
+
+	// Like ssh, fields take the first value given
+	if host.Identity != "~/.ssh/first" || host.User != "deploy" {
+		t.Errorf("expected the first values, got Identity=%q User=%q", host.Identity, host.User)
+	}
+	if got := host.Directives[4].Line; got != 6 {
+		t.Errorf("expected directive line numbers, got %d", got)
+	}
+
+	wantOthers := []string{"LocalForward", "IdentityFile", "LocalForward", "User"}
+	var others []string
+	for _, d := range host.OtherDirectives() {
+		others = append(others, d.Key)
+	}
+	if !reflect.DeepEqual(others, wantOthers) {
+		t.Errorf("OtherDirectives() = %v, want %v", others, wantOthers)
+	}
+}
+
+func TestUpdateHostThroughDirectives(t *testing.T) {
+	path := writeTestConfig(t, `Host web
+    HostName web.example.com
+    # keys
+    IdentityFile ~/.ssh/first
+    IdentityFile ~/.ssh/second
+    SendEnv LANG
+    Compression yes
+`)
+
+	host, err := GetSSHHostFromFile("web", path)
+	if err != nil {
+		t.Fatalf("GetSSHHostFromFile() error = %v", err)
+	}
+
+	host.SetAll("IdentityFile", []string{"~/.ssh/first", "~/.ssh/third", "~/.ssh/second"})
+	host.Set("Compression", "")
+	host.SetAll("SendEnv", []string{"LANG", "LC_*"})
+	host.Set("User", "deploy")
+	if err := UpdateSSHHostInFile("web", *host, path); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() error = %v", err)
+	}
+
+	want := `Host web
+    HostName web.example.com
+    # keys
+    IdentityFile ~/.ssh/first
+    IdentityFile ~/.ssh/third
+    IdentityFile ~/.ssh/second
+    User deploy
+    SendEnv LANG
+    SendEnv LC_*
+`
+	if got := readTestConfig(t, path); got != want {
+		t.Errorf("config:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUpdateHostFieldsKeepRepeatedDirectives(t *testing.T) {
+	path := writeTestConfig(t, "Host web\n    IdentityFile ~/.ssh/first\n    IdentityFile ~/.ssh/second\n")
+
+	host, err := GetSSHHostFromFile("web", path)
+	if err != nil {
+		t.Fatalf("GetSSHHostFromFile() error = %v", err)
+	}
+
+	// Fields assigned directly replace the first occurrence only
+	host.Identity = "~/.ssh/new"
+	if err := UpdateSSHHostInFile("web", *host, path); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() error = %v", err)
+	}
+
+	want := "Host web\n    IdentityFile ~/.ssh/new\n    IdentityFile ~/.ssh/second\n"
+	if got := readTestConfig(t, path); got != want {
+		t.Errorf("config = %q, want %q", got, want)
+	}
+}
+
+func TestValidateDirective(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{"Compression", "yes", false},
+		{"compression", "NO", false},
+		{"Compression", "maybe", true},
+		{"ServerAliveInterval", "60", false},
+		{"ConnectTimeout", "1m30s", false},
+		{"ServerAliveInterval", "1 minute", true},
+		{"ControlPersist", "yes", false},
+		{"ControlPersist", "10m", false},
+		{"Port", "2222", false},
+		{"Port", "0", true},
+		{"Port", "70000", true},
+		{"ConnectionAttempts", "3", false},
+		{"ConnectionAttempts", "-1", true},
+		{"StrictHostKeyChecking", "accept-new", false},
+		{"StrictHostKeyChecking", "sometimes", true},
+		{"IdentityFile", "~/.ssh/id_%h", false},
+		{"IdentityFile", `"~/My Keys/id"`, false},
+		{"IdentityFile", "~/My Keys/id", true},
+		{"LocalForward", "8080 localhost:80", false},
+		{"LocalForward", "127.0.0.1:8080 db.internal:5432", false},
+		{"LocalForward", "8080", true},
+		{"LocalForward", "8080 localhost", true},
+		{"RemoteForward", "9000", false},
+		{"DynamicForward", "localhost:1080", false},
+		{"SetEnv", "FOO=bar", false},
+		{"UnknownOption", "anything", false},
+		{"Compression", "", true},
+		{"Bad Key", "yes", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateDirective(tt.key, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateDirective(%q, %q) error = %v, wantErr %v", tt.key, tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseCommandOptions(t *testing.T) {
+	got, err := ParseCommandOptions(`-o compression=yes -o "SetEnv = FOO=bar" -oSendEnv=LANG LC_*`)
+	if err != nil {
+		t.Fatalf("ParseCommandOptions() error = %v", err)
+	}
+	want := []Directive{
+		{Key: "Compression", Value: "yes"},
+		{Key: "SetEnv", Value: "FOO=bar"},
+		{Key: "SendEnv", Value: "LANG LC_*"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCommandOptions() = %v, want %v", got, want)
+	}
+	if formatted := FormatCommandOptions(got); formatted != `-o Compression=yes -o SetEnv=FOO=bar -o SendEnv="LANG LC_*"` {
+		t.Errorf("FormatCommandOptions() = %q", formatted)
+	}
+
+	if _, err := ParseCommandOptions("-o ServerAliveInterval=often"); err == nil {
+		t.Error("expected an error for an invalid duration")
+	}
+	if _, err := ParseCommandOptions(`-o ProxyCommand="nc %h %p`); err == nil {
+		t.Error("expected an error for an unterminated quote")
+	}
+}
+
+func TestCommandOptionsRoundTrip(t *testing.T) {
+	directives := []Directive{
+		{Key: "ProxyCommand", Value: "ssh -o StrictHostKeyChecking=no -W %h:%p bastion"},
+		{Key: "RemoteCommand", Value: `sh -c "tmux attach -t main -o logs"`},
+		{Key: "SendEnv", Value: "LANG LC_*"},
+		{Key: "IdentityAgent", Value: `C:\agent\`},
+		{Key: "Compression", Value: "yes"},
+	}
+
+	got, err := ParseCommandOptions(FormatCommandOptions(directives))
+	if err != nil {
+		t.Fatalf("ParseCommandOptions() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, directives) {
+		t.Errorf("round-trip = %v, want %v", got, directives)
+	}
+}
+
+func TestParseCommandOptionsUnquotedArguments(t *testing.T) {
+	got, err := ParseCommandOptions(`-o ProxyCommand=sh -c "nc %h %p" -o User=deploy`)
+	if err != nil {
+		t.Fatalf("ParseCommandOptions() error = %v", err)
+	}
+	want := []Directive{
+		{Key: "ProxyCommand", Value: `sh -c "nc %h %p"`},
+		{Key: "User", Value: "deploy"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCommandOptions() = %v, want %v", got, want)
+	}
+}
+
+func TestMatchDirectiveQuery(t *testing.T) {
+	host := SSHHost{Name: "web"}
+	host.Set("User", "deploy")
+	host.SetAll("LocalForward", []string{"8080 localhost:80", "5432 db:5432"})
+
+	tests := []struct {
+		query       string
+		wantMatched bool
+		wantOK      bool
+	}{
+		{"user=DEP", true, true},
+		{"localforward=5432", true, true},
+		{"port=2222", false, true},
+		{"web", false, false},
+		{"notakeyword=x", false, false},
+	}
+	for _, tt := range tests {
+		matched, ok := host.MatchDirectiveQuery(tt.query)
+		if matched != tt.wantMatched || ok != tt.wantOK {
+			t.Errorf("MatchDirectiveQuery(%q) = %v, %v, want %v, %v", tt.query, matched, ok, tt.wantMatched, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// SystemSSHConfigPath is the system-wide client configuration, read after the
+// user's config when no explicit config file is given
+var SystemSSHConfigPath = "/etc/ssh/ssh_config"
+
+// Sources used for values that were not read from a config block
+const (
+	SourceGlobal  = "(global)"
+	SourceDefault = "(default)"
+)
+
+// EffectiveValue is the value a keyword takes for a host and where it was set
+type EffectiveValue struct {
+	Key        string // Keyword as written in the config file
+	Value      string
+	Source     string // Header of the block that set it (e.g. "Host *.prod"), SourceGlobal or SourceDefault
+	SourceFile string // Empty for defaults
+	Line       int    // 1-based line number, 0 for defaults
+}
+
+// EffectiveConfig is the configuration ssh would use for a host, similar to
+// the output of "ssh -G"
+type EffectiveConfig struct {
+	Host        string
+	Values      []EffectiveValue // In the order the keywords were first set
+	Conditional []AppliedMatch   // Match blocks that may apply but could not be evaluated
+}
+
+// Get returns the first effective value of a keyword
+func (c *EffectiveConfig) Get(key string) (EffectiveValue, bool) {
+	for _, value := range c.Values {
+		if strings.EqualFold(value.Key, key) {
+			return value, true
+		}
+	}
+	return EffectiveValue{}, false
+}
+
+// GetAll returns every effective value of a keyword, for multi-valued keywords
+func (c *EffectiveConfig) GetAll(key string) []EffectiveValue {
+	var values []EffectiveValue
+	for _, value := range c.Values {
+		if strings.EqualFold(value.Key, key) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Value returns the first effective value of a keyword, or "" if it is not set
+func (c *EffectiveConfig) Value(key string) string {
+	value, _ := c.Get(key)
+	return value.Value
+}
+
+// Hostname returns the effective address ssh connects to
+func (c *EffectiveConfig) Hostname() string { return c.Value("HostName") }
+
+// User returns the effective remote user
+func (c *EffectiveConfig) User() string { return c.Value("User") }
+
+// Port returns the effective port
+func (c *EffectiveConfig) Port() string { return c.Value("Port") }
+
+// ResolveEffectiveConfig applies OpenSSH's first-match-wins rules to compute the
+// configuration used to connect to hostName. An empty configFile selects the
+// default SSH config followed by the system config; like "ssh -F", an explicit
+// config file is read on its own.
+func ResolveEffectiveConfig(hostName, configFile string) (*EffectiveConfig, error) {
+	files := []string{configFile}
+	if configFile == "" {
+		defaultPath, err := GetDefaultSSHConfigPath()
+		if err != nil {
+			return nil, err
+		}
+		files = []string{defaultPath}
+		if IsSystemConfigEnabled() {
+			files = append(files, SystemSSHConfigPath)
+		}
+	}
+
+	r := &resolver{
+		effective: &EffectiveConfig{Host: hostName},
+		processed: make(map[string]bool),
+	}
+	for _, file := range files {
+		if err := r.resolveFile(file); err != nil {
+			return nil, err
+		}
+	}
+	r.applyDefaults()
+
+	return r.effective, nil
+}
+
+// resolver walks config files in order, collecting effective values
+type resolver struct {
+	effective *EffectiveConfig
+	processed map[string]bool
+}
+
+// resolveFile applies the blocks of a config file that match the host,
+// following includes where they appear
+func (r *resolver) resolveFile(configPath string) error {
+	absPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve absolute path for %s: %w", configPath, err)
+	}
+	if r.processed[absPath] {
+		return nil
+	}
+	r.processed[absPath] = true
+
+	cfg, err := readConfigFile(configPath, false)
+	if err != nil {
+		return err
+	}
+
+	for _, block := range cfg.Blocks {
+		source := SourceGlobal
+		if block.Header != nil {
+			source = block.Header.Key + " " + block.Header.Value
+		}
+
+		switch {
+		case block.IsHost():
+			if !MatchHostPatterns(block.Patterns(), r.effective.Host) {
+				continue
+			}
+		case block.IsMatch():
+			match := MatchBlock{
+				Condition:  block.Header.Value,
+				Criteria:   ParseMatchCriteria(block.Header.Value),
+				SourceFile: absPath,
+				Line:       block.Header.Number,
+			}
+			for _, line := range block.Directives() {
+				match.Directives = append(match.Directives, Directive{Key: line.Key, Value: line.Value, Line: line.Number})
+			}
+
+			status := match.Evaluate(r.currentHost())
+			if status == MatchMaybe {
+				r.effective.Conditional = append(r.effective.Conditional, AppliedMatch{Block: match, Status: status})
+			}
+			if status != MatchYes {
+				continue
+			}
+		}
+
+		for _, line := range block.Directives() {
+			if line.Value == "" {
+				continue
+			}
+
+			if strings.EqualFold(line.Key, "include") {
+				for _, pattern := range splitQuotedArgs(line.Value) {
+					files, err := resolveIncludePattern(pattern, configPath)
+					if err != nil {
+						continue
+					}
+					for _, file := range files {
+						// Skip files that can't be read rather than failing completely
+						_ = r.resolveFile(file)
+					}
+				}
+				continue
+			}
+
+			r.set(EffectiveValue{
+				Key:        line.Key,
+				Value:      line.Value,
+				Source:     source,
+				SourceFile: absPath,
+				Line:       line.Number,
+			})
+		}
+	}
+
+	return nil
+}
+
+// set records a value unless the keyword was already set by an earlier block
+func (r *resolver) set(value EffectiveValue) {
+	if !IsMultiValueKeyword(value.Key) {
+		if _, exists := r.effective.Get(value.Key); exists {
+			return
+		}
+	}
+	if isSingleArgKeyword(value.Key) {
+		value.Value = UnquoteArg(value.Value)
+	}
+	if strings.EqualFold(value.Key, "hostname") {
+		value.Value = expandHostToken(value.Value, r.effective.Host)
+	}
+	r.effective.Values = append(r.effective.Values, value)
+}
+
+// isSingleArgKeyword reports whether a keyword takes a single argument, which
+// ssh reads without the quotes around it
+func isSingleArgKeyword(key string) bool {
+	switch strings.ToLower(key) {
+	case "hostname", "user", "proxyjump", "hostkeyalias":
+		return true
+	}
+	switch keywordSpecs[strings.ToLower(key)].Type {
+	case DirectiveYesNo, DirectiveChoice, DirectiveInteger, DirectivePort, DirectiveDuration, DirectivePath:
+		return true
+	}
+	return false
+}
+
+// currentHost describes the host as resolved so far, for Match evaluation
+func (r *resolver) currentHost() SSHHost {
+	return SSHHost{
+		Name:     r.effective.Host,
+		Aliases:  []string{r.effective.Host},
+		Hostname: r.effective.Hostname(),
+		User:     r.effective.User(),
+	}
+}
+
+// applyDefaults fills in the values ssh uses when the config sets none
+func (r *resolver) applyDefaults() {
+	defaults := []EffectiveValue{
+		{Key: "HostName", Value: r.effective.Host},
+		{Key: "User", Value: currentUsername()},
+		{Key: "Port", Value: "22"},
+	}
+	for _, value := range defaults {
+		if value.Value == "" {
+			continue
+		}
+		value.Source = SourceDefault
+		r.set(value)
+	}
+}
+
+// expandHostToken replaces the %h token of a HostName value
+func expandHostToken(value, host string) string {
+	value = strings.ReplaceAll(value, "%%", "\x00")
+	value = strings.ReplaceAll(value, "%h", host)
+	return strings.ReplaceAll(value, "\x00", "%")
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveEffectiveConfig(t *testing.T) {
+	content := `User globaluser
+
+Host web
+    HostName %h.example.com
+    IdentityFile ~/.ssh/web
+
+Host *.prod !db.prod web
+    Port 2222
+    User produser
+    IdentityFile ~/.ssh/prod
+
+Match originalhost web exec "true"
+    Compression yes
+
+Match originalhost web
+    ForwardAgent yes
+    Port 3333
+
+Host *
+    ServerAliveInterval 60
+`
+	path := writeTestConfig(t, content)
+
+	cfg, err := ResolveEffectiveConfig("web", path)
+	if err != nil {
+		t.Fatalf("ResolveEffectiveConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		value  string
+		source string
+		line   int
+	}{
+		{"hostname", "web.example.com", "Host web", 4},
+		{"user", "globaluser", SourceGlobal, 1},
+		{"port", "2222", "Host *.prod !db.prod web", 8},
+		{"forwardagent", "yes", "Match originalhost web", 16},
+		{"serveraliveinterval", "60", "Host *", 20},
+	}
+	for _, tt := range tests {
+		got, ok := cfg.Get(tt.key)
+		if !ok {
+			t.Errorf("Get(%q) not found", tt.key)
+			continue
+		}
+		if got.Value != tt.value || got.Source != tt.source || got.Line != tt.line {
+			t.Errorf("Get(%q) = {%q %q %d}, want {%q %q %d}",
+				tt.key, got.Value, got.Source, got.Line, tt.value, tt.source, tt.line)
+		}
+	}
+
+	if identities := cfg.GetAll("IdentityFile"); len(identities) != 2 {
+		t.Errorf("expected 2 identity files, got %d", len(identities))
+	}
+	if _, ok := cfg.Get("Compression"); ok {
+		t.Error("a Match block with exec should not be applied")
+	}
+	if len(cfg.Conditional) != 1 {
+		t.Errorf("expected 1 conditional Match block, got %d", len(cfg.Conditional))
+	}
+
+	other, err := ResolveEffectiveConfig("db.prod", path)
+	if err != nil {
+		t.Fatalf("ResolveEffectiveConfig() error = %v", err)
+	}
+	if got, _ := other.Get("Port"); got.Value != "22" || got.Source != SourceDefault {
+		t.Errorf("negated host should get the default port, got %q from %q", got.Value, got.Source)
+	}
+	if other.Hostname() != "db.prod" {
+		t.Errorf("Hostname() = %q, want %q", other.Hostname(), "db.prod")
+	}
+}
+
+func TestResolveEffectiveConfigUnquotesValues(t *testing.T) {
+	path := writeTestConfig(t, "Host web\n    HostName \"%h.example.com\"\n    User \"bob\"\n    Port \"2222\"\n    IdentityFile \"~/.ssh/my key\"\n    ProxyJump 'bastion'\n    SendEnv \"LANG LC_*\"\n")
+
+	cfg, err := ResolveEffectiveConfig("web", path)
+	if err != nil {
+		t.Fatalf("ResolveEffectiveConfig() error = %v", err)
+	}
+	tests := []struct {
+		key   string
+		value string
+	}{
+		{"HostName", "web.example.com"},
+		{"User", "bob"},
+		{"Port", "2222"},
+		{"IdentityFile", "~/.ssh/my key"},
+		{"ProxyJump", "bastion"},
+		// Free-form values are kept as written
+		{"SendEnv", `"LANG LC_*"`},
+	}
+	for _, tt := range tests {
+		if got := cfg.Value(tt.key); got != tt.value {
+			t.Errorf("Value(%q) = %q, want %q", tt.key, got, tt.value)
+		}
+	}
+}
+
+func TestResolveEffectiveConfigFollowsIncludesInPlace(t *testing.T) {
+	path := writeTestConfig(t, "Include extra\n\nHost *\n    User fallback\n")
+	extra := filepath.Join(filepath.Dir(path), "extra")
+	if err := os.WriteFile(extra, []byte("Host app\n    User included\n"), 0600); err != nil {
+		t.Fatalf("Failed to write include: %v", err)
+	}
+
+	cfg, err := ResolveEffectiveConfig("app", path)
+	if err != nil {
+		t.Fatalf("ResolveEffectiveConfig() error = %v", err)
+	}
+	got, _ := cfg.Get("User")
+	if got.Value != "included" || got.SourceFile != extra {
+		t.Errorf("User = %q from %q, want %q from %q", got.Value, got.SourceFile, "included", extra)
+	}
+}
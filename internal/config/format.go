@@ -0,0 +1,282 @@
+package config
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// formatIndent is the indentation of directives inside Host and Match blocks,
+// the same sshm uses when it writes a new block
+const formatIndent = "    "
+
+// FormatOptions controls how config files are formatted
+type FormatOptions struct {
+	SortHosts bool // Sort Host blocks by name where it can't change their meaning
+}
+
+// FormattedFile is the content of a config file before and after formatting
+type FormattedFile struct {
+	Path      string
+	Original  []byte
+	Formatted []byte
+}
+
+// Changed reports whether formatting changes the file
+func (f FormattedFile) Changed() bool {
+	return string(f.Original) != string(f.Formatted)
+}
+
+// ConfigTreeFiles returns the config file at configPath, or the default config
+// file when configPath is empty, and every existing file it includes, sorted
+func ConfigTreeFiles(configPath string) ([]string, error) {
+	files, err := GetAllConfigFilesFromBase(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing []string
+	for _, file := range files {
+		if _, err := os.Stat(file); err == nil {
+			existing = append(existing, file)
+		}
+	}
+	sort.Strings(existing)
+	return existing, nil
+}
+
+// FormatFiles formats config files without writing them
+func FormatFiles(paths []string, opts FormatOptions) ([]FormattedFile, error) {
+	var files []FormattedFile
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, FormattedFile{
+			Path:      path,
+			Original:  data,
+			Formatted: FormatConfigBytes(path, data, opts),
+		})
+	}
+	return files, nil
+}
+
+// WriteFormattedFiles formats config files and writes the ones that change,
+// as a single backed-up change
+func WriteFormattedFiles(paths []string, opts FormatOptions) error {
+	return modifyConfigFiles(paths, func(files []*ConfigFile) error {
+		for _, cfg := range files {
+			*cfg = *ParseConfigBytes(cfg.Path, FormatConfigBytes(cfg.Path, cfg.Bytes(), opts))
+		}
+		return nil
+	})
+}
+
+// FormatConfigBytes returns the canonical form of a config file: directives
+// indented by four spaces inside blocks, known keywords in their documented
+// casing, a single space between keyword and value, one blank line between
+// blocks and "# Tags:" comments merged directly above their Host line.
+// Every comment and the line endings of the file are kept; a UTF-8 byte
+// order mark, which ssh rejects, is dropped.
+func FormatConfigBytes(path string, data []byte, opts FormatOptions) []byte {
+	cfg := ParseConfigBytes(path, data)
+
+	sections := make([]formatSection, 0, len(cfg.Blocks))
+	for i, block := range cfg.Blocks {
+		sections = append(sections, formatBlock(block, i == 0))
+	}
+	if opts.SortHosts {
+		sortHostSections(sections[1:])
+	}
+
+	var groups [][]string
+	for _, section := range sections {
+		if len(section.lines) > 0 {
+			groups = append(groups, section.lines)
+		}
+		if len(section.after) > 0 {
+			groups = append(groups, section.after)
+		}
+	}
+
+	var lines []string
+	for i, group := range groups {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, group...)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	newline := cfg.newline()
+	return []byte(strings.Join(lines, newline) + newline)
+}
+
+// formatSection is a formatted block
+type formatSection struct {
+	block *ConfigBlock
+	lines []string
+	// after holds the comments between this block and the next one that are
+	// not indented, such as a heading for the following hosts
+	after []string
+}
+
+// formatBlock renders a block in canonical form
+func formatBlock(block *ConfigBlock, global bool) formatSection {
+	section := formatSection{block: block}
+
+	indent := formatIndent
+	if global {
+		indent = ""
+	}
+
+	// Comments above the header, with tag comments merged right above it
+	var tags []string
+	hasTags := false
+	for _, line := range block.Leading {
+		if lineTags, ok := parseTagsComment(line.Comment); ok {
+			tags = append(tags, lineTags...)
+			hasTags = true
+			continue
+		}
+		section.lines = append(section.lines, line.Comment)
+	}
+	if hasTags && len(tags) > 0 {
+		section.lines = append(section.lines, formatTagsComment(tags))
+	}
+
+	if block.Header != nil {
+		value := block.Header.Value
+		if !strings.ContainsAny(value, `"'\`) {
+			value = strings.Join(strings.Fields(value), " ")
+		}
+		section.lines = append(section.lines, formatKeyword(block.Header.Key)+" "+value+formatTrailing(block.Header.Trailing))
+	}
+
+	// Everything after the last directive that is not indented belongs
+	// between blocks rather than to this one
+	last := block.lastDirectiveIndex()
+	body, tail := block.Body, []*ConfigLine(nil)
+	if !global {
+		end := len(body)
+		for end > last+1 {
+			line := body[end-1]
+			if line.Kind == LineComment && line.Indent != "" {
+				break
+			}
+			end--
+		}
+		body, tail = body[:end], body[end:]
+	}
+
+	section.lines = append(section.lines, formatBodyLines(body, indent)...)
+	section.after = formatBodyLines(tail, "")
+	return section
+}
+
+// formatBodyLines renders the lines of a block body with the given
+// indentation, dropping leading and trailing blank lines and collapsing runs
+// of blank lines into one
+func formatBodyLines(lines []*ConfigLine, indent string) []string {
+	var out []string
+	blank := false
+	for _, line := range lines {
+		switch line.Kind {
+		case LineBlank:
+			blank = len(out) > 0
+			continue
+		case LineComment:
+			if blank {
+				out = append(out, "")
+			}
+			out = append(out, indent+line.Comment)
+		case LineDirective:
+			if blank {
+				out = append(out, "")
+			}
+			text := indent + formatKeyword(line.Key)
+			if line.Value != "" {
+				text += " " + line.Value
+			}
+			out = append(out, text+formatTrailing(line.Trailing))
+		}
+		blank = false
+	}
+	return out
+}
+
+// formatKeyword returns the documented spelling of a keyword, or the keyword
+// as written if ssh doesn't know it
+func formatKeyword(key string) string {
+	if canonical, ok := CanonicalKeyword(key); ok {
+		return canonical
+	}
+	return key
+}
+
+// formatTrailing keeps a trailing comment, separated by a single space, and
+// drops trailing whitespace
+func formatTrailing(trailing string) string {
+	comment := strings.TrimSpace(trailing)
+	if comment == "" {
+		return ""
+	}
+	return " " + comment
+}
+
+// sortHostSections sorts Host blocks by name. Only runs of consecutive blocks
+// naming concrete hosts are sorted, as moving a block past a wildcard pattern
+// or Match block could change which values apply. Runs defining a host twice,
+// or split by a heading comment, are left alone.
+func sortHostSections(sections []formatSection) {
+	start := 0
+	for start < len(sections) {
+		end := start
+		for end < len(sections) && isSortableHostBlock(sections[end].block) {
+			end++
+			if len(sections[end-1].after) > 0 {
+				break
+			}
+		}
+		if end-start > 1 {
+			sortHostRun(sections[start:end])
+		}
+		if end == start {
+			end++
+		}
+		start = end
+	}
+}
+
+// isSortableHostBlock reports whether a block is a Host block made only of
+// concrete aliases
+func isSortableHostBlock(block *ConfigBlock) bool {
+	if !block.IsHost() {
+		return false
+	}
+	patterns := block.Patterns()
+	return len(patterns) > 0 && len(ConcreteAliases(patterns)) == len(patterns)
+}
+
+// sortHostRun sorts a run of Host blocks in place, keeping the comments that
+// follow the run at its end
+func sortHostRun(run []formatSection) {
+	seen := make(map[string]bool)
+	for _, section := range run {
+		for _, alias := range section.block.Patterns() {
+			if seen[alias] {
+				return
+			}
+			seen[alias] = true
+		}
+	}
+
+	after := run[len(run)-1].after
+	run[len(run)-1].after = nil
+	sort.SliceStable(run, func(i, j int) bool {
+		return strings.ToLower(run[i].block.Patterns()[0]) < strings.ToLower(run[j].block.Patterns()[0])
+	})
+	run[len(run)-1].after = after
+}
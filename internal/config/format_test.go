@@ -0,0 +1,168 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatConfigBytes(t *testing.T) {
+	input := `# Global settings
+
+
+include work.conf
+hostname  ignored
+
+
+# Production web server
+# Tags: prod,web
+Host   web    web-alias  # main site
+  hostname web.example.com
+	USER=deploy
+    port 2222
+
+
+    # keys
+    identityfile ~/.ssh/web
+    UnknownOption yes
+
+# Databases
+
+# Tags: db
+host db
+hostname db.example.com
+match host db exec "true"
+  ForwardAgent  yes
+`
+	want := `# Global settings
+
+Include work.conf
+HostName ignored
+
+# Production web server
+# Tags: prod, web
+Host web web-alias # main site
+    HostName web.example.com
+    User deploy
+    Port 2222
+
+    # keys
+    IdentityFile ~/.ssh/web
+    UnknownOption yes
+
+# Databases
+
+# Tags: db
+Host db
+    HostName db.example.com
+
+Match host db exec "true"
+    ForwardAgent yes
+`
+
+	got := string(FormatConfigBytes("config", []byte(input), FormatOptions{}))
+	if got != want {
+		t.Errorf("FormatConfigBytes():\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Formatting is stable
+	if again := string(FormatConfigBytes("config", []byte(got), FormatOptions{})); again != got {
+		t.Errorf("formatting twice changed the output:\n%s", again)
+	}
+}
+
+func TestFormatConfigBytesSortHosts(t *testing.T) {
+	input := `Host zeta
+    HostName z
+
+Host alpha
+    HostName a
+
+Host *.internal
+    User admin
+
+Host mid
+    HostName m
+
+Host beta
+    HostName b
+
+# Legacy
+
+Host old
+`
+	want := `Host alpha
+    HostName a
+
+Host zeta
+    HostName z
+
+Host *.internal
+    User admin
+
+Host beta
+    HostName b
+
+Host mid
+    HostName m
+
+# Legacy
+
+Host old
+`
+	got := string(FormatConfigBytes("config", []byte(input), FormatOptions{SortHosts: true}))
+	if got != want {
+		t.Errorf("FormatConfigBytes() with sort:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatConfigBytesSortKeepsDuplicatesInOrder(t *testing.T) {
+	input := "Host web\n    User a\n\nHost api web\n    User b\n"
+	got := string(FormatConfigBytes("config", []byte(input), FormatOptions{SortHosts: true}))
+	if got != input {
+		t.Errorf("blocks defining the same host were reordered:\n%s", got)
+	}
+}
+
+func TestWriteFormattedFiles(t *testing.T) {
+	path := writeTestConfig(t, "Include work\nhost a\nhostname a.example.com\n")
+	work := filepath.Join(filepath.Dir(path), "work")
+	if err := os.WriteFile(work, []byte("Host b\n\tHostName b.example.com\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	files, err := ConfigTreeFiles(path)
+	if err != nil {
+		t.Fatalf("ConfigTreeFiles() error = %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected the config and its include, got %v", files)
+	}
+
+	if err := WriteFormattedFiles(files, FormatOptions{}); err != nil {
+		t.Fatalf("WriteFormattedFiles() error = %v", err)
+	}
+	if got, want := readTestConfig(t, path), "Include work\n\nHost a\n    HostName a.example.com\n"; got != want {
+		t.Errorf("config = %q, want %q", got, want)
+	}
+	if got, want := readTestConfig(t, work), "Host b\n    HostName b.example.com\n"; got != want {
+		t.Errorf("work = %q, want %q", got, want)
+	}
+
+	// Both files were changed together and are undone together
+	restored, err := UndoLastChange()
+	if err != nil {
+		t.Fatalf("UndoLastChange() error = %v", err)
+	}
+	if len(restored) != 2 {
+		t.Errorf("expected both files to be restored, got %d", len(restored))
+	}
+}
+
+func TestFormatConfigBytesKeepsLineEndings(t *testing.T) {
+	input := "\ufeffhost a\r\nhostname=a.example.com\r\n"
+	want := "Host a\r\n    HostName a.example.com\r\n"
+	if got := string(FormatConfigBytes("config", []byte(input), FormatOptions{})); got != want {
+		t.Errorf("FormatConfigBytes() = %q, want %q", got, want)
+	}
+}
@@ -16,9 +16,28 @@ type KeyBindings struct {
 	DisableEscQuit bool `json:"disable_esc_quit"`
 }
 
+// BackupSettings controls how many config snapshots are kept per file
+type BackupSettings struct {
+	// MaxCount is the number of snapshots kept per config file, 0 keeps them all
+	MaxCount int `json:"max_count"`
+
+	// MaxAgeDays removes snapshots older than this many days, 0 keeps them all
+	MaxAgeDays int `json:"max_age_days"`
+}
+
+// SystemConfigSettings controls the system-wide SSH config layer
+type SystemConfigSettings struct {
+	// Enabled shows the hosts and defaults of /etc/ssh/ssh_config, read-only,
+	// when no config file is given with -c
+	Enabled bool `json:"enabled"`
+}
+
 // AppConfig represents the main application configuration
 type AppConfig struct {
-	KeyBindings KeyBindings `json:"key_bindings"`
+	KeyBindings  KeyBindings             `json:"key_bindings"`
+	Backups      BackupSettings          `json:"backups"`
+	SystemConfig SystemConfigSettings    `json:"system_config"`
+	Templates    map[string]HostTemplate `json:"templates,omitempty"`
 }
 
 // GetDefaultKeyBindings returns the default key bindings configuration
@@ -29,10 +48,27 @@ func GetDefaultKeyBindings() KeyBindings {
 	}
 }
 
+// GetDefaultBackupSettings returns the default backup retention policy
+func GetDefaultBackupSettings() BackupSettings {
+	return BackupSettings{
+		MaxCount:   50,
+		MaxAgeDays: 90,
+	}
+}
+
+// GetDefaultSystemConfigSettings returns the default system config settings
+func GetDefaultSystemConfigSettings() SystemConfigSettings {
+	return SystemConfigSettings{
+		Enabled: true,
+	}
+}
+
 // GetDefaultAppConfig returns the default application configuration
 func GetDefaultAppConfig() AppConfig {
 	return AppConfig{
-		KeyBindings: GetDefaultKeyBindings(),
+		KeyBindings:  GetDefaultKeyBindings(),
+		Backups:      GetDefaultBackupSettings(),
+		SystemConfig: GetDefaultSystemConfigSettings(),
 	}
 }
 
@@ -80,7 +116,8 @@ func LoadAppConfig() (*AppConfig, error) {
 		return nil, err
 	}
 
-	var config AppConfig
+	// Start from the defaults so that sections missing from the file keep them
+	config := GetDefaultAppConfig()
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
@@ -113,7 +150,7 @@ func SaveAppConfig(config *AppConfig) error {
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0644)
+	return writeFileAtomic(configPath, data, 0644)
 }
 
 // mergeWithDefaults ensures all required fields are set with defaults if missing
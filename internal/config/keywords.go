@@ -0,0 +1,105 @@
+package config
+
+import "strings"
+
+// sshKeywords lists the keywords understood by the OpenSSH client, as
+// documented in ssh_config(5), with their canonical spelling. Deprecated
+// keywords still accepted by ssh and common vendor extensions are included.
+var sshKeywords = []string{
+	"AddKeysToAgent", "AddressFamily", "BatchMode", "BindAddress", "BindInterface",
+	"CanonicalDomains", "CanonicalizeFallbackLocal", "CanonicalizeHostname",
+	"CanonicalizeMaxDots", "CanonicalizePermittedCNAMEs", "CASignatureAlgorithms",
+	"CertificateFile", "ChallengeResponseAuthentication", "ChannelTimeout", "CheckHostIP",
+	"Cipher", "Ciphers", "ClearAllForwardings", "Compression", "ConnectionAttempts",
+	"ConnectTimeout", "ControlMaster", "ControlPath", "ControlPersist", "DynamicForward",
+	"EnableEscapeCommandline", "EnableSSHKeysign", "EscapeChar", "ExitOnForwardFailure",
+	"FingerprintHash", "ForkAfterAuthentication", "ForwardAgent", "ForwardX11",
+	"ForwardX11Timeout", "ForwardX11Trusted", "GatewayPorts", "GlobalKnownHostsFile",
+	"GSSAPIAuthentication", "GSSAPIClientIdentity", "GSSAPIDelegateCredentials",
+	"GSSAPIKexAlgorithms", "GSSAPIKeyExchange", "GSSAPIRenewalForcesRekey",
+	"GSSAPIServerIdentity", "GSSAPITrustDns", "HashKnownHosts", "Host",
+	"HostbasedAcceptedAlgorithms", "HostbasedAuthentication", "HostbasedKeyTypes",
+	"HostKeyAlgorithms", "HostKeyAlias", "HostName", "IdentitiesOnly", "IdentityAgent",
+	"IdentityFile", "IgnoreUnknown", "Include", "IPQoS", "KbdInteractiveAuthentication",
+	"KbdInteractiveDevices", "KexAlgorithms", "KnownHostsCommand", "LocalCommand",
+	"LocalForward", "LogLevel", "LogVerbose", "MACs", "Match",
+	"NoHostAuthenticationForLocalhost", "NumberOfPasswordPrompts", "ObscureKeystrokeTiming",
+	"PasswordAuthentication", "PermitLocalCommand", "PermitRemoteOpen", "PKCS11Provider",
+	"Port", "PreferredAuthentications", "Protocol", "ProxyCommand", "ProxyJump",
+	"ProxyUseFdpass", "PubkeyAcceptedAlgorithms", "PubkeyAcceptedKeyTypes",
+	"PubkeyAuthentication", "RefuseConnection", "RekeyLimit", "RemoteCommand",
+	"RemoteForward", "RequestTTY", "RequiredRSASize", "RevokedHostKeys",
+	"SecurityKeyProvider", "SendEnv", "ServerAliveCountMax", "ServerAliveInterval",
+	"SessionType", "SetEnv", "StdinNull", "StreamLocalBindMask", "StreamLocalBindUnlink",
+	"StrictHostKeyChecking", "SyslogFacility", "Tag", "TCPKeepAlive", "Tunnel",
+	"TunnelDevice", "UpdateHostKeys", "UseKeychain", "User", "UserKnownHostsFile",
+	"VerifyHostKeyDNS", "VisualHostKey", "WarnWeakCrypto", "XAuthLocation",
+}
+
+// canonicalKeywords maps lowercase keywords to their canonical spelling
+var canonicalKeywords = func() map[string]string {
+	keywords := make(map[string]string, len(sshKeywords))
+	for _, keyword := range sshKeywords {
+		keywords[strings.ToLower(keyword)] = keyword
+	}
+	return keywords
+}()
+
+// CanonicalKeyword returns the canonical spelling of an ssh_config keyword,
+// and false if ssh doesn't know the keyword
+func CanonicalKeyword(key string) (string, bool) {
+	canonical, ok := canonicalKeywords[strings.ToLower(key)]
+	return canonical, ok
+}
+
+// SuggestKeyword returns the known keyword closest to a misspelled one, or ""
+// if none is close enough or several are equally close
+func SuggestKeyword(key string) string {
+	lower := strings.ToLower(key)
+	maxDistance := 2
+	if len(lower) <= 4 {
+		maxDistance = 1
+	}
+
+	best, bestDistance, ties := "", maxDistance+1, 0
+	for _, keyword := range sshKeywords {
+		distance := editDistance(lower, strings.ToLower(keyword))
+		switch {
+		case distance < bestDistance:
+			best, bestDistance, ties = keyword, distance, 0
+		case distance == bestDistance:
+			ties++
+		}
+	}
+	if best == "" || ties > 0 {
+		return ""
+	}
+	return best
+}
+
+// editDistance returns the Damerau-Levenshtein distance between two strings,
+// counting swapped adjacent letters as a single edit
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
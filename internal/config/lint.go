@@ -0,0 +1,594 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Lint rules, reported with each finding so that they can be filtered
+const (
+	LintRuleDuplicateHost    = "duplicate-host"
+	LintRuleShadowed         = "shadowed"
+	LintRuleUnknownKeyword   = "unknown-keyword"
+	LintRuleInvalidValue     = "invalid-value"
+	LintRuleIdentityMissing  = "identity-missing"
+	LintRuleIdentityMode     = "identity-permissions"
+	LintRuleProxyJumpUnknown = "proxyjump-unknown"
+	LintRuleProxyJumpCycle   = "proxyjump-cycle"
+	LintRuleIncludeNoMatch   = "include-no-match"
+	LintRuleIncludeBroken    = "include-unreadable"
+	LintRuleByteOrderMark    = "byte-order-mark"
+)
+
+// LintFinding is a problem found in the config tree
+type LintFinding struct {
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Message  string   `json:"message"`
+	Fixable  bool     `json:"fixable"`
+
+	fixKeyword string // Keyword to write instead of the misspelled one
+	fixPath    string // Key file whose permissions must be restricted
+	fixBOM     bool   // The byte order mark at the start of File must be removed
+}
+
+// String formats the finding like a compiler diagnostic
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s [%s]", f.File, f.Line, f.Severity, f.Message, f.Rule)
+}
+
+// lintBlock is a block of the config tree, in the order ssh reads them
+type lintBlock struct {
+	file  string
+	block *ConfigBlock
+	// scope holds the patterns of the Host block an Include appeared in, for
+	// the global section of included files; nil when it applies to every host
+	scope []string
+	// conditional is set for Match blocks and files included from them, which
+	// can't be evaluated statically
+	conditional bool
+}
+
+// appliesTo reports whether the block certainly applies to a host alias
+func (b lintBlock) appliesTo(alias string) bool {
+	if b.conditional {
+		return false
+	}
+	if b.scope != nil && !MatchHostPatterns(b.scope, alias) {
+		return false
+	}
+	if b.block.IsHost() {
+		return MatchHostPatterns(b.block.Patterns(), alias)
+	}
+	return b.block.Header == nil
+}
+
+// label describes the block in messages
+func (b lintBlock) label() string {
+	if b.block.Header == nil {
+		return "the global section"
+	}
+	return fmt.Sprintf("'%s %s'", b.block.Header.Key, b.block.Header.Value)
+}
+
+// linter walks a config tree and collects findings
+type linter struct {
+	blocks    []lintBlock
+	processed map[string]bool
+	findings  []LintFinding
+}
+
+// LintConfig checks the config tree starting at configPath, or at the default
+// config when configPath is empty, and returns its findings sorted by position
+func LintConfig(configPath string) ([]LintFinding, error) {
+	if configPath == "" {
+		var err error
+		configPath, err = GetDefaultSSHConfigPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := os.Stat(configPath); err != nil {
+		return nil, err
+	}
+
+	l := &linter{processed: make(map[string]bool)}
+	if err := l.walk(configPath, nil, false); err != nil {
+		return nil, err
+	}
+
+	l.checkKeywords()
+	l.checkHosts()
+	l.checkIdentityFiles()
+	l.checkProxyJumps()
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		a, b := l.findings[i], l.findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return l.findings, nil
+}
+
+// add records a finding
+func (l *linter) add(finding LintFinding) {
+	l.findings = append(l.findings, finding)
+}
+
+// walk reads a config file and the files it includes, in the order ssh does
+func (l *linter) walk(configPath string, scope []string, conditional bool) error {
+	absPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve absolute path for %s: %w", configPath, err)
+	}
+	if l.processed[absPath] {
+		return nil
+	}
+	l.processed[absPath] = true
+
+	cfg, err := readConfigFile(absPath, false)
+	if err != nil {
+		return err
+	}
+	if cfg.bom {
+		l.add(LintFinding{
+			Severity: SeverityError,
+			Rule:     LintRuleByteOrderMark,
+			File:     absPath,
+			Line:     1,
+			Message:  "file starts with a UTF-8 byte order mark, which ssh rejects",
+			Fixable:  true,
+			fixBOM:   true,
+		})
+	}
+
+	for _, block := range cfg.Blocks {
+		entry := lintBlock{file: absPath, block: block, conditional: conditional || block.IsMatch()}
+		if block.Header == nil {
+			entry.scope = scope
+		}
+		l.blocks = append(l.blocks, entry)
+
+		// Files included from a block only apply where the block applies
+		includeScope := scope
+		if block.IsHost() {
+			includeScope = block.Patterns()
+		}
+
+		for _, line := range block.Directives() {
+			if !strings.EqualFold(line.Key, "include") || line.Value == "" {
+				continue
+			}
+			for _, pattern := range splitQuotedArgs(line.Value) {
+				files, err := resolveIncludePattern(pattern, absPath)
+				if err != nil || len(files) == 0 {
+					l.add(LintFinding{
+						Severity: SeverityWarning,
+						Rule:     LintRuleIncludeNoMatch,
+						File:     absPath,
+						Line:     line.Number,
+						Message:  fmt.Sprintf("Include '%s' matches no file", pattern),
+					})
+					continue
+				}
+				for _, file := range files {
+					err := l.walk(file, includeScope, entry.conditional)
+					if err == nil {
+						_, err = os.Stat(file)
+					}
+					if err != nil {
+						l.add(LintFinding{
+							Severity: SeverityError,
+							Rule:     LintRuleIncludeBroken,
+							File:     absPath,
+							Line:     line.Number,
+							Message:  fmt.Sprintf("included file %s can't be read: %v", file, err),
+						})
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkKeywords reports keywords ssh doesn't know, unless IgnoreUnknown
+// allows them, and suggests the closest known keyword. Values of known
+// keywords are checked against their type.
+func (l *linter) checkKeywords() {
+	var ignored []string
+	for _, entry := range l.blocks {
+		for _, line := range entry.block.Directives() {
+			if strings.EqualFold(line.Key, "IgnoreUnknown") {
+				for _, pattern := range splitPatternList(line.Value) {
+					ignored = append(ignored, strings.ToLower(pattern))
+				}
+			}
+		}
+	}
+
+	for _, entry := range l.blocks {
+		for _, line := range entry.block.Lines() {
+			if line.Kind != LineDirective {
+				continue
+			}
+			if _, ok := CanonicalKeyword(line.Key); ok {
+				if line == entry.block.Header || line.Value == "" {
+					continue
+				}
+				if err := ValidateDirective(line.Key, line.Value); err != nil {
+					l.add(LintFinding{
+						Severity: SeverityError,
+						Rule:     LintRuleInvalidValue,
+						File:     entry.file,
+						Line:     line.Number,
+						Message:  err.Error(),
+					})
+				}
+				continue
+			}
+			if MatchHostPatterns(ignored, strings.ToLower(line.Key)) {
+				continue
+			}
+
+			finding := LintFinding{
+				Severity: SeverityError,
+				Rule:     LintRuleUnknownKeyword,
+				File:     entry.file,
+				Line:     line.Number,
+				Message:  fmt.Sprintf("unknown keyword '%s'", line.Key),
+			}
+			if suggestion := SuggestKeyword(line.Key); suggestion != "" {
+				finding.Message += fmt.Sprintf(", did you mean '%s'?", suggestion)
+				finding.Fixable = true
+				finding.fixKeyword = suggestion
+			}
+			l.add(finding)
+		}
+	}
+}
+
+// checkHosts reports hosts defined more than once and directives that are
+// ignored because an earlier block matching the host already sets them
+func (l *linter) checkHosts() {
+	defined := make(map[string]lintBlock)
+
+	for i, entry := range l.blocks {
+		if !entry.block.IsHost() || entry.conditional {
+			continue
+		}
+		aliases := ConcreteAliases(entry.block.Patterns())
+
+		for _, alias := range aliases {
+			if first, ok := defined[alias]; ok {
+				l.add(LintFinding{
+					Severity: SeverityError,
+					Rule:     LintRuleDuplicateHost,
+					File:     entry.file,
+					Line:     entry.block.Header.Number,
+					Message: fmt.Sprintf("host '%s' is already defined at %s:%d, ssh uses the first definition",
+						alias, first.file, first.block.Header.Number),
+				})
+				continue
+			}
+			defined[alias] = entry
+		}
+
+		for _, line := range entry.block.Directives() {
+			key := strings.ToLower(line.Key)
+			if IsMultiValueKeyword(key) || key == "include" {
+				continue
+			}
+			for _, alias := range aliases {
+				earlier, earlierLine := l.firstSetting(l.blocks[:i], alias, key)
+				if earlier == nil {
+					continue
+				}
+				// Duplicated hosts are already reported as such
+				if earlier.block.IsHost() && containsPattern(earlier.block.Patterns(), alias) {
+					continue
+				}
+				l.add(LintFinding{
+					Severity: SeverityWarning,
+					Rule:     LintRuleShadowed,
+					File:     entry.file,
+					Line:     line.Number,
+					Message: fmt.Sprintf("%s of host '%s' is ignored, %s at %s:%d already sets it",
+						line.Key, alias, earlier.label(), earlier.file, earlierLine.Number),
+				})
+				break
+			}
+		}
+	}
+}
+
+// firstSetting returns the first block of blocks that applies to alias and
+// sets the keyword, with the line setting it
+func (l *linter) firstSetting(blocks []lintBlock, alias, key string) (*lintBlock, *ConfigLine) {
+	for i := range blocks {
+		if !blocks[i].appliesTo(alias) {
+			continue
+		}
+		for _, line := range blocks[i].block.Directives() {
+			if strings.ToLower(line.Key) == key && line.Value != "" {
+				return &blocks[i], line
+			}
+		}
+	}
+	return nil, nil
+}
+
+// containsPattern reports whether a pattern list contains a pattern as written
+func containsPattern(patterns []string, pattern string) bool {
+	for _, p := range patterns {
+		if p == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIdentityFiles reports identity files that don't exist, or that other
+// users can read, which makes ssh refuse them
+func (l *linter) checkIdentityFiles() {
+	homeDir, _ := os.UserHomeDir()
+
+	for _, entry := range l.blocks {
+		for _, line := range entry.block.Directives() {
+			if !strings.EqualFold(line.Key, "IdentityFile") {
+				continue
+			}
+			path, ok := expandIdentityPath(line.Value, homeDir)
+			if !ok {
+				continue
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				l.add(LintFinding{
+					Severity: SeverityWarning,
+					Rule:     LintRuleIdentityMissing,
+					File:     entry.file,
+					Line:     line.Number,
+					Message:  fmt.Sprintf("IdentityFile %s does not exist", line.Value),
+				})
+				continue
+			}
+
+			if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+				l.add(LintFinding{
+					Severity: SeverityError,
+					Rule:     LintRuleIdentityMode,
+					File:     entry.file,
+					Line:     line.Number,
+					Message: fmt.Sprintf("IdentityFile %s is accessible by other users (mode %04o), ssh will refuse it",
+						line.Value, info.Mode().Perm()),
+					Fixable: true,
+					fixPath: path,
+				})
+			}
+		}
+	}
+}
+
+// expandIdentityPath turns an IdentityFile value into a path that can be
+// checked. Values using runtime tokens or relative paths can't be checked.
+func expandIdentityPath(value, homeDir string) (string, bool) {
+	args := splitQuotedArgs(value)
+	if len(args) == 0 {
+		return "", false
+	}
+	path := args[0]
+	if strings.EqualFold(path, "none") || homeDir == "" {
+		return "", false
+	}
+
+	path = strings.ReplaceAll(path, "%d", homeDir)
+	if strings.Contains(path, "%") || strings.Contains(path, "${") {
+		return "", false
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		path = filepath.Join(homeDir, path[1:])
+	}
+	if !filepath.IsAbs(path) {
+		return "", false
+	}
+	return path, true
+}
+
+// proxyJump is a ProxyJump directive as it applies to a host
+type proxyJump struct {
+	targets []string
+	file    string
+	line    int
+}
+
+// checkProxyJumps reports ProxyJump targets that are neither a defined host nor
+// a host name, and hosts that end up jumping through themselves
+func (l *linter) checkProxyJumps() {
+	known := make(map[string]bool)
+	for _, entry := range l.blocks {
+		if entry.block.IsHost() {
+			for _, alias := range ConcreteAliases(entry.block.Patterns()) {
+				known[alias] = true
+			}
+		}
+	}
+
+	for _, entry := range l.blocks {
+		for _, line := range entry.block.Directives() {
+			if !strings.EqualFold(line.Key, "ProxyJump") {
+				continue
+			}
+			for _, target := range parseProxyJump(line.Value) {
+				if known[target] || looksLikeHostname(target) {
+					continue
+				}
+				l.add(LintFinding{
+					Severity: SeverityWarning,
+					Rule:     LintRuleProxyJumpUnknown,
+					File:     entry.file,
+					Line:     line.Number,
+					Message:  fmt.Sprintf("ProxyJump target '%s' is not a defined host", target),
+				})
+			}
+		}
+	}
+
+	// The ProxyJump that applies to each host, which may come from a pattern block
+	jumps := make(map[string]proxyJump)
+	for alias := range known {
+		entry, line := l.firstSetting(l.blocks, alias, "proxyjump")
+		if entry == nil {
+			continue
+		}
+		jumps[alias] = proxyJump{targets: parseProxyJump(line.Value), file: entry.file, line: line.Number}
+	}
+
+	aliases := make([]string, 0, len(jumps))
+	for alias := range jumps {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	// Depth-first search, reporting each cycle once from its first alias
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(alias string)
+	visit = func(alias string) {
+		state[alias] = inProgress
+		path = append(path, alias)
+		for _, target := range jumps[alias].targets {
+			switch state[target] {
+			case inProgress:
+				start := 0
+				for path[start] != target {
+					start++
+				}
+				cycle := append(append([]string(nil), path[start:]...), target)
+				jump := jumps[cycle[0]]
+				l.add(LintFinding{
+					Severity: SeverityError,
+					Rule:     LintRuleProxyJumpCycle,
+					File:     jump.file,
+					Line:     jump.line,
+					Message:  fmt.Sprintf("ProxyJump cycle: %s", strings.Join(cycle, " -> ")),
+				})
+			case unvisited:
+				if _, ok := jumps[target]; ok {
+					visit(target)
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[alias] = done
+	}
+	for _, alias := range aliases {
+		if state[alias] == unvisited {
+			visit(alias)
+		}
+	}
+}
+
+// parseProxyJump returns the host names of a ProxyJump value
+// ("[user@]host[:port]" or "ssh://[user@]host[:port]", comma separated)
+func parseProxyJump(value string) []string {
+	if strings.EqualFold(strings.TrimSpace(value), "none") {
+		return nil
+	}
+
+	var hosts []string
+	for _, hop := range strings.Split(value, ",") {
+		hop = strings.TrimPrefix(strings.TrimSpace(hop), "ssh://")
+		if at := strings.LastIndex(hop, "@"); at >= 0 {
+			hop = hop[at+1:]
+		}
+		if strings.HasPrefix(hop, "[") {
+			if end := strings.Index(hop, "]"); end > 0 {
+				hop = hop[1:end]
+			}
+		} else if colon := strings.LastIndex(hop, ":"); colon >= 0 && strings.Count(hop, ":") == 1 {
+			hop = hop[:colon]
+		}
+		if hop != "" {
+			hosts = append(hosts, hop)
+		}
+	}
+	return hosts
+}
+
+// looksLikeHostname reports whether a ProxyJump target can be reached without
+// a Host block: an IP address, a dotted host name or localhost
+func looksLikeHostname(target string) bool {
+	return net.ParseIP(target) != nil || strings.Contains(target, ".") ||
+		target == "localhost" || strings.Contains(target, "%")
+}
+
+// FixLintFindings applies the safe automatic repairs of fixable findings:
+// misspelled keywords are corrected, byte order marks removed and identity
+// files made private. It returns the number of findings fixed.
+func FixLintFindings(findings []LintFinding) (int, error) {
+	fixed := 0
+
+	fileFixes := make(map[string][]LintFinding)
+	var files []string
+	for _, finding := range findings {
+		switch {
+		case finding.fixKeyword != "" || finding.fixBOM:
+			if _, ok := fileFixes[finding.File]; !ok {
+				files = append(files, finding.File)
+			}
+			fileFixes[finding.File] = append(fileFixes[finding.File], finding)
+		case finding.fixPath != "":
+			if err := SetSecureFilePermissions(finding.fixPath); err != nil {
+				return fixed, fmt.Errorf("failed to fix permissions of %s: %w", finding.fixPath, err)
+			}
+			fixed++
+		}
+	}
+
+	for _, file := range files {
+		count := 0
+		err := modifyConfigFile(file, func(cfg *ConfigFile) error {
+			count = 0
+			for _, finding := range fileFixes[file] {
+				if finding.fixBOM && cfg.bom {
+					cfg.bom = false
+					count++
+				}
+			}
+			for _, block := range cfg.Blocks {
+				for _, line := range block.Lines() {
+					for _, finding := range fileFixes[file] {
+						if finding.fixKeyword != "" && line.Kind == LineDirective && line.Number == finding.Line {
+							line.SetKey(finding.fixKeyword)
+							count++
+						}
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fixed, err
+		}
+		fixed += count
+	}
+
+	return fixed, nil
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// findingsByRule groups lint findings by rule
+func findingsByRule(findings []LintFinding) map[string][]LintFinding {
+	byRule := make(map[string][]LintFinding)
+	for _, finding := range findings {
+		byRule[finding.Rule] = append(byRule[finding.Rule], finding)
+	}
+	return byRule
+}
+
+func TestLintConfig(t *testing.T) {
+	path := writeTestConfig(t, `Include missing/*.conf
+Include work
+
+Host *
+    User deploy
+
+Host web
+    HostName web.example.com
+    User admin
+    HostNmae typo.example.com
+    ProxyJump bastion
+
+Host bastion
+    HostName bastion.example.com
+    ProxyJump web
+
+Host db
+    ProxyJump nowhere,10.0.0.1,jump.example.com:2222
+    IdentityFile /does/not/exist
+    UseFancyThing yes
+`)
+	work := "Host web\n    HostName other.example.com\n"
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), "work"), []byte(work), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	findings, err := LintConfig(path)
+	if err != nil {
+		t.Fatalf("LintConfig() error = %v", err)
+	}
+	byRule := findingsByRule(findings)
+
+	tests := []struct {
+		rule    string
+		line    int
+		message string
+	}{
+		{LintRuleIncludeNoMatch, 1, "missing/*.conf"},
+		{LintRuleShadowed, 9, "User of host 'web' is ignored, 'Host *'"},
+		{LintRuleUnknownKeyword, 10, "did you mean 'HostName'?"},
+		{LintRuleUnknownKeyword, 20, "unknown keyword 'UseFancyThing'"},
+		{LintRuleProxyJumpCycle, 15, "bastion -> web -> bastion"},
+		{LintRuleProxyJumpUnknown, 18, "'nowhere'"},
+		{LintRuleIdentityMissing, 19, "/does/not/exist"},
+	}
+	for _, tt := range tests {
+		found := false
+		for _, finding := range byRule[tt.rule] {
+			if finding.Line == tt.line && strings.Contains(finding.Message, tt.message) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("missing %s finding at line %d containing %q, got %v", tt.rule, tt.line, tt.message, byRule[tt.rule])
+		}
+	}
+
+	// The included file is read first, so the duplicate is the one in the main file
+	duplicates := byRule[LintRuleDuplicateHost]
+	if len(duplicates) != 1 || duplicates[0].Line != 7 || !strings.Contains(duplicates[0].Message, "work:1") {
+		t.Errorf("duplicate-host findings = %v", duplicates)
+	}
+	if len(byRule[LintRuleProxyJumpUnknown]) != 1 {
+		t.Errorf("only 'nowhere' should be an unknown ProxyJump target: %v", byRule[LintRuleProxyJumpUnknown])
+	}
+	if len(byRule[LintRuleProxyJumpCycle]) != 1 {
+		t.Errorf("the cycle should be reported once: %v", byRule[LintRuleProxyJumpCycle])
+	}
+}
+
+func TestLintConfigIgnoreUnknown(t *testing.T) {
+	path := writeTestConfig(t, "IgnoreUnknown UseKeychain,Add*\n\nHost a\n    AddFancyOption yes\n")
+
+	findings, err := LintConfig(path)
+	if err != nil {
+		t.Fatalf("LintConfig() error = %v", err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("expected no findings, got %v", findings)
+	}
+}
+
+func TestFixLintFindings(t *testing.T) {
+	path := writeTestConfig(t, "Host a\n    HostNmae a.example.com # primary\n    Prot 22\n    IdentityFile KEY\n")
+	key := filepath.Join(t.TempDir(), "id_test")
+	if err := os.WriteFile(key, []byte("key"), 0644); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	content := strings.Replace(readTestConfig(t, path), "KEY", key, 1)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	findings, err := LintConfig(path)
+	if err != nil {
+		t.Fatalf("LintConfig() error = %v", err)
+	}
+	fixed, err := FixLintFindings(findings)
+	if err != nil {
+		t.Fatalf("FixLintFindings() error = %v", err)
+	}
+
+	want := strings.Replace(content, "HostNmae", "HostName", 1)
+	want = strings.Replace(want, "Prot ", "Port ", 1)
+	if got := readTestConfig(t, path); got != want {
+		t.Errorf("fixed config:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	wantFixed := 2
+	if runtime.GOOS != "windows" {
+		wantFixed = 3
+		if info, _ := os.Stat(key); info.Mode().Perm() != 0600 {
+			t.Errorf("key mode = %v, want 0600", info.Mode().Perm())
+		}
+	}
+	if fixed != wantFixed {
+		t.Errorf("fixed = %d, want %d", fixed, wantFixed)
+	}
+
+	findings, _ = LintConfig(path)
+	if len(findings) != 0 {
+		t.Errorf("findings left after fixing: %v", findings)
+	}
+}
+
+func TestSuggestKeyword(t *testing.T) {
+	tests := map[string]string{
+		"HostNmae":     "HostName",
+		"identityfile": "",
+		"Usr":          "User",
+		"Prot":         "Port",
+		"ProxyJmup":    "ProxyJump",
+		"Frobnicate":   "",
+	}
+	for key, want := range tests {
+		if want == "" {
+			if _, ok := CanonicalKeyword(key); ok {
+				continue
+			}
+		}
+		if got := SuggestKeyword(key); got != want {
+			t.Errorf("SuggestKeyword(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestParseProxyJump(t *testing.T) {
+	tests := map[string][]string{
+		"none":                            nil,
+		"bastion":                         {"bastion"},
+		"user@jump:2222,ssh://b@[::1]:22": {"jump", "::1"},
+		"fe80::1":                         {"fe80::1"},
+	}
+	for value, want := range tests {
+		got := parseProxyJump(value)
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("parseProxyJump(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestLintInvalidValues(t *testing.T) {
+	path := writeTestConfig(t, "Host web\n    HostName web.example.com\n    Port 99999\n    Compression maybe\n    ServerAliveInterval 1h30m\n")
+
+	findings, err := LintConfig(path)
+	if err != nil {
+		t.Fatalf("LintConfig() error = %v", err)
+	}
+
+	var lines []int
+	for _, finding := range findings {
+		if finding.Rule == LintRuleInvalidValue {
+			lines = append(lines, finding.Line)
+		}
+	}
+	if len(lines) != 2 || lines[0] != 3 || lines[1] != 4 {
+		t.Errorf("expected invalid values on lines 3 and 4, got %v", findings)
+	}
+}
+
+func TestLintFixesByteOrderMark(t *testing.T) {
+	path := writeTestConfig(t, "\ufeffHost web\r\n    HostName web.example.com\r\n")
+
+	findings, err := LintConfig(path)
+	if err != nil {
+		t.Fatalf("LintConfig() error = %v", err)
+	}
+	if len(findings) != 1 || findings[0].Rule != LintRuleByteOrderMark || !findings[0].Fixable {
+		t.Fatalf("expected a fixable byte-order-mark finding, got %v", findings)
+	}
+
+	if fixed, err := FixLintFindings(findings); err != nil || fixed != 1 {
+		t.Fatalf("FixLintFindings() = %d, %v", fixed, err)
+	}
+	if got, want := readTestConfig(t, path), "Host web\r\n    HostName web.example.com\r\n"; got != want {
+		t.Errorf("config = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,504 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// managedByKey is the metadata key marking the hosts written by a manifest,
+// as in "# sshm: managed-by: fleet"
+const managedByKey = "managed-by"
+
+// Manifest declares hosts that "sshm apply" keeps in the SSH config. Hosts
+// use the fields of "sshm list --format yaml". Only the hosts a manifest
+// wrote are ever changed or removed by it; the others are left alone.
+type Manifest struct {
+	// Name marks the hosts of the manifest; it defaults to the name of the
+	// manifest file without its extension
+	Name string `json:"name" yaml:"name"`
+	// File is the config file of the hosts that don't name one, the main
+	// config by default
+	File  string       `json:"file" yaml:"file"`
+	Hosts []HostRecord `json:"hosts" yaml:"hosts"`
+
+	declared []declaredHost
+}
+
+// declaredHost is a host of a manifest as it should be written
+type declaredHost struct {
+	host SSHHost
+	file string // Absolute path, empty for the manifest's default file
+}
+
+// LoadManifest reads a YAML or JSON manifest and checks its hosts. Relative
+// file paths are relative to the manifest.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		// JSON is converted to YAML so that numbers such as ports decode
+		// into the string fields of HostRecord
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if data, err = yaml.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+
+	var m Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if m.Name == "" {
+		m.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if strings.ContainsAny(m.Name, " \t\r\n") {
+		return nil, fmt.Errorf("manifest name '%s' cannot contain spaces", m.Name)
+	}
+
+	dir := filepath.Dir(path)
+	if m.File != "" {
+		if m.File, err = resolveManifestPath(dir, m.File); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, record := range m.Hosts {
+		host, err := record.manifestHost(m.Name)
+		if err != nil {
+			return nil, fmt.Errorf("host %d: %w", i+1, err)
+		}
+		for _, alias := range host.Aliases {
+			if seen[alias] {
+				return nil, fmt.Errorf("host '%s' is declared more than once", alias)
+			}
+			seen[alias] = true
+		}
+
+		declared := declaredHost{host: host}
+		if record.File != "" {
+			if declared.file, err = resolveManifestPath(dir, record.File); err != nil {
+				return nil, err
+			}
+		}
+		m.declared = append(m.declared, declared)
+	}
+	return &m, nil
+}
+
+// resolveManifestPath returns the absolute path of a file named in a
+// manifest, expanding a leading "~"
+func resolveManifestPath(dir, path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[1:])
+	} else if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return filepath.Abs(path)
+}
+
+// manifestHost builds the host a record of a manifest declares
+func (r HostRecord) manifestHost(manifest string) (SSHHost, error) {
+	if r.Name == "" {
+		return SSHHost{}, fmt.Errorf("name is required")
+	}
+	patterns := append([]string{r.Name}, r.Aliases...)
+	for _, pattern := range patterns {
+		if !IsConcreteAlias(pattern) || strings.ContainsAny(pattern, " \t,") {
+			return SSHHost{}, fmt.Errorf("'%s' is not a host name", pattern)
+		}
+	}
+	for _, tag := range r.Tags {
+		if err := ValidateTag(tag); err != nil {
+			return SSHHost{}, fmt.Errorf("host '%s': %w", r.Name, err)
+		}
+	}
+
+	host := SSHHost{
+		Name:     r.Name,
+		Aliases:  patterns,
+		Patterns: patterns,
+		Tags:     r.Tags,
+		Group:    NormalizeGroupPath(r.Group),
+		Metadata: HostMetadata{
+			Description: r.Description,
+			Owner:       r.Owner,
+			Environment: r.Environment,
+			Links:       r.Links,
+			Notes:       r.Notes,
+			Extra:       []MetadataEntry{{Key: managedByKey, Value: manifest}},
+		},
+	}
+
+	var options []Directive
+	for _, option := range r.Options {
+		if isHostFieldKey(option.Key) {
+			return SSHHost{}, fmt.Errorf("host '%s': set %s with its own field rather than in options", r.Name, LookupKeyword(option.Key).Name)
+		}
+		options = append(options, Directive{Key: LookupKeyword(option.Key).Name, Value: option.Value})
+	}
+	host.Set("HostName", r.Hostname)
+	host.Set("User", r.User)
+	host.Set("Port", r.Port)
+	host.Set("ProxyJump", r.ProxyJump)
+	host.SetOtherDirectives(options)
+	var identities []string
+	for _, identity := range r.IdentityFiles {
+		identities = append(identities, QuoteArg(identity))
+	}
+	if len(identities) > 0 {
+		host.SetAll("IdentityFile", identities)
+	}
+
+	if err := host.ValidateDirectives(); err != nil {
+		return SSHHost{}, fmt.Errorf("host '%s': %w", r.Name, err)
+	}
+	return host, nil
+}
+
+// ManagedBy returns the name of the manifest that wrote the host, or "" for
+// hosts written by hand
+func (h SSHHost) ManagedBy() string {
+	for _, entry := range h.Metadata.Extra {
+		if entry.Key == managedByKey {
+			return entry.Value
+		}
+	}
+	return ""
+}
+
+// PlanAction is what applying a manifest does to a host
+type PlanAction int
+
+const (
+	PlanAdd    PlanAction = iota // The host is new
+	PlanChange                   // Values of the host change
+	PlanMove                     // The host moves to another file, its values may change too
+	PlanRemove                   // The host is no longer declared
+)
+
+func (a PlanAction) String() string {
+	switch a {
+	case PlanChange:
+		return "change"
+	case PlanMove:
+		return "move"
+	case PlanRemove:
+		return "remove"
+	default:
+		return "add"
+	}
+}
+
+// PlanStep is the change applying a manifest makes to one host
+type PlanStep struct {
+	Action  PlanAction
+	Name    string
+	File    string   // File the host is written to, or removed from
+	From    string   // File a moved host leaves
+	Changes []string // Values that change, as "field: old -> new"
+
+	host    SSHHost // Host as declared, unset for removals
+	current SSHHost // Host as in the config, unset for additions
+}
+
+// Plan lists the changes that make the config match a manifest
+type Plan struct {
+	Manifest string
+	Steps    []PlanStep
+	paths    []string // Files the steps change
+}
+
+// IsEmpty reports whether the config already matches the manifest
+func (p *Plan) IsEmpty() bool {
+	return len(p.Steps) == 0
+}
+
+// Count returns the number of steps taking an action
+func (p *Plan) Count(action PlanAction) int {
+	count := 0
+	for _, step := range p.Steps {
+		if step.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// PlanManifest compares a manifest with the config tree at configPath, or
+// the default config when configPath is empty. A declared host that exists
+// without being managed by the manifest is a conflict, as hosts written by
+// hand are never overwritten.
+func PlanManifest(m *Manifest, configPath string) (*Plan, error) {
+	if configPath == "" {
+		var err error
+		if configPath, err = GetDefaultSSHConfigPath(); err != nil {
+			return nil, err
+		}
+	}
+	mainFile, err := filepath.Abs(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := ParseConfigTree(configPath)
+	if err != nil {
+		return nil, err
+	}
+	treeFiles, err := ConfigTreeFiles(configPath)
+	if err != nil {
+		return nil, err
+	}
+	inTree := map[string]bool{versionKey(mainFile): true}
+	for _, file := range treeFiles {
+		inTree[versionKey(file)] = true
+	}
+
+	defaultFile := mainFile
+	if m.File != "" {
+		defaultFile = m.File
+	}
+
+	plan := &Plan{Manifest: m.Name}
+	claimed := make(map[int]bool)
+	for _, declared := range m.declared {
+		host := declared.host
+		file := declared.file
+		if file == "" {
+			file = defaultFile
+		}
+		if !inTree[versionKey(file)] {
+			return nil, fmt.Errorf("%s is not part of the SSH config; include it first", file)
+		}
+
+		// Any alias of the host may already be defined
+		current := -1
+		for i, existing := range parsed.Hosts {
+			for _, alias := range host.Aliases {
+				if !existing.HasAlias(alias) {
+					continue
+				}
+				if existing.ManagedBy() != m.Name {
+					return nil, fmt.Errorf("host '%s' %w in %s and is not managed by manifest '%s'", alias, ErrHostExists, existing.SourceFile, m.Name)
+				}
+				if claimed[i] && current != i {
+					return nil, fmt.Errorf("host '%s' is defined by several hosts of the manifest", existing.Name)
+				}
+				current = i
+			}
+		}
+
+		if current < 0 {
+			plan.Steps = append(plan.Steps, PlanStep{Action: PlanAdd, Name: host.Name, File: file, host: host})
+			continue
+		}
+		claimed[current] = true
+
+		existing := parsed.Hosts[current]
+		host.Metadata.Extra = mergeManagedExtra(existing.Metadata.Extra, m.Name)
+		step := PlanStep{
+			Action:  PlanChange,
+			Name:    host.Name,
+			File:    file,
+			Changes: diffHostRecords(NewHostRecord(existing), NewHostRecord(host)),
+			host:    host,
+			current: existing,
+		}
+		if versionKey(existing.SourceFile) != versionKey(file) {
+			step.Action = PlanMove
+			step.From = existing.SourceFile
+		} else if len(step.Changes) == 0 {
+			continue
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	for i, existing := range parsed.Hosts {
+		if !claimed[i] && existing.ManagedBy() == m.Name {
+			plan.Steps = append(plan.Steps, PlanStep{Action: PlanRemove, Name: existing.Name, File: existing.SourceFile, current: existing})
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, step := range plan.Steps {
+		for _, path := range []string{step.From, step.File} {
+			if path != "" && !seen[versionKey(path)] {
+				seen[versionKey(path)] = true
+				plan.paths = append(plan.paths, path)
+			}
+		}
+	}
+	return plan, nil
+}
+
+// mergeManagedExtra keeps the other metadata entries of a managed host, such
+// as ones written by newer versions of sshm, and marks it as managed
+func mergeManagedExtra(extra []MetadataEntry, manifest string) []MetadataEntry {
+	var merged []MetadataEntry
+	for _, entry := range extra {
+		if entry.Key != managedByKey {
+			merged = append(merged, entry)
+		}
+	}
+	return append(merged, MetadataEntry{Key: managedByKey, Value: manifest})
+}
+
+// diffHostRecords describes the values that differ between two hosts
+func diffHostRecords(from, to HostRecord) []string {
+	list := func(items []string) string { return strings.Join(items, ", ") }
+	options := func(r HostRecord) string {
+		var items []string
+		for _, option := range r.Options {
+			items = append(items, option.Key+"="+option.Value)
+		}
+		return list(items)
+	}
+	port := func(r HostRecord) string {
+		if r.Port == "" {
+			return "22"
+		}
+		return r.Port
+	}
+
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"name", from.Name, to.Name},
+		{"aliases", list(from.Aliases), list(to.Aliases)},
+		{"hostname", from.Hostname, to.Hostname},
+		{"user", from.User, to.User},
+		{"port", port(from), port(to)},
+		{"identity_files", list(from.IdentityFiles), list(to.IdentityFiles)},
+		{"proxy_jump", from.ProxyJump, to.ProxyJump},
+		{"options", options(from), options(to)},
+		{"tags", list(from.Tags), list(to.Tags)},
+		{"group", from.Group, to.Group},
+		{"description", from.Description, to.Description},
+		{"owner", from.Owner, to.Owner},
+		{"environment", from.Environment, to.Environment},
+		{"links", list(from.Links), list(to.Links)},
+		{"notes", from.Notes, to.Notes},
+	}
+
+	var changes []string
+	for _, field := range fields {
+		switch {
+		case field.from == field.to:
+		case strings.Contains(field.from+field.to, "\n"):
+			changes = append(changes, field.name+" changed")
+		default:
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", field.name, quoteEmpty(field.from), quoteEmpty(field.to)))
+		}
+	}
+	return changes
+}
+
+// quoteEmpty shows empty values as "" in plan changes
+func quoteEmpty(value string) string {
+	if value == "" {
+		return `""`
+	}
+	return value
+}
+
+// stage makes the changes of the plan to config files loaded in memory
+func (p *Plan) stage(files map[string]*ConfigFile) error {
+	for _, step := range p.Steps {
+		target := files[versionKey(step.File)]
+		switch step.Action {
+		case PlanAdd:
+			for _, alias := range step.host.Aliases {
+				if target.FindHostBlock(alias) != nil {
+					return fmt.Errorf("host '%s' %w in %s", alias, ErrHostExists, step.File)
+				}
+			}
+			target.AppendBlock(newHostBlock(step.host))
+
+		case PlanChange, PlanMove, PlanRemove:
+			source := files[versionKey(step.current.SourceFile)]
+			block := source.FindHostBlock(step.current.Name)
+			if block == nil {
+				return fmt.Errorf("host '%s' %w in %s", step.current.Name, ErrHostNotFound, step.current.SourceFile)
+			}
+			if step.Action == PlanRemove {
+				source.RemoveBlock(block)
+				continue
+			}
+
+			if value := strings.Join(step.host.Patterns, " "); strings.Join(block.Patterns(), " ") != value {
+				block.Header.SetValue(value)
+			}
+			applyMetadataToBlock(block, step.host.Metadata)
+			applyGroupToBlock(block, step.host.Group)
+			applyTagsToBlock(block, step.host.Tags)
+			applyHostToBlock(block, step.host)
+			if step.Action == PlanMove {
+				source.RemoveBlock(block)
+				target.AppendBlock(block)
+			}
+		}
+	}
+	return nil
+}
+
+// Diff returns a unified diff of the files the plan changes, without
+// writing them
+func (p *Plan) Diff() (string, error) {
+	files := make(map[string]*ConfigFile, len(p.paths))
+	originals := make(map[string][]byte, len(p.paths))
+	for _, path := range p.paths {
+		cfg, err := readConfigFile(path, false)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		originals[path] = cfg.Bytes()
+		files[versionKey(path)] = cfg
+	}
+	if err := p.stage(files); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, path := range p.paths {
+		b.WriteString(UnifiedDiff(path, path, originals[path], files[versionKey(path)].Bytes()))
+	}
+	return b.String(), nil
+}
+
+// ApplyPlan makes the changes of a plan. All the files involved are backed up
+// and written as a single change, so that either every host is updated or
+// none is. The plan fails if a file changed on disk since it was made.
+func ApplyPlan(p *Plan) error {
+	if p.IsEmpty() {
+		return nil
+	}
+	return modifyConfigFiles(p.paths, func(loaded []*ConfigFile) error {
+		files := make(map[string]*ConfigFile, len(loaded))
+		for i, path := range p.paths {
+			files[versionKey(path)] = loaded[i]
+		}
+		return p.stage(files)
+	})
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestManifest writes a manifest next to a config file
+func writeTestManifest(t *testing.T, configPath, name, content string) *Manifest {
+	t.Helper()
+	path := filepath.Join(filepath.Dir(configPath), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	m, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest() error = %v", err)
+	}
+	return m
+}
+
+// planSummary lists the steps of a plan as "action name"
+func planSummary(plan *Plan) []string {
+	var steps []string
+	for _, step := range plan.Steps {
+		steps = append(steps, step.Action.String()+" "+step.Name)
+	}
+	return steps
+}
+
+func TestLoadManifest(t *testing.T) {
+	path := writeTestConfig(t, "")
+
+	m := writeTestManifest(t, path, "fleet.json", `{"hosts": [{"name": "web1", "hostname": "10.0.0.1", "port": 2222}]}`)
+	if m.Name != "fleet" {
+		t.Errorf("Name = %q, want the file name", m.Name)
+	}
+	if len(m.declared) != 1 || m.declared[0].host.Port != "2222" {
+		t.Errorf("declared = %+v, want web1 on port 2222", m.declared)
+	}
+
+	invalid := map[string]string{
+		"unknown field": "hosts:\n  - name: web1\n    hostnme: 10.0.0.1\n",
+		"duplicate":     "hosts:\n  - name: web1\n  - name: web2\n    aliases: [web1]\n",
+		"pattern":       "hosts:\n  - name: web*\n",
+		"field option":  "hosts:\n  - name: web1\n    options:\n      - {key: user, value: root}\n",
+		"bad value":     "hosts:\n  - name: web1\n    port: ssh\n",
+	}
+	for name, content := range invalid {
+		manifest := filepath.Join(filepath.Dir(path), "invalid.yaml")
+		if err := os.WriteFile(manifest, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write manifest: %v", err)
+		}
+		if _, err := LoadManifest(manifest); err == nil {
+			t.Errorf("LoadManifest(%s) should fail", name)
+		}
+	}
+}
+
+func TestPlanAndApplyManifest(t *testing.T) {
+	path := writeTestConfig(t, "Include work\n\nHost manual\n    HostName 10.0.9.9\n")
+	work := filepath.Join(filepath.Dir(path), "work")
+	if err := os.WriteFile(work, nil, 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	m := writeTestManifest(t, path, "fleet.yaml", `hosts:
+  - name: web1
+    hostname: 10.0.0.1
+    user: deploy
+    tags: [web]
+  - name: db1
+    hostname: 10.0.1.1
+    file: work
+`)
+	plan, err := PlanManifest(m, path)
+	if err != nil {
+		t.Fatalf("PlanManifest() error = %v", err)
+	}
+	if got := strings.Join(planSummary(plan), ", "); got != "add web1, add db1" {
+		t.Fatalf("plan = %s", got)
+	}
+	if diff, err := plan.Diff(); err != nil || !strings.Contains(diff, "+Host db1") {
+		t.Errorf("Diff() = %q, %v", diff, err)
+	}
+	if err := ApplyPlan(plan); err != nil {
+		t.Fatalf("ApplyPlan() error = %v", err)
+	}
+	if got := readTestConfig(t, path); !strings.Contains(got, "# sshm: managed-by: fleet\n# Tags: web\nHost web1\n") {
+		t.Errorf("config = %q, want web1 marked as managed", got)
+	}
+
+	// Applying the same manifest again changes nothing
+	if plan, err = PlanManifest(m, path); err != nil || !plan.IsEmpty() {
+		t.Fatalf("second plan = %v, %v, want no changes", planSummary(plan), err)
+	}
+
+	// A comment added by hand survives changes to the host
+	content := strings.Replace(readTestConfig(t, path), "    User deploy\n", "    User deploy\n    # keep me\n", 1)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	m = writeTestManifest(t, path, "fleet.yaml", `hosts:
+  - name: web1
+    hostname: 10.0.0.1
+    user: admin
+    tags: [web]
+    file: work
+`)
+	if plan, err = PlanManifest(m, path); err != nil {
+		t.Fatalf("PlanManifest() error = %v", err)
+	}
+	if got := strings.Join(planSummary(plan), ", "); got != "move web1, remove db1" {
+		t.Fatalf("plan = %s", got)
+	}
+	if changes := plan.Steps[0].Changes; len(changes) != 1 || changes[0] != "user: deploy -> admin" {
+		t.Errorf("Changes = %v", changes)
+	}
+	if err := ApplyPlan(plan); err != nil {
+		t.Fatalf("ApplyPlan() error = %v", err)
+	}
+	if got := readTestConfig(t, path); got != "Include work\n\nHost manual\n    HostName 10.0.9.9\n" {
+		t.Errorf("main config = %q, want only the hand-written host", got)
+	}
+	if got := readTestConfig(t, work); !strings.Contains(got, "    User admin\n    # keep me\n") || strings.Contains(got, "db1") {
+		t.Errorf("work = %q, want web1 changed with its comment and db1 removed", got)
+	}
+}
+
+func TestPlanManifestConflicts(t *testing.T) {
+	path := writeTestConfig(t, "Host manual\n    HostName 10.0.9.9\n\n# sshm: managed-by: other\nHost other1\n    HostName 10.0.8.8\n")
+
+	for _, name := range []string{"manual", "other1"} {
+		m := writeTestManifest(t, path, "fleet.yaml", "hosts:\n  - name: "+name+"\n    hostname: 10.0.0.1\n")
+		if _, err := PlanManifest(m, path); !errors.Is(err, ErrHostExists) {
+			t.Errorf("PlanManifest(%s) error = %v, want ErrHostExists", name, err)
+		}
+	}
+
+	// Hosts of other manifests are never removed
+	m := writeTestManifest(t, path, "fleet.yaml", "hosts: []\n")
+	if plan, err := PlanManifest(m, path); err != nil || !plan.IsEmpty() {
+		t.Errorf("plan = %v, %v, want no changes", planSummary(plan), err)
+	}
+
+	m = writeTestManifest(t, path, "fleet.yaml", "file: elsewhere\nhosts:\n  - name: web1\n")
+	if _, err := PlanManifest(m, path); err == nil || !strings.Contains(err.Error(), "not part of the SSH config") {
+		t.Errorf("PlanManifest() error = %v, want a file outside the config to be refused", err)
+	}
+}
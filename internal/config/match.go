@@ -0,0 +1,191 @@
+package config
+
+import (
+	"os/user"
+	"strings"
+)
+
+// Directive is a single keyword and value read from a config block
+type Directive struct {
+	Key   string
+	Value string
+	Line  int // 1-based line number in the source file
+}
+
+// MatchCriterion is one condition of a Match line, such as "host *.prod" or "!exec ..."
+type MatchCriterion struct {
+	Keyword  string // Lowercased criterion keyword (host, originalhost, user, localuser, exec, all, canonical, final, ...)
+	Argument string // Argument as written, empty for all, canonical and final
+	Negated  bool   // Criterion was prefixed with '!'
+}
+
+// MatchBlock is a Match section with its criteria and directives
+type MatchBlock struct {
+	Condition  string // Criteria as written on the Match line
+	Criteria   []MatchCriterion
+	Directives []Directive
+	SourceFile string
+	Line       int // Line number of the Match keyword
+}
+
+// MatchStatus tells whether a Match block applies to a host
+type MatchStatus int
+
+const (
+	MatchNo MatchStatus = iota
+	MatchYes
+	MatchMaybe // Depends on runtime state sshm cannot evaluate (exec, canonical, ...)
+)
+
+func (s MatchStatus) String() string {
+	switch s {
+	case MatchYes:
+		return "applies"
+	case MatchMaybe:
+		return "may apply"
+	default:
+		return "does not apply"
+	}
+}
+
+// AppliedMatch is a Match block that applies, or may apply, to a host
+type AppliedMatch struct {
+	Block  MatchBlock
+	Status MatchStatus
+}
+
+// matchKeywordsWithoutArgument lists the criteria that take no argument
+var matchKeywordsWithoutArgument = map[string]bool{
+	"all":       true,
+	"canonical": true,
+	"final":     true,
+}
+
+// ParseMatchCriteria splits the value of a Match line into criteria
+func ParseMatchCriteria(value string) []MatchCriterion {
+	var criteria []MatchCriterion
+
+	args := splitQuotedArgs(value)
+	for i := 0; i < len(args); i++ {
+		keyword := strings.ToLower(args[i])
+		criterion := MatchCriterion{}
+		if strings.HasPrefix(keyword, "!") {
+			criterion.Negated = true
+			keyword = keyword[1:]
+		}
+		criterion.Keyword = keyword
+
+		if !matchKeywordsWithoutArgument[keyword] && i+1 < len(args) {
+			i++
+			criterion.Argument = args[i]
+		}
+		criteria = append(criteria, criterion)
+	}
+
+	return criteria
+}
+
+// Evaluate tells whether the Match block applies to a host. Criteria that
+// depend on runtime state (exec, canonical, localnetwork, ...) yield MatchMaybe.
+func (m MatchBlock) Evaluate(host SSHHost) MatchStatus {
+	if len(m.Criteria) == 0 {
+		return MatchNo
+	}
+
+	status := MatchYes
+	for _, criterion := range m.Criteria {
+		result := criterion.evaluate(host)
+		if criterion.Negated {
+			switch result {
+			case MatchYes:
+				result = MatchNo
+			case MatchNo:
+				result = MatchYes
+			}
+		}
+
+		switch result {
+		case MatchNo:
+			return MatchNo
+		case MatchMaybe:
+			status = MatchMaybe
+		}
+	}
+
+	return status
+}
+
+// evaluate checks a single criterion, ignoring negation
+func (c MatchCriterion) evaluate(host SSHHost) MatchStatus {
+	switch c.Keyword {
+	case "all":
+		return MatchYes
+	case "host":
+		// Matched against the target hostname, after HostName is applied
+		target := host.Hostname
+		if target == "" {
+			target = host.Name
+		}
+		return matchStatus(MatchHostPatterns(splitPatternList(c.Argument), target))
+	case "originalhost":
+		// Matched against the name given on the command line
+		for _, alias := range host.Aliases {
+			if MatchHostPatterns(splitPatternList(c.Argument), alias) {
+				return MatchYes
+			}
+		}
+		return matchStatus(MatchHostPatterns(splitPatternList(c.Argument), host.Name))
+	case "user":
+		remoteUser := host.User
+		if remoteUser == "" {
+			remoteUser = currentUsername()
+		}
+		return matchStatus(remoteUser != "" && MatchHostPatterns(splitPatternList(c.Argument), remoteUser))
+	case "localuser":
+		localUser := currentUsername()
+		return matchStatus(localUser != "" && MatchHostPatterns(splitPatternList(c.Argument), localUser))
+	default:
+		// exec, canonical, final, localnetwork, tagged and unknown criteria
+		return MatchMaybe
+	}
+}
+
+// splitPatternList splits a comma-separated pattern list
+func splitPatternList(list string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(list, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// matchStatus converts a boolean match into a MatchStatus
+func matchStatus(matched bool) MatchStatus {
+	if matched {
+		return MatchYes
+	}
+	return MatchNo
+}
+
+// currentUsername returns the name of the local user, or "" if unknown
+func currentUsername() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
+
+// MatchBlocksFor returns the Match blocks that apply, or may apply, to a host,
+// in file order
+func (p *ParsedConfig) MatchBlocksFor(host SSHHost) []AppliedMatch {
+	var applied []AppliedMatch
+	for _, block := range p.MatchBlocks {
+		if status := block.Evaluate(host); status != MatchNo {
+			applied = append(applied, AppliedMatch{Block: block, Status: status})
+		}
+	}
+	return applied
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMatchCriteria(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []MatchCriterion
+	}{
+		{"all", []MatchCriterion{{Keyword: "all"}}},
+		{"host *.prod,!db.prod user deploy", []MatchCriterion{
+			{Keyword: "host", Argument: "*.prod,!db.prod"},
+			{Keyword: "user", Argument: "deploy"},
+		}},
+		{`originalhost bastion !exec "nc -z vpn 22"`, []MatchCriterion{
+			{Keyword: "originalhost", Argument: "bastion"},
+			{Keyword: "exec", Argument: "nc -z vpn 22", Negated: true},
+		}},
+		{"canonical final localuser root", []MatchCriterion{
+			{Keyword: "canonical"},
+			{Keyword: "final"},
+			{Keyword: "localuser", Argument: "root"},
+		}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseMatchCriteria(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseMatchCriteria(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMatchBlockEvaluate(t *testing.T) {
+	host := SSHHost{Name: "web", Aliases: []string{"web", "web.alias"}, Hostname: "web.prod", User: "deploy"}
+
+	tests := []struct {
+		condition string
+		want      MatchStatus
+	}{
+		{"all", MatchYes},
+		{"host *.prod", MatchYes},
+		{"host *.dev", MatchNo},
+		{"!host *.dev", MatchYes},
+		{"host *.prod,!web.prod", MatchNo},
+		{"originalhost web.alias", MatchYes},
+		{"originalhost web.prod", MatchNo},
+		{"user deploy", MatchYes},
+		{"user root", MatchNo},
+		{`host *.prod exec "true"`, MatchMaybe},
+		{`host *.dev exec "true"`, MatchNo},
+		{"canonical", MatchMaybe},
+	}
+
+	for _, tt := range tests {
+		block := MatchBlock{Condition: tt.condition, Criteria: ParseMatchCriteria(tt.condition)}
+		if got := block.Evaluate(host); got != tt.want {
+			t.Errorf("Evaluate(%q) = %v, want %v", tt.condition, got, tt.want)
+		}
+	}
+}
+
+func TestParseConfigTreeMatchBlocks(t *testing.T) {
+	path := writeTestConfig(t, `Host web
+    HostName web.prod
+    User deploy
+
+Match host *.prod exec "test -f /tmp/vpn"
+    ProxyJump bastion
+    ForwardAgent yes
+
+Host db
+    HostName db.internal
+`)
+
+	parsed, err := ParseConfigTree(path)
+	if err != nil {
+		t.Fatalf("ParseConfigTree() error = %v", err)
+	}
+
+	if len(parsed.Hosts) != 2 {
+		t.Fatalf("expected 2 hosts, got %d", len(parsed.Hosts))
+	}
+	web := parsed.Hosts[0]
+	if web.Options != "" || web.ProxyJump != "" {
+		t.Errorf("Match directives leaked into host web: options=%q proxyjump=%q", web.Options, web.ProxyJump)
+	}
+
+	if len(parsed.MatchBlocks) != 1 {
+		t.Fatalf("expected 1 Match block, got %d", len(parsed.MatchBlocks))
+	}
+	match := parsed.MatchBlocks[0]
+	if match.Line != 5 || len(match.Directives) != 2 || match.Directives[0].Key != "ProxyJump" {
+		t.Errorf("unexpected Match block: %+v", match)
+	}
+
+	applied := parsed.MatchBlocksFor(web)
+	if len(applied) != 1 || applied[0].Status != MatchMaybe {
+		t.Errorf("MatchBlocksFor(web) = %+v, want one conditional match", applied)
+	}
+	if applied := parsed.MatchBlocksFor(parsed.Hosts[1]); len(applied) != 0 {
+		t.Errorf("MatchBlocksFor(db) = %+v, want none", applied)
+	}
+}
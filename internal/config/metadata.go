@@ -0,0 +1,233 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// metadataPrefix starts the comments holding sshm metadata about a host
+const metadataPrefix = "# sshm:"
+
+// HostMetadata describes a host beyond what ssh reads. It is stored in
+// "# sshm: key: value" comments directly above the Host line, next to the
+// "# Tags:" comment, so that it travels with the block when it is moved,
+// renamed or formatted.
+type HostMetadata struct {
+	Description string
+	Owner       string // Person or team responsible for the host
+	Environment string // e.g. production, staging
+	Links       []string
+	Notes       string // Markdown, possibly spanning several lines
+	// Extra holds entries with other keys, kept as written
+	Extra []MetadataEntry
+}
+
+// MetadataEntry is one "# sshm:" comment
+type MetadataEntry struct {
+	Key   string
+	Value string
+}
+
+// IsEmpty reports whether no metadata is set
+func (m HostMetadata) IsEmpty() bool {
+	return m.Description == "" && m.Owner == "" && m.Environment == "" &&
+		len(m.Links) == 0 && m.Notes == "" && len(m.Extra) == 0
+}
+
+// Entries returns the metadata as the comments sshm writes, in order
+func (m HostMetadata) Entries() []MetadataEntry {
+	var entries []MetadataEntry
+	add := func(key, value string) {
+		if value != "" {
+			entries = append(entries, MetadataEntry{Key: key, Value: value})
+		}
+	}
+	add("description", m.Description)
+	add("owner", m.Owner)
+	add("environment", m.Environment)
+	for _, link := range m.Links {
+		add("link", link)
+	}
+	if notes := strings.TrimRight(m.Notes, "\r\n"); notes != "" {
+		// Every line is kept, blank ones included, so markdown survives
+		for _, line := range strings.Split(notes, "\n") {
+			entries = append(entries, MetadataEntry{Key: "notes", Value: strings.TrimRight(line, " \t\r")})
+		}
+	}
+	for _, entry := range m.Extra {
+		add(entry.Key, entry.Value)
+	}
+	return entries
+}
+
+// Matches reports whether any metadata value contains the query, which must
+// be lowercase
+func (m HostMetadata) Matches(query string) bool {
+	for _, entry := range m.Entries() {
+		if strings.Contains(strings.ToLower(entry.Value), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchMetadataQuery matches queries such as "owner=platform" or "env=prod"
+// against the metadata of the host. ok is false when the query doesn't name
+// a metadata key, so that it can be handled as another kind of query.
+func (h *SSHHost) MatchMetadataQuery(query string) (matched, ok bool) {
+	key, value, found := strings.Cut(query, "=")
+	if !found {
+		return false, false
+	}
+	key = canonicalMetadataKey(strings.TrimSpace(key))
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	var values []string
+	switch key {
+	case "description", "owner", "environment", "link", "notes":
+		for _, entry := range h.Metadata.Entries() {
+			if entry.Key == key {
+				values = append(values, entry.Value)
+			}
+		}
+	default:
+		// ssh_config keywords are matched against the directives
+		if _, known := CanonicalKeyword(key); known {
+			return false, false
+		}
+		found = false
+		for _, entry := range h.Metadata.Extra {
+			if entry.Key == key {
+				values = append(values, entry.Value)
+				found = true
+			}
+		}
+		if !found {
+			return false, false
+		}
+	}
+
+	for _, v := range values {
+		if strings.Contains(strings.ToLower(v), value) {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// canonicalMetadataKey returns the key under which a metadata entry is stored
+func canonicalMetadataKey(key string) string {
+	key = strings.ToLower(key)
+	switch key {
+	case "env":
+		return "environment"
+	case "links":
+		return "link"
+	case "note":
+		return "notes"
+	}
+	return key
+}
+
+// parseMetadataComment extracts the entry held by a "# sshm:" comment
+func parseMetadataComment(comment string) (MetadataEntry, bool) {
+	if !strings.HasPrefix(comment, metadataPrefix) {
+		return MetadataEntry{}, false
+	}
+
+	rest := strings.TrimSpace(strings.TrimPrefix(comment, metadataPrefix))
+	key, value, _ := strings.Cut(rest, ":")
+	key = strings.TrimSpace(key)
+	if key == "" || strings.ContainsAny(key, " \t") {
+		return MetadataEntry{}, false
+	}
+	return MetadataEntry{Key: canonicalMetadataKey(key), Value: strings.TrimSpace(value)}, true
+}
+
+// formatMetadataComment renders an entry as a "# sshm:" comment
+func formatMetadataComment(entry MetadataEntry) string {
+	if entry.Value == "" {
+		return metadataPrefix + " " + entry.Key + ":"
+	}
+	return metadataPrefix + " " + entry.Key + ": " + entry.Value
+}
+
+// Metadata returns the metadata declared in "# sshm:" comments above the header
+func (b *ConfigBlock) Metadata() HostMetadata {
+	var m HostMetadata
+	var notes []string
+	for _, line := range b.Leading {
+		entry, ok := parseMetadataComment(line.Comment)
+		if !ok {
+			continue
+		}
+		switch entry.Key {
+		case "description":
+			if m.Description == "" {
+				m.Description = entry.Value
+			}
+		case "owner":
+			if m.Owner == "" {
+				m.Owner = entry.Value
+			}
+		case "environment":
+			if m.Environment == "" {
+				m.Environment = entry.Value
+			}
+		case "link":
+			if entry.Value != "" {
+				m.Links = append(m.Links, entry.Value)
+			}
+		case "notes":
+			notes = append(notes, entry.Value)
+		default:
+			m.Extra = append(m.Extra, entry)
+		}
+	}
+	m.Notes = strings.TrimRight(strings.Join(notes, "\n"), "\n")
+	return m
+}
+
+// applyMetadataToBlock updates the "# sshm:" comments above a Host block.
+// Comments are left untouched when the metadata didn't change; otherwise they
+// are rewritten where the first one was, or above the "# Group:" and "# Tags:"
+// comments.
+func applyMetadataToBlock(block *ConfigBlock, metadata HostMetadata) {
+	if reflect.DeepEqual(block.Metadata().Entries(), metadata.Entries()) {
+		return
+	}
+
+	pos := -1
+	var kept []*ConfigLine
+	for _, line := range block.Leading {
+		if _, ok := parseMetadataComment(line.Comment); ok {
+			if pos < 0 {
+				pos = len(kept)
+			}
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if pos < 0 {
+		pos = len(kept)
+		for i, line := range kept {
+			_, isGroup := parseGroupComment(line.Comment)
+			_, isTags := parseTagsComment(line.Comment)
+			if isGroup || isTags {
+				pos = i
+				break
+			}
+		}
+	}
+
+	var lines []*ConfigLine
+	for _, entry := range metadata.Entries() {
+		lines = append(lines, newCommentLine(block.Header.Indent, formatMetadataComment(entry)))
+	}
+
+	leading := make([]*ConfigLine, 0, len(kept)+len(lines))
+	leading = append(leading, kept[:pos]...)
+	leading = append(leading, lines...)
+	leading = append(leading, kept[pos:]...)
+	block.Leading = leading
+}
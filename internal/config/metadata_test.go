@@ -0,0 +1,165 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const metadataConfig = `# Main site
+# sshm: description: Production web server
+# sshm: owner: platform-team
+# sshm: env: production
+# sshm: link: https://wiki.example.com/web
+# sshm: link: https://grafana.example.com/d/web
+# sshm: notes: ## Deploys
+# sshm: notes:
+# sshm: notes: - run ./deploy.sh
+# sshm: ticket: OPS-42
+# Tags: prod, web
+Host web
+    HostName web.example.com
+`
+
+func TestParseHostMetadata(t *testing.T) {
+	path := writeTestConfig(t, metadataConfig)
+
+	host, err := GetSSHHostFromFile("web", path)
+	if err != nil {
+		t.Fatalf("GetSSHHostFromFile() error = %v", err)
+	}
+
+	want := HostMetadata{
+		Description: "Production web server",
+		Owner:       "platform-team",
+		Environment: "production",
+		Links:       []string{"https://wiki.example.com/web", "https://grafana.example.com/d/web"},
+		Notes:       "## Deploys\n\n- run ./deploy.sh",
+		Extra:       []MetadataEntry{{Key: "ticket", Value: "OPS-42"}},
+	}
+	if !reflect.DeepEqual(host.Metadata, want) {
+		t.Errorf("Metadata = %+v, want %+v", host.Metadata, want)
+	}
+	if got := strings.Join(host.Tags, ","); got != "prod,web" {
+		t.Errorf("Tags = %s, want prod,web", got)
+	}
+}
+
+func TestUpdateHostMetadata(t *testing.T) {
+	path := writeTestConfig(t, metadataConfig)
+
+	host, err := GetSSHHostFromFile("web", path)
+	if err != nil {
+		t.Fatalf("GetSSHHostFromFile() error = %v", err)
+	}
+
+	// Unchanged metadata leaves the comments as written
+	host.Name = "www"
+	if err := UpdateSSHHostInFile("web", *host, path); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() error = %v", err)
+	}
+	want := strings.Replace(metadataConfig, "Host web", "Host www", 1)
+	if got := readTestConfig(t, path); got != want {
+		t.Errorf("renaming changed the metadata:\n%s", got)
+	}
+
+	host.Metadata.Owner = "web-team"
+	host.Metadata.Links = nil
+	host.Metadata.Notes = "Rebooted weekly"
+	if err := UpdateSSHHostInFile("www", *host, path); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() error = %v", err)
+	}
+	want = `# Main site
+# sshm: description: Production web server
+# sshm: owner: web-team
+# sshm: environment: production
+# sshm: notes: Rebooted weekly
+# sshm: ticket: OPS-42
+# Tags: prod, web
+Host www
+    HostName web.example.com
+`
+	if got := readTestConfig(t, path); got != want {
+		t.Errorf("config:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestAddHostWithMetadata(t *testing.T) {
+	path := writeTestConfig(t, "Host a\n")
+
+	host := SSHHost{
+		Name:     "b",
+		Hostname: "b.example.com",
+		Tags:     []string{"db"},
+		Metadata: HostMetadata{Description: "Database", Notes: "line one\nline two\n"},
+	}
+	if err := AddSSHHostToFile(host, path); err != nil {
+		t.Fatalf("AddSSHHostToFile() error = %v", err)
+	}
+
+	want := "Host a\n\n# sshm: description: Database\n# sshm: notes: line one\n# sshm: notes: line two\n# Tags: db\nHost b\n    HostName b.example.com\n"
+	if got := readTestConfig(t, path); got != want {
+		t.Errorf("config = %q, want %q", got, want)
+	}
+}
+
+func TestMetadataSurvivesMoveAndFormat(t *testing.T) {
+	userPath := setupSystemConfig(t, "Include work\n\n"+metadataConfig, "")
+	work := filepath.Join(filepath.Dir(userPath), "work")
+	if err := os.WriteFile(work, []byte("Host other\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if err := MoveHostToFile("web", work); err != nil {
+		t.Fatalf("MoveHostToFile() error = %v", err)
+	}
+	if err := WriteFormattedFiles([]string{work}, FormatOptions{}); err != nil {
+		t.Fatalf("WriteFormattedFiles() error = %v", err)
+	}
+
+	hosts, err := ParseSSHConfigFile(work)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	host, err := findHostByAlias(hosts, "web")
+	if err != nil {
+		t.Fatalf("moved host not found: %v", err)
+	}
+	if host.Metadata.Owner != "platform-team" || host.Metadata.Notes != "## Deploys\n\n- run ./deploy.sh" || len(host.Metadata.Links) != 2 {
+		t.Errorf("metadata lost after move and format: %+v", host.Metadata)
+	}
+}
+
+func TestMatchMetadataQuery(t *testing.T) {
+	host := SSHHost{Name: "web", Metadata: HostMetadata{
+		Owner:       "Platform-Team",
+		Environment: "production",
+		Extra:       []MetadataEntry{{Key: "ticket", Value: "OPS-42"}},
+	}}
+
+	tests := []struct {
+		query       string
+		wantMatched bool
+		wantOK      bool
+	}{
+		{"owner=platform", true, true},
+		{"env=prod", true, true},
+		{"environment=staging", false, true},
+		{"description=web", false, true},
+		{"ticket=ops-42", true, true},
+		{"user=deploy", false, false},
+		{"platform", false, false},
+	}
+	for _, tt := range tests {
+		matched, ok := host.MatchMetadataQuery(tt.query)
+		if matched != tt.wantMatched || ok != tt.wantOK {
+			t.Errorf("MatchMetadataQuery(%q) = %v, %v, want %v, %v", tt.query, matched, ok, tt.wantMatched, tt.wantOK)
+		}
+	}
+
+	if !host.Metadata.Matches("platform") {
+		t.Error("expected free text to match the owner")
+	}
+}
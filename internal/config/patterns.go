@@ -0,0 +1,125 @@
+package config
+
+import "strings"
+
+// IsNegatedPattern reports whether a Host pattern is a negation (e.g. "!web1.old")
+func IsNegatedPattern(pattern string) bool {
+	return strings.HasPrefix(pattern, "!")
+}
+
+// IsWildcardPattern reports whether a Host pattern contains wildcards
+func IsWildcardPattern(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?")
+}
+
+// IsConcreteAlias reports whether a Host pattern names a single connectable host
+func IsConcreteAlias(pattern string) bool {
+	return pattern != "" && !IsNegatedPattern(pattern) && !IsWildcardPattern(pattern)
+}
+
+// SplitHostPatterns splits the value of a Host line into its patterns.
+// Quoted patterns and comma-separated lists are accepted as well, as OpenSSH
+// does.
+func SplitHostPatterns(value string) []string {
+	var patterns []string
+	for _, field := range splitQuotedArgs(value) {
+		for _, pattern := range strings.Split(field, ",") {
+			if pattern != "" {
+				patterns = append(patterns, pattern)
+			}
+		}
+	}
+	return patterns
+}
+
+// ConcreteAliases returns the patterns that name a single connectable host
+func ConcreteAliases(patterns []string) []string {
+	var aliases []string
+	for _, pattern := range patterns {
+		if IsConcreteAlias(pattern) {
+			aliases = append(aliases, pattern)
+		}
+	}
+	return aliases
+}
+
+// MatchPattern reports whether a host name matches a single pattern,
+// ignoring negation. Matching is case-insensitive, like OpenSSH.
+func MatchPattern(pattern, host string) bool {
+	pattern = strings.ToLower(strings.TrimPrefix(pattern, "!"))
+	return matchGlob(pattern, strings.ToLower(host))
+}
+
+// matchGlob matches s against a pattern where '*' matches any sequence and
+// '?' matches exactly one character
+func matchGlob(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			// Collapse consecutive stars and try every possible split
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if pattern == "" {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if matchGlob(pattern, s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		default:
+			if s == "" || pattern[0] != s[0] {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		}
+	}
+	return s == ""
+}
+
+// MatchHostPatterns applies OpenSSH Host matching rules: a negated pattern that
+// matches excludes the host, otherwise any matching pattern selects it
+func MatchHostPatterns(patterns []string, host string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		if !MatchPattern(pattern, host) {
+			continue
+		}
+		if IsNegatedPattern(pattern) {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
+
+// HasAlias reports whether the host can be reached under the given name
+func (h SSHHost) HasAlias(name string) bool {
+	if h.Name == name {
+		return true
+	}
+	for _, alias := range h.Aliases {
+		if alias == name {
+			return true
+		}
+	}
+	return false
+}
+
+// OtherAliases returns the concrete aliases of the host besides its primary name
+func (h SSHHost) OtherAliases() []string {
+	var others []string
+	for _, alias := range h.Aliases {
+		if alias != h.Name {
+			others = append(others, alias)
+		}
+	}
+	return others
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitHostPatterns(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"web1", []string{"web1"}},
+		{"web1 web1.prod !web1.old", []string{"web1", "web1.prod", "!web1.old"}},
+		{"a,b  c", []string{"a", "b", "c"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if got := SplitHostPatterns(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitHostPatterns(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestConcreteAliases(t *testing.T) {
+	got := ConcreteAliases([]string{"web1", "*.prod", "web1.prod", "!web1.old", "db?"})
+	want := []string{"web1", "web1.prod"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ConcreteAliases() = %v, want %v", got, want)
+	}
+}
+
+func TestMatchHostPatterns(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		host     string
+		want     bool
+	}{
+		{[]string{"*"}, "anything", true},
+		{[]string{"*.prod"}, "web.prod", true},
+		{[]string{"*.prod"}, "web.dev", false},
+		{[]string{"web?"}, "web1", true},
+		{[]string{"web?"}, "web10", false},
+		{[]string{"WEB1"}, "web1", true},
+		{[]string{"*.prod", "!db.prod"}, "db.prod", false},
+		{[]string{"*.prod", "!db.prod"}, "web.prod", true},
+		{[]string{"!db.prod"}, "web.prod", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchHostPatterns(tt.patterns, tt.host); got != tt.want {
+			t.Errorf("MatchHostPatterns(%v, %q) = %v, want %v", tt.patterns, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestParseMultiPatternHost(t *testing.T) {
+	path := writeTestConfig(t, `Host web1 web1.prod !web1.old
+    HostName 10.0.0.1
+
+Host *.prod !db.prod
+    User deploy
+`)
+
+	hosts, err := ParseSSHConfigFile(path)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	if len(hosts) != 1 {
+		t.Fatalf("expected 1 host, got %d", len(hosts))
+	}
+
+	host := hosts[0]
+	if host.Name != "web1" {
+		t.Errorf("Name = %q, want %q", host.Name, "web1")
+	}
+	if want := []string{"web1", "web1.prod"}; !reflect.DeepEqual(host.Aliases, want) {
+		t.Errorf("Aliases = %v, want %v", host.Aliases, want)
+	}
+	if want := []string{"web1", "web1.prod", "!web1.old"}; !reflect.DeepEqual(host.Patterns, want) {
+		t.Errorf("Patterns = %v, want %v", host.Patterns, want)
+	}
+	if !host.HasAlias("web1.prod") || host.HasAlias("web1.old") {
+		t.Error("HasAlias() should accept concrete aliases only")
+	}
+}
+
+func TestUpdateMultiPatternHostKeepsAliases(t *testing.T) {
+	path := writeTestConfig(t, `Host web1 web1.prod,!web1.old
+    HostName 10.0.0.1
+`)
+
+	host := SSHHost{Name: "web2", Hostname: "10.0.0.2", Port: "22"}
+	if err := UpdateSSHHostInFile("web1", host, path); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() error = %v", err)
+	}
+
+	want := `Host web2 web1.prod,!web1.old
+    HostName 10.0.0.2
+`
+	if got := readTestConfig(t, path); got != want {
+		t.Errorf("unexpected result:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The secondary alias still resolves to the same block
+	if err := DeleteSSHHostFromFile("web1.prod", path); err != nil {
+		t.Fatalf("DeleteSSHHostFromFile() by alias error = %v", err)
+	}
+}
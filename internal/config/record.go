@@ -0,0 +1,73 @@
+package config
+
+import "strings"
+
+// HostRecord is a host as written by "sshm list" and "sshm show" in JSON and
+// YAML. Fields are only ever added, never renamed or removed, so that scripts
+// reading them keep working. Lists are empty rather than null.
+type HostRecord struct {
+	Name          string         `json:"name" yaml:"name"`
+	Aliases       []string       `json:"aliases" yaml:"aliases"` // Other names on the Host line
+	Hostname      string         `json:"hostname" yaml:"hostname"`
+	User          string         `json:"user" yaml:"user"`
+	Port          string         `json:"port" yaml:"port"`
+	IdentityFiles []string       `json:"identity_files" yaml:"identity_files"`
+	ProxyJump     string         `json:"proxy_jump" yaml:"proxy_jump"`
+	Options       []RecordOption `json:"options" yaml:"options"` // Other directives, in order
+	Tags          []string       `json:"tags" yaml:"tags"`
+	Group         string         `json:"group" yaml:"group"`
+	Description   string         `json:"description" yaml:"description"`
+	Owner         string         `json:"owner" yaml:"owner"`
+	Environment   string         `json:"environment" yaml:"environment"`
+	Links         []string       `json:"links" yaml:"links"`
+	Notes         string         `json:"notes" yaml:"notes"`
+	File          string         `json:"file" yaml:"file"`     // Config file the host is defined in
+	System        bool           `json:"system" yaml:"system"` // Read-only host of the system-wide config
+}
+
+// RecordOption is a directive of a HostRecord
+type RecordOption struct {
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// NewHostRecord describes a host with the HostRecord schema
+func NewHostRecord(host SSHHost) HostRecord {
+	record := HostRecord{
+		Name:          host.Name,
+		Aliases:       emptyIfNil(host.OtherAliases()),
+		Hostname:      host.Hostname,
+		User:          host.User,
+		Port:          host.Port,
+		IdentityFiles: []string{},
+		ProxyJump:     host.ProxyJump,
+		Options:       []RecordOption{},
+		Tags:          emptyIfNil(host.Tags),
+		Group:         host.Group,
+		Description:   host.Metadata.Description,
+		Owner:         host.Metadata.Owner,
+		Environment:   host.Metadata.Environment,
+		Links:         emptyIfNil(host.Metadata.Links),
+		Notes:         host.Metadata.Notes,
+		File:          host.SourceFile,
+		System:        host.System,
+	}
+	for _, identity := range host.GetAll("IdentityFile") {
+		record.IdentityFiles = append(record.IdentityFiles, UnquoteArg(identity))
+	}
+	for _, d := range host.OtherDirectives() {
+		if !strings.EqualFold(d.Key, "IdentityFile") {
+			record.Options = append(record.Options, RecordOption{Key: LookupKeyword(d.Key).Name, Value: d.Value})
+		}
+	}
+	return record
+}
+
+// emptyIfNil returns an empty list instead of nil, written as [] rather than
+// null in JSON
+func emptyIfNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
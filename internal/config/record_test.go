@@ -0,0 +1,41 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewHostRecord(t *testing.T) {
+	host := SSHHost{Name: "web", Aliases: []string{"web", "www"}, Patterns: []string{"web", "www"}, SourceFile: "/tmp/config"}
+	host.Set("HostName", "web.example.com")
+	host.Set("IdentityFile", `"~/.ssh/my key"`)
+	host.Set("Compression", "yes")
+
+	record := NewHostRecord(host)
+	if record.Hostname != "web.example.com" || record.File != "/tmp/config" {
+		t.Errorf("record = %+v", record)
+	}
+	if len(record.Aliases) != 1 || record.Aliases[0] != "www" {
+		t.Errorf("Aliases = %v, want [www]", record.Aliases)
+	}
+	if len(record.IdentityFiles) != 1 || record.IdentityFiles[0] != "~/.ssh/my key" {
+		t.Errorf("IdentityFiles = %v, want the path unquoted", record.IdentityFiles)
+	}
+	if len(record.Options) != 1 || record.Options[0] != (RecordOption{Key: "Compression", Value: "yes"}) {
+		t.Errorf("Options = %v, want Compression only", record.Options)
+	}
+	if record.Tags == nil || record.Links == nil {
+		t.Error("Lists should be empty rather than nil")
+	}
+}
+
+func TestHostErrors(t *testing.T) {
+	path := writeTestConfig(t, "Host web\n    HostName web.example.com\n")
+
+	if _, err := GetSSHHostFromFile("missing", path); !errors.Is(err, ErrHostNotFound) {
+		t.Errorf("GetSSHHostFromFile(missing) error = %v, want ErrHostNotFound", err)
+	}
+	if err := AddSSHHostToFile(SSHHost{Name: "web"}, path); !errors.Is(err, ErrHostExists) {
+		t.Errorf("AddSSHHostToFile(web) error = %v, want ErrHostExists", err)
+	}
+}
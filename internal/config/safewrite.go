@@ -0,0 +1,206 @@
+package config
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrConfigChanged is returned when a config file changed on disk since sshm
+// last read it, so that changes made outside sshm are not overwritten
+var ErrConfigChanged = errors.New("config file changed on disk since it was read")
+
+// errLockBusy is returned by tryLockFile when another process holds the lock
+var errLockBusy = errors.New("lock is held by another process")
+
+// lockTimeout is how long to wait for another sshm process to finish writing
+const lockTimeout = 10 * time.Second
+
+// configMutex protects SSH config file operations from race conditions
+var configMutex sync.Mutex
+
+// lockConfig serializes config writes with other goroutines and with every
+// other sshm process, through an advisory lock file in the sshm config
+// directory. The returned function releases the lock.
+func lockConfig() (func(), error) {
+	configMutex.Lock()
+
+	unlock, err := lockConfigFile()
+	if err != nil {
+		configMutex.Unlock()
+		return nil, err
+	}
+
+	return func() {
+		unlock()
+		configMutex.Unlock()
+	}, nil
+}
+
+// lockConfigFile takes the cross-process lock, waiting up to lockTimeout
+func lockConfigFile() (func(), error) {
+	configDir, err := GetSSHMConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sshm config directory: %w", err)
+	}
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create sshm config directory: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(configDir, "sshm.lock"), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err := tryLockFile(file)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLockBusy) {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock config: %w", err)
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("timed out waiting for another sshm process to finish writing the SSH config")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	return func() {
+		_ = unlockFile(file)
+		file.Close()
+	}, nil
+}
+
+// modifyConfigFile loads a config file under the config lock, applies change
+// to it and writes it back, after taking a backup of the current content
+func modifyConfigFile(configPath string, change func(cfg *ConfigFile) error) error {
+	return modifyConfigFiles([]string{configPath}, func(files []*ConfigFile) error {
+		return change(files[0])
+	})
+}
+
+// fileVersions remembers the content hash of each config file as sshm last
+// read or wrote it, keyed by absolute path
+var (
+	fileVersions      = make(map[string][sha256.Size]byte)
+	fileVersionsMutex sync.Mutex
+)
+
+// versionKey returns the key used in fileVersions for a path
+func versionKey(path string) string {
+	if absPath, err := filepath.Abs(path); err == nil {
+		return absPath
+	}
+	return path
+}
+
+// recordVersion remembers the content of a file as sshm has seen it
+func recordVersion(path string, data []byte) {
+	fileVersionsMutex.Lock()
+	defer fileVersionsMutex.Unlock()
+	fileVersions[versionKey(path)] = sha256.Sum256(data)
+}
+
+// checkVersion fails with ErrConfigChanged if the content of a file differs
+// from the last version sshm has seen. The new content is then recorded, so
+// that retrying the change after reviewing it succeeds.
+func checkVersion(path string, data []byte) error {
+	fileVersionsMutex.Lock()
+	defer fileVersionsMutex.Unlock()
+
+	key := versionKey(path)
+	current := sha256.Sum256(data)
+	seen, ok := fileVersions[key]
+	fileVersions[key] = current
+	if ok && seen != current {
+		return fmt.Errorf("%s: %w, review it and try again", path, ErrConfigChanged)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces a file by writing a temporary file next to it,
+// syncing it to disk and renaming it over the original, so that readers and
+// crashes never see a partial file. Symlinks are followed so the link itself
+// is kept, and the mode of an existing file is preserved.
+func writeFileAtomic(path string, data []byte, defaultMode os.FileMode) error {
+	target, err := resolveSymlinks(path)
+	if err != nil {
+		return err
+	}
+
+	mode := defaultMode
+	if info, err := os.Stat(target); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(target)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(target)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, target); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", target, err)
+	}
+
+	syncDir(dir)
+	return nil
+}
+
+// resolveSymlinks follows symlinks to the file they point to, even if that
+// file does not exist yet
+func resolveSymlinks(path string) (string, error) {
+	for i := 0; i < 255; i++ {
+		info, err := os.Lstat(path)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			return path, nil
+		}
+
+		link, err := os.Readlink(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read symlink %s: %w", path, err)
+		}
+		if !filepath.IsAbs(link) {
+			link = filepath.Join(filepath.Dir(path), link)
+		}
+		path = link
+	}
+	return "", fmt.Errorf("too many levels of symbolic links: %s", path)
+}
+
+// syncDir flushes a directory entry to disk after a rename. Errors are ignored
+// as not every platform supports syncing directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	d.Close()
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteFileAtomicKeepsModeAndSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "real_config")
+	if err := os.WriteFile(target, []byte("Host a\n"), 0640); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	link := filepath.Join(dir, "config")
+	if err := os.Symlink("real_config", link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := writeFileAtomic(link, []byte("Host b\n"), 0600); err != nil {
+		t.Fatalf("writeFileAtomic() error = %v", err)
+	}
+
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatalf("Lstat() error = %v", err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Error("the symlink was replaced by a regular file")
+	}
+	if got := readTestConfig(t, target); got != "Host b\n" {
+		t.Errorf("target content = %q, want %q", got, "Host b\n")
+	}
+	if runtime.GOOS != "windows" {
+		if info, _ := os.Stat(target); info.Mode().Perm() != 0640 {
+			t.Errorf("mode = %v, want %v", info.Mode().Perm(), os.FileMode(0640))
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("expected no temporary file left behind, got %d entries", len(entries))
+	}
+}
+
+func TestUpdateRefusesFileChangedOnDisk(t *testing.T) {
+	path := writeTestConfig(t, "Host a\n    HostName a\n")
+
+	host, err := GetSSHHostFromFile("a", path)
+	if err != nil {
+		t.Fatalf("GetSSHHostFromFile() error = %v", err)
+	}
+
+	// Another program edits the file after sshm read it
+	external := "Host a\n    HostName a\n\nHost b\n    HostName b\n"
+	if err := os.WriteFile(path, []byte(external), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	host.User = "admin"
+	err = UpdateSSHHostInFile("a", *host, path)
+	if !errors.Is(err, ErrConfigChanged) {
+		t.Fatalf("UpdateSSHHostInFile() error = %v, want ErrConfigChanged", err)
+	}
+	if got := readTestConfig(t, path); got != external {
+		t.Errorf("file was overwritten:\n%s", got)
+	}
+
+	// Retrying after the conflict applies the change on top of the new content
+	if err := UpdateSSHHostInFile("a", *host, path); err != nil {
+		t.Fatalf("UpdateSSHHostInFile() retry error = %v", err)
+	}
+	want := "Host a\n    HostName a\n    User admin\n\nHost b\n    HostName b\n"
+	if got := readTestConfig(t, path); got != want {
+		t.Errorf("unexpected result:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestReadOnlyPathsKeepChangeDetection(t *testing.T) {
+	path := writeTestConfig(t, "Host a\n    HostName a\n")
+	host, err := GetSSHHostFromFile("a", path)
+	if err != nil {
+		t.Fatalf("GetSSHHostFromFile() error = %v", err)
+	}
+
+	// Another program edits the file, then sshm only reads it before saving
+	external := "Host a\n    HostName a\n\nHost b\n    HostName b\n"
+	if err := os.WriteFile(path, []byte(external), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := ResolveEffectiveConfig("a", path); err != nil {
+		t.Fatalf("ResolveEffectiveConfig() error = %v", err)
+	}
+	if _, err := LintConfig(path); err != nil {
+		t.Fatalf("LintConfig() error = %v", err)
+	}
+	if _, err := FormatFiles([]string{path}, FormatOptions{}); err != nil {
+		t.Fatalf("FormatFiles() error = %v", err)
+	}
+	if _, err := HostExistsInSpecificFile("a", path); err != nil {
+		t.Fatalf("HostExistsInSpecificFile() error = %v", err)
+	}
+
+	host.User = "admin"
+	if err := UpdateSSHHostInFile("a", *host, path); !errors.Is(err, ErrConfigChanged) {
+		t.Fatalf("UpdateSSHHostInFile() error = %v, want ErrConfigChanged", err)
+	}
+	if got := readTestConfig(t, path); got != external {
+		t.Errorf("file was overwritten:\n%s", got)
+	}
+}
+
+func TestConfigLockIsExclusive(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	unlock, err := lockConfigFile()
+	if err != nil {
+		t.Fatalf("lockConfigFile() error = %v", err)
+	}
+
+	configDir, _ := GetSSHMConfigDir()
+	other, err := os.OpenFile(filepath.Join(configDir, "sshm.lock"), os.O_RDWR, 0600)
+	if err != nil {
+		t.Fatalf("Failed to open lock file: %v", err)
+	}
+	defer other.Close()
+
+	if err := tryLockFile(other); !errors.Is(err, errLockBusy) {
+		t.Errorf("tryLockFile() while locked error = %v, want errLockBusy", err)
+	}
+
+	unlock()
+	if err := tryLockFile(other); err != nil {
+		t.Errorf("tryLockFile() after unlock error = %v", err)
+	}
+	_ = unlockFile(other)
+}
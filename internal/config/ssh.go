@@ -1,27 +1,43 @@
 package config
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
-	"sync"
+)
+
+// Errors wrapped by the functions looking up and writing hosts, to tell
+// missing and duplicate hosts apart with errors.Is
+var (
+	ErrHostNotFound = errors.New("not found")
+	ErrHostExists   = errors.New("already exists")
 )
 
 // SSHHost represents an SSH host configuration
 type SSHHost struct {
-	Name       string
+	Name       string   // Primary alias: the first concrete pattern on the Host line
+	Aliases    []string // Every concrete alias on the Host line, including Name
+	Patterns   []string // Every pattern on the Host line as written, including negations and wildcards
 	Hostname   string
 	User       string
 	Port       string
-	Identity   string
+	Identity   string // First IdentityFile, the others are listed in Options
 	ProxyJump  string
-	Options    string
+	Options    string // Directives without a dedicated field, one "Key value" per line
 	Tags       []string
-	SourceFile string // Path to the config file where this host is defined
+	Group      string       // Slash-separated path from the "# Group:" comment, e.g. "prod/eu/db"
+	Metadata   HostMetadata // Read from "# sshm:" comments above the Host line
+	SourceFile string       // Path to the config file where this host is defined
+	// System is set for hosts read from the system-wide config, which sshm
+	// shows but never changes
+	System bool
+	// Directives holds every directive of the block in order. The fields above
+	// mirror the first occurrence of their keyword, as ssh uses it. Nil for
+	// hosts that were not read from a config file.
+	Directives []Directive
 }
 
 // GetDefaultSSHConfigPath returns the default SSH config path for the current platform
@@ -103,46 +119,6 @@ func ensureSSHDirectory() error {
 	return nil
 }
 
-// configMutex protects SSH config file operations from race conditions
-var configMutex sync.Mutex
-
-// backupConfig creates a backup of the SSH config file in ~/.config/sshm/backups/
-func backupConfig(configPath string) error {
-	// Get backup directory and ensure it exists
-	backupDir, err := GetSSHMBackupDir()
-	if err != nil {
-		return fmt.Errorf("failed to get backup directory: %w", err)
-	}
-
-	if err := os.MkdirAll(backupDir, 0755); err != nil {
-		return fmt.Errorf("failed to create backup directory: %w", err)
-	}
-
-	// Create simple backup filename (overwrites previous backup)
-	filename := filepath.Base(configPath)
-	backupPath := filepath.Join(backupDir, filename+".backup")
-
-	// Copy file
-	src, err := os.Open(configPath)
-	if err != nil {
-		return err
-	}
-	defer src.Close()
-
-	dst, err := os.Create(backupPath)
-	if err != nil {
-		return err
-	}
-	defer dst.Close()
-
-	if _, err = io.Copy(dst, src); err != nil {
-		return err
-	}
-
-	// Set appropriate permissions
-	return os.Chmod(backupPath, 0600)
-}
-
 // ParseSSHConfig parses the SSH config file and returns the list of hosts
 func ParseSSHConfig() ([]SSHHost, error) {
 	configPath, err := GetDefaultSSHConfigPath()
@@ -157,17 +133,59 @@ func ParseSSHConfigFile(configPath string) ([]SSHHost, error) {
 	return parseSSHConfigFileWithProcessedFiles(configPath, make(map[string]bool))
 }
 
+// ParsedConfig holds everything read from a config file and its includes
+type ParsedConfig struct {
+	Hosts       []SSHHost
+	Patterns    []SSHHost // Host blocks made only of wildcards and negations, such as "Host *"
+	MatchBlocks []MatchBlock
+	Diagnostics []Diagnostic // Problems met while reading, such as unreadable includes
+
+	peek      bool     // Don't record the files as read, see findHostForUpdate
+	system    bool     // Reading the system-wide config, see ParseConfigLayers
+	including []string // Files being parsed, outermost first, to detect include cycles
+}
+
+// ParseConfigTree parses a config file and its includes, returning hosts,
+// pattern blocks and Match blocks. An empty path selects the default SSH config file.
+func ParseConfigTree(configPath string) (*ParsedConfig, error) {
+	if configPath == "" {
+		var err error
+		configPath, err = GetDefaultSSHConfigPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	parsed := &ParsedConfig{}
+	if err := parsed.parseFile(configPath, make(map[string]bool)); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
 // parseSSHConfigFileWithProcessedFiles parses SSH config with include support
 func parseSSHConfigFileWithProcessedFiles(configPath string, processedFiles map[string]bool) ([]SSHHost, error) {
+	parsed := &ParsedConfig{}
+	if err := parsed.parseFile(configPath, processedFiles); err != nil {
+		return nil, err
+	}
+	if parsed.Hosts == nil {
+		return []SSHHost{}, nil
+	}
+	return parsed.Hosts, nil
+}
+
+// parseFile parses a single config file into p, following its includes
+func (p *ParsedConfig) parseFile(configPath string, processedFiles map[string]bool) error {
 	// Resolve absolute path to prevent infinite recursion
 	absPath, err := filepath.Abs(configPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve absolute path for %s: %w", configPath, err)
+		return fmt.Errorf("failed to resolve absolute path for %s: %w", configPath, err)
 	}
 
 	// Check for circular includes
 	if processedFiles[absPath] {
-		return []SSHHost{}, nil // Skip already processed files silently
+		return nil // Skip already processed files silently
 	}
 	processedFiles[absPath] = true
 
@@ -177,146 +195,202 @@ func parseSSHConfigFileWithProcessedFiles(configPath string, processedFiles map[
 		if absPath == getMainConfigPath() {
 			// Ensure .ssh directory exists with proper permissions
 			if err := ensureSSHDirectory(); err != nil {
-				return nil, fmt.Errorf("failed to create .ssh directory: %w", err)
+				return fmt.Errorf("failed to create .ssh directory: %w", err)
 			}
 
 			file, err := os.OpenFile(configPath, os.O_CREATE|os.O_WRONLY, 0600)
 			if err != nil {
-				return nil, fmt.Errorf("failed to create SSH config file: %w", err)
+				return fmt.Errorf("failed to create SSH config file: %w", err)
 			}
 			file.Close()
 
 			// Set secure permissions on the config file
 			if err := SetSecureFilePermissions(configPath); err != nil {
-				return nil, fmt.Errorf("failed to set secure permissions: %w", err)
+				return fmt.Errorf("failed to set secure permissions: %w", err)
 			}
 		}
 
-		// File doesn't exist, return empty host list
-		return []SSHHost{}, nil
+		// File doesn't exist, nothing to add
+		return nil
 	}
 
-	file, err := os.Open(configPath)
+	cfg, err := readConfigFile(configPath, !p.peek)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer file.Close()
-
-	var hosts []SSHHost
-	var currentHost *SSHHost
-	var pendingTags []string
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		// Ignore empty lines
-		if line == "" {
-			continue
-		}
-
-		// Check for tags comment
-		if strings.HasPrefix(line, "# Tags:") {
-			tagsStr := strings.TrimPrefix(line, "# Tags:")
-			tagsStr = strings.TrimSpace(tagsStr)
-			if tagsStr != "" {
-				// Split tags by comma and trim whitespace
-				for _, tag := range strings.Split(tagsStr, ",") {
-					tag = strings.TrimSpace(tag)
-					if tag != "" {
-						pendingTags = append(pendingTags, tag)
-					}
-				}
+	if cfg.bom {
+		p.addDiagnostic(SeverityWarning, absPath, 1, "file starts with a UTF-8 byte order mark, which ssh rejects; 'sshm lint --fix' removes it")
+	}
+
+	p.including = append(p.including, absPath)
+	defer func() { p.including = p.including[:len(p.including)-1] }()
+
+	for _, block := range cfg.Blocks {
+		// Index of the host collecting directives from this block, -1 when the
+		// block is the global section, a Match block or a wildcard pattern
+		current := -1
+		// Index of the pattern block collecting directives, -1 otherwise
+		currentPattern := -1
+
+		if block.IsHost() {
+			patterns := block.Patterns()
+			aliases := ConcreteAliases(patterns)
+			if len(aliases) > 0 {
+				p.Hosts = append(p.Hosts, SSHHost{
+					Name:       aliases[0],
+					Aliases:    aliases,
+					Patterns:   patterns,
+					Port:       "22",             // Default port
+					Tags:       block.Tags(),     // Tags declared above the Host line
+					Group:      block.Group(),    // Group declared above the Host line
+					Metadata:   block.Metadata(), // Metadata declared above the Host line
+					SourceFile: absPath,          // Track which file this host comes from
+					System:     p.system,
+				})
+				current = len(p.Hosts) - 1
+			} else if len(patterns) > 0 {
+				// Blocks made only of wildcards and negations hold defaults shared by many hosts
+				p.Patterns = append(p.Patterns, SSHHost{
+					Name:       strings.Join(patterns, " "),
+					Patterns:   patterns,
+					Tags:       block.Tags(),
+					Group:      block.Group(),
+					Metadata:   block.Metadata(),
+					SourceFile: absPath,
+					System:     p.system,
+				})
+				currentPattern = len(p.Patterns) - 1
 			}
-			continue
-		}
-
-		// Ignore other comments
-		if strings.HasPrefix(line, "#") {
-			continue
 		}
 
-		// Split line into words
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
-			continue
+		// Index of the Match block collecting directives, -1 otherwise
+		currentMatch := -1
+		if block.IsMatch() {
+			p.MatchBlocks = append(p.MatchBlocks, MatchBlock{
+				Condition:  block.Header.Value,
+				Criteria:   ParseMatchCriteria(block.Header.Value),
+				SourceFile: absPath,
+				Line:       block.Header.Number,
+			})
+			currentMatch = len(p.MatchBlocks) - 1
 		}
 
-		key := strings.ToLower(parts[0])
-		value := strings.Join(parts[1:], " ")
+		for _, line := range block.Directives() {
+			key := strings.ToLower(line.Key)
+			value := line.Value
+			if value == "" {
+				continue
+			}
 
-		switch key {
-		case "include":
-			// Handle Include directive
-			includeHosts, err := processIncludeDirective(value, configPath, processedFiles)
-			if err != nil {
-				// Don't fail the entire parse if include fails, just skip it
+			if key == "include" {
+				// An Include line may name several files or patterns. Don't
+				// fail the entire parse if one fails, report it and go on.
+				patterns, err := SplitArgs(value)
+				if err != nil {
+					p.addDiagnostic(SeverityError, absPath, line.Number, "invalid Include: %v", err)
+				}
+				for _, pattern := range patterns {
+					p.processIncludeDirective(pattern, absPath, line.Number, processedFiles)
+				}
 				continue
 			}
-			hosts = append(hosts, includeHosts...)
-		case "host":
-			// New host, save previous one if it exists
-			if currentHost != nil {
-				hosts = append(hosts, *currentHost)
+
+			if currentMatch >= 0 {
+				match := &p.MatchBlocks[currentMatch]
+				match.Directives = append(match.Directives, Directive{Key: line.Key, Value: value, Line: line.Number})
 			}
-			// Skip hosts with wildcards (*, ?) as they are typically patterns, not actual hosts
-			if strings.ContainsAny(value, "*?") {
-				currentHost = nil
-				pendingTags = nil
-				continue
+			directive := Directive{Key: line.Key, Value: value, Line: line.Number}
+			if current >= 0 {
+				applyDirectiveToHost(&p.Hosts[current], directive)
 			}
-			// Create new host
-			currentHost = &SSHHost{
-				Name:       value,
-				Port:       "22",        // Default port
-				Tags:       pendingTags, // Assign pending tags to this host
-				SourceFile: absPath,     // Track which file this host comes from
+			if currentPattern >= 0 {
+				applyDirectiveToHost(&p.Patterns[currentPattern], directive)
 			}
-			// Clear pending tags for next host
-			pendingTags = nil
+		}
+	}
+
+	return nil
+}
+
+// applyDirectiveToHost adds a directive from a Host block to a host. Like
+// ssh, dedicated fields take the first value given for their keyword.
+func applyDirectiveToHost(host *SSHHost, directive Directive) {
+	first := len(host.GetAll(directive.Key)) == 0
+	host.Directives = append(host.Directives, directive)
+
+	if first {
+		switch strings.ToLower(directive.Key) {
 		case "hostname":
-			if currentHost != nil {
-				currentHost.Hostname = value
-			}
+			host.Hostname = directive.Value
+			return
 		case "user":
-			if currentHost != nil {
-				currentHost.User = value
-			}
+			host.User = directive.Value
+			return
 		case "port":
-			if currentHost != nil {
-				currentHost.Port = value
-			}
+			host.Port = directive.Value
+			return
 		case "identityfile":
-			if currentHost != nil {
-				currentHost.Identity = value
-			}
+			host.Identity = directive.Value
+			return
 		case "proxyjump":
-			if currentHost != nil {
-				currentHost.ProxyJump = value
-			}
-		default:
-			// Handle other SSH options
-			if currentHost != nil && strings.TrimSpace(line) != "" {
-				// Store options in config format (key value), not command format
-				if currentHost.Options == "" {
-					currentHost.Options = parts[0] + " " + value
-				} else {
-					currentHost.Options += "\n" + parts[0] + " " + value
-				}
-			}
+			host.ProxyJump = directive.Value
+			return
 		}
 	}
 
-	// Add the last host if it exists
-	if currentHost != nil {
-		hosts = append(hosts, *currentHost)
+	// Store options in config format (key value), not command format
+	option := directive.Key + " " + directive.Value
+	if host.Options == "" {
+		host.Options = option
+	} else {
+		host.Options += "\n" + option
+	}
+}
+
+// processIncludeDirective processes an Include directive and parses the
+// included files into p. Files that can't be read, bad patterns and include
+// cycles are skipped and reported as diagnostics.
+func (p *ParsedConfig) processIncludeDirective(pattern string, baseConfigPath string, line int, processedFiles map[string]bool) {
+	matches, err := resolveIncludePattern(pattern, baseConfigPath)
+	if err != nil {
+		p.addDiagnostic(SeverityError, baseConfigPath, line, "invalid Include pattern '%s': %v", pattern, err)
+		return
+	}
+
+	for _, match := range matches {
+		absMatch, err := filepath.Abs(match)
+		if err == nil {
+			if cycle := p.includeCycle(absMatch); cycle != nil {
+				p.addDiagnostic(SeverityWarning, baseConfigPath, line, "include cycle skipped: %s", strings.Join(cycle, " -> "))
+				continue
+			}
+		}
+
+		if _, err := os.Stat(match); err != nil {
+			p.addDiagnostic(SeverityError, baseConfigPath, line, "included file %s skipped: %v", match, err)
+			continue
+		}
+
+		// Recursively parse the included file
+		if err := p.parseFile(match, processedFiles); err != nil {
+			p.addDiagnostic(SeverityError, baseConfigPath, line, "included file %s skipped: %v", match, err)
+		}
 	}
+}
 
-	return hosts, scanner.Err()
+// includeCycle returns the chain of includes leading back to a file that is
+// being parsed, or nil if including the file doesn't create a cycle
+func (p *ParsedConfig) includeCycle(absPath string) []string {
+	for i, file := range p.including {
+		if file == absPath {
+			return append(append([]string(nil), p.including[i:]...), absPath)
+		}
+	}
+	return nil
 }
 
-// processIncludeDirective processes an Include directive and returns hosts from included files
-func processIncludeDirective(pattern string, baseConfigPath string, processedFiles map[string]bool) ([]SSHHost, error) {
+// resolveIncludePattern expands an Include pattern into the config files it names
+func resolveIncludePattern(pattern string, baseConfigPath string) ([]string, error) {
 	// Expand tilde to home directory
 	if strings.HasPrefix(pattern, "~") {
 		homeDir, err := os.UserHomeDir()
@@ -338,7 +412,7 @@ func processIncludeDirective(pattern string, baseConfigPath string, processedFil
 		return nil, fmt.Errorf("failed to glob pattern %s: %w", pattern, err)
 	}
 
-	var allHosts []SSHHost
+	var files []string
 	for _, match := range matches {
 		// Skip directories
 		if info, err := os.Stat(match); err == nil && info.IsDir() {
@@ -355,16 +429,10 @@ func processIncludeDirective(pattern string, baseConfigPath string, processedFil
 			continue
 		}
 
-		// Recursively parse the included file
-		hosts, err := parseSSHConfigFileWithProcessedFiles(match, processedFiles)
-		if err != nil {
-			// Skip files that can't be parsed rather than failing completely
-			continue
-		}
-		allHosts = append(allHosts, hosts...)
+		files = append(files, match)
 	}
 
-	return allHosts, nil
+	return files, nil
 }
 
 // getMainConfigPath returns the main SSH config path for comparison
@@ -385,101 +453,219 @@ func AddSSHHost(host SSHHost) error {
 
 // AddSSHHostToFile adds a new SSH host to a specific config file
 func AddSSHHostToFile(host SSHHost, configPath string) error {
-	configMutex.Lock()
-	defer configMutex.Unlock()
-
-	// Create backup before modification if file exists
-	if _, err := os.Stat(configPath); err == nil {
-		if err := backupConfig(configPath); err != nil {
-			return fmt.Errorf("failed to create backup: %w", err)
+	return modifyConfigFile(configPath, func(cfg *ConfigFile) error {
+		// Check if host already exists in the specified config file
+		if cfg.FindHostBlock(host.Name) != nil {
+			return fmt.Errorf("host '%s' %w", host.Name, ErrHostExists)
 		}
-	}
 
-	// Check if host already exists in the specified config file
-	exists, err := HostExistsInFile(host.Name, configPath)
-	if err != nil {
-		return err
+		cfg.AppendBlock(newHostBlock(host))
+		return nil
+	})
+}
+
+// newHostBlock builds a Host block for a new host in the format written by sshm
+func newHostBlock(host SSHHost) *ConfigBlock {
+	value := host.Name
+	if len(host.Patterns) > 0 {
+		value = strings.Join(host.Patterns, " ")
 	}
-	if exists {
-		return fmt.Errorf("host '%s' already exists", host.Name)
+	block := &ConfigBlock{
+		Header: newDirectiveLine("", "Host", value),
 	}
 
-	// Open file in append mode
-	file, err := os.OpenFile(configPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-	if err != nil {
-		return err
+	for _, entry := range host.Metadata.Entries() {
+		block.Leading = append(block.Leading, newCommentLine("", formatMetadataComment(entry)))
 	}
-	defer file.Close()
-
-	// Write the configuration
-	_, err = file.WriteString("\n")
-	if err != nil {
-		return err
+	if group := NormalizeGroupPath(host.Group); group != "" {
+		block.Leading = append(block.Leading, newCommentLine("", formatGroupComment(group)))
 	}
-
-	// Write tags if present
 	if len(host.Tags) > 0 {
-		_, err = file.WriteString("# Tags: " + strings.Join(host.Tags, ", ") + "\n")
-		if err != nil {
-			return err
+		block.Leading = append(block.Leading, newCommentLine("", formatTagsComment(host.Tags)))
+	}
+
+	applyHostToBlock(block, host)
+	return block
+}
+
+// hostFields lists the directives mapped to dedicated SSHHost fields, in the
+// order sshm writes them
+var hostFields = []struct {
+	key   string
+	value func(SSHHost) string
+}{
+	{"HostName", func(h SSHHost) string { return h.Hostname }},
+	{"User", func(h SSHHost) string { return h.User }},
+	{"Port", func(h SSHHost) string { return h.Port }},
+	{"IdentityFile", func(h SSHHost) string { return h.Identity }},
+	{"ProxyJump", func(h SSHHost) string { return h.ProxyJump }},
+}
+
+// isHostFieldKey reports whether a keyword is mapped to a dedicated SSHHost field
+func isHostFieldKey(key string) bool {
+	for _, field := range hostFields {
+		if strings.EqualFold(field.key, key) {
+			return true
 		}
 	}
+	return false
+}
 
-	// Write host configuration
-	_, err = file.WriteString(fmt.Sprintf("Host %s\n", host.Name))
-	if err != nil {
-		return err
+// applyHostToBlock updates the directives of a Host block so that they match
+// the given host. Lines whose value does not change are left untouched, and
+// comments, blank lines and Include directives are kept in place.
+func applyHostToBlock(block *ConfigBlock, host SSHHost) {
+	indent := block.bodyIndent()
+
+	// Wanted values of each keyword in order, with the spelling to write new
+	// lines with
+	wanted := make(map[string][]string)
+	names := make(map[string]string)
+	var order []string
+	for _, d := range host.directivesToWrite() {
+		key := strings.ToLower(d.Key)
+		if _, ok := wanted[key]; !ok {
+			order = append(order, key)
+			names[key] = d.Key
+		}
+		wanted[key] = append(wanted[key], d.Value)
 	}
 
-	_, err = file.WriteString(fmt.Sprintf("    HostName %s\n", host.Hostname))
-	if err != nil {
-		return err
+	// Existing lines of a keyword take its wanted values in order, lines
+	// beyond them are removed
+	used := make(map[string]int)
+	for _, line := range block.Directives() {
+		key := strings.ToLower(line.Key)
+		if key == "include" {
+			continue
+		}
+		if used[key] >= len(wanted[key]) {
+			block.removeBody(line)
+			continue
+		}
+		line.SetValue(wanted[key][used[key]])
+		used[key]++
 	}
 
-	if host.User != "" {
-		_, err = file.WriteString(fmt.Sprintf("    User %s\n", host.User))
-		if err != nil {
-			return err
+	for _, key := range order {
+		values := wanted[key][used[key]:]
+		if key == "port" && used[key] == 0 && len(values) == 1 && values[0] == "22" {
+			// Port 22 is the default and is only written when already present
+			continue
+		}
+		for _, value := range values {
+			block.insertBody(directiveInsertIndex(block, key), newDirectiveLine(indent, names[key], value))
 		}
 	}
+}
 
-	if host.Port != "" && host.Port != "22" {
-		_, err = file.WriteString(fmt.Sprintf("    Port %s\n", host.Port))
-		if err != nil {
-			return err
+// directiveInsertIndex returns where a new directive goes: after the last line
+// of the same keyword, after the last field directive for keywords mapped to a
+// field, or at the end of the block
+func directiveInsertIndex(block *ConfigBlock, key string) int {
+	for i := len(block.Body) - 1; i >= 0; i-- {
+		line := block.Body[i]
+		if line.Kind == LineDirective && strings.EqualFold(line.Key, key) {
+			return i + 1
 		}
 	}
+	if isHostFieldKey(key) {
+		return fieldInsertIndex(block)
+	}
+	return block.lastDirectiveIndex() + 1
+}
 
-	if host.Identity != "" {
-		_, err = file.WriteString(fmt.Sprintf("    IdentityFile %s\n", host.Identity))
-		if err != nil {
-			return err
+// fieldInsertIndex returns where a new field directive goes: after the last
+// field directive, or before the first other directive
+func fieldInsertIndex(block *ConfigBlock) int {
+	pos := -1
+	for i, line := range block.Body {
+		if line.Kind != LineDirective {
+			continue
 		}
+		if isHostFieldKey(line.Key) {
+			pos = i + 1
+		} else if pos < 0 {
+			pos = i
+		}
+	}
+	if pos < 0 {
+		return block.lastDirectiveIndex() + 1
 	}
+	return pos
+}
 
-	if host.ProxyJump != "" {
-		_, err = file.WriteString(fmt.Sprintf("    ProxyJump %s\n", host.ProxyJump))
-		if err != nil {
-			return err
+// applyTagsToBlock updates the "# Tags:" comment above a Host block
+func applyTagsToBlock(block *ConfigBlock, tags []string) {
+	var tagLine *ConfigLine
+	for i := 0; i < len(block.Leading); i++ {
+		line := block.Leading[i]
+		if _, ok := parseTagsComment(line.Comment); !ok {
+			continue
+		}
+		if tagLine != nil || len(tags) == 0 {
+			// Remove duplicate tag lines, or all of them when tags were cleared
+			block.Leading = append(block.Leading[:i], block.Leading[i+1:]...)
+			i--
+			continue
 		}
+		tagLine = line
 	}
 
-	// Write SSH options
-	if host.Options != "" {
-		// Split options by newlines and write each one
-		options := strings.Split(host.Options, "\n")
-		for _, option := range options {
-			option = strings.TrimSpace(option)
-			if option != "" {
-				_, err = file.WriteString(fmt.Sprintf("    %s\n", option))
-				if err != nil {
-					return err
-				}
-			}
+	if len(tags) == 0 {
+		return
+	}
+
+	if tagLine == nil {
+		block.Leading = append(block.Leading, newCommentLine(block.Header.Indent, formatTagsComment(tags)))
+		return
+	}
+
+	if current := block.Tags(); strings.Join(current, ",") != strings.Join(tags, ",") {
+		tagLine.SetComment(formatTagsComment(tags))
+	}
+}
+
+// applyGroupToBlock updates the "# Group:" comment above a Host block. A new
+// comment is written above the "# Tags:" comment.
+func applyGroupToBlock(block *ConfigBlock, group string) {
+	group = NormalizeGroupPath(group)
+	if block.Group() == group {
+		return
+	}
+
+	var groupLine *ConfigLine
+	for i := 0; i < len(block.Leading); i++ {
+		line := block.Leading[i]
+		if _, ok := parseGroupComment(line.Comment); !ok {
+			continue
 		}
+		if groupLine != nil || group == "" {
+			// Remove duplicate group lines, or all of them when the group was cleared
+			block.Leading = append(block.Leading[:i], block.Leading[i+1:]...)
+			i--
+			continue
+		}
+		groupLine = line
 	}
 
-	return nil
+	if group == "" {
+		return
+	}
+	if groupLine != nil {
+		groupLine.SetComment(formatGroupComment(group))
+		return
+	}
+
+	pos := len(block.Leading)
+	for i, line := range block.Leading {
+		if _, ok := parseTagsComment(line.Comment); ok {
+			pos = i
+			break
+		}
+	}
+	line := newCommentLine(block.Header.Indent, formatGroupComment(group))
+	block.Leading = append(block.Leading[:pos], append([]*ConfigLine{line}, block.Leading[pos:]...)...)
 }
 
 // ParseSSHOptionsFromCommand converts SSH command line options to config format
@@ -544,7 +730,7 @@ func HostExists(hostName string) (bool, error) {
 	}
 
 	for _, host := range hosts {
-		if host.Name == hostName {
+		if host.HasAlias(hostName) {
 			return true, nil
 		}
 	}
@@ -559,34 +745,12 @@ func HostExistsInFile(hostName string, configPath string) (bool, error) {
 
 // HostExistsInSpecificFile checks if a host exists in a specific file only (no includes)
 func HostExistsInSpecificFile(hostName string, configPath string) (bool, error) {
-	file, err := os.Open(configPath)
+	cfg, err := readConfigFile(configPath, false)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
 		return false, err
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		// Check for Host declaration
-		if strings.HasPrefix(strings.ToLower(line), "host ") {
-			// Extract host names (can be multiple hosts on one line)
-			hostPart := strings.TrimSpace(line[5:]) // Remove "host "
-			hostNames := strings.Fields(hostPart)
-
-			for _, name := range hostNames {
-				if name == hostName {
-					return true, nil
-				}
-			}
-		}
-	}
-
-	return false, scanner.Err()
+	return cfg.FindHostBlock(hostName) != nil, nil
 }
 
 // GetSSHHost retrieves a specific host configuration by name
@@ -597,11 +761,11 @@ func GetSSHHost(hostName string) (*SSHHost, error) {
 	}
 
 	for _, host := range hosts {
-		if host.Name == hostName {
+		if host.HasAlias(hostName) {
 			return &host, nil
 		}
 	}
-	return nil, fmt.Errorf("host '%s' not found", hostName)
+	return nil, fmt.Errorf("host '%s' %w", hostName, ErrHostNotFound)
 }
 
 // GetSSHHostFromFile retrieves a specific host configuration by name from a specific config file
@@ -612,11 +776,11 @@ func GetSSHHostFromFile(hostName string, configPath string) (*SSHHost, error) {
 	}
 
 	for _, host := range hosts {
-		if host.Name == hostName {
+		if host.HasAlias(hostName) {
 			return &host, nil
 		}
 	}
-	return nil, fmt.Errorf("host '%s' not found", hostName)
+	return nil, fmt.Errorf("host '%s' %w", hostName, ErrHostNotFound)
 }
 
 // UpdateSSHHost updates an existing SSH host configuration
@@ -626,152 +790,57 @@ func UpdateSSHHost(oldName string, newHost SSHHost) error {
 
 // UpdateSSHHostInFile updates an existing SSH host configuration in a specific file
 func UpdateSSHHostInFile(oldName string, newHost SSHHost, configPath string) error {
-	configMutex.Lock()
-	defer configMutex.Unlock()
-
-	// Create backup before modification
-	if err := backupConfig(configPath); err != nil {
-		return fmt.Errorf("failed to create backup: %w", err)
-	}
-
-	// Read the current config
-	content, err := os.ReadFile(configPath)
-	if err != nil {
-		return err
-	}
-
-	lines := strings.Split(string(content), "\n")
-	var newLines []string
-	i := 0
-	hostFound := false
-
-	for i < len(lines) {
-		line := strings.TrimSpace(lines[i])
-
-		// Check for tags comment followed by Host
-		if strings.HasPrefix(line, "# Tags:") && i+1 < len(lines) {
-			nextLine := strings.TrimSpace(lines[i+1])
-			if nextLine == "Host "+oldName {
-				// Found the host to update, skip the old configuration
-				hostFound = true
-
-				// Skip until we find the end of this host block (empty line or next Host)
-				i += 2 // Skip tags and Host line
-				for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-					i++
-				}
-
-				// Skip any trailing empty lines after the host block
-				for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-					i++
-				}
-
-				// Insert new configuration at this position
-				// Add empty line only if the previous line is not empty
-				if len(newLines) > 0 && strings.TrimSpace(newLines[len(newLines)-1]) != "" {
-					newLines = append(newLines, "")
-				}
-				if len(newHost.Tags) > 0 {
-					newLines = append(newLines, "# Tags: "+strings.Join(newHost.Tags, ", "))
-				}
-				newLines = append(newLines, "Host "+newHost.Name)
-				newLines = append(newLines, "    HostName "+newHost.Hostname)
-				if newHost.User != "" {
-					newLines = append(newLines, "    User "+newHost.User)
-				}
-				if newHost.Port != "" && newHost.Port != "22" {
-					newLines = append(newLines, "    Port "+newHost.Port)
-				}
-				if newHost.Identity != "" {
-					newLines = append(newLines, "    IdentityFile "+newHost.Identity)
-				}
-				if newHost.ProxyJump != "" {
-					newLines = append(newLines, "    ProxyJump "+newHost.ProxyJump)
-				}
-				// Write SSH options
-				if newHost.Options != "" {
-					options := strings.Split(newHost.Options, "\n")
-					for _, option := range options {
-						option = strings.TrimSpace(option)
-						if option != "" {
-							newLines = append(newLines, "    "+option)
-						}
-					}
-				}
-
-				// Add empty line after the host configuration for separation
-				newLines = append(newLines, "")
-
-				continue
-			}
+	return modifyConfigFile(configPath, func(cfg *ConfigFile) error {
+		block := cfg.FindHostBlock(oldName)
+		if block == nil {
+			return fmt.Errorf("host '%s' %w", oldName, ErrHostNotFound)
 		}
 
-		// Check for Host line without tags
-		if strings.HasPrefix(line, "Host ") && strings.Fields(line)[1] == oldName {
-			hostFound = true
-
-			// Skip until we find the end of this host block
-			i++ // Skip Host line
-			for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-				i++
-			}
-
-			// Skip any trailing empty lines after the host block
-			for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-				i++
-			}
+		updateHostBlock(block, oldName, newHost)
+		return nil
+	})
+}
 
-			// Insert new configuration
-			// Add empty line only if the previous line is not empty
-			if len(newLines) > 0 && strings.TrimSpace(newLines[len(newLines)-1]) != "" {
-				newLines = append(newLines, "")
-			}
-			if len(newHost.Tags) > 0 {
-				newLines = append(newLines, "# Tags: "+strings.Join(newHost.Tags, ", "))
-			}
-			newLines = append(newLines, "Host "+newHost.Name)
-			newLines = append(newLines, "    HostName "+newHost.Hostname)
-			if newHost.User != "" {
-				newLines = append(newLines, "    User "+newHost.User)
-			}
-			if newHost.Port != "" && newHost.Port != "22" {
-				newLines = append(newLines, "    Port "+newHost.Port)
-			}
-			if newHost.Identity != "" {
-				newLines = append(newLines, "    IdentityFile "+newHost.Identity)
-			}
-			if newHost.ProxyJump != "" {
-				newLines = append(newLines, "    ProxyJump "+newHost.ProxyJump)
-			}
-			// Write SSH options
-			if newHost.Options != "" {
-				options := strings.Split(newHost.Options, "\n")
-				for _, option := range options {
-					option = strings.TrimSpace(option)
-					if option != "" {
-						newLines = append(newLines, "    "+option)
-					}
-				}
-			}
+// updateHostBlock applies the name, metadata and directives of a host to its
+// block
+func updateHostBlock(block *ConfigBlock, oldName string, newHost SSHHost) {
+	renameHostBlock(block, oldName, newHost.Name)
+	applyMetadataToBlock(block, newHost.Metadata)
+	applyGroupToBlock(block, newHost.Group)
+	applyTagsToBlock(block, newHost.Tags)
+	applyHostToBlock(block, newHost)
+}
 
-			// Add empty line after the host configuration for separation
-			newLines = append(newLines, "")
+// renameHostBlock replaces a name on a Host line, keeping the other patterns
+// and the way they are separated
+func renameHostBlock(block *ConfigBlock, oldName, newName string) {
+	if oldName == newName {
+		return
+	}
+	block.Header.SetValue(replaceHostPattern(block.Header.Value, oldName, newName))
+}
 
+// replaceHostPattern replaces every occurrence of a pattern in a Host value
+func replaceHostPattern(value, oldPattern, newPattern string) string {
+	var b strings.Builder
+	for len(value) > 0 {
+		end := strings.IndexAny(value, " \t,")
+		if end < 0 {
+			end = len(value)
+		}
+		if end == 0 {
+			b.WriteByte(value[0])
+			value = value[1:]
 			continue
 		}
-
-		// Keep other lines as-is
-		newLines = append(newLines, lines[i])
-		i++
-	}
-
-	if !hostFound {
-		return fmt.Errorf("host '%s' not found", oldName)
+		if value[:end] == oldPattern {
+			b.WriteString(newPattern)
+		} else {
+			b.WriteString(value[:end])
+		}
+		value = value[end:]
 	}
-
-	// Write back to file
-	newContent := strings.Join(newLines, "\n")
-	return os.WriteFile(configPath, []byte(newContent), 0600)
+	return b.String()
 }
 
 // DeleteSSHHost removes an SSH host configuration from the config file
@@ -781,84 +850,15 @@ func DeleteSSHHost(hostName string) error {
 
 // DeleteSSHHostFromFile deletes an SSH host from a specific config file
 func DeleteSSHHostFromFile(hostName, configPath string) error {
-	configMutex.Lock()
-	defer configMutex.Unlock()
-
-	// Create backup before modification
-	if err := backupConfig(configPath); err != nil {
-		return fmt.Errorf("failed to create backup: %w", err)
-	}
-
-	// Read the current config
-	content, err := os.ReadFile(configPath)
-	if err != nil {
-		return err
-	}
-
-	lines := strings.Split(string(content), "\n")
-	var newLines []string
-	i := 0
-	hostFound := false
-
-	for i < len(lines) {
-		line := strings.TrimSpace(lines[i])
-
-		// Check for tags comment followed by Host
-		if strings.HasPrefix(line, "# Tags:") && i+1 < len(lines) {
-			nextLine := strings.TrimSpace(lines[i+1])
-			if nextLine == "Host "+hostName {
-				// Found the host to delete, skip the configuration
-				hostFound = true
-
-				// Skip tags comment and Host line
-				i += 2
-
-				// Skip until we find the end of this host block (empty line or next Host)
-				for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-					i++
-				}
-
-				// Skip any trailing empty lines after the host block
-				for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-					i++
-				}
-
-				continue
-			}
+	return modifyConfigFile(configPath, func(cfg *ConfigFile) error {
+		block := cfg.FindHostBlock(hostName)
+		if block == nil {
+			return fmt.Errorf("host '%s' %w", hostName, ErrHostNotFound)
 		}
 
-		// Check for Host line without tags
-		if strings.HasPrefix(line, "Host ") && strings.Fields(line)[1] == hostName {
-			hostFound = true
-
-			// Skip Host line
-			i++
-
-			// Skip until we find the end of this host block
-			for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !strings.HasPrefix(strings.TrimSpace(lines[i]), "Host ") {
-				i++
-			}
-
-			// Skip any trailing empty lines after the host block
-			for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
-				i++
-			}
-
-			continue
-		}
-
-		// Keep other lines as-is
-		newLines = append(newLines, lines[i])
-		i++
-	}
-
-	if !hostFound {
-		return fmt.Errorf("host '%s' not found", hostName)
-	}
-
-	// Write back to file
-	newContent := strings.Join(newLines, "\n")
-	return os.WriteFile(configPath, []byte(newContent), 0600)
+		cfg.RemoveBlock(block)
+		return nil
+	})
 }
 
 // FindHostInAllConfigs finds a host in all configuration files and returns the host with its source file
@@ -867,14 +867,34 @@ func FindHostInAllConfigs(hostName string) (*SSHHost, error) {
 	if err != nil {
 		return nil, err
 	}
+	return findHostByAlias(hosts, hostName)
+}
+
+// findHostForUpdate locates the file of a host before changing it. Unlike
+// FindHostInAllConfigs it does not count as reading the files, so changes
+// made on disk since the caller last read them are still detected.
+func findHostForUpdate(hostName string) (*SSHHost, error) {
+	configPath, err := GetDefaultSSHConfigPath()
+	if err != nil {
+		return nil, err
+	}
 
+	parsed := &ParsedConfig{peek: true}
+	if err := parsed.parseFile(configPath, make(map[string]bool)); err != nil {
+		return nil, err
+	}
+	return findHostByAlias(parsed.Hosts, hostName)
+}
+
+// findHostByAlias returns the host reachable under the given name
+func findHostByAlias(hosts []SSHHost, hostName string) (*SSHHost, error) {
 	for _, host := range hosts {
-		if host.Name == hostName {
+		if host.HasAlias(hostName) {
 			return &host, nil
 		}
 	}
 
-	return nil, fmt.Errorf("host '%s' not found in any configuration file", hostName)
+	return nil, fmt.Errorf("host '%s' %w in any configuration file", hostName, ErrHostNotFound)
 }
 
 // GetAllConfigFiles returns all SSH config files (main + included files)
@@ -883,16 +903,7 @@ func GetAllConfigFiles() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	processedFiles := make(map[string]bool)
-	_, _ = parseSSHConfigFileWithProcessedFiles(configPath, processedFiles)
-
-	files := make([]string, 0, len(processedFiles))
-	for file := range processedFiles {
-		files = append(files, file)
-	}
-
-	return files, nil
+	return listConfigFiles(configPath), nil
 }
 
 // GetAllConfigFilesFromBase returns all SSH config files starting from a specific base config file
@@ -901,20 +912,29 @@ func GetAllConfigFilesFromBase(baseConfigPath string) ([]string, error) {
 		// Fallback to default behavior
 		return GetAllConfigFiles()
 	}
+	return listConfigFiles(baseConfigPath), nil
+}
 
+// listConfigFiles returns a config file and the files it includes. Like
+// findHostForUpdate it does not count as reading the files, so listing them,
+// as the TUI does when it polls for changes, leaves the detection of changes
+// made on disk intact.
+func listConfigFiles(configPath string) []string {
 	processedFiles := make(map[string]bool)
-	_, _ = parseSSHConfigFileWithProcessedFiles(baseConfigPath, processedFiles)
+	parsed := &ParsedConfig{peek: true}
+	_ = parsed.parseFile(configPath, processedFiles)
 
 	files := make([]string, 0, len(processedFiles))
 	for file := range processedFiles {
 		files = append(files, file)
 	}
+	return files
+}
 
-	return files, nil
-} // UpdateSSHHostV2 updates an existing SSH host configuration, searching in all config files
+// UpdateSSHHostV2 updates an existing SSH host configuration, searching in all config files
 func UpdateSSHHostV2(oldName string, newHost SSHHost) error {
 	// Find the host to determine which file it's in
-	existingHost, err := FindHostInAllConfigs(oldName)
+	existingHost, err := findHostForUpdate(oldName)
 	if err != nil {
 		return err
 	}
@@ -927,7 +947,7 @@ func UpdateSSHHostV2(oldName string, newHost SSHHost) error {
 // DeleteSSHHostV2 removes an SSH host configuration, searching in all config files
 func DeleteSSHHostV2(hostName string) error {
 	// Find the host to determine which file it's in
-	existingHost, err := FindHostInAllConfigs(hostName)
+	existingHost, err := findHostForUpdate(hostName)
 	if err != nil {
 		return err
 	}
@@ -976,7 +996,7 @@ func GetIncludedConfigFiles() ([]string, error) {
 // MoveHostToFile moves an SSH host from its current config file to a target config file
 func MoveHostToFile(hostName string, targetConfigFile string) error {
 	// Find the host in all configs to get its current location and data
-	host, err := FindHostInAllConfigs(hostName)
+	host, err := findHostForUpdate(hostName)
 	if err != nil {
 		return err
 	}
@@ -986,22 +1006,24 @@ func MoveHostToFile(hostName string, targetConfigFile string) error {
 		return fmt.Errorf("host '%s' is already in the target config file '%s'", hostName, targetConfigFile)
 	}
 
-	// First, add the host to the target config file
-	err = AddSSHHostToFile(*host, targetConfigFile)
-	if err != nil {
-		return fmt.Errorf("failed to add host to target file: %v", err)
-	}
+	// Both files are written as one transaction, so that a failure never
+	// leaves the host in both files or in neither
+	return modifyConfigFiles([]string{host.SourceFile, targetConfigFile}, func(files []*ConfigFile) error {
+		source, target := files[0], files[1]
 
-	// Then, remove the host from its current source file
-	err = DeleteSSHHostFromFile(hostName, host.SourceFile)
-	if err != nil {
-		// If removal fails, we should try to rollback the addition, but for simplicity
-		// we'll just return the error. In a production environment, you might want
-		// to implement a proper rollback mechanism.
-		return fmt.Errorf("failed to remove host from source file: %v", err)
-	}
+		block := source.FindHostBlock(hostName)
+		if block == nil {
+			return fmt.Errorf("host '%s' %w in %s", hostName, ErrHostNotFound, host.SourceFile)
+		}
+		if target.FindHostBlock(hostName) != nil {
+			return fmt.Errorf("host '%s' %w in %s", hostName, ErrHostExists, targetConfigFile)
+		}
 
-	return nil
+		// Move the block as-is so that comments and unmapped directives travel with it
+		source.RemoveBlock(block)
+		target.AppendBlock(block)
+		return nil
+	})
 }
 
 // GetConfigFilesExcludingCurrent returns all config files except the one containing the specified host
@@ -500,35 +500,31 @@ func TestBackupConfigToSSHMDirectory(t *testing.T) {
 		t.Errorf("Backup directory was not created: %s", backupDir)
 	}
 
-	// Verify backup file was created
-	files, err := os.ReadDir(backupDir)
+	// Verify a snapshot was recorded for the config file
+	backups, err := ListBackups(configPath)
 	if err != nil {
-		t.Fatalf("Failed to read backup directory: %v", err)
+		t.Fatalf("ListBackups() error = %v", err)
 	}
 
-	if len(files) != 1 {
-		t.Errorf("Expected 1 backup file, got %d", len(files))
+	if len(backups) != 1 {
+		t.Fatalf("Expected 1 backup, got %d", len(backups))
+	}
+	if backups[0].SourceFile != configPath {
+		t.Errorf("Backup source file: got %s, want %s", backups[0].SourceFile, configPath)
+	}
+	if !strings.HasPrefix(backups[0].Path, backupDir) {
+		t.Errorf("Backup %s is not in %s", backups[0].Path, backupDir)
 	}
 
-	if len(files) > 0 {
-		backupFile := files[0]
-		expectedName := "config.backup"
-		if backupFile.Name() != expectedName {
-			t.Errorf("Backup file has unexpected name: got %s, want %s", backupFile.Name(), expectedName)
-		}
-
-		// Verify backup content
-		backupContent, err := os.ReadFile(filepath.Join(backupDir, backupFile.Name()))
-		if err != nil {
-			t.Fatalf("Failed to read backup file: %v", err)
-		}
-
-		if string(backupContent) != configContent {
-			t.Errorf("Backup content doesn't match original")
-		}
+	backupContent, err := os.ReadFile(backups[0].Path)
+	if err != nil {
+		t.Fatalf("Failed to read backup file: %v", err)
+	}
+	if string(backupContent) != configContent {
+		t.Errorf("Backup content doesn't match original")
 	}
 
-	// Test that subsequent backups overwrite the previous one
+	// Test that subsequent backups keep the previous ones
 	newConfigContent := `Host test-host-updated
     HostName updated.example.com
     User updateduser
@@ -545,26 +541,21 @@ func TestBackupConfigToSSHMDirectory(t *testing.T) {
 		t.Fatalf("Second backupConfig() error = %v", err)
 	}
 
-	// Verify still only one backup file exists
-	files, err = os.ReadDir(backupDir)
+	backups, err = ListBackups(configPath)
 	if err != nil {
-		t.Fatalf("Failed to read backup directory after second backup: %v", err)
+		t.Fatalf("ListBackups() error = %v", err)
 	}
-
-	if len(files) != 1 {
-		t.Errorf("Expected still 1 backup file after overwrite, got %d", len(files))
+	if len(backups) != 2 {
+		t.Fatalf("Expected 2 backups after second backup, got %d", len(backups))
 	}
 
-	// Verify backup content was updated
-	if len(files) > 0 {
-		backupContent, err := os.ReadFile(filepath.Join(backupDir, files[0].Name()))
-		if err != nil {
-			t.Fatalf("Failed to read updated backup file: %v", err)
-		}
-
-		if string(backupContent) != newConfigContent {
-			t.Errorf("Updated backup content doesn't match new config content")
-		}
+	// Newest snapshot comes first
+	backupContent, err = os.ReadFile(backups[0].Path)
+	if err != nil {
+		t.Fatalf("Failed to read updated backup file: %v", err)
+	}
+	if string(backupContent) != newConfigContent {
+		t.Errorf("Newest backup content doesn't match new config content")
 	}
 }
 
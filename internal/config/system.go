@@ -0,0 +1,46 @@
+package config
+
+import "fmt"
+
+// IsSystemConfigEnabled reports whether the system-wide SSH config is read as
+// a read-only layer, as set in the app config. It is enabled when the app
+// config can't be read.
+func IsSystemConfigEnabled() bool {
+	appConfig, err := LoadAppConfig()
+	if err != nil {
+		return GetDefaultSystemConfigSettings().Enabled
+	}
+	return appConfig.SystemConfig.Enabled
+}
+
+// ParseConfigLayers parses a config file and its includes like
+// ParseConfigTree. When no config file is given and the system layer is
+// enabled, the system-wide config and its includes, usually
+// /etc/ssh/ssh_config.d/*.conf, are read after it, as ssh does. Hosts and
+// patterns from the system layer are marked System: sshm shows them but never
+// writes them, as every write goes through the user's config tree.
+func ParseConfigLayers(configPath string) (*ParsedConfig, error) {
+	parsed, err := ParseConfigTree(configPath)
+	if err != nil {
+		return nil, err
+	}
+	if configPath != "" || !IsSystemConfigEnabled() {
+		return parsed, nil
+	}
+
+	parsed.system = true
+	if err := parsed.parseFile(SystemSSHConfigPath, make(map[string]bool)); err != nil {
+		parsed.addDiagnostic(SeverityWarning, SystemSSHConfigPath, 0, "system config skipped: %v", err)
+	}
+	parsed.system = false
+	return parsed, nil
+}
+
+// FindHost returns the first host declaring the given alias
+func (p *ParsedConfig) FindHost(hostName string) (*SSHHost, error) {
+	host, err := findHostByAlias(p.Hosts, hostName)
+	if err != nil {
+		return nil, fmt.Errorf("host '%s' %w", hostName, ErrHostNotFound)
+	}
+	return host, nil
+}
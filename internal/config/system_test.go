@@ -0,0 +1,105 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupSystemConfig writes a user config and a system config in temporary
+// directories and points SystemSSHConfigPath at the latter
+func setupSystemConfig(t *testing.T, user, system string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.MkdirAll(sshDir, 0700); err != nil {
+		t.Fatalf("Failed to create .ssh: %v", err)
+	}
+	userPath := filepath.Join(sshDir, "config")
+	if err := os.WriteFile(userPath, []byte(user), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	systemPath := filepath.Join(t.TempDir(), "ssh_config")
+	if err := os.WriteFile(systemPath, []byte(system), 0644); err != nil {
+		t.Fatalf("Failed to write system config: %v", err)
+	}
+	original := SystemSSHConfigPath
+	SystemSSHConfigPath = systemPath
+	t.Cleanup(func() { SystemSSHConfigPath = original })
+
+	return userPath
+}
+
+func TestParseConfigLayers(t *testing.T) {
+	userPath := setupSystemConfig(t, "Host web\n    HostName web.example.com\n", "Host bastion\n    HostName bastion.corp\n\nHost *\n    SendEnv LANG\n")
+
+	parsed, err := ParseConfigLayers("")
+	if err != nil {
+		t.Fatalf("ParseConfigLayers() error = %v", err)
+	}
+	if len(parsed.Hosts) != 2 || len(parsed.Patterns) != 1 {
+		t.Fatalf("expected hosts from both layers, got %d hosts and %d patterns", len(parsed.Hosts), len(parsed.Patterns))
+	}
+
+	web, err := parsed.FindHost("web")
+	if err != nil {
+		t.Fatalf("FindHost(web) error = %v", err)
+	}
+	if web.System {
+		t.Error("a host of the user config must not be marked System")
+	}
+	bastion, err := parsed.FindHost("bastion")
+	if err != nil {
+		t.Fatalf("FindHost(bastion) error = %v", err)
+	}
+	if !bastion.System || bastion.SourceFile != SystemSSHConfigPath {
+		t.Errorf("expected bastion to come from the system config, got System=%v SourceFile=%s", bastion.System, bastion.SourceFile)
+	}
+	if !parsed.Patterns[0].System {
+		t.Error("expected the system pattern block to be marked System")
+	}
+
+	// Writes only see the user's config tree
+	if err := DeleteSSHHostFromFile("bastion", userPath); err == nil {
+		t.Error("expected deleting a system host to fail")
+	}
+
+	// An explicit config file is read on its own, like "ssh -F"
+	parsed, err = ParseConfigLayers(userPath)
+	if err != nil {
+		t.Fatalf("ParseConfigLayers(path) error = %v", err)
+	}
+	if len(parsed.Hosts) != 1 {
+		t.Errorf("expected only the given file to be read, got %d hosts", len(parsed.Hosts))
+	}
+}
+
+func TestParseConfigLayersDisabled(t *testing.T) {
+	setupSystemConfig(t, "Host web\n", "Host bastion\n    User admin\n")
+
+	appConfig := GetDefaultAppConfig()
+	appConfig.SystemConfig.Enabled = false
+	if err := SaveAppConfig(&appConfig); err != nil {
+		t.Fatalf("SaveAppConfig() error = %v", err)
+	}
+
+	parsed, err := ParseConfigLayers("")
+	if err != nil {
+		t.Fatalf("ParseConfigLayers() error = %v", err)
+	}
+	if len(parsed.Hosts) != 1 || parsed.Hosts[0].Name != "web" {
+		t.Errorf("expected the system layer to be skipped, got %v", parsed.Hosts)
+	}
+
+	cfg, err := ResolveEffectiveConfig("bastion", "")
+	if err != nil {
+		t.Fatalf("ResolveEffectiveConfig() error = %v", err)
+	}
+	if got := cfg.User(); got == "admin" {
+		t.Error("expected effective values to ignore the disabled system layer")
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TagCount is a tag and the number of hosts carrying it
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// CountTags counts the hosts carrying each tag, most used tags first
+func CountTags(hosts []SSHHost) []TagCount {
+	counts := make(map[string]int)
+	for _, host := range hosts {
+		for _, tag := range host.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags
+}
+
+// ValidateTag checks that a tag can be written in a "# Tags:" comment
+func ValidateTag(tag string) error {
+	if strings.TrimSpace(tag) == "" {
+		return fmt.Errorf("tag cannot be empty")
+	}
+	if strings.ContainsAny(tag, ",\r\n") {
+		return fmt.Errorf("tag '%s' cannot contain commas or line breaks", tag)
+	}
+	if strings.TrimSpace(tag) != tag {
+		return fmt.Errorf("tag '%s' cannot start or end with spaces", tag)
+	}
+	return nil
+}
+
+// RenameTags replaces the given tags by another one on every host of the
+// config tree at configPath, or of the default config when it is empty. Hosts
+// carrying several of them are left with a single one, so renaming several
+// tags merges them. It returns the number of hosts changed.
+func RenameTags(configPath string, from []string, to string) (int, error) {
+	if err := ValidateTag(to); err != nil {
+		return 0, err
+	}
+	return rewriteTags(configPath, func(tags []string) []string {
+		var result []string
+		for _, tag := range tags {
+			if containsString(from, tag) {
+				tag = to
+			}
+			if !containsString(result, tag) {
+				result = append(result, tag)
+			}
+		}
+		return result
+	})
+}
+
+// DeleteTags removes the given tags from every host of the config tree at
+// configPath, or of the default config when it is empty. It returns the number
+// of hosts changed.
+func DeleteTags(configPath string, tags []string) (int, error) {
+	return rewriteTags(configPath, func(current []string) []string {
+		return TagsRemove.Apply(current, tags)
+	})
+}
+
+// rewriteTags changes the "# Tags:" comments of every Host block of a config
+// tree. All the files involved are backed up and written as a single change.
+func rewriteTags(configPath string, change func(tags []string) []string) (int, error) {
+	paths, err := ConfigTreeFiles(configPath)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	err = modifyConfigFiles(paths, func(files []*ConfigFile) error {
+		changed = 0
+		for _, file := range files {
+			for _, block := range file.Blocks {
+				if !block.IsHost() {
+					continue
+				}
+				current := block.Tags()
+				tags := change(current)
+				if strings.Join(tags, ",") == strings.Join(current, ",") {
+					continue
+				}
+				applyTagsToBlock(block, tags)
+				changed++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
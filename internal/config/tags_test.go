@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCountTags(t *testing.T) {
+	hosts := []SSHHost{
+		{Name: "a", Tags: []string{"web", "prod"}},
+		{Name: "b", Tags: []string{"prod"}},
+		{Name: "c", Tags: []string{"db"}},
+	}
+
+	want := []TagCount{{Tag: "prod", Count: 2}, {Tag: "db", Count: 1}, {Tag: "web", Count: 1}}
+	if got := CountTags(hosts); !reflect.DeepEqual(got, want) {
+		t.Errorf("CountTags() = %v, want %v", got, want)
+	}
+}
+
+// writeTagsTestConfigs writes a main config including a work config, both
+// using tags
+func writeTagsTestConfigs(t *testing.T) (string, string) {
+	t.Helper()
+	mainConfig := writeTestConfig(t, "Include work\n\n# Tags: prod, web\nHost web1\n\n# Tags: production\nHost web2\n")
+	work := filepath.Join(filepath.Dir(mainConfig), "work")
+	if err := os.WriteFile(work, []byte("# Tags: Prod, production, db\nHost db1\n\nHost other\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return mainConfig, work
+}
+
+func TestRenameTags(t *testing.T) {
+	mainConfig, work := writeTagsTestConfigs(t)
+
+	// Merging keeps a single copy of the target tag
+	changed, err := RenameTags(mainConfig, []string{"prod", "Prod"}, "production")
+	if err != nil {
+		t.Fatalf("RenameTags() error = %v", err)
+	}
+	if changed != 2 {
+		t.Errorf("expected 2 hosts changed, got %d", changed)
+	}
+	if got, want := readTestConfig(t, mainConfig), "Include work\n\n# Tags: production, web\nHost web1\n\n# Tags: production\nHost web2\n"; got != want {
+		t.Errorf("config = %q, want %q", got, want)
+	}
+	if got, want := readTestConfig(t, work), "# Tags: production, db\nHost db1\n\nHost other\n"; got != want {
+		t.Errorf("work = %q, want %q", got, want)
+	}
+
+	// Both files were changed together and are undone together
+	restored, err := UndoLastChange()
+	if err != nil {
+		t.Fatalf("UndoLastChange() error = %v", err)
+	}
+	if len(restored) != 2 {
+		t.Errorf("expected both files to be restored, got %d", len(restored))
+	}
+
+	if _, err := RenameTags(mainConfig, []string{"web"}, "a,b"); err == nil {
+		t.Error("expected a tag with a comma to be rejected")
+	}
+}
+
+func TestDeleteTags(t *testing.T) {
+	mainConfig, work := writeTagsTestConfigs(t)
+
+	changed, err := DeleteTags(mainConfig, []string{"production"})
+	if err != nil {
+		t.Fatalf("DeleteTags() error = %v", err)
+	}
+	if changed != 2 {
+		t.Errorf("expected 2 hosts changed, got %d", changed)
+	}
+	if got, want := readTestConfig(t, mainConfig), "Include work\n\n# Tags: prod, web\nHost web1\n\nHost web2\n"; got != want {
+		t.Errorf("config = %q, want %q", got, want)
+	}
+	if got, want := readTestConfig(t, work), "# Tags: Prod, db\nHost db1\n\nHost other\n"; got != want {
+		t.Errorf("work = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// HostTemplate holds the values new hosts start from. Values may contain the
+// variables {{name}}, the name of the new host, and {{env}}, its environment.
+type HostTemplate struct {
+	HostName     string   `json:"hostname,omitempty"`
+	User         string   `json:"user,omitempty"`
+	Port         string   `json:"port,omitempty"`
+	IdentityFile string   `json:"identity_file,omitempty"`
+	ProxyJump    string   `json:"proxy_jump,omitempty"`
+	Options      string   `json:"options,omitempty"` // As on the ssh command line, e.g. "-o Compression=yes"
+	Group        string   `json:"group,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Owner        string   `json:"owner,omitempty"`
+	Environment  string   `json:"environment,omitempty"`
+	Links        []string `json:"links,omitempty"`
+}
+
+// templateVarPattern matches a template variable such as "{{name}}"
+var templateVarPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// TemplateVars returns the variables available to a template
+func TemplateVars(name, env string) map[string]string {
+	return map[string]string{"name": name, "env": env}
+}
+
+// ExpandTemplateVars replaces the template variables in a value. Unknown and
+// empty variables are errors, so that no host is written with a half-built
+// value such as "web1..example.com".
+func ExpandTemplateVars(value string, vars map[string]string) (string, error) {
+	var err error
+	expanded := templateVarPattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := templateVarPattern.FindStringSubmatch(match)[1]
+		v, ok := vars[name]
+		switch {
+		case err != nil:
+		case !ok:
+			err = fmt.Errorf("unknown template variable '%s', use {{name}} or {{env}}", match)
+		case v == "":
+			err = fmt.Errorf("template variable '%s' is empty", match)
+		}
+		return v
+	})
+	if err != nil {
+		return "", err
+	}
+	return expanded, nil
+}
+
+// NewHost builds a host named name from the template. env replaces the
+// environment of the template when it is set.
+func (t HostTemplate) NewHost(name, env string) (SSHHost, error) {
+	if env == "" {
+		env = t.Environment
+	}
+	vars := TemplateVars(name, env)
+
+	values := []*string{&t.HostName, &t.User, &t.Port, &t.IdentityFile, &t.ProxyJump, &t.Options, &t.Group, &t.Description, &t.Owner}
+	for _, value := range values {
+		expanded, err := ExpandTemplateVars(*value, vars)
+		if err != nil {
+			return SSHHost{}, err
+		}
+		*value = expanded
+	}
+	tags, err := expandTemplateList(t.Tags, vars)
+	if err != nil {
+		return SSHHost{}, err
+	}
+	links, err := expandTemplateList(t.Links, vars)
+	if err != nil {
+		return SSHHost{}, err
+	}
+	options, err := ParseCommandOptions(t.Options)
+	if err != nil {
+		return SSHHost{}, err
+	}
+
+	host := SSHHost{
+		Name:  name,
+		Tags:  tags,
+		Group: NormalizeGroupPath(t.Group),
+		Metadata: HostMetadata{
+			Description: t.Description,
+			Owner:       t.Owner,
+			Environment: env,
+			Links:       links,
+		},
+	}
+	host.Set("HostName", t.HostName)
+	host.Set("User", t.User)
+	host.Set("Port", t.Port)
+	if t.IdentityFile != "" {
+		host.Set("IdentityFile", QuoteArg(t.IdentityFile))
+	}
+	host.Set("ProxyJump", t.ProxyJump)
+	host.SetOtherDirectives(options)
+	return host, nil
+}
+
+// expandTemplateList expands the variables of every item of a list, leaving
+// out the items that end up empty
+func expandTemplateList(items []string, vars map[string]string) ([]string, error) {
+	var result []string
+	for _, item := range items {
+		expanded, err := ExpandTemplateVars(item, vars)
+		if err != nil {
+			return nil, err
+		}
+		if expanded = strings.TrimSpace(expanded); expanded != "" {
+			result = append(result, expanded)
+		}
+	}
+	return result, nil
+}
+
+// TemplateFromHost builds a template from an existing host. The name of the
+// host is replaced by {{name}} in its HostName; a HostName that doesn't
+// contain it is left out, as every host made from the template would
+// otherwise point at the same machine. Notes are specific to the host and
+// are left out too.
+func TemplateFromHost(host SSHHost) HostTemplate {
+	t := HostTemplate{
+		User:         host.User,
+		Port:         host.Port,
+		IdentityFile: UnquoteArg(host.Identity),
+		ProxyJump:    host.ProxyJump,
+		Options:      FormatCommandOptions(host.OtherDirectives()),
+		Group:        host.Group,
+		Tags:         append([]string(nil), host.Tags...),
+		Description:  host.Metadata.Description,
+		Owner:        host.Metadata.Owner,
+		Environment:  host.Metadata.Environment,
+		Links:        append([]string(nil), host.Metadata.Links...),
+	}
+	if host.Name != "" && strings.Contains(host.Hostname, host.Name) {
+		t.HostName = strings.ReplaceAll(host.Hostname, host.Name, "{{name}}")
+	}
+	return t
+}
+
+// ValidateTemplateName checks that a template name can be given on the
+// command line
+func ValidateTemplateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("template name cannot be empty")
+	}
+	if strings.ContainsAny(name, " \t\r\n") {
+		return fmt.Errorf("template name '%s' cannot contain spaces", name)
+	}
+	return nil
+}
+
+// LoadHostTemplates returns the host templates of the app config
+func LoadHostTemplates() (map[string]HostTemplate, error) {
+	appConfig, err := LoadAppConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load templates: %w", err)
+	}
+	return appConfig.Templates, nil
+}
+
+// GetHostTemplate returns a template of the app config by name
+func GetHostTemplate(name string) (HostTemplate, error) {
+	templates, err := LoadHostTemplates()
+	if err != nil {
+		return HostTemplate{}, err
+	}
+	t, ok := templates[name]
+	if !ok {
+		if len(templates) == 0 {
+			return HostTemplate{}, fmt.Errorf("template '%s' not found, no template is defined", name)
+		}
+		return HostTemplate{}, fmt.Errorf("template '%s' not found, available templates: %s", name, strings.Join(TemplateNames(templates), ", "))
+	}
+	return t, nil
+}
+
+// SaveHostTemplate adds a template to the app config. An existing template is
+// never replaced.
+func SaveHostTemplate(name string, t HostTemplate) error {
+	if err := ValidateTemplateName(name); err != nil {
+		return err
+	}
+	appConfig, err := LoadAppConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load templates: %w", err)
+	}
+	if _, exists := appConfig.Templates[name]; exists {
+		return fmt.Errorf("template '%s' already exists", name)
+	}
+	if appConfig.Templates == nil {
+		appConfig.Templates = make(map[string]HostTemplate)
+	}
+	appConfig.Templates[name] = t
+	return SaveAppConfig(appConfig)
+}
+
+// TemplateNames returns the names of templates, sorted
+func TemplateNames(templates map[string]HostTemplate) []string {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
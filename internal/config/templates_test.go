@@ -0,0 +1,118 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandTemplateVars(t *testing.T) {
+	vars := TemplateVars("web1", "prod")
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr string
+	}{
+		{value: "{{name}}.{{env}}.example.com", want: "web1.prod.example.com"},
+		{value: "{{ name }}-admin", want: "web1-admin"},
+		{value: "no variables", want: "no variables"},
+		{value: "{{host}}.example.com", wantErr: "unknown template variable"},
+	}
+	for _, tt := range tests {
+		got, err := ExpandTemplateVars(tt.value, vars)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ExpandTemplateVars(%q) error = %v, want %q", tt.value, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ExpandTemplateVars(%q) = %q, %v, want %q", tt.value, got, err, tt.want)
+		}
+	}
+
+	if _, err := ExpandTemplateVars("{{name}}.{{env}}.example.com", TemplateVars("web1", "")); err == nil {
+		t.Error("Expected an error for an empty {{env}}")
+	}
+}
+
+func TestHostTemplateNewHost(t *testing.T) {
+	template := HostTemplate{
+		HostName:     "{{name}}.{{env}}.example.com",
+		User:         "deploy",
+		IdentityFile: "~/.ssh/{{env}} key",
+		Options:      "-o ServerAliveInterval=60",
+		Group:        "{{env}}/db",
+		Tags:         []string{"database", "{{env}}"},
+		Environment:  "prod",
+	}
+
+	host, err := template.NewHost("db3", "staging")
+	if err != nil {
+		t.Fatalf("NewHost() error = %v", err)
+	}
+	if host.Hostname != "db3.staging.example.com" {
+		t.Errorf("Hostname = %q, want db3.staging.example.com", host.Hostname)
+	}
+	if host.Identity != `"~/.ssh/staging key"` {
+		t.Errorf("Identity = %q, want it quoted", host.Identity)
+	}
+	if host.Group != "staging/db" || host.Metadata.Environment != "staging" {
+		t.Errorf("Group = %q, Environment = %q", host.Group, host.Metadata.Environment)
+	}
+	if strings.Join(host.Tags, ",") != "database,staging" {
+		t.Errorf("Tags = %v", host.Tags)
+	}
+	if host.Get("ServerAliveInterval") != "60" {
+		t.Errorf("ServerAliveInterval = %q, want 60", host.Get("ServerAliveInterval"))
+	}
+
+	// Without an override the environment of the template is used
+	host, err = template.NewHost("db4", "")
+	if err != nil || host.Hostname != "db4.prod.example.com" {
+		t.Errorf("NewHost() = %q, %v, want db4.prod.example.com", host.Hostname, err)
+	}
+}
+
+func TestTemplateFromHost(t *testing.T) {
+	host := SSHHost{Name: "web1", Tags: []string{"web"}, Metadata: HostMetadata{Environment: "prod", Notes: "Only for web1"}}
+	host.Set("HostName", "web1.example.com")
+	host.Set("User", "deploy")
+	host.Set("Compression", "yes")
+
+	template := TemplateFromHost(host)
+	if template.HostName != "{{name}}.example.com" {
+		t.Errorf("HostName = %q, want {{name}}.example.com", template.HostName)
+	}
+	if template.User != "deploy" || template.Options != "-o Compression=yes" || template.Environment != "prod" {
+		t.Errorf("TemplateFromHost() = %+v", template)
+	}
+
+	host.Set("HostName", "10.0.0.5")
+	if template := TemplateFromHost(host); template.HostName != "" {
+		t.Errorf("HostName = %q, want a fixed address left out", template.HostName)
+	}
+}
+
+func TestSaveHostTemplate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	if err := SaveHostTemplate("prod-db", HostTemplate{User: "postgres"}); err != nil {
+		t.Fatalf("SaveHostTemplate() error = %v", err)
+	}
+	if err := SaveHostTemplate("prod-db", HostTemplate{User: "other"}); err == nil {
+		t.Error("Expected an error when replacing a template")
+	}
+	if err := SaveHostTemplate("prod db", HostTemplate{}); err == nil {
+		t.Error("Expected an error for a name with spaces")
+	}
+
+	template, err := GetHostTemplate("prod-db")
+	if err != nil || template.User != "postgres" {
+		t.Errorf("GetHostTemplate() = %+v, %v", template, err)
+	}
+	if _, err := GetHostTemplate("missing"); err == nil || !strings.Contains(err.Error(), "prod-db") {
+		t.Errorf("GetHostTemplate(missing) error = %v, want the available templates", err)
+	}
+}
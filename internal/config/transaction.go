@@ -0,0 +1,168 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// writeStagedFile writes one file of a transaction. It is a variable so that
+// tests can make a write fail half-way through a transaction.
+var writeStagedFile = writeFileAtomic
+
+// stagedFile is a config file taking part in a transaction
+type stagedFile struct {
+	cfg      *ConfigFile
+	original []byte // Content as read, to roll back to
+	existed  bool
+}
+
+// modifyConfigFiles changes several config files as one transaction. The files
+// are loaded under the config lock, changed in memory by change, validated,
+// backed up under a single timestamp and written one after the other. If any
+// write fails, the files already written are restored to their previous
+// content, so that a change is never left half applied.
+func modifyConfigFiles(paths []string, change func(files []*ConfigFile) error) error {
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	staged := make([]*stagedFile, 0, len(paths))
+	files := make([]*ConfigFile, 0, len(paths))
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		key := versionKey(path)
+		if seen[key] {
+			return fmt.Errorf("%s is listed twice in the same change", path)
+		}
+		seen[key] = true
+
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if err := checkVersion(path, data); err != nil {
+			return err
+		}
+
+		cfg := ParseConfigBytes(path, data)
+		staged = append(staged, &stagedFile{cfg: cfg, original: data, existed: err == nil})
+		files = append(files, cfg)
+	}
+
+	if err := change(files); err != nil {
+		return err
+	}
+
+	// Only files whose content actually changed are backed up and written
+	var changed []*stagedFile
+	for _, file := range staged {
+		if string(file.cfg.Bytes()) != string(file.original) {
+			changed = append(changed, file)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if err := validateStagedFiles(staged); err != nil {
+		return err
+	}
+
+	var changedPaths []string
+	for _, file := range changed {
+		changedPaths = append(changedPaths, file.cfg.Path)
+	}
+	if err := snapshotFiles(changedPaths); err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	for i, file := range changed {
+		data := file.cfg.Bytes()
+		if err := writeStagedFile(file.cfg.Path, data, 0600); err != nil {
+			err = fmt.Errorf("failed to write %s: %w", file.cfg.Path, err)
+			if rollbackErr := rollbackStagedFiles(changed[:i]); rollbackErr != nil {
+				return fmt.Errorf("%w; rollback failed, restore from backups: %v", err, rollbackErr)
+			}
+			if i > 0 {
+				return fmt.Errorf("%w; changes to other files were rolled back", err)
+			}
+			return err
+		}
+		recordVersion(file.cfg.Path, data)
+	}
+
+	return nil
+}
+
+// validateStagedFiles checks the new content of the files of a transaction
+// before anything is written: every directive the change adds or rewrites
+// must have a valid value, and the change must not define a host in more
+// files than before. Lines left as they were read are not checked, so that
+// existing mistakes don't block unrelated changes.
+func validateStagedFiles(staged []*stagedFile) error {
+	before := make(map[string]int)
+	after := make(map[string]int)
+
+	for _, file := range staged {
+		for _, block := range file.cfg.Blocks {
+			for _, line := range block.Body {
+				if line.Kind != LineDirective || (line.Number != 0 && !line.dirty) {
+					continue
+				}
+				if err := ValidateDirective(line.Key, line.Value); err != nil {
+					return fmt.Errorf("invalid content staged for %s: %w", file.cfg.Path, err)
+				}
+			}
+		}
+
+		countHostDefinitions(ParseConfigBytes(file.cfg.Path, file.original), before)
+		countHostDefinitions(file.cfg, after)
+	}
+
+	for alias, count := range after {
+		if count > 1 && count > before[alias] {
+			return fmt.Errorf("change would define host '%s' more than once", alias)
+		}
+	}
+	return nil
+}
+
+// countHostDefinitions counts the Host blocks defining each concrete alias
+func countHostDefinitions(cfg *ConfigFile, counts map[string]int) {
+	for _, block := range cfg.Blocks {
+		if !block.IsHost() {
+			continue
+		}
+		for _, alias := range ConcreteAliases(block.Patterns()) {
+			counts[alias]++
+		}
+	}
+}
+
+// rollbackStagedFiles restores files written by a failed transaction to their
+// previous content, removing the ones that didn't exist before
+func rollbackStagedFiles(written []*stagedFile) error {
+	var failed []string
+	for _, file := range written {
+		path := file.cfg.Path
+		var err error
+		if file.existed {
+			err = writeFileAtomic(path, file.original, 0600)
+		} else {
+			err = os.Remove(path)
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		recordVersion(path, file.original)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%s", strings.Join(failed, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeMoveTestConfigs sets up a default config including a second file
+func writeMoveTestConfigs(t *testing.T) (string, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.MkdirAll(sshDir, 0700); err != nil {
+		t.Fatalf("Failed to create .ssh: %v", err)
+	}
+	mainConfig := filepath.Join(sshDir, "config")
+	otherConfig := filepath.Join(sshDir, "work")
+	main := "Include work\n\n# Tags: web\nHost web\n    HostName web.example.com\n    ForwardAgent yes\n"
+	if err := os.WriteFile(mainConfig, []byte(main), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := os.WriteFile(otherConfig, []byte("Host db\n    HostName db.example.com\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return mainConfig, otherConfig
+}
+
+func TestMoveHostToFileRollsBackOnWriteFailure(t *testing.T) {
+	mainConfig, otherConfig := writeMoveTestConfigs(t)
+	mainBefore := readTestConfig(t, mainConfig)
+	otherBefore := readTestConfig(t, otherConfig)
+
+	// The first file is written, the second write fails
+	writes := 0
+	writeStagedFile = func(path string, data []byte, mode os.FileMode) error {
+		writes++
+		if writes == 2 {
+			return errors.New("disk full")
+		}
+		return writeFileAtomic(path, data, mode)
+	}
+	defer func() { writeStagedFile = writeFileAtomic }()
+
+	err := MoveHostToFile("web", otherConfig)
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("MoveHostToFile() error = %v, want the write failure", err)
+	}
+	if !strings.Contains(err.Error(), "rolled back") {
+		t.Errorf("error should say the change was rolled back: %v", err)
+	}
+	if got := readTestConfig(t, mainConfig); got != mainBefore {
+		t.Errorf("source not rolled back:\n%s", got)
+	}
+	if got := readTestConfig(t, otherConfig); got != otherBefore {
+		t.Errorf("target not rolled back:\n%s", got)
+	}
+
+	// The rollback leaves sshm in sync with the files, so the move can be retried
+	writeStagedFile = writeFileAtomic
+	if err := MoveHostToFile("web", otherConfig); err != nil {
+		t.Fatalf("MoveHostToFile() retry error = %v", err)
+	}
+	if got := readTestConfig(t, mainConfig); got != "Include work\n" {
+		t.Errorf("source after move = %q", got)
+	}
+	want := "Host db\n    HostName db.example.com\n\n# Tags: web\nHost web\n    HostName web.example.com\n    ForwardAgent yes\n"
+	if got := readTestConfig(t, otherConfig); got != want {
+		t.Errorf("target after move:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestModifyConfigFilesRejectsDuplicatedHost(t *testing.T) {
+	mainConfig, otherConfig := writeMoveTestConfigs(t)
+	otherBefore := readTestConfig(t, otherConfig)
+
+	// Copying a block instead of moving it must be caught before writing
+	err := modifyConfigFiles([]string{mainConfig, otherConfig}, func(files []*ConfigFile) error {
+		files[1].AppendBlock(files[0].FindHostBlock("web"))
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Fatalf("modifyConfigFiles() error = %v, want a duplicate host error", err)
+	}
+	if got := readTestConfig(t, otherConfig); got != otherBefore {
+		t.Errorf("target was written:\n%s", got)
+	}
+}
+
+func TestModifyConfigFilesValidatesChangedDirectives(t *testing.T) {
+	mainConfig, otherConfig := writeMoveTestConfigs(t)
+	if err := os.WriteFile(otherConfig, []byte("Host db\n    HostName db.example.com\n    Port often\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	mainBefore := readTestConfig(t, mainConfig)
+
+	// A directive the change adds or rewrites must have a valid value
+	for _, change := range []func(block *ConfigBlock){
+		func(block *ConfigBlock) { block.insertBody(0, newDirectiveLine("    ", "Port", "often")) },
+		func(block *ConfigBlock) { block.Directives()[0].SetValue("") },
+	} {
+		err := modifyConfigFiles([]string{mainConfig}, func(files []*ConfigFile) error {
+			change(files[0].FindHostBlock("web"))
+			return nil
+		})
+		if err == nil || !strings.Contains(err.Error(), "invalid content staged") {
+			t.Errorf("modifyConfigFiles() error = %v, want an invalid content error", err)
+		}
+		if got := readTestConfig(t, mainConfig); got != mainBefore {
+			t.Errorf("config was written:\n%s", got)
+		}
+	}
+
+	// Invalid lines the change leaves alone don't block it
+	err := modifyConfigFiles([]string{otherConfig}, func(files []*ConfigFile) error {
+		files[0].FindHostBlock("db").Directives()[0].SetValue("db2.example.com")
+		return nil
+	})
+	if err != nil {
+		t.Errorf("modifyConfigFiles() error = %v", err)
+	}
+	if got, want := readTestConfig(t, otherConfig), "Host db\n    HostName db2.example.com\n    Port often\n"; got != want {
+		t.Errorf("work = %q, want %q", got, want)
+	}
+}
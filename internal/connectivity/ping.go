@@ -48,6 +48,9 @@ type PingManager struct {
 	results map[string]*HostPingResult
 	mutex   sync.RWMutex
 	timeout time.Duration
+
+	// configFile is the SSH config used to resolve effective settings, empty for the default
+	configFile string
 }
 
 // NewPingManager creates a new ping manager with the specified timeout
@@ -58,6 +61,13 @@ func NewPingManager(timeout time.Duration) *PingManager {
 	}
 }
 
+// SetConfigFile sets the SSH config used to resolve hostnames, users and ports
+func (pm *PingManager) SetConfigFile(configFile string) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+	pm.configFile = configFile
+}
+
 // GetStatus returns the current status for a host
 func (pm *PingManager) GetStatus(hostName string) PingStatus {
 	pm.mutex.RLock()
@@ -98,16 +108,8 @@ func (pm *PingManager) PingHost(ctx context.Context, host config.SSHHost) *HostP
 	// Mark as connecting
 	pm.updateStatus(host.Name, StatusConnecting, nil, 0)
 
-	// Determine the actual hostname and port
-	hostname := host.Hostname
-	if hostname == "" {
-		hostname = host.Name
-	}
-
-	port := host.Port
-	if port == "" {
-		port = "22"
-	}
+	// Determine the actual hostname, port and user
+	hostname, port, user := pm.resolveTarget(host)
 
 	// Create context with timeout
 	pingCtx, cancel := context.WithTimeout(ctx, pm.timeout)
@@ -130,7 +132,7 @@ func (pm *PingManager) PingHost(ctx context.Context, host config.SSHHost) *HostP
 
 	// If TCP connection succeeds, try SSH handshake
 	sshConfig := &ssh.ClientConfig{
-		User:            host.User,
+		User:            user,
 		HostKeyCallback: ssh.InsecureIgnoreHostKey(), // For ping purposes only
 		Timeout:         time.Second * 2,             // Short timeout for handshake
 	}
@@ -159,6 +161,37 @@ func (pm *PingManager) PingHost(ctx context.Context, host config.SSHHost) *HostP
 	}
 }
 
+// resolveTarget returns the hostname, port and user ssh would use for a host,
+// falling back to the host's own fields if the config can't be resolved
+func (pm *PingManager) resolveTarget(host config.SSHHost) (hostname, port, user string) {
+	hostname, port, user = host.Hostname, host.Port, host.User
+
+	pm.mutex.RLock()
+	configFile := pm.configFile
+	pm.mutex.RUnlock()
+
+	// Only values read from the config override the host, defaults are applied below
+	if effective, err := config.ResolveEffectiveConfig(host.Name, configFile); err == nil {
+		configured := func(key, fallback string) string {
+			if value, ok := effective.Get(key); ok && value.Source != config.SourceDefault {
+				return value.Value
+			}
+			return fallback
+		}
+		hostname = configured("HostName", hostname)
+		port = configured("Port", port)
+		user = configured("User", user)
+	}
+
+	if hostname == "" {
+		hostname = host.Name
+	}
+	if port == "" {
+		port = "22"
+	}
+	return hostname, port, user
+}
+
 // PingAllHosts pings all hosts concurrently and returns a channel of results
 func (pm *PingManager) PingAllHosts(ctx context.Context, hosts []config.SSHHost) <-chan *HostPingResult {
 	resultChan := make(chan *HostPingResult, len(hosts))
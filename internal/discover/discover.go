@@ -0,0 +1,329 @@
+// Package discover finds the hosts connected to with ssh that are missing
+// from the config, in known_hosts files and shell histories.
+package discover
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/importer"
+	"github.com/Gu1llaum-3/sshm/internal/validation"
+)
+
+// HistoryFile is a shell history file to scan
+type HistoryFile struct {
+	Path  string
+	Shell Shell
+}
+
+// Sources lists the files to scan. Missing files are skipped.
+type Sources struct {
+	KnownHosts []string
+	Histories  []HistoryFile
+}
+
+// DefaultSources returns the known_hosts files of the user and the history
+// files of bash, zsh and fish, including the one named by $HISTFILE
+func DefaultSources() (Sources, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Sources{}, err
+	}
+	sshDir, err := config.GetSSHDirectory()
+	if err != nil {
+		return Sources{}, err
+	}
+
+	sources := Sources{
+		KnownHosts: []string{filepath.Join(sshDir, "known_hosts"), filepath.Join(sshDir, "known_hosts2")},
+	}
+	if histfile := os.Getenv("HISTFILE"); histfile != "" {
+		shell := Bash
+		if strings.Contains(filepath.Base(histfile), "zsh") || strings.Contains(filepath.Base(histfile), "zhistory") {
+			shell = Zsh
+		}
+		sources.Histories = append(sources.Histories, HistoryFile{Path: histfile, Shell: shell})
+	}
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	for _, h := range []HistoryFile{
+		{Path: filepath.Join(home, ".bash_history"), Shell: Bash},
+		{Path: filepath.Join(home, ".zsh_history"), Shell: Zsh},
+		{Path: filepath.Join(home, ".zhistory"), Shell: Zsh},
+		{Path: filepath.Join(dataHome, "fish", "fish_history"), Shell: Fish},
+	} {
+		if len(sources.Histories) == 0 || sources.Histories[0].Path != h.Path {
+			sources.Histories = append(sources.Histories, h)
+		}
+	}
+	return sources, nil
+}
+
+// Suggestion is a host found in the scanned files that the config lacks
+type Suggestion struct {
+	// Host holds the values seen most often for the host, under a suggested
+	// name
+	Host config.SSHHost
+	// Count is the number of connections in histories and of known_hosts
+	// entries naming the host
+	Count int
+	// Files lists the files the host was found in
+	Files []string
+}
+
+// Result holds the suggestions of a scan, the most frequent first
+type Result struct {
+	Suggestions []Suggestion
+	Scanned     []string // Files that were read
+	Warnings    []string
+}
+
+// tally counts the values seen for a setting of a host
+type tally struct {
+	counts map[string]int
+	order  []string
+}
+
+// add counts a value, ignoring empty ones
+func (t *tally) add(value string) {
+	if value == "" {
+		return
+	}
+	if t.counts == nil {
+		t.counts = make(map[string]int)
+	}
+	if t.counts[value] == 0 {
+		t.order = append(t.order, value)
+	}
+	t.counts[value]++
+}
+
+// best returns the value seen most often, the first seen on a tie
+func (t *tally) best() string {
+	best := ""
+	for _, value := range t.order {
+		if best == "" || t.counts[value] > t.counts[best] {
+			best = value
+		}
+	}
+	return best
+}
+
+// sighting gathers what was seen of one host and port
+type sighting struct {
+	hostname   string
+	port       string
+	count      int
+	users      tally
+	identities tally
+	jumps      tally
+	files      []string
+}
+
+// scan collects the sightings of the scanned files
+type scan struct {
+	sightings map[string]*sighting
+	existing  []config.SSHHost
+	result    *Result
+}
+
+// add records a connection to a host found in a file, unless the config
+// already has the host
+func (s *scan) add(inv Invocation, file string) {
+	port := inv.Port
+	if port == "" {
+		port = "22"
+	}
+	if !usableHostname(inv.Hostname) || !validation.ValidatePort(port) || inConfig(s.existing, inv.Hostname, port) {
+		return
+	}
+
+	key := inv.Hostname + " " + port
+	st, ok := s.sightings[key]
+	if !ok {
+		st = &sighting{hostname: inv.Hostname, port: port}
+		s.sightings[key] = st
+	}
+	st.count++
+	st.users.add(inv.User)
+	st.identities.add(inv.Identity)
+	st.jumps.add(inv.ProxyJump)
+	for _, f := range st.files {
+		if f == file {
+			return
+		}
+	}
+	st.files = append(st.files, file)
+}
+
+// usableHostname reports whether a hostname can be suggested. Loopback
+// addresses are left out.
+func usableHostname(hostname string) bool {
+	if hostname == "localhost" || strings.HasSuffix(hostname, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(hostname); ip != nil {
+		return !ip.IsLoopback() && !ip.IsUnspecified()
+	}
+	return validation.ValidateHostname(hostname)
+}
+
+// inConfig reports whether a host of the config is reached under a name or
+// connects to a hostname and port
+func inConfig(existing []config.SSHHost, hostname, port string) bool {
+	for _, host := range existing {
+		if host.HasAlias(hostname) {
+			return true
+		}
+		hostPort := host.Port
+		if hostPort == "" {
+			hostPort = "22"
+		}
+		if strings.EqualFold(host.Hostname, hostname) && hostPort == port {
+			return true
+		}
+	}
+	return false
+}
+
+// readSource reads a file to scan, reporting whether it exists
+func (s *scan) readSource(path string) ([]byte, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			s.result.Warnings = append(s.result.Warnings, fmt.Sprintf("could not read %s: %v", path, err))
+		}
+		return nil, false
+	}
+	s.result.Scanned = append(s.result.Scanned, path)
+	return data, true
+}
+
+// Discover scans the files of the sources for hosts that the existing hosts
+// of the config don't cover, and suggests them ranked by how often they
+// appear. Hashed known_hosts entries are matched against the hosts found in
+// histories and in plain entries.
+func Discover(sources Sources, existing []config.SSHHost) *Result {
+	s := &scan{sightings: make(map[string]*sighting), existing: existing, result: &Result{}}
+
+	for _, h := range sources.Histories {
+		data, ok := s.readSource(h.Path)
+		if !ok {
+			continue
+		}
+		for _, command := range historyCommands(h.Shell, data) {
+			for _, inv := range ParseCommandLine(command) {
+				s.add(inv, h.Path)
+			}
+		}
+	}
+
+	hashedByFile := make(map[string][]hashedHost)
+	var knownFiles []string
+	for _, path := range sources.KnownHosts {
+		data, ok := s.readSource(path)
+		if !ok {
+			continue
+		}
+		hosts, hashed := parseKnownHosts(data)
+		for _, host := range hosts {
+			s.add(Invocation{Hostname: host.Hostname, Port: host.Port}, path)
+		}
+		hashedByFile[path] = hashed
+		knownFiles = append(knownFiles, path)
+	}
+
+	for _, path := range knownFiles {
+		unresolved := 0
+		for _, entry := range hashedByFile[path] {
+			matched := false
+			for _, st := range s.sightings {
+				if entry.matches(st.hostname, st.port) {
+					s.add(Invocation{Hostname: st.hostname, Port: st.port}, path)
+					matched = true
+					break
+				}
+			}
+			if !matched && !entry.matchesConfig(existing) {
+				unresolved++
+			}
+		}
+		if unresolved > 0 {
+			s.result.Warnings = append(s.result.Warnings,
+				fmt.Sprintf("%d hashed entries of %s match no host seen elsewhere and can't be read", unresolved, path))
+		}
+	}
+
+	sightings := make([]*sighting, 0, len(s.sightings))
+	for _, st := range s.sightings {
+		sightings = append(sightings, st)
+	}
+	sort.Slice(sightings, func(i, j int) bool {
+		if sightings[i].count != sightings[j].count {
+			return sightings[i].count > sightings[j].count
+		}
+		if sightings[i].hostname != sightings[j].hostname {
+			return sightings[i].hostname < sightings[j].hostname
+		}
+		return sightings[i].port < sightings[j].port
+	})
+
+	names := make(map[string]bool)
+	for _, st := range sightings {
+		name := importer.FreeName(suggestedName(st.hostname), func(n string) bool {
+			if names[n] {
+				return true
+			}
+			for _, host := range existing {
+				if host.HasAlias(n) {
+					return true
+				}
+			}
+			return false
+		})
+		names[name] = true
+		s.result.Suggestions = append(s.result.Suggestions, Suggestion{
+			Host:  st.host(name),
+			Count: st.count,
+			Files: st.files,
+		})
+	}
+	return s.result
+}
+
+// suggestedName returns the name to suggest for a hostname: the first label
+// of a domain name, or the address itself
+func suggestedName(hostname string) string {
+	if net.ParseIP(hostname) != nil {
+		return strings.ReplaceAll(hostname, ":", "-")
+	}
+	label, _, _ := strings.Cut(hostname, ".")
+	if len(label) > 50 {
+		label = label[:50]
+	}
+	return label
+}
+
+// host builds the host suggested for a sighting
+func (st *sighting) host(name string) config.SSHHost {
+	host := config.SSHHost{Name: name, Aliases: []string{name}, Patterns: []string{name}}
+	host.Set("HostName", st.hostname)
+	host.Set("User", st.users.best())
+	if st.port != "22" {
+		host.Set("Port", st.port)
+	}
+	if identity := st.identities.best(); identity != "" {
+		host.Set("IdentityFile", config.QuoteArg(identity))
+	}
+	host.Set("ProxyJump", st.jumps.best())
+	return host
+}
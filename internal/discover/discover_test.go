@@ -0,0 +1,84 @@
+package discover
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// writeSource writes a file to scan
+func writeSource(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	bash := writeSource(t, dir, "bash_history", "ssh deploy@web.example.com\nssh -p 2222 web.example.com\nssh web.example.com\nssh web\nssh localhost\n")
+	zsh := writeSource(t, dir, "zsh_history", ": 1700000000:0;ssh -i ~/.ssh/ops ops@db.example.com\n")
+	knownHosts := writeSource(t, dir, "known_hosts",
+		"web.example.com ssh-ed25519 AAAA\n10.0.9.9 ssh-ed25519 AAAA\n"+
+			hashKnownHost("db.example.com")+" ssh-ed25519 AAAA\n"+
+			hashKnownHost("unknown.example.com")+" ssh-ed25519 AAAA\n"+
+			hashKnownHost("configured.example.com")+" ssh-ed25519 AAAA\n")
+
+	existing := []config.SSHHost{
+		{Name: "web", Aliases: []string{"web"}, Hostname: "10.0.0.1"},
+		{Name: "old", Aliases: []string{"old"}, Hostname: "10.0.9.9", Port: "22"},
+		{Name: "conf", Aliases: []string{"conf"}, Hostname: "configured.example.com"},
+	}
+	sources := Sources{
+		KnownHosts: []string{knownHosts, filepath.Join(dir, "missing")},
+		Histories:  []HistoryFile{{Path: bash, Shell: Bash}, {Path: zsh, Shell: Zsh}},
+	}
+	result := Discover(sources, existing)
+
+	if len(result.Scanned) != 3 {
+		t.Errorf("scanned %v, want the 3 existing files", result.Scanned)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "1 hashed entries") {
+		t.Errorf("warnings = %v, want one about the unresolved hashed entry", result.Warnings)
+	}
+
+	// web is an alias of the config, localhost and 10.0.9.9 are left out
+	var names []string
+	for _, s := range result.Suggestions {
+		names = append(names, s.Host.Name)
+	}
+	if want := []string{"web-2", "db", "web-3"}; strings.Join(names, " ") != strings.Join(want, " ") {
+		t.Fatalf("suggestions = %v, want %v", names, want)
+	}
+
+	top := result.Suggestions[0]
+	if top.Count != 3 || top.Host.Hostname != "web.example.com" || top.Host.User != "deploy" || top.Host.Port != "" || len(top.Files) != 2 {
+		t.Errorf("top suggestion = %+v, count %d, files %v", top.Host, top.Count, top.Files)
+	}
+	db := result.Suggestions[1]
+	if db.Count != 2 || db.Host.User != "ops" || db.Host.Identity != "~/.ssh/ops" || len(db.Files) != 2 {
+		t.Errorf("db = %+v, count %d, want it matched in known_hosts", db.Host, db.Count)
+	}
+	if port := result.Suggestions[2].Host.Port; port != "2222" {
+		t.Errorf("web-3 port = %q, want 2222", port)
+	}
+}
+
+func TestSuggestedName(t *testing.T) {
+	tests := map[string]string{
+		"web.example.com": "web",
+		"10.0.0.1":        "10.0.0.1",
+		"fe80::1":         "fe80--1",
+		"bastion":         "bastion",
+	}
+	for hostname, want := range tests {
+		if got := suggestedName(hostname); got != want {
+			t.Errorf("suggestedName(%q) = %q, want %q", hostname, got, want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package discover
+
+import (
+	"bufio"
+	"bytes"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// Shell is the shell a history file was written by, which sets its format
+type Shell string
+
+const (
+	Bash Shell = "bash"
+	Zsh  Shell = "zsh"
+	Fish Shell = "fish"
+)
+
+// Invocation is a connection made with ssh, as read from a command line
+type Invocation struct {
+	Hostname  string
+	User      string
+	Port      string
+	Identity  string
+	ProxyJump string
+}
+
+// historyCommands returns the command lines of a history file
+func historyCommands(shell Shell, data []byte) []string {
+	var commands []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch shell {
+		case Zsh:
+			// Extended history lines are ": <start>:<duration>;<command>"
+			if strings.HasPrefix(line, ": ") {
+				if _, command, found := strings.Cut(line, ";"); found {
+					line = command
+				}
+			}
+		case Fish:
+			// Fish writes "- cmd: <command>" followed by "when:" and "paths:"
+			command, found := strings.CutPrefix(line, "- cmd: ")
+			if !found {
+				continue
+			}
+			line = strings.ReplaceAll(command, `\\`, `\`)
+		default:
+			// Bash writes "#<timestamp>" before commands with HISTTIMEFORMAT
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			commands = append(commands, line)
+		}
+	}
+	return commands
+}
+
+// commandSeparators matches the operators chaining the commands of a line
+var commandSeparators = regexp.MustCompile(`\|\||&&|[;|&]`)
+
+// commandWrappers are the commands that run the command following them
+var commandWrappers = map[string]bool{
+	"sudo": true, "exec": true, "command": true, "time": true, "nohup": true, "env": true, "builtin": true,
+}
+
+// envAssignment matches a variable set for a command, as in "TERM=xterm ssh"
+var envAssignment = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// ParseCommandLine returns the ssh invocations of a command line
+func ParseCommandLine(line string) []Invocation {
+	var invocations []Invocation
+	for _, command := range commandSeparators.Split(line, -1) {
+		args, err := config.SplitArgs(command)
+		if err != nil {
+			args = strings.Fields(command)
+		}
+		for len(args) > 0 && (commandWrappers[args[0]] || envAssignment.MatchString(args[0]) ||
+			(strings.HasPrefix(args[0], "-") && len(args) > 1)) {
+			args = args[1:]
+		}
+		if len(args) == 0 || filepath.Base(args[0]) != "ssh" {
+			continue
+		}
+		if inv, ok := parseSSHArgs(args[1:]); ok {
+			invocations = append(invocations, inv)
+		}
+	}
+	return invocations
+}
+
+// sshArgFlags are the options of ssh that take a value
+const sshArgFlags = "BbcDEeFIiJLlmOopQRSWw"
+
+// parseSSHArgs reads the destination and the connection options of the
+// arguments of ssh. It reports false when there is no usable destination.
+func parseSSHArgs(args []string) (Invocation, bool) {
+	var inv Invocation
+	var destination string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			if destination == "" && i+1 < len(args) {
+				destination = args[i+1]
+			}
+			break
+		}
+		// Options may follow the destination, up to the remote command
+		if !strings.HasPrefix(arg, "-") || len(arg) < 2 {
+			if destination != "" {
+				break
+			}
+			destination = arg
+			continue
+		}
+
+		// Flags may be grouped, the last one taking a value, as in -vp 2222
+		for j := 1; j < len(arg); j++ {
+			flag := arg[j]
+			if !strings.ContainsRune(sshArgFlags, rune(flag)) {
+				continue
+			}
+			value := arg[j+1:]
+			if value == "" && i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+			inv.setOption(flag, value)
+			break
+		}
+	}
+
+	if destination == "" || strings.ContainsAny(destination, "$`*?{}()<>\\") {
+		return inv, false
+	}
+	if rest, found := strings.CutPrefix(destination, "ssh://"); found {
+		u, err := url.Parse("ssh://" + rest)
+		if err != nil || u.Hostname() == "" {
+			return inv, false
+		}
+		if u.User != nil && u.User.Username() != "" {
+			inv.User = u.User.Username()
+		}
+		if u.Port() != "" {
+			inv.Port = u.Port()
+		}
+		inv.Hostname = u.Hostname()
+	} else {
+		if at := strings.LastIndex(destination, "@"); at >= 0 {
+			inv.User = destination[:at]
+			destination = destination[at+1:]
+		}
+		inv.Hostname = destination
+	}
+	inv.Hostname = strings.ToLower(strings.TrimSuffix(inv.Hostname, "."))
+	return inv, inv.Hostname != ""
+}
+
+// setOption records the value of an ssh option that sets up the connection
+func (inv *Invocation) setOption(flag byte, value string) {
+	switch flag {
+	case 'p':
+		inv.Port = value
+	case 'l':
+		inv.User = value
+	case 'i':
+		if inv.Identity == "" {
+			inv.Identity = value
+		}
+	case 'J':
+		inv.ProxyJump = value
+	case 'o':
+		key, val, found := strings.Cut(value, "=")
+		if !found {
+			key, val, _ = strings.Cut(value, " ")
+		}
+		val = strings.TrimSpace(val)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "port":
+			inv.Port = val
+		case "user":
+			inv.User = val
+		case "identityfile":
+			if inv.Identity == "" {
+				inv.Identity = val
+			}
+		case "proxyjump":
+			inv.ProxyJump = val
+		}
+	}
+}
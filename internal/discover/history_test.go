@@ -0,0 +1,54 @@
+package discover
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCommandLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want []Invocation
+	}{
+		{"ssh web.example.com", []Invocation{{Hostname: "web.example.com"}}},
+		{"ssh -p 2222 -i ~/.ssh/deploy -J bastion deploy@10.0.0.5 uptime", []Invocation{
+			{Hostname: "10.0.0.5", User: "deploy", Port: "2222", Identity: "~/.ssh/deploy", ProxyJump: "bastion"},
+		}},
+		{"ssh -vp2200 -l admin DB.example.com.", []Invocation{{Hostname: "db.example.com", User: "admin", Port: "2200"}}},
+		{`ssh -o "Port 2022" -o User=ops -o ProxyJump=jump api.lan`, []Invocation{
+			{Hostname: "api.lan", User: "ops", Port: "2022", ProxyJump: "jump"},
+		}},
+		{"ssh ssh://git@git.example.com:7999", []Invocation{{Hostname: "git.example.com", User: "git", Port: "7999"}}},
+		{"cd /tmp && TERM=xterm sudo ssh a.example.com; ls | ssh b.example.com cat", []Invocation{
+			{Hostname: "a.example.com"}, {Hostname: "b.example.com"},
+		}},
+		{"ssh d.example.com -p 2200 uptime -p 1", []Invocation{{Hostname: "d.example.com", Port: "2200"}}},
+		{"/usr/bin/ssh -- c.example.com", []Invocation{{Hostname: "c.example.com"}}},
+		{"ssh $HOST", nil},
+		{"ssh -V", nil},
+		{"scp file host:/tmp", nil},
+		{"echo ssh web", nil},
+	}
+	for _, tt := range tests {
+		if got := ParseCommandLine(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseCommandLine(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestHistoryCommands(t *testing.T) {
+	tests := []struct {
+		shell Shell
+		data  string
+		want  []string
+	}{
+		{Bash, "#1700000000\nssh web\nls\n", []string{"ssh web", "ls"}},
+		{Zsh, ": 1700000000:0;ssh web\nssh db\n", []string{"ssh web", "ssh db"}},
+		{Fish, "- cmd: ssh web\n  when: 1700000000\n- cmd: ls\n", []string{"ssh web", "ls"}},
+	}
+	for _, tt := range tests {
+		if got := historyCommands(tt.shell, []byte(tt.data)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("historyCommands(%s) = %q, want %q", tt.shell, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package discover
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// knownHost is a host read from a known_hosts file
+type knownHost struct {
+	Hostname string
+	Port     string // Empty for the default port
+}
+
+// hashedHost is a hashed known_hosts entry, "|1|<salt>|<hash>"
+type hashedHost struct {
+	salt []byte
+	hash []byte
+}
+
+// matches reports whether a hashed entry is the one of a host and port
+func (h hashedHost) matches(hostname, port string) bool {
+	name := hostname
+	if port != "" && port != "22" {
+		name = "[" + hostname + "]:" + port
+	}
+	mac := hmac.New(sha1.New, h.salt)
+	mac.Write([]byte(name))
+	return hmac.Equal(mac.Sum(nil), h.hash)
+}
+
+// matchesConfig reports whether a hashed entry is the one of a host of the
+// config, which is not suggested
+func (h hashedHost) matchesConfig(existing []config.SSHHost) bool {
+	for _, host := range existing {
+		port := host.Port
+		if port == "" {
+			port = "22"
+		}
+		if host.Hostname != "" && h.matches(strings.ToLower(host.Hostname), port) {
+			return true
+		}
+		for _, alias := range host.Aliases {
+			if h.matches(alias, port) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseKnownHosts reads the hosts of a known_hosts file. Hashed entries can't
+// be read back; they are returned apart to be matched against the hosts found
+// elsewhere.
+func parseKnownHosts(data []byte) ([]knownHost, []hashedHost) {
+	var hosts []knownHost
+	var hashed []hashedHost
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		// Lines marked @cert-authority or @revoked don't name known hosts
+		if strings.HasPrefix(fields[0], "@") {
+			continue
+		}
+
+		if strings.HasPrefix(fields[0], "|1|") {
+			parts := strings.Split(fields[0], "|")
+			if len(parts) != 4 {
+				continue
+			}
+			salt, errSalt := base64.StdEncoding.DecodeString(parts[2])
+			hash, errHash := base64.StdEncoding.DecodeString(parts[3])
+			if errSalt == nil && errHash == nil {
+				hashed = append(hashed, hashedHost{salt: salt, hash: hash})
+			}
+			continue
+		}
+
+		// A line lists the names and addresses of a host; the first name
+		// without wildcards is kept
+		for _, pattern := range strings.Split(fields[0], ",") {
+			if pattern == "" || strings.ContainsAny(pattern, "*?!") {
+				continue
+			}
+			host := knownHost{Hostname: pattern}
+			if strings.HasPrefix(pattern, "[") {
+				end := strings.Index(pattern, "]")
+				if end < 0 {
+					continue
+				}
+				host.Hostname = pattern[1:end]
+				host.Port = strings.TrimPrefix(pattern[end+1:], ":")
+			}
+			host.Hostname = strings.ToLower(host.Hostname)
+			hosts = append(hosts, host)
+			break
+		}
+	}
+	return hosts, hashed
+}
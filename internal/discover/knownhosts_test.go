@@ -0,0 +1,38 @@
+package discover
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+// hashKnownHost hashes a known_hosts name as ssh-keygen -H does
+func hashKnownHost(name string) string {
+	salt := []byte("0123456789abcdefghij")
+	mac := hmac.New(sha1.New, salt)
+	mac.Write([]byte(name))
+	return "|1|" + base64.StdEncoding.EncodeToString(salt) + "|" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestParseKnownHosts(t *testing.T) {
+	data := "# comment\n" +
+		"web.example.com,10.0.0.1 ssh-ed25519 AAAA\n" +
+		"[git.example.com]:7999 ssh-rsa AAAA\n" +
+		"*.internal,!bad.internal ssh-rsa AAAA\n" +
+		"@cert-authority *.example.com ssh-rsa AAAA\n" +
+		hashKnownHost("[db.example.com]:2222") + " ssh-ed25519 AAAA\n"
+
+	hosts, hashed := parseKnownHosts([]byte(data))
+	want := []knownHost{{Hostname: "web.example.com"}, {Hostname: "git.example.com", Port: "7999"}}
+	if !reflect.DeepEqual(hosts, want) {
+		t.Errorf("hosts = %+v, want %+v", hosts, want)
+	}
+	if len(hashed) != 1 {
+		t.Fatalf("got %d hashed entries, want 1", len(hashed))
+	}
+	if !hashed[0].matches("db.example.com", "2222") || hashed[0].matches("db.example.com", "22") {
+		t.Error("the hashed entry should only match db.example.com on port 2222")
+	}
+}
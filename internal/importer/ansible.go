@@ -0,0 +1,43 @@
+package importer
+
+import (
+	"github.com/Gu1llaum-3/sshm/internal/ansible"
+)
+
+// parseAnsible reads the hosts of an Ansible inventory, in the INI or the
+// YAML format. The groups of a host, direct or through children, become its
+// tags, and its connection variables take group variables into account.
+func parseAnsible(data []byte, b *builder) error {
+	inv, err := ansible.ParseInventory(data)
+	if err != nil {
+		return err
+	}
+
+	for _, host := range inv.Hosts() {
+		if connection := host.Var("ansible_connection"); connection != "" && connection != "ssh" && connection != "paramiko" {
+			b.warn("host '%s' uses the %s connection, skipped", host.Name, connection)
+			continue
+		}
+
+		hostname := host.Var("ansible_host")
+		if hostname == "" {
+			hostname = host.Name
+		}
+		s := session{
+			name:      host.Name,
+			hostname:  hostname,
+			user:      host.Var("ansible_user"),
+			port:      host.Var("ansible_port"),
+			proxyJump: host.ProxyJump(),
+			tags:      host.Groups,
+		}
+		if key := host.Var("ansible_ssh_private_key_file"); key != "" {
+			s.identities = []string{key}
+		}
+		if host.Var("ansible_password") != "" || host.Var("ansible_ssh_pass") != "" {
+			b.warn("host '%s': passwords are not imported", host.Name)
+		}
+		b.add(s)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAnsible(t *testing.T) {
+	data := `[web]
+web1 ansible_host=10.0.0.1 ansible_port=2222 ansible_ssh_private_key_file="~/.ssh/web key"
+localhost ansible_connection=local
+
+[db]
+db1.example.com ansible_ssh_common_args="-o ProxyJump=bastion"
+
+[prod:children]
+web
+db
+
+[prod:vars]
+ansible_user=deploy
+`
+	result, err := Parse("ansible", []byte(data), Options{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(result.Hosts) != 2 || len(result.Warnings) != 1 {
+		t.Fatalf("got %d hosts and warnings %v, want localhost skipped", len(result.Hosts), result.Warnings)
+	}
+
+	web := findHost(t, result, "web1")
+	if web.Hostname != "10.0.0.1" || web.User != "deploy" || web.Port != "2222" || web.Identity != `"~/.ssh/web key"` {
+		t.Errorf("web1 = %+v", web)
+	}
+	if !reflect.DeepEqual(web.Tags, []string{"web", "prod"}) {
+		t.Errorf("web1 tags = %v, want [web prod]", web.Tags)
+	}
+
+	// Without ansible_host, the inventory name is the hostname
+	db := findHost(t, result, "db1.example.com")
+	if db.Hostname != "db1.example.com" || db.ProxyJump != "bastion" || !reflect.DeepEqual(db.Tags, []string{"db", "prod"}) {
+		t.Errorf("db1 = %+v", db)
+	}
+}
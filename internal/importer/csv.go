@@ -0,0 +1,120 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// sshmCSVColumns maps the fields read from CSV files to the headers they may
+// have. The first header of each field is the one "sshm list --format csv"
+// writes; the others let spreadsheets made by hand be imported too.
+var sshmCSVColumns = map[string][]string{
+	"name":           {"name", "host", "alias", "label"},
+	"aliases":        {"aliases"},
+	"hostname":       {"hostname", "address", "ip"},
+	"user":           {"user", "username", "login"},
+	"port":           {"port"},
+	"identity_files": {"identity_files", "identity_file", "identity", "key"},
+	"proxy_jump":     {"proxy_jump", "jump_host"},
+	"tags":           {"tags", "tag"},
+	"group":          {"group", "folder"},
+	"description":    {"description"},
+	"owner":          {"owner"},
+	"environment":    {"environment", "env"},
+	"links":          {"links", "link"},
+	"system":         {"system"},
+}
+
+// parseCSV reads a CSV file with a header line, such as the output of
+// "sshm list --format csv". Lists are separated by semicolons. Hosts of the
+// system-wide config are left out, as they are already read from there.
+func parseCSV(data []byte, b *builder) error {
+	rows, err := readCSVTable(data, sshmCSVColumns)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if row["system"] == "true" {
+			continue
+		}
+		b.add(session{
+			name:       row["name"],
+			aliases:    splitList(row["aliases"]),
+			hostname:   row["hostname"],
+			user:       row["user"],
+			port:       row["port"],
+			identities: splitList(row["identity_files"]),
+			proxyJump:  row["proxy_jump"],
+			folder:     row["group"],
+			tags:       splitList(row["tags"]),
+			metadata: config.HostMetadata{
+				Description: row["description"],
+				Owner:       row["owner"],
+				Environment: row["environment"],
+				Links:       splitList(row["links"]),
+			},
+		})
+	}
+	return nil
+}
+
+// readCSVTable reads the rows of a CSV file as maps from field to value,
+// matching the header line against the headers each field may have. Case,
+// spaces and punctuation in headers are ignored.
+func readCSVTable(data []byte, columns map[string][]string) ([]map[string]string, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	fields := make(map[string]string)
+	for field, headers := range columns {
+		for _, header := range headers {
+			fields[normalizeHeader(header)] = field
+		}
+	}
+	index := make(map[string]int)
+	for i, header := range records[0] {
+		if field, ok := fields[normalizeHeader(header)]; ok {
+			if _, seen := index[field]; !seen {
+				index[field] = i
+			}
+		}
+	}
+	if _, ok := index["hostname"]; !ok {
+		return nil, fmt.Errorf("no hostname column in the CSV header")
+	}
+
+	var rows []map[string]string
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(index))
+		for field, i := range index {
+			if i < len(record) {
+				row[field] = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// normalizeHeader keeps the lowercase letters and digits of a CSV header
+func normalizeHeader(header string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, header)
+}
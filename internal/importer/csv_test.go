@@ -0,0 +1,78 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	// The output of sshm list --format csv
+	data := `name,aliases,hostname,user,port,identity_files,proxy_jump,tags,group,description,owner,environment,links,file,system
+web1,www;w1,10.0.0.1,deploy,2222,~/.ssh/a;~/.ssh/b,bastion,web;prod,prod/eu,Web server,platform,production,https://wiki/web,/home/me/.ssh/config,false
+web1,,10.0.0.2,,22,,,,,,,,,/home/me/.ssh/config,false
+global,,10.0.0.3,,22,,,,,,,,,/etc/ssh/ssh_config,true
+`
+	result, err := Parse("csv", []byte(data), Options{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(result.Hosts) != 2 {
+		t.Fatalf("got %d hosts, want the system host left out", len(result.Hosts))
+	}
+
+	web := findHost(t, result, "web1")
+	if !reflect.DeepEqual(web.Patterns, []string{"web1", "www", "w1"}) {
+		t.Errorf("Patterns = %v", web.Patterns)
+	}
+	if web.Port != "2222" || web.ProxyJump != "bastion" || web.Group != "prod/eu" {
+		t.Errorf("web1 = %+v", web)
+	}
+	if got := web.GetAll("IdentityFile"); !reflect.DeepEqual(got, []string{"~/.ssh/a", "~/.ssh/b"}) {
+		t.Errorf("IdentityFile = %v", got)
+	}
+	if !reflect.DeepEqual(web.Tags, []string{"web", "prod"}) || web.Metadata.Owner != "platform" || web.Metadata.Links[0] != "https://wiki/web" {
+		t.Errorf("web1 tags and metadata = %v, %+v", web.Tags, web.Metadata)
+	}
+
+	// A second host of the same name gets a suffix
+	if second := findHost(t, result, "web1-2"); second.Hostname != "10.0.0.2" {
+		t.Errorf("web1-2 = %+v", second)
+	}
+
+	if _, err := Parse("csv", []byte("name,user\nweb,root\n"), Options{}); err == nil {
+		t.Error("Parse() without a hostname column should fail")
+	}
+}
+
+func TestParseTermius(t *testing.T) {
+	data := `Groups,Label,Tags,Hostname/IP,Protocol,Port,Username,SSH Key
+Company/Prod,API server,"api,prod",api.example.com,ssh,22,deploy,~/.ssh/api_key
+,Router,,192.168.1.1,telnet,23,admin,
+,,,10.0.0.7,ssh,,,vault key
+`
+	result, err := Parse("termius-csv", []byte(data), Options{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(result.Hosts) != 2 {
+		t.Fatalf("got %d hosts, want 2", len(result.Hosts))
+	}
+
+	api := findHost(t, result, "API-server")
+	if api.Hostname != "api.example.com" || api.User != "deploy" || api.Identity != "~/.ssh/api_key" || api.Group != "Company/Prod" {
+		t.Errorf("API-server = %+v", api)
+	}
+	if !reflect.DeepEqual(api.Tags, []string{"api", "prod"}) {
+		t.Errorf("Tags = %v", api.Tags)
+	}
+
+	// Hosts without a label are named after their address
+	if host := findHost(t, result, "10.0.0.7"); host.Identity != "" {
+		t.Errorf("10.0.0.7 Identity = %q, want the vault key left out", host.Identity)
+	}
+	warnings := strings.Join(result.Warnings, "\n")
+	if !strings.Contains(warnings, "'Router' uses telnet") || !strings.Contains(warnings, "vault key") {
+		t.Errorf("warnings = %v", result.Warnings)
+	}
+}
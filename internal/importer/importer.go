@@ -0,0 +1,247 @@
+// Package importer reads the sessions saved by other SSH clients and turns
+// them into SSH hosts that can be written to the config.
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/validation"
+)
+
+// FolderMode is how the folders sessions are sorted in become part of a host
+type FolderMode int
+
+const (
+	FoldersAsGroups FolderMode = iota // The folder path becomes the group of the host
+	FoldersAsTags                     // Every folder of the path becomes a tag
+)
+
+// ParseFolderMode reads a folder mode given on the command line
+func ParseFolderMode(mode string) (FolderMode, error) {
+	switch mode {
+	case "group", "groups":
+		return FoldersAsGroups, nil
+	case "tag", "tags":
+		return FoldersAsTags, nil
+	}
+	return 0, fmt.Errorf("unsupported folder mode '%s' (use group or tag)", mode)
+}
+
+// Options controls how sessions are imported
+type Options struct {
+	Folders FolderMode
+}
+
+// Result holds the hosts read from an export
+type Result struct {
+	Hosts []config.SSHHost
+	// Warnings lists the sessions left out and the values that could not be
+	// carried over
+	Warnings []string
+}
+
+// parser reads the sessions of one export format
+type parser func(data []byte, b *builder) error
+
+var parsers = map[string]parser{
+	"ansible":       parseAnsible,
+	"csv":           parseCSV,
+	"mobaxterm-ini": parseMobaXterm,
+	"putty-reg":     parsePuTTY,
+	"termius-csv":   parseTermius,
+}
+
+// Formats returns the supported export formats, sorted
+func Formats() []string {
+	formats := make([]string, 0, len(parsers))
+	for format := range parsers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Parse reads the hosts of an export. Sessions that don't use SSH or lack a
+// hostname are left out with a warning, and hosts whose names collide get a
+// numbered suffix.
+func Parse(format string, data []byte, opts Options) (*Result, error) {
+	parse, ok := parsers[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format '%s' (use %s)", format, strings.Join(Formats(), ", "))
+	}
+
+	b := &builder{opts: opts, names: make(map[string]bool)}
+	if err := parse(decodeText(data), b); err != nil {
+		return nil, err
+	}
+	return &b.result, nil
+}
+
+// decodeText returns an export as UTF-8. Windows tools such as regedit write
+// UTF-16 with a byte order mark.
+func decodeText(data []byte) []byte {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}), bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		bigEndian := data[0] == 0xfe
+		units := make([]uint16, 0, len(data)/2)
+		for i := 2; i+1 < len(data); i += 2 {
+			if bigEndian {
+				units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+			} else {
+				units = append(units, uint16(data[i+1])<<8|uint16(data[i]))
+			}
+		}
+		return []byte(string(utf16.Decode(units)))
+	case bytes.HasPrefix(data, []byte{0xef, 0xbb, 0xbf}):
+		return data[3:]
+	}
+	return data
+}
+
+// session is a connection read from an export, before it becomes a host
+type session struct {
+	name       string
+	aliases    []string
+	hostname   string
+	user       string
+	port       string
+	identities []string
+	proxyJump  string
+	folder     string // Folder path, segments separated by "/"
+	tags       []string
+	metadata   config.HostMetadata
+}
+
+// builder collects the hosts of an export, keeping their names unique
+type builder struct {
+	result Result
+	opts   Options
+	names  map[string]bool // Aliases of the hosts collected so far
+}
+
+// warn records a warning
+func (b *builder) warn(format string, args ...interface{}) {
+	b.result.Warnings = append(b.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+// invalidAliasChars matches the runs of characters a Host alias can't hold
+var invalidAliasChars = regexp.MustCompile(`[\s#*?!,"'=]+`)
+
+// hostAlias turns a session name into a Host alias
+func hostAlias(name string) string {
+	alias := strings.Trim(invalidAliasChars.ReplaceAllString(strings.TrimSpace(name), "-"), "-")
+	if len(alias) > 50 {
+		alias = strings.TrimRight(alias[:50], "-")
+	}
+	return alias
+}
+
+// FreeName returns name, or name followed by the first "-N" suffix, that is
+// not taken
+func FreeName(name string, taken func(string) bool) string {
+	if !taken(name) {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+// add turns a session into a host, or warns about why it is left out
+func (b *builder) add(s session) {
+	label := s.name
+	if label == "" {
+		label = s.hostname
+	}
+
+	name := hostAlias(label)
+	if name == "" || !validation.ValidateHostName(name) {
+		b.warn("session '%s' has no usable name, skipped", label)
+		return
+	}
+	if s.hostname == "" {
+		b.warn("session '%s' has no hostname, skipped", label)
+		return
+	}
+	if !validation.ValidateHostname(s.hostname) && !validation.ValidateIP(s.hostname) {
+		b.warn("session '%s': invalid hostname '%s', skipped", label, s.hostname)
+		return
+	}
+	if !validation.ValidatePort(s.port) {
+		b.warn("session '%s': invalid port '%s', skipped", label, s.port)
+		return
+	}
+
+	name = FreeName(name, func(n string) bool { return b.names[n] })
+	patterns := []string{name}
+	for _, alias := range s.aliases {
+		if alias = hostAlias(alias); alias != "" && alias != name && !b.names[alias] {
+			patterns = append(patterns, alias)
+		}
+	}
+	for _, alias := range patterns {
+		b.names[alias] = true
+	}
+
+	host := config.SSHHost{Name: name, Aliases: patterns, Patterns: patterns, Metadata: s.metadata}
+	host.Set("HostName", s.hostname)
+	host.Set("User", s.user)
+	if s.port != "22" {
+		host.Set("Port", s.port)
+	}
+	var identities []string
+	for _, identity := range s.identities {
+		identities = append(identities, config.QuoteArg(identity))
+	}
+	if len(identities) > 0 {
+		host.SetAll("IdentityFile", identities)
+	}
+	host.Set("ProxyJump", s.proxyJump)
+
+	folder := config.NormalizeGroupPath(strings.ReplaceAll(s.folder, `\`, "/"))
+	tags := s.tags
+	if folder != "" {
+		if b.opts.Folders == FoldersAsTags {
+			tags = append(tags, strings.Split(folder, "/")...)
+		} else {
+			host.Group = folder
+		}
+	}
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" && config.ValidateTag(tag) == nil && !containsTag(host.Tags, tag) {
+			host.Tags = append(host.Tags, tag)
+		}
+	}
+
+	b.result.Hosts = append(b.result.Hosts, host)
+}
+
+// containsTag reports whether a tag is listed, ignoring case
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList splits a list written in one field on commas or semicolons
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
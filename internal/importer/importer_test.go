@@ -0,0 +1,63 @@
+package importer
+
+import (
+	"testing"
+	"unicode/utf16"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// findHost returns an imported host by name
+func findHost(t *testing.T, result *Result, name string) config.SSHHost {
+	t.Helper()
+	for _, host := range result.Hosts {
+		if host.Name == name {
+			return host
+		}
+	}
+	t.Fatalf("host %s not imported, got %d hosts and warnings %v", name, len(result.Hosts), result.Warnings)
+	return config.SSHHost{}
+}
+
+func TestHostAlias(t *testing.T) {
+	tests := map[string]string{
+		"web1":               "web1",
+		"  My Server (prod)": "My-Server-(prod)",
+		"db, primary #1":     "db-primary-1",
+		"*?!":                "",
+	}
+	for name, want := range tests {
+		if got := hostAlias(name); got != want {
+			t.Errorf("hostAlias(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestFreeName(t *testing.T) {
+	taken := map[string]bool{"web": true, "web-2": true}
+	if got := FreeName("web", func(name string) bool { return taken[name] }); got != "web-3" {
+		t.Errorf("FreeName(web) = %q, want web-3", got)
+	}
+	if got := FreeName("db", func(name string) bool { return taken[name] }); got != "db" {
+		t.Errorf("FreeName(db) = %q, want db", got)
+	}
+}
+
+func TestDecodeText(t *testing.T) {
+	data := []byte{0xff, 0xfe}
+	for _, unit := range utf16.Encode([]rune("héllo")) {
+		data = append(data, byte(unit), byte(unit>>8))
+	}
+	if got := string(decodeText(data)); got != "héllo" {
+		t.Errorf("decodeText(UTF-16LE) = %q, want héllo", got)
+	}
+	if got := string(decodeText([]byte("\xef\xbb\xbfabc"))); got != "abc" {
+		t.Errorf("decodeText(UTF-8 BOM) = %q, want abc", got)
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	if _, err := Parse("securecrt", nil, Options{}); err == nil {
+		t.Error("Parse(securecrt) should fail")
+	}
+}
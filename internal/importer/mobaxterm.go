@@ -0,0 +1,122 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// mobaXtermSSH is the session type MobaXterm writes for SSH bookmarks
+const mobaXtermSSH = "109"
+
+// Positions of the values of an SSH bookmark, among the "%"-separated fields
+// following its type
+const (
+	mobaHost       = 1
+	mobaPort       = 2
+	mobaUser       = 3
+	mobaJumpHost   = 8
+	mobaJumpPort   = 9
+	mobaJumpUser   = 10
+	mobaKey        = 14
+	mobaFieldCount = 15
+)
+
+// mobaXtermEscapes are the sequences MobaXterm writes in place of characters
+// that separate its fields
+var mobaXtermEscapes = strings.NewReplacer(
+	"__PERCENT__", "%",
+	"__DIEZE__", "#",
+	"__PTVIRG__", ";",
+	"__DBLQUO__", `"`,
+	"__PIPE__", "|",
+)
+
+// parseMobaXterm reads the bookmarks of a MobaXterm.ini file or of a
+// .mxtsessions export. Bookmark folders ("SubRep") are kept as folders.
+func parseMobaXterm(data []byte, b *builder) error {
+	inBookmarks := false
+	folder := ""
+	skipped := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			section := strings.ToLower(strings.Trim(line, "[]"))
+			inBookmarks = section == "bookmarks" || strings.HasPrefix(section, "bookmarks_")
+			folder = ""
+			continue
+		}
+		if !inBookmarks {
+			continue
+		}
+
+		name, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		switch name {
+		case "SubRep":
+			folder = mobaXtermEscapes.Replace(value)
+			continue
+		case "ImgNum":
+			continue
+		}
+
+		// A bookmark is "#type#settings#terminal#..."
+		parts := strings.Split(value, "#")
+		if len(parts) < 3 || parts[0] != "" {
+			continue
+		}
+		if parts[1] != mobaXtermSSH {
+			skipped++
+			continue
+		}
+
+		fields := strings.Split(parts[2], "%")
+		for len(fields) < mobaFieldCount {
+			fields = append(fields, "")
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(mobaXtermEscapes.Replace(fields[i]))
+		}
+
+		name = mobaXtermEscapes.Replace(name)
+		s := session{
+			name:     name,
+			hostname: fields[mobaHost],
+			user:     fields[mobaUser],
+			port:     fields[mobaPort],
+			folder:   folder,
+		}
+		if key := fields[mobaKey]; key != "" {
+			if strings.HasPrefix(key, `_ProfileDir_\`) {
+				key = "~/" + strings.ReplaceAll(strings.TrimPrefix(key, `_ProfileDir_\`), `\`, "/")
+			}
+			if strings.Contains(key, "_CurrentDrive_") {
+				b.warn("session '%s': key %s is relative to the drive MobaXterm runs from", name, key)
+			}
+			s.identities = []string{key}
+		}
+		if jump := fields[mobaJumpHost]; jump != "" {
+			if user := fields[mobaJumpUser]; user != "" {
+				jump = user + "@" + jump
+			}
+			if port := fields[mobaJumpPort]; port != "" && port != "22" {
+				jump += ":" + port
+			}
+			s.proxyJump = jump
+		}
+		b.add(s)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if skipped > 0 {
+		b.warn("%d bookmark(s) of other session types than SSH skipped", skipped)
+	}
+	return nil
+}
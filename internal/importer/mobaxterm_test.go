@@ -0,0 +1,58 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const mobaXtermExport = `[Bookmarks]
+SubRep=
+ImgNum=42
+web1=#109#0%10.0.0.1%22%root%%-1%-1%%%22%%0%0%0%%%-1%0%0%0%%1080%%0%0%1#MobaFont%10%0%0%0%15%236,236,236%0,0,0%180,180,180%0%-1%0%%xterm%-1%-1%_Std_Colors_0_%80%24%0%1%-1%<none>%%0#0# #-1
+
+[Bookmarks_1]
+SubRep=Work\Prod
+ImgNum=41
+db one=#109#0%10.0.1.1%2222%admin%%-1%-1%%bastion%2200%jump%0%0%0%_ProfileDir_\.ssh\id_ed25519%%-1%0%0%0%%1080%%0%0%1#MobaFont%10#0# #-1
+desktop=#91#4%10.0.2.2%3389%%-1%0%0%0%-1%0%0%-1#MobaFont%10#0# #-1
+`
+
+func TestParseMobaXterm(t *testing.T) {
+	result, err := Parse("mobaxterm-ini", []byte(mobaXtermExport), Options{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(result.Hosts) != 2 {
+		t.Fatalf("got %d hosts, want 2", len(result.Hosts))
+	}
+
+	web := findHost(t, result, "web1")
+	if web.Hostname != "10.0.0.1" || web.User != "root" || web.Port != "" || web.Group != "" {
+		t.Errorf("web1 = %+v", web)
+	}
+
+	db := findHost(t, result, "db-one")
+	if db.Hostname != "10.0.1.1" || db.User != "admin" || db.Port != "2222" {
+		t.Errorf("db-one = %+v", db)
+	}
+	if db.ProxyJump != "jump@bastion:2200" || db.Identity != "~/.ssh/id_ed25519" {
+		t.Errorf("db-one ProxyJump = %q, Identity = %q", db.ProxyJump, db.Identity)
+	}
+	if db.Group != "Work/Prod" {
+		t.Errorf("Group = %q, want Work/Prod", db.Group)
+	}
+	if !strings.Contains(strings.Join(result.Warnings, "\n"), "1 bookmark(s)") {
+		t.Errorf("warnings = %v, want the RDP bookmark skipped", result.Warnings)
+	}
+
+	// Folders become tags on request
+	result, err = Parse("mobaxterm-ini", []byte(mobaXtermExport), Options{Folders: FoldersAsTags})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	db = findHost(t, result, "db-one")
+	if db.Group != "" || !reflect.DeepEqual(db.Tags, []string{"Work", "Prod"}) {
+		t.Errorf("db-one Group = %q, Tags = %v, want tags Work and Prod", db.Group, db.Tags)
+	}
+}
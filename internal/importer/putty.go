@@ -0,0 +1,138 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// puttySessionsKey is the registry key PuTTY keeps its saved sessions under
+const puttySessionsKey = `\software\simontatham\putty\sessions\`
+
+// puttySSHProxy is the ProxyMethod of sessions connecting through another
+// SSH server, available since PuTTY 0.77
+const puttySSHProxy = 6
+
+// parsePuTTY reads the sessions of a registry export made with
+// reg export HKCU\Software\SimonTatham\PuTTY\Sessions putty.reg
+func parsePuTTY(data []byte, b *builder) error {
+	var names []string
+	sessions := make(map[string]map[string]string)
+	var current map[string]string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "["):
+			current = nil
+			key := strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
+			i := strings.Index(strings.ToLower(key), puttySessionsKey)
+			if i < 0 {
+				continue
+			}
+			name := key[i+len(puttySessionsKey):]
+			if unescaped, err := url.PathUnescape(name); err == nil {
+				name = unescaped
+			}
+			if name == "" || strings.Contains(name, `\`) || name == "Default Settings" {
+				continue
+			}
+			if _, seen := sessions[name]; !seen {
+				names = append(names, name)
+				sessions[name] = make(map[string]string)
+			}
+			current = sessions[name]
+
+		case strings.HasPrefix(line, `"`) && current != nil:
+			key, value, ok := parseRegistryValue(line)
+			if ok {
+				current[key] = value
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		values := sessions[name]
+		if protocol := values["Protocol"]; protocol != "" && protocol != "ssh" {
+			b.warn("session '%s' uses %s rather than ssh, skipped", name, protocol)
+			continue
+		}
+
+		s := session{name: name, hostname: values["HostName"], user: values["UserName"], port: values["PortNumber"]}
+		if user, host, found := strings.Cut(s.hostname, "@"); found {
+			s.user, s.hostname = user, host
+		}
+		if key := values["PublicKeyFile"]; key != "" {
+			s.identities = []string{key}
+			if strings.HasSuffix(strings.ToLower(key), ".ppk") {
+				b.warn("session '%s': convert %s to the OpenSSH format with puttygen", name, key)
+			}
+		}
+
+		switch method, _ := strconv.Atoi(values["ProxyMethod"]); {
+		case method == puttySSHProxy && values["ProxyHost"] != "":
+			s.proxyJump = values["ProxyHost"]
+			if user := values["ProxyUsername"]; user != "" {
+				s.proxyJump = user + "@" + s.proxyJump
+			}
+			if port := values["ProxyPort"]; port != "" && port != "22" {
+				s.proxyJump += ":" + port
+			}
+		case method != 0:
+			b.warn("session '%s': its proxy is not carried over", name)
+		}
+
+		b.add(s)
+	}
+	return nil
+}
+
+// parseRegistryValue reads a "Name"="value" or "Name"=dword:0000001 line of
+// a registry export. DWORD values are returned in decimal.
+func parseRegistryValue(line string) (string, string, bool) {
+	key, rest, ok := readRegistryString(line)
+	if !ok || !strings.HasPrefix(rest, "=") {
+		return "", "", false
+	}
+	rest = rest[1:]
+
+	if strings.HasPrefix(rest, "dword:") {
+		n, err := strconv.ParseUint(strings.TrimPrefix(rest, "dword:"), 16, 32)
+		if err != nil {
+			return "", "", false
+		}
+		return key, strconv.FormatUint(n, 10), true
+	}
+	value, _, ok := readRegistryString(rest)
+	return key, value, ok
+}
+
+// readRegistryString reads a quoted string with backslash escapes and returns
+// what follows it
+func readRegistryString(s string) (string, string, bool) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", "", false
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case '"':
+			return b.String(), s[i+1:], true
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", "", false
+}
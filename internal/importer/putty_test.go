@@ -0,0 +1,65 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+const puttyExport = `Windows Registry Editor Version 5.00
+
+[HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions]
+
+[HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions\Default%20Settings]
+"HostName"=""
+
+[HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions\Web%20Server]
+"HostName"="deploy@web.example.com"
+"PortNumber"=dword:00000016
+"Protocol"="ssh"
+"PublicKeyFile"="C:\\Users\\me\\.ssh\\web.ppk"
+"ProxyMethod"=dword:00000006
+"ProxyHost"="bastion.example.com"
+"ProxyPort"=dword:00000899
+"ProxyUsername"="jump"
+
+[HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions\db]
+"HostName"="10.0.0.5"
+"UserName"="admin"
+"PortNumber"=dword:00000870
+"Protocol"="ssh"
+
+[HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions\switch]
+"HostName"="10.0.0.9"
+"Protocol"="telnet"
+`
+
+func TestParsePuTTY(t *testing.T) {
+	result, err := Parse("putty-reg", []byte(strings.ReplaceAll(puttyExport, "\n", "\r\n")), Options{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(result.Hosts) != 2 {
+		t.Fatalf("got %d hosts, want 2", len(result.Hosts))
+	}
+
+	web := findHost(t, result, "Web-Server")
+	if web.Hostname != "web.example.com" || web.User != "deploy" || web.Port != "" {
+		t.Errorf("Web-Server = %+v", web)
+	}
+	if web.Identity != `C:\Users\me\.ssh\web.ppk` {
+		t.Errorf("Identity = %q", web.Identity)
+	}
+	if web.ProxyJump != "jump@bastion.example.com:2201" {
+		t.Errorf("ProxyJump = %q, want jump@bastion.example.com:2201", web.ProxyJump)
+	}
+
+	db := findHost(t, result, "db")
+	if db.Hostname != "10.0.0.5" || db.User != "admin" || db.Port != "2160" {
+		t.Errorf("db = %+v", db)
+	}
+
+	warnings := strings.Join(result.Warnings, "\n")
+	if !strings.Contains(warnings, "puttygen") || !strings.Contains(warnings, "'switch' uses telnet") {
+		t.Errorf("warnings = %v, want the .ppk key and the telnet session", result.Warnings)
+	}
+}
@@ -0,0 +1,216 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/validation"
+)
+
+// Action is what an import does with a host
+type Action int
+
+const (
+	ActionImport    Action = iota // Write the new host
+	ActionSkip                    // Leave the host out
+	ActionRename                  // Write the host under another name
+	ActionOverwrite               // Replace the existing host of the same name
+	ActionUpdate                  // Merge the host into the existing one, where it is
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionSkip:
+		return "skip"
+	case ActionRename:
+		return "rename"
+	case ActionOverwrite:
+		return "overwrite"
+	case ActionUpdate:
+		return "update"
+	default:
+		return "import"
+	}
+}
+
+// ParseConflictAction reads what to do with conflicting hosts, as given on
+// the command line
+func ParseConflictAction(action string) (Action, error) {
+	switch action {
+	case "skip":
+		return ActionSkip, nil
+	case "rename":
+		return ActionRename, nil
+	case "overwrite":
+		return ActionOverwrite, nil
+	case "update":
+		return ActionUpdate, nil
+	}
+	return 0, fmt.Errorf("unsupported conflict action '%s' (use skip, rename, overwrite or update)", action)
+}
+
+// Candidate is an imported host with what to do with it
+type Candidate struct {
+	Host config.SSHHost
+	// Existing is the host of the config using the same name, nil when the
+	// imported host is new
+	Existing *config.SSHHost
+	Action   Action
+	Name     string // Name to write the host under, the new one when renamed
+}
+
+// Conflict reports whether a host of the config already uses the name
+func (c Candidate) Conflict() bool {
+	return c.Existing != nil
+}
+
+// CanOverwrite reports whether the existing host may be replaced or updated.
+// Hosts of the system-wide config are read-only.
+func (c Candidate) CanOverwrite() bool {
+	return c.Existing != nil && !c.Existing.System
+}
+
+// Resolve pairs imported hosts with the hosts of the config they conflict
+// with. New hosts are imported; conflicting ones take the given action,
+// falling back to skip for hosts that can't be overwritten.
+func Resolve(hosts, existing []config.SSHHost, onConflict Action) []Candidate {
+	candidates := make([]Candidate, 0, len(hosts))
+	for _, host := range hosts {
+		c := Candidate{Host: host, Name: host.Name}
+		for i := range existing {
+			for _, alias := range host.Aliases {
+				if c.Existing == nil && existing[i].HasAlias(alias) {
+					c.Existing = &existing[i]
+				}
+			}
+		}
+		candidates = append(candidates, c)
+	}
+
+	for i := range candidates {
+		if !candidates[i].Conflict() {
+			continue
+		}
+		switch {
+		case onConflict == ActionRename:
+			candidates[i].Action = ActionRename
+			candidates[i].Name = FreeName(candidates[i].Host.Name, func(name string) bool {
+				return NameTaken(name, existing, candidates, i)
+			})
+		case (onConflict == ActionOverwrite || onConflict == ActionUpdate) && candidates[i].CanOverwrite():
+			candidates[i].Action = onConflict
+		default:
+			candidates[i].Action = ActionSkip
+		}
+	}
+	return candidates
+}
+
+// NameTaken reports whether a name is used by a host of the config or by
+// another candidate written to the config
+func NameTaken(name string, existing []config.SSHHost, candidates []Candidate, self int) bool {
+	for _, host := range existing {
+		if host.HasAlias(name) {
+			return true
+		}
+	}
+	for i, c := range candidates {
+		if i == self || c.Action == ActionSkip {
+			continue
+		}
+		if c.Name == name || (c.Name == c.Host.Name && c.Host.HasAlias(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateRename checks a new name for a candidate
+func ValidateRename(name string, existing []config.SSHHost, candidates []Candidate, self int) error {
+	if !validation.ValidateHostName(name) {
+		return fmt.Errorf("invalid host name: cannot contain spaces or special characters")
+	}
+	if NameTaken(name, existing, candidates, self) {
+		return fmt.Errorf("host '%s' %w", name, config.ErrHostExists)
+	}
+	return nil
+}
+
+// Write adds the hosts of the candidates that are not skipped to a config
+// file, replacing the hosts they overwrite and updating the hosts they are
+// merged into, as a single change. It returns the number of hosts written.
+func Write(candidates []Candidate, targetConfigFile string) (int, error) {
+	var hosts, replace, update []config.SSHHost
+	for _, c := range candidates {
+		host := c.Host
+		switch c.Action {
+		case ActionSkip:
+			continue
+		case ActionUpdate:
+			update = append(update, Merge(*c.Existing, c.Host))
+			continue
+		case ActionOverwrite:
+			replace = append(replace, *c.Existing)
+		case ActionRename:
+			// Other aliases of the imported host are dropped with its name
+			host.Name = c.Name
+			host.Aliases = []string{c.Name}
+			host.Patterns = []string{c.Name}
+		}
+		hosts = append(hosts, host)
+	}
+	if len(hosts) == 0 && len(update) == 0 {
+		return 0, nil
+	}
+	if err := config.ImportHosts(hosts, replace, update, targetConfigFile); err != nil {
+		return 0, err
+	}
+	return len(hosts) + len(update), nil
+}
+
+// Merge returns an existing host with the values set on an imported one.
+// Values the import leaves empty are kept, and so are the tags when the
+// imported host has none.
+func Merge(existing, imported config.SSHHost) config.SSHHost {
+	host := existing
+	host.Directives = append([]config.Directive(nil), existing.Directives...)
+	for _, key := range []string{"HostName", "User", "Port", "ProxyJump"} {
+		if value := imported.Get(key); value != "" {
+			host.Set(key, value)
+		}
+	}
+	if identities := imported.GetAll("IdentityFile"); len(identities) > 0 {
+		host.SetAll("IdentityFile", identities)
+	}
+	if imported.Group != "" {
+		host.Group = imported.Group
+	}
+	if len(imported.Tags) > 0 {
+		host.Tags = append([]string(nil), imported.Tags...)
+	}
+	return host
+}
+
+// Summary counts what an import does
+func Summary(candidates []Candidate) string {
+	counts := make(map[Action]int)
+	for _, c := range candidates {
+		counts[c.Action]++
+	}
+	return fmt.Sprintf("%d to import, %d renamed, %d to overwrite, %d to update, %d skipped",
+		counts[ActionImport], counts[ActionRename], counts[ActionOverwrite], counts[ActionUpdate], counts[ActionSkip])
+}
+
+// Endpoint describes where a host connects to, as user@hostname:port
+func Endpoint(host config.SSHHost) string {
+	var b strings.Builder
+	if host.User != "" {
+		b.WriteString(host.User + "@")
+	}
+	b.WriteString(host.Hostname)
+	if host.Port != "" && host.Port != "22" {
+		b.WriteString(":" + host.Port)
+	}
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// importedHost builds an imported host
+func importedHost(name, hostname string) config.SSHHost {
+	host := config.SSHHost{Name: name, Aliases: []string{name}, Patterns: []string{name}}
+	host.Set("HostName", hostname)
+	return host
+}
+
+func TestResolve(t *testing.T) {
+	existing := []config.SSHHost{
+		{Name: "web", Aliases: []string{"web", "www"}},
+		{Name: "global", Aliases: []string{"global"}, System: true},
+	}
+	hosts := []config.SSHHost{importedHost("www", "10.0.0.1"), importedHost("global", "10.0.0.2"), importedHost("api", "10.0.0.3")}
+
+	candidates := Resolve(hosts, existing, ActionOverwrite)
+	if candidates[0].Action != ActionOverwrite || candidates[0].Existing.Name != "web" {
+		t.Errorf("www = %+v, want web overwritten", candidates[0])
+	}
+	if candidates[1].Action != ActionSkip {
+		t.Errorf("global action = %s, want system hosts skipped", candidates[1].Action)
+	}
+	if candidates[2].Action != ActionImport || candidates[2].Conflict() {
+		t.Errorf("api = %+v, want a new host", candidates[2])
+	}
+
+	candidates = Resolve(hosts, existing, ActionRename)
+	if candidates[0].Name != "www-2" || candidates[1].Name != "global-2" {
+		t.Errorf("names = %s, %s, want www-2 and global-2", candidates[0].Name, candidates[1].Name)
+	}
+	if err := ValidateRename("api", existing, candidates, 0); err == nil {
+		t.Error("ValidateRename(api) should fail, another imported host uses it")
+	}
+	if err := ValidateRename("new name", existing, candidates, 0); err == nil {
+		t.Error("ValidateRename(new name) should fail")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("Host web\n    HostName 10.0.9.9\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	parsed, err := config.ParseConfigTree(path)
+	if err != nil {
+		t.Fatalf("ParseConfigTree() error = %v", err)
+	}
+
+	hosts := []config.SSHHost{importedHost("web", "10.0.0.1"), importedHost("api", "10.0.0.2")}
+	candidates := Resolve(hosts, parsed.Hosts, ActionRename)
+	written, err := Write(candidates, path)
+	if err != nil || written != 2 {
+		t.Fatalf("Write() = %d, %v", written, err)
+	}
+	data, _ := os.ReadFile(path)
+	want := "Host web\n    HostName 10.0.9.9\n\nHost web-2\n    HostName 10.0.0.1\n\nHost api\n    HostName 10.0.0.2\n"
+	if string(data) != want {
+		t.Errorf("config = %q, want %q", data, want)
+	}
+
+	// Overwriting replaces the existing host
+	if parsed, err = config.ParseConfigTree(path); err != nil {
+		t.Fatalf("ParseConfigTree() error = %v", err)
+	}
+	candidates = Resolve(hosts[:1], parsed.Hosts, ActionOverwrite)
+	if _, err := Write(candidates, path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "10.0.9.9") || strings.Count(string(data), "Host web\n") != 1 {
+		t.Errorf("config = %q, want web replaced", data)
+	}
+	// Updating changes the values set on the imported host and its tags,
+	// keeping the others
+	if parsed, err = config.ParseConfigTree(path); err != nil {
+		t.Fatalf("ParseConfigTree() error = %v", err)
+	}
+	update := config.SSHHost{Name: "api", Aliases: []string{"api"}, Patterns: []string{"api"}, Tags: []string{"prod"}}
+	update.Set("User", "deploy")
+	candidates = Resolve([]config.SSHHost{update}, parsed.Hosts, ActionUpdate)
+	if candidates[0].Action != ActionUpdate {
+		t.Fatalf("api action = %s, want update", candidates[0].Action)
+	}
+	if written, err := Write(candidates, path); err != nil || written != 1 {
+		t.Fatalf("Write() = %d, %v", written, err)
+	}
+	data, _ = os.ReadFile(path)
+	if !strings.Contains(string(data), "# Tags: prod\nHost api\n    HostName 10.0.0.2\n    User deploy\n") {
+		t.Errorf("config = %q, want api updated", data)
+	}
+}
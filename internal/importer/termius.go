@@ -0,0 +1,56 @@
+package importer
+
+import "strings"
+
+// termiusCSVColumns maps fields to the headers of a Termius CSV export
+var termiusCSVColumns = map[string][]string{
+	"name":       {"Label", "Alias"},
+	"hostname":   {"Hostname/IP", "Hostname", "Address"},
+	"protocol":   {"Protocol"},
+	"port":       {"Port"},
+	"user":       {"Username"},
+	"key":        {"SSH Key", "Key"},
+	"group":      {"Groups", "Group"},
+	"tags":       {"Tags"},
+	"proxy_jump": {"Host Chaining", "Jump Host"},
+}
+
+// parseTermius reads a CSV export of Termius hosts. Groups are nested with
+// slashes. Keys stored in the Termius vault can't be exported this way, so
+// only key columns holding a path are carried over.
+func parseTermius(data []byte, b *builder) error {
+	rows, err := readCSVTable(data, termiusCSVColumns)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		label := row["name"]
+		if label == "" {
+			label = row["hostname"]
+		}
+		if protocol := strings.ToLower(row["protocol"]); protocol != "" && protocol != "ssh" {
+			b.warn("session '%s' uses %s rather than ssh, skipped", label, protocol)
+			continue
+		}
+
+		s := session{
+			name:      row["name"],
+			hostname:  row["hostname"],
+			user:      row["user"],
+			port:      row["port"],
+			proxyJump: row["proxy_jump"],
+			folder:    row["group"],
+			tags:      splitList(row["tags"]),
+		}
+		if key := row["key"]; key != "" {
+			if strings.ContainsAny(key, `/\`) {
+				s.identities = []string{key}
+			} else {
+				b.warn("session '%s': key '%s' is kept in Termius, export it and set IdentityFile", label, key)
+			}
+		}
+		b.add(s)
+	}
+	return nil
+}
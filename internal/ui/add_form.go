@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"fmt"
 	"os"
 	"os/user"
 	"path/filepath"
@@ -9,12 +10,14 @@ import (
 	"github.com/Gu1llaum-3/sshm/internal/config"
 	"github.com/Gu1llaum-3/sshm/internal/validation"
 
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type addFormModel struct {
 	inputs     []textinput.Model
+	notes      textarea.Model // Multi-line input shown at notesInput
 	focused    int
 	err        string
 	styles     Styles
@@ -22,6 +25,10 @@ type addFormModel struct {
 	width      int
 	height     int
 	configFile string
+	pattern    bool     // Adding a wildcard pattern block instead of a host
+	knownTags  []string // Tags used in the config, to complete the tags input
+	templates  map[string]config.HostTemplate
+	template   string // Template the form was filled from, if any
 }
 
 // NewAddForm creates a new add form model
@@ -47,7 +54,7 @@ func NewAddForm(hostname string, styles Styles, width, height int, configFile st
 		}
 	}
 
-	inputs := make([]textinput.Model, 8)
+	inputs := make([]textinput.Model, formInputCount)
 
 	// Name input
 	inputs[nameInput] = textinput.New()
@@ -95,20 +102,113 @@ func NewAddForm(hostname string, styles Styles, width, height int, configFile st
 	inputs[optionsInput].CharLimit = 500
 	inputs[optionsInput].Width = 70
 
+	// Metadata inputs
+	setupMetadataInputs(inputs, config.HostMetadata{})
+
+	// Group input
+	inputs[groupInput] = textinput.New()
+	inputs[groupInput].Placeholder = "prod/eu/db"
+	inputs[groupInput].CharLimit = 200
+	inputs[groupInput].Width = 50
+
 	// Tags input
 	inputs[tagsInput] = textinput.New()
 	inputs[tagsInput].Placeholder = "production, web, database"
 	inputs[tagsInput].CharLimit = 200
 	inputs[tagsInput].Width = 50
+	knownTags := loadKnownTags(configFile)
+	setupTagInput(&inputs[tagsInput], knownTags)
+
+	// Templates are optional, the form works without them
+	templates, _ := config.LoadHostTemplates()
 
 	return &addFormModel{
 		inputs:     inputs,
+		notes:      newNotesArea(""),
 		focused:    nameInput,
 		styles:     styles,
 		width:      width,
 		height:     height,
 		configFile: configFile,
+		knownTags:  knownTags,
+		templates:  templates,
+	}
+}
+
+// useTemplate fills the form with the values of a template, or clears them
+// when name is empty. Variables are kept as written and expanded when the
+// host is saved, once its name is known.
+func (m *addFormModel) useTemplate(name string) error {
+	var t config.HostTemplate
+	if name != "" {
+		var ok bool
+		if t, ok = m.templates[name]; !ok {
+			return fmt.Errorf("template '%s' not found", name)
+		}
+	}
+	m.template = name
+
+	m.inputs[hostnameInput].SetValue(t.HostName)
+	m.inputs[userInput].SetValue(t.User)
+	m.inputs[portInput].SetValue(t.Port)
+	m.inputs[identityInput].SetValue(t.IdentityFile)
+	m.inputs[proxyJumpInput].SetValue(t.ProxyJump)
+	m.inputs[optionsInput].SetValue(t.Options)
+	m.inputs[descriptionInput].SetValue(t.Description)
+	m.inputs[ownerInput].SetValue(t.Owner)
+	m.inputs[environmentInput].SetValue(t.Environment)
+	m.inputs[linksInput].SetValue(strings.Join(t.Links, ", "))
+	m.inputs[groupInput].SetValue(t.Group)
+	m.inputs[tagsInput].SetValue(strings.Join(t.Tags, ", "))
+	updateTagSuggestions(&m.inputs[tagsInput], m.knownTags)
+	return nil
+}
+
+// nextTemplate fills the form with the template following the current one,
+// going back to an empty form after the last
+func (m *addFormModel) nextTemplate() {
+	names := config.TemplateNames(m.templates)
+	next := ""
+	for i, name := range names {
+		if name == m.template && i+1 < len(names) {
+			next = names[i+1]
+		}
+	}
+	if m.template == "" && len(names) > 0 {
+		next = names[0]
 	}
+	_ = m.useTemplate(next)
+}
+
+// expandTemplateInputs returns a copy of the form inputs with the template
+// variables replaced, from the host name and environment typed in the form
+func expandTemplateInputs(inputs []textinput.Model) ([]textinput.Model, error) {
+	vars := config.TemplateVars(
+		strings.TrimSpace(inputs[nameInput].Value()),
+		strings.TrimSpace(inputs[environmentInput].Value()),
+	)
+	expanded := append([]textinput.Model(nil), inputs...)
+	for i := range expanded {
+		if i == nameInput || i == notesInput {
+			continue
+		}
+		value, err := config.ExpandTemplateVars(expanded[i].Value(), vars)
+		if err != nil {
+			return nil, err
+		}
+		expanded[i].SetValue(value)
+	}
+	return expanded, nil
+}
+
+// NewPatternAddForm creates an add form for a wildcard pattern block such as "Host *.internal"
+func NewPatternAddForm(styles Styles, width, height int, configFile string) *addFormModel {
+	m := NewAddForm("", styles, width, height, configFile)
+	m.pattern = true
+	m.inputs[nameInput].Placeholder = "*.internal"
+	m.inputs[hostnameInput].Placeholder = "%h.example.com"
+	m.inputs[userInput].Placeholder = ""
+	return m
 }
 
 const (
@@ -119,7 +219,14 @@ const (
 	identityInput
 	proxyJumpInput
 	optionsInput
+	descriptionInput
+	ownerInput
+	environmentInput
+	linksInput
+	notesInput // Edited in a textarea rather than a text input
+	groupInput
 	tagsInput
+	formInputCount
 )
 
 // Messages for communication with parent model
@@ -153,9 +260,20 @@ func (m *addFormModel) Update(msg tea.Msg) (*addFormModel, tea.Cmd) {
 			// Allow submission from any field with Ctrl+S (Save)
 			return m, m.submitForm()
 
+		case "ctrl+t":
+			if !m.pattern && len(m.templates) > 0 {
+				m.nextTemplate()
+				return m, nil
+			}
+
 		case "tab", "shift+tab", "enter", "up", "down":
 			s := msg.String()
 
+			// Enter and arrows move inside the notes, only Tab leaves them
+			if m.focused == notesInput && s != "tab" && s != "shift+tab" {
+				break
+			}
+
 			// Handle form submission
 			if s == "enter" && m.focused == len(m.inputs)-1 {
 				return m, m.submitForm()
@@ -174,14 +292,7 @@ func (m *addFormModel) Update(msg tea.Msg) (*addFormModel, tea.Cmd) {
 				m.focused = len(m.inputs) - 1
 			}
 
-			for i := range m.inputs {
-				if i == m.focused {
-					cmds = append(cmds, m.inputs[i].Focus())
-					continue
-				}
-				m.inputs[i].Blur()
-			}
-
+			cmds = append(cmds, focusFormInput(m.inputs, &m.notes, m.focused))
 			return m, tea.Batch(cmds...)
 		}
 
@@ -197,11 +308,8 @@ func (m *addFormModel) Update(msg tea.Msg) (*addFormModel, tea.Cmd) {
 	}
 
 	// Update inputs
-	cmd := make([]tea.Cmd, len(m.inputs))
-	for i := range m.inputs {
-		m.inputs[i], cmd[i] = m.inputs[i].Update(msg)
-	}
-	cmds = append(cmds, cmd...)
+	cmds = append(cmds, updateFormInputs(m.inputs, &m.notes, msg)...)
+	updateTagSuggestions(&m.inputs[tagsInput], m.knownTags)
 
 	return m, tea.Batch(cmds...)
 }
@@ -213,24 +321,28 @@ func (m *addFormModel) View() string {
 
 	var b strings.Builder
 
-	b.WriteString(m.styles.FormTitle.Render("Add SSH Host Configuration"))
+	title := "Add SSH Host Configuration"
+	if m.pattern {
+		title = "Add Host Pattern"
+	}
+	b.WriteString(m.styles.FormTitle.Render(title))
 	b.WriteString("\n\n")
 
-	fields := []string{
-		"Host Name *",
-		"Hostname/IP *",
-		"User",
-		"Port",
-		"Identity File",
-		"ProxyJump",
-		"SSH Options",
-		"Tags (comma-separated)",
+	if !m.pattern && len(m.templates) > 0 {
+		template := "none"
+		if m.template != "" {
+			template = m.template + " ({{name}} and {{env}} are replaced when saving)"
+		}
+		b.WriteString(m.styles.FormHelp.Render("Template: " + template + " • Ctrl+T: next template"))
+		b.WriteString("\n\n")
 	}
 
+	fields := formFieldLabels(m.pattern)
+
 	for i, field := range fields {
 		b.WriteString(m.styles.FormField.Render(field))
 		b.WriteString("\n")
-		b.WriteString(m.inputs[i].View())
+		b.WriteString(formInputView(m.inputs, m.notes, i))
 		b.WriteString("\n\n")
 	}
 
@@ -272,8 +384,19 @@ func (m standaloneAddForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // RunAddForm provides backward compatibility for standalone add form
 func RunAddForm(hostname string, configFile string) error {
+	return RunAddFormFromTemplate(hostname, "", configFile)
+}
+
+// RunAddFormFromTemplate runs the standalone add form, filled from a host
+// template when templateName is set
+func RunAddFormFromTemplate(hostname, templateName, configFile string) error {
 	styles := NewStyles(80)
 	addForm := NewAddForm(hostname, styles, 80, 24, configFile)
+	if templateName != "" {
+		if err := addForm.useTemplate(templateName); err != nil {
+			return err
+		}
+	}
 	m := standaloneAddForm{addForm}
 
 	p := tea.NewProgram(m, tea.WithAltScreen())
@@ -283,30 +406,45 @@ func RunAddForm(hostname string, configFile string) error {
 
 func (m *addFormModel) submitForm() tea.Cmd {
 	return func() tea.Msg {
-		// Get values
-		name := strings.TrimSpace(m.inputs[nameInput].Value())
-		hostname := strings.TrimSpace(m.inputs[hostnameInput].Value())
-		user := strings.TrimSpace(m.inputs[userInput].Value())
-		port := strings.TrimSpace(m.inputs[portInput].Value())
-		identity := strings.TrimSpace(m.inputs[identityInput].Value())
-		proxyJump := strings.TrimSpace(m.inputs[proxyJumpInput].Value())
-		options := strings.TrimSpace(m.inputs[optionsInput].Value())
-
-		// Set defaults
-		if user == "" {
-			user = m.inputs[userInput].Placeholder
-		}
-		if port == "" {
-			port = "22"
+		inputs := m.inputs
+		if m.template != "" {
+			var err error
+			if inputs, err = expandTemplateInputs(m.inputs); err != nil {
+				return addFormSubmitMsg{err: err}
+			}
 		}
-		// Do not auto-fill identity with placeholder if left empty; keep it empty so it's optional
 
-		// Validate all fields
-		if err := validation.ValidateHost(name, hostname, port, identity); err != nil {
-			return addFormSubmitMsg{err: err}
+		// Get values
+		name := strings.TrimSpace(inputs[nameInput].Value())
+		hostname := strings.TrimSpace(inputs[hostnameInput].Value())
+		user := strings.TrimSpace(inputs[userInput].Value())
+		port := strings.TrimSpace(inputs[portInput].Value())
+		identity := strings.TrimSpace(inputs[identityInput].Value())
+		proxyJump := strings.TrimSpace(inputs[proxyJumpInput].Value())
+		options := strings.TrimSpace(inputs[optionsInput].Value())
+
+		if m.pattern {
+			// Patterns only set what is typed, the rest is left to other blocks
+			if err := validation.ValidatePattern(name, hostname, port, identity); err != nil {
+				return addFormSubmitMsg{err: err}
+			}
+		} else {
+			// Set defaults
+			if user == "" {
+				user = inputs[userInput].Placeholder
+			}
+			if port == "" {
+				port = "22"
+			}
+			// Do not auto-fill identity with placeholder if left empty; keep it empty so it's optional
+
+			// Validate all fields
+			if err := validation.ValidateHost(name, hostname, port, identity); err != nil {
+				return addFormSubmitMsg{err: err}
+			}
 		}
 
-		tagsStr := strings.TrimSpace(m.inputs[tagsInput].Value())
+		tagsStr := strings.TrimSpace(inputs[tagsInput].Value())
 		var tags []string
 		if tagsStr != "" {
 			for _, tag := range strings.Split(tagsStr, ",") {
@@ -319,19 +457,27 @@ func (m *addFormModel) submitForm() tea.Cmd {
 
 		// Create host configuration
 		host := config.SSHHost{
-			Name:      name,
-			Hostname:  hostname,
-			User:      user,
-			Port:      port,
-			Identity:  identity,
-			ProxyJump: proxyJump,
-			Options:   config.ParseSSHOptionsFromCommand(options),
-			Tags:      tags,
+			Name:     name,
+			Tags:     tags,
+			Group:    config.NormalizeGroupPath(inputs[groupInput].Value()),
+			Metadata: formMetadata(inputs, m.notes, config.HostMetadata{}),
+		}
+		if err := setFormDirectives(&host, hostname, user, port, identity, proxyJump, options); err != nil {
+			return addFormSubmitMsg{err: err}
 		}
 
 		// Add to config
 		var err error
-		if m.configFile != "" {
+		if m.pattern {
+			configFile := m.configFile
+			if configFile == "" {
+				configFile, err = config.GetDefaultSSHConfigPath()
+				if err != nil {
+					return addFormSubmitMsg{err: err}
+				}
+			}
+			err = config.AddPatternBlockToFile(host, configFile)
+		} else if m.configFile != "" {
 			err = config.AddSSHHostToFile(host, m.configFile)
 		} else {
 			err = config.AddSSHHost(host)
@@ -339,3 +485,169 @@ func (m *addFormModel) submitForm() tea.Cmd {
 		return addFormSubmitMsg{hostname: name, err: err}
 	}
 }
+
+// setFormDirectives sets the directives edited through the host form. Options
+// are given as on the ssh command line and their values are checked against
+// the type of their keyword.
+func setFormDirectives(host *config.SSHHost, hostname, user, port, identity, proxyJump, options string) error {
+	others, err := config.ParseCommandOptions(options)
+	if err != nil {
+		return err
+	}
+
+	// Fields set the first occurrence of their keyword, repeated keywords
+	// listed in the options come after it
+	host.SetOtherDirectives(nil)
+	host.Set("HostName", hostname)
+	host.Set("User", user)
+	host.Set("Port", port)
+	if identity != "" {
+		// Paths with spaces, common on Windows, must be quoted
+		identity = config.QuoteArg(identity)
+	}
+	host.Set("IdentityFile", identity)
+	host.Set("ProxyJump", proxyJump)
+	host.SetOtherDirectives(others)
+	return nil
+}
+
+// formFieldLabels returns the labels of the host form inputs, in input order
+func formFieldLabels(pattern bool) []string {
+	if pattern {
+		return []string{
+			"Pattern *",
+			"Hostname/IP",
+			"User",
+			"Port",
+			"Identity File",
+			"ProxyJump",
+			"SSH Options",
+			"Description",
+			"Owner",
+			"Environment",
+			"Links (comma-separated)",
+			"Notes (Markdown)",
+			"Group (e.g. prod/eu/db)",
+			"Tags (comma-separated, → completes)",
+		}
+	}
+	return []string{
+		"Host Name *",
+		"Hostname/IP *",
+		"User",
+		"Port",
+		"Identity File",
+		"ProxyJump",
+		"SSH Options",
+		"Description",
+		"Owner",
+		"Environment",
+		"Links (comma-separated)",
+		"Notes (Markdown)",
+		"Group (e.g. prod/eu/db)",
+		"Tags (comma-separated, → completes)",
+	}
+}
+
+// setupMetadataInputs creates the inputs for the host metadata, filled with
+// the given values
+func setupMetadataInputs(inputs []textinput.Model, metadata config.HostMetadata) {
+	inputs[descriptionInput] = textinput.New()
+	inputs[descriptionInput].Placeholder = "Main web server"
+	inputs[descriptionInput].CharLimit = 200
+	inputs[descriptionInput].Width = 50
+	inputs[descriptionInput].SetValue(metadata.Description)
+
+	inputs[ownerInput] = textinput.New()
+	inputs[ownerInput].Placeholder = "platform-team"
+	inputs[ownerInput].CharLimit = 100
+	inputs[ownerInput].Width = 30
+	inputs[ownerInput].SetValue(metadata.Owner)
+
+	inputs[environmentInput] = textinput.New()
+	inputs[environmentInput].Placeholder = "production"
+	inputs[environmentInput].CharLimit = 50
+	inputs[environmentInput].Width = 30
+	inputs[environmentInput].SetValue(metadata.Environment)
+
+	inputs[linksInput] = textinput.New()
+	inputs[linksInput].Placeholder = "https://wiki.example.com/web"
+	inputs[linksInput].CharLimit = 500
+	inputs[linksInput].Width = 70
+	inputs[linksInput].SetValue(strings.Join(metadata.Links, ", "))
+}
+
+// newNotesArea creates the multi-line input for the host notes
+func newNotesArea(notes string) textarea.Model {
+	area := textarea.New()
+	area.Placeholder = "Anything worth knowing about this host"
+	area.ShowLineNumbers = false
+	area.CharLimit = 2000
+	area.SetWidth(70)
+	area.SetHeight(4)
+	area.SetValue(notes)
+	return area
+}
+
+// focusFormInput focuses the input at the given position, whether it is a
+// text input or the notes
+func focusFormInput(inputs []textinput.Model, notes *textarea.Model, focused int) tea.Cmd {
+	var cmd tea.Cmd
+	for i := range inputs {
+		if i == notesInput {
+			continue
+		}
+		if i == focused {
+			cmd = inputs[i].Focus()
+			continue
+		}
+		inputs[i].Blur()
+	}
+	if focused == notesInput {
+		return notes.Focus()
+	}
+	notes.Blur()
+	return cmd
+}
+
+// updateFormInputs passes a message to every input of the host form
+func updateFormInputs(inputs []textinput.Model, notes *textarea.Model, msg tea.Msg) []tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(inputs)+1)
+	for i := range inputs {
+		if i == notesInput {
+			continue
+		}
+		var cmd tea.Cmd
+		inputs[i], cmd = inputs[i].Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	var cmd tea.Cmd
+	*notes, cmd = notes.Update(msg)
+	return append(cmds, cmd)
+}
+
+// formInputView renders the input at the given position of the host form
+func formInputView(inputs []textinput.Model, notes textarea.Model, i int) string {
+	if i == notesInput {
+		return notes.View()
+	}
+	return inputs[i].View()
+}
+
+// formMetadata builds the host metadata from the form inputs. Entries the
+// form doesn't show are kept from the given metadata.
+func formMetadata(inputs []textinput.Model, notes textarea.Model, current config.HostMetadata) config.HostMetadata {
+	metadata := config.HostMetadata{
+		Description: strings.TrimSpace(inputs[descriptionInput].Value()),
+		Owner:       strings.TrimSpace(inputs[ownerInput].Value()),
+		Environment: strings.TrimSpace(inputs[environmentInput].Value()),
+		Notes:       strings.TrimSpace(notes.Value()),
+		Extra:       current.Extra,
+	}
+	for _, link := range strings.Split(inputs[linksInput].Value(), ",") {
+		if link = strings.TrimSpace(link); link != "" {
+			metadata.Links = append(metadata.Links, link)
+		}
+	}
+	return metadata
+}
@@ -0,0 +1,230 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// bulkAction is what a bulk form does with the selected hosts
+type bulkAction int
+
+const (
+	bulkTags bulkAction = iota
+	bulkExport
+)
+
+// defaultExportFile is the file selected hosts are exported to by default
+const defaultExportFile = "sshm-hosts.conf"
+
+// bulkFormModel asks for the input of a bulk action on several hosts: the
+// tags to add, remove or replace, or the file to export the hosts to
+type bulkFormModel struct {
+	action    bulkAction
+	tagAction config.TagAction
+	input     textinput.Model
+	hosts     []config.SSHHost
+	knownTags []string // Tags used in the config, to complete the tags input
+	styles    Styles
+	width     int
+	height    int
+	err       string
+}
+
+// bulkFormSubmitMsg is sent once a bulk action was applied
+type bulkFormSubmitMsg struct {
+	status string // Confirmation shown in the host list
+	err    error
+}
+
+type bulkFormCancelMsg struct{}
+
+// NewBulkForm creates a form applying an action to several hosts
+func NewBulkForm(action bulkAction, hosts []config.SSHHost, knownTags []string, styles Styles, width, height int) *bulkFormModel {
+	input := textinput.New()
+	input.Width = 40
+	input.Focus()
+
+	switch action {
+	case bulkTags:
+		input.Placeholder = "prod, web"
+		input.CharLimit = 200
+		setupTagInput(&input, knownTags)
+	case bulkExport:
+		input.Placeholder = defaultExportFile
+		input.CharLimit = 255
+		input.SetValue(defaultExportFile)
+	}
+
+	return &bulkFormModel{
+		action:    action,
+		input:     input,
+		hosts:     hosts,
+		knownTags: knownTags,
+		styles:    styles,
+		width:     width,
+		height:    height,
+	}
+}
+
+func (m *bulkFormModel) Update(msg tea.Msg) (*bulkFormModel, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			return m, func() tea.Msg { return bulkFormCancelMsg{} }
+		case "enter":
+			return m, m.submit()
+		case "tab":
+			if m.action == bulkTags {
+				// Cycle through adding, removing and replacing tags
+				m.tagAction = (m.tagAction + 1) % 3
+				return m, nil
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.action == bulkTags {
+		updateTagSuggestions(&m.input, m.knownTags)
+	}
+	return m, cmd
+}
+
+func (m *bulkFormModel) View() string {
+	var sections []string
+
+	var title, label string
+	switch m.action {
+	case bulkTags:
+		title = fmt.Sprintf("🏷️  Tags of %d host(s)", len(m.hosts))
+		label = "Tags (comma-separated, → completes):"
+	case bulkExport:
+		title = fmt.Sprintf("📤 Export %d host(s)", len(m.hosts))
+		label = "Export to file:"
+	}
+	sections = append(sections, m.styles.Header.Render(title))
+	sections = append(sections, m.styles.HelpText.Render(hostNamesSummary(m.hosts, 5)))
+
+	if m.err != "" {
+		sections = append(sections, m.styles.Error.Render("Error: "+m.err))
+	}
+
+	var fields []string
+	if m.action == bulkTags {
+		fields = append(fields, m.styles.Label.Render("Action:"))
+		var actions []string
+		for _, action := range []config.TagAction{config.TagsAdd, config.TagsRemove, config.TagsReplace} {
+			if action == m.tagAction {
+				actions = append(actions, m.styles.FocusedLabel.Render("["+action.String()+"]"))
+			} else {
+				actions = append(actions, m.styles.HelpText.Render(" "+action.String()+" "))
+			}
+		}
+		fields = append(fields, strings.Join(actions, " "), "")
+	}
+	fields = append(fields, m.styles.FocusedLabel.Render(label))
+	fields = append(fields, m.input.View())
+	sections = append(sections, lipgloss.JoinVertical(lipgloss.Left, fields...))
+
+	helpText := " Enter: apply • Esc: cancel"
+	if m.action == bulkTags {
+		helpText = " Tab: change action • Enter: apply • Esc: cancel"
+	}
+	sections = append(sections, m.styles.HelpText.Render(helpText))
+
+	content := lipgloss.JoinVertical(lipgloss.Left, sections...)
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		m.styles.FormContainer.Render(content),
+	)
+}
+
+// submit applies the action to the hosts
+func (m *bulkFormModel) submit() tea.Cmd {
+	hosts := m.hosts
+	value := strings.TrimSpace(m.input.Value())
+
+	switch m.action {
+	case bulkTags:
+		tagAction := m.tagAction
+		var tags []string
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		return func() tea.Msg {
+			if len(tags) == 0 && tagAction != config.TagsReplace {
+				return bulkFormSubmitMsg{err: fmt.Errorf("enter at least one tag")}
+			}
+			if err := config.UpdateHostsTags(hosts, tagAction, tags); err != nil {
+				return bulkFormSubmitMsg{err: err}
+			}
+			return bulkFormSubmitMsg{status: fmt.Sprintf("Updated the tags of %d host(s)", len(hosts))}
+		}
+
+	default:
+		return func() tea.Msg {
+			path, err := exportPath(value)
+			if err != nil {
+				return bulkFormSubmitMsg{err: err}
+			}
+			data, err := config.ExportHosts(hosts)
+			if err != nil {
+				return bulkFormSubmitMsg{err: err}
+			}
+			// O_EXCL so that an existing file is never overwritten
+			file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+			if err != nil {
+				return bulkFormSubmitMsg{err: err}
+			}
+			if _, err := file.Write(data); err != nil {
+				file.Close()
+				return bulkFormSubmitMsg{err: err}
+			}
+			if err := file.Close(); err != nil {
+				return bulkFormSubmitMsg{err: err}
+			}
+			return bulkFormSubmitMsg{status: fmt.Sprintf("Exported %d host(s) to %s", len(hosts), path)}
+		}
+	}
+}
+
+// exportPath resolves the file hosts are exported to, expanding "~"
+func exportPath(value string) (string, error) {
+	if value == "" {
+		value = defaultExportFile
+	}
+	if value == "~" || strings.HasPrefix(value, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		value = filepath.Join(home, strings.TrimPrefix(value, "~"))
+	}
+	return filepath.Abs(value)
+}
+
+// hostNamesSummary lists the names of hosts, up to limit of them
+func hostNamesSummary(hosts []config.SSHHost, limit int) string {
+	var names []string
+	for i, host := range hosts {
+		if i == limit {
+			names = append(names, fmt.Sprintf("and %d more", len(hosts)-limit))
+			break
+		}
+		names = append(names, host.Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// diagnosticsModel lists the problems met while reading the configuration
+type diagnosticsModel struct {
+	diagnostics []config.Diagnostic
+	offset      int
+	styles      Styles
+	width       int
+	height      int
+}
+
+// diagnosticsCloseMsg is sent when the diagnostics panel is closed
+type diagnosticsCloseMsg struct{}
+
+// NewDiagnosticsPanel creates a panel listing config diagnostics
+func NewDiagnosticsPanel(diagnostics []config.Diagnostic, styles Styles, width, height int) *diagnosticsModel {
+	return &diagnosticsModel{
+		diagnostics: diagnostics,
+		styles:      styles,
+		width:       width,
+		height:      height,
+	}
+}
+
+func (m *diagnosticsModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *diagnosticsModel) Update(msg tea.Msg) (*diagnosticsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.styles = NewStyles(m.width)
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q", "W":
+			return m, func() tea.Msg { return diagnosticsCloseMsg{} }
+		case "up", "k":
+			if m.offset > 0 {
+				m.offset--
+			}
+		case "down", "j":
+			if m.offset < len(m.contentLines())-1 {
+				m.offset++
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// contentLines renders every diagnostic as a list of lines
+func (m *diagnosticsModel) contentLines() []string {
+	errorStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color(ErrorColor))
+	warningStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("214"))
+	messageStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("255"))
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("243"))
+
+	if len(m.diagnostics) == 0 {
+		return []string{mutedStyle.Render("No problems found while reading the configuration")}
+	}
+
+	var lines []string
+	for i, diagnostic := range m.diagnostics {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		label := warningStyle.Render("⚠ warning")
+		if diagnostic.Severity == config.SeverityError {
+			label = errorStyle.Render("✗ error")
+		}
+		position := formatConfigFile(diagnostic.File)
+		if diagnostic.Line > 0 {
+			position = fmt.Sprintf("%s:%d", position, diagnostic.Line)
+		}
+		lines = append(lines, label+mutedStyle.Render("  "+position))
+		lines = append(lines, messageStyle.Render("    "+diagnostic.Message))
+	}
+	return lines
+}
+
+func (m *diagnosticsModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render(fmt.Sprintf("Config Problems (%d)", len(m.diagnostics))))
+	b.WriteString("\n")
+	b.WriteString(m.styles.HelpText.Render("Hosts from the files below may be missing, run 'sshm lint' for a full check"))
+	b.WriteString("\n\n")
+
+	lines := m.contentLines()
+
+	// Keep room for the title, hint lines and container borders
+	visible := m.height - 10
+	if visible < 5 {
+		visible = 5
+	}
+	end := m.offset + visible
+	if end > len(lines) {
+		end = len(lines)
+	}
+	b.WriteString(strings.Join(lines[m.offset:end], "\n"))
+	b.WriteString("\n\n")
+
+	b.WriteString(m.styles.HelpText.Render("↑/↓: scroll • ESC/q: back"))
+
+	return m.styles.FormContainer.Render(b.String())
+}
@@ -0,0 +1,368 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/discover"
+	"github.com/Gu1llaum-3/sshm/internal/importer"
+	"github.com/Gu1llaum-3/sshm/internal/validation"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// discoverEdit is the value typed in the discovery checklist
+type discoverEdit int
+
+const (
+	discoverEditNone    discoverEdit = iota
+	discoverEditName                 // Name of the highlighted host
+	discoverEditTags                 // Tags of the highlighted host
+	discoverEditAllTags              // Tags of every checked host
+)
+
+// discoverChecklistModel lists the discovered hosts and lets the user check
+// the ones to add, name and tag them, and choose the file they go to
+type discoverChecklistModel struct {
+	suggestions []discover.Suggestion
+	checked     []bool
+	existing    []config.SSHHost
+	files       []string // Config files the hosts can be added to
+	file        int      // Index of the target file
+	knownTags   []string
+	selected    int
+	editing     discoverEdit
+	input       textinput.Model
+	err         string
+	confirmed   bool
+	styles      Styles
+	width       int
+	height      int
+}
+
+// newDiscoverChecklist creates the checklist of discovered hosts, to be
+// added to the first of the files
+func newDiscoverChecklist(suggestions []discover.Suggestion, existing []config.SSHHost, files, knownTags []string, styles Styles, width, height int) *discoverChecklistModel {
+	input := textinput.New()
+	input.CharLimit = 200
+	input.Width = 40
+	return &discoverChecklistModel{
+		suggestions: suggestions,
+		checked:     make([]bool, len(suggestions)),
+		existing:    existing,
+		files:       files,
+		knownTags:   knownTags,
+		input:       input,
+		styles:      styles,
+		width:       width,
+		height:      height,
+	}
+}
+
+func (m *discoverChecklistModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *discoverChecklistModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.styles = NewStyles(m.width)
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.editing != discoverEditNone {
+			return m.updateEdit(msg)
+		}
+
+		m.err = ""
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			return m, tea.Quit
+		case "enter":
+			if m.checkedCount() == 0 {
+				m.err = "Check at least one host with Space"
+				return m, nil
+			}
+			m.confirmed = true
+			return m, tea.Quit
+		case "up", "k":
+			if m.selected > 0 {
+				m.selected--
+			}
+		case "down", "j":
+			if m.selected < len(m.suggestions)-1 {
+				m.selected++
+			}
+		case " ":
+			m.toggle(m.selected)
+		case "a":
+			// Check every host, or uncheck them all when they already are
+			all := m.checkedCount() < len(m.suggestions)
+			for i := range m.suggestions {
+				if m.checked[i] != all {
+					m.toggle(i)
+				}
+			}
+		case "f":
+			if len(m.files) > 0 {
+				m.file = (m.file + 1) % len(m.files)
+			}
+		case "n":
+			return m, m.startEdit(discoverEditName, m.suggestions[m.selected].Host.Name)
+		case "t":
+			return m, m.startEdit(discoverEditTags, strings.Join(m.suggestions[m.selected].Host.Tags, ", "))
+		case "T":
+			if m.checkedCount() == 0 {
+				m.err = "Check the hosts to tag with Space"
+				return m, nil
+			}
+			return m, m.startEdit(discoverEditAllTags, "")
+		}
+	}
+
+	return m, nil
+}
+
+// startEdit focuses the input to type a name or tags
+func (m *discoverChecklistModel) startEdit(edit discoverEdit, value string) tea.Cmd {
+	m.editing = edit
+	m.input.ShowSuggestions = false
+	m.input.SetSuggestions(nil)
+	if edit != discoverEditName {
+		setupTagInput(&m.input, m.knownTags)
+	}
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	m.input.Focus()
+	return textinput.Blink
+}
+
+// updateEdit handles keys while a name or tags are typed
+func (m *discoverChecklistModel) updateEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		m.stopEdit()
+		return m, nil
+	case "enter":
+		value := strings.TrimSpace(m.input.Value())
+		if m.editing == discoverEditName {
+			if err := m.validateName(value, m.selected); err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			m.rename(m.selected, value)
+			m.stopEdit()
+			return m, nil
+		}
+
+		var tags []string
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag == "" {
+				continue
+			}
+			if err := config.ValidateTag(tag); err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			tags = append(tags, tag)
+		}
+		for i := range m.suggestions {
+			if (m.editing == discoverEditTags && i == m.selected) || (m.editing == discoverEditAllTags && m.checked[i]) {
+				m.suggestions[i].Host.Tags = tags
+			}
+		}
+		m.stopEdit()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.editing != discoverEditName {
+		updateTagSuggestions(&m.input, m.knownTags)
+	}
+	return m, cmd
+}
+
+// stopEdit leaves the input
+func (m *discoverChecklistModel) stopEdit() {
+	m.editing = discoverEditNone
+	m.err = ""
+	m.input.Blur()
+}
+
+// toggle checks or unchecks a host. A host checked under a name another
+// checked host uses is given a free one.
+func (m *discoverChecklistModel) toggle(i int) {
+	m.checked[i] = !m.checked[i]
+	if m.checked[i] && m.nameTaken(m.suggestions[i].Host.Name, i) {
+		m.rename(i, importer.FreeName(m.suggestions[i].Host.Name, func(name string) bool {
+			return m.nameTaken(name, i)
+		}))
+	}
+}
+
+// rename changes the name a host is added under
+func (m *discoverChecklistModel) rename(i int, name string) {
+	host := &m.suggestions[i].Host
+	host.Name = name
+	host.Aliases = []string{name}
+	host.Patterns = []string{name}
+}
+
+// nameTaken reports whether a name is used by a host of the config or by
+// another checked host
+func (m *discoverChecklistModel) nameTaken(name string, self int) bool {
+	for _, host := range m.existing {
+		if host.HasAlias(name) {
+			return true
+		}
+	}
+	for i, s := range m.suggestions {
+		if i != self && m.checked[i] && s.Host.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateName checks a new name for a host
+func (m *discoverChecklistModel) validateName(name string, self int) error {
+	if !validation.ValidateHostName(name) {
+		return fmt.Errorf("invalid host name: cannot contain spaces or special characters")
+	}
+	if m.nameTaken(name, self) {
+		return fmt.Errorf("host '%s' %w", name, config.ErrHostExists)
+	}
+	return nil
+}
+
+// checkedCount returns the number of checked hosts
+func (m *discoverChecklistModel) checkedCount() int {
+	count := 0
+	for _, checked := range m.checked {
+		if checked {
+			count++
+		}
+	}
+	return count
+}
+
+// target returns the file the hosts are added to
+func (m *discoverChecklistModel) target() string {
+	if len(m.files) == 0 {
+		return ""
+	}
+	return m.files[m.file]
+}
+
+func (m *discoverChecklistModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render(fmt.Sprintf("Discovered %d host(s) missing from the config", len(m.suggestions))))
+	b.WriteString("\n\n")
+
+	// Keep room for the title, file, input, hint lines and container borders
+	visible := m.height - 14
+	if visible < 5 {
+		visible = 5
+	}
+	start := 0
+	if m.selected >= visible {
+		start = m.selected - visible + 1
+	}
+	end := start + visible
+	if end > len(m.suggestions) {
+		end = len(m.suggestions)
+	}
+
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(SecondaryColor))
+	for i := start; i < end; i++ {
+		s := m.suggestions[i]
+		box := "[ ]"
+		if m.checked[i] {
+			box = "[x]"
+		}
+		line := fmt.Sprintf("%s %-20s %-32s %4d×", box, s.Host.Name, importer.Endpoint(s.Host), s.Count)
+		details := "  " + discoverFileNames(s.Files)
+		if len(s.Host.Tags) > 0 {
+			details += "  #" + strings.Join(s.Host.Tags, " #")
+		}
+
+		if i == m.selected {
+			b.WriteString(m.styles.Selected.Render("▶ " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString(dimStyle.Render(details))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(m.styles.Label.Render("Add to: "))
+	b.WriteString(m.target())
+	b.WriteString(fmt.Sprintf("\n%d host(s) checked\n\n", m.checkedCount()))
+
+	switch m.editing {
+	case discoverEditName:
+		b.WriteString(m.styles.FocusedLabel.Render("Name: "))
+	case discoverEditTags:
+		b.WriteString(m.styles.FocusedLabel.Render("Tags (comma-separated, → completes): "))
+	case discoverEditAllTags:
+		b.WriteString(m.styles.FocusedLabel.Render(fmt.Sprintf("Tags of the %d checked host(s): ", m.checkedCount())))
+	}
+	if m.editing != discoverEditNone {
+		b.WriteString(m.input.View())
+		b.WriteString("\n\n")
+	}
+	if m.err != "" {
+		b.WriteString(m.styles.Error.Render(m.err))
+		b.WriteString("\n\n")
+	}
+
+	if m.editing != discoverEditNone {
+		b.WriteString(m.styles.HelpText.Render("Enter: apply • ESC: cancel"))
+	} else {
+		b.WriteString(m.styles.HelpText.Render("↑/↓: navigate • Space: check • a: all • n: name • t: tags • T: tag checked • f: file • Enter: add • ESC/q: cancel"))
+	}
+
+	return m.styles.FormContainer.Render(b.String())
+}
+
+// discoverFileNames names the files a host was found in
+func discoverFileNames(files []string) string {
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, filepath.Base(file))
+	}
+	return strings.Join(names, ", ")
+}
+
+// RunDiscoverChecklist shows the discovered hosts and lets the user check the
+// ones to add, name and tag them, and pick the file among files they are added
+// to. It returns the checked hosts, the file, and whether adding them was
+// confirmed.
+func RunDiscoverChecklist(suggestions []discover.Suggestion, existing []config.SSHHost, files, knownTags []string) ([]config.SSHHost, string, bool, error) {
+	m := newDiscoverChecklist(suggestions, existing, files, knownTags, NewStyles(80), 80, 24)
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return nil, "", false, err
+	}
+	return m.checkedHosts(), m.target(), m.confirmed, nil
+}
+
+// checkedHosts returns the hosts checked to be added
+func (m *discoverChecklistModel) checkedHosts() []config.SSHHost {
+	var hosts []config.SSHHost
+	for i, s := range m.suggestions {
+		if m.checked[i] {
+			hosts = append(hosts, s.Host)
+		}
+	}
+	return hosts
+}
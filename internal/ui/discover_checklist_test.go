@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/discover"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDiscoverChecklist(t *testing.T) {
+	existing := []config.SSHHost{{Name: "web", Aliases: []string{"web"}}}
+	suggestions := []discover.Suggestion{
+		{Host: config.SSHHost{Name: "web-2", Aliases: []string{"web-2"}, Hostname: "web.example.com"}, Count: 3},
+		{Host: config.SSHHost{Name: "db", Aliases: []string{"db"}, Hostname: "db.example.com"}, Count: 1},
+	}
+	m := newDiscoverChecklist(suggestions, existing, []string{"/tmp/config", "/tmp/work"}, nil, NewStyles(80), 80, 24)
+
+	press := func(keys ...string) {
+		for _, k := range keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			switch k {
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case " ":
+				msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+			case "ctrl+u":
+				msg = tea.KeyMsg{Type: tea.KeyCtrlU}
+			}
+			m.Update(msg)
+		}
+	}
+	typeText := func(text string) {
+		press("ctrl+u")
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+	}
+
+	// Nothing is added until a host is checked
+	press("enter")
+	if m.confirmed || m.err == "" {
+		t.Fatal("Enter without checked hosts should show an error")
+	}
+
+	// A name used by the config is refused
+	press("n")
+	typeText("web")
+	press("enter")
+	if m.err == "" || m.suggestions[0].Host.Name != "web-2" {
+		t.Errorf("renaming to web: err = %q, name = %s, want it refused", m.err, m.suggestions[0].Host.Name)
+	}
+	typeText("www")
+	press("enter")
+	if m.editing != discoverEditNone || m.suggestions[0].Host.Name != "www" {
+		t.Errorf("name = %s, want www", m.suggestions[0].Host.Name)
+	}
+
+	// Tags apply to the highlighted host, or to every checked one
+	press("t")
+	typeText("web, prod")
+	press("enter")
+	press("a", "T")
+	typeText("lab")
+	press("enter")
+	if !reflect.DeepEqual(m.suggestions[0].Host.Tags, []string{"lab"}) || !reflect.DeepEqual(m.suggestions[1].Host.Tags, []string{"lab"}) {
+		t.Errorf("tags = %v, %v, want lab on both", m.suggestions[0].Host.Tags, m.suggestions[1].Host.Tags)
+	}
+
+	if view := m.View(); !strings.Contains(view, "[x] www") {
+		t.Errorf("view should show www checked:\n%s", view)
+	}
+
+	press("j", " ", "f", "enter")
+	if !m.confirmed || m.target() != "/tmp/work" {
+		t.Errorf("confirmed = %v, target = %s, want /tmp/work", m.confirmed, m.target())
+	}
+	hosts := m.checkedHosts()
+	if len(hosts) != 1 || hosts[0].Name != "www" {
+		t.Errorf("checked hosts = %+v, want www only", hosts)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/Gu1llaum-3/sshm/internal/config"
 	"github.com/Gu1llaum-3/sshm/internal/validation"
 
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -13,6 +14,7 @@ import (
 
 type editFormModel struct {
 	inputs       []textinput.Model
+	notes        textarea.Model // Multi-line input shown at notesInput
 	focused      int
 	err          string
 	success      bool
@@ -22,6 +24,8 @@ type editFormModel struct {
 	width        int
 	height       int
 	configFile   string
+	pattern      bool     // Editing a wildcard pattern block instead of a host
+	knownTags    []string // Tags used in the config, to complete the tags input
 }
 
 // NewEditForm creates a new edit form model
@@ -40,7 +44,27 @@ func NewEditForm(hostName string, styles Styles, width, height int, configFile s
 		return nil, err
 	}
 
-	inputs := make([]textinput.Model, 8)
+	return newEditFormForHost(host, hostName, styles, width, height, configFile), nil
+}
+
+// NewPatternEditForm creates an edit form for a wildcard pattern block such as "Host *.internal"
+func NewPatternEditForm(pattern string, styles Styles, width, height int, configFile string) (*editFormModel, error) {
+	host, err := config.GetPatternBlock(pattern, configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	m := newEditFormForHost(host, host.Name, styles, width, height, configFile)
+	m.pattern = true
+	m.inputs[nameInput].Placeholder = "*.internal"
+	m.inputs[hostnameInput].Placeholder = "%h.example.com"
+	m.inputs[userInput].Placeholder = ""
+	return m, nil
+}
+
+// newEditFormForHost builds the edit form inputs from an existing host
+func newEditFormForHost(host *config.SSHHost, hostName string, styles Styles, width, height int, configFile string) *editFormModel {
+	inputs := make([]textinput.Model, formInputCount)
 
 	// Name input
 	inputs[nameInput] = textinput.New()
@@ -76,7 +100,7 @@ func NewEditForm(hostName string, styles Styles, width, height int, configFile s
 	inputs[identityInput].Placeholder = "~/.ssh/id_rsa"
 	inputs[identityInput].CharLimit = 200
 	inputs[identityInput].Width = 50
-	inputs[identityInput].SetValue(host.Identity)
+	inputs[identityInput].SetValue(config.UnquoteArg(host.Identity))
 
 	// ProxyJump input
 	inputs[proxyJumpInput] = textinput.New()
@@ -90,7 +114,17 @@ func NewEditForm(hostName string, styles Styles, width, height int, configFile s
 	inputs[optionsInput].Placeholder = "-o Compression=yes -o ServerAliveInterval=60"
 	inputs[optionsInput].CharLimit = 500
 	inputs[optionsInput].Width = 70
-	inputs[optionsInput].SetValue(config.FormatSSHOptionsForCommand(host.Options))
+	inputs[optionsInput].SetValue(config.FormatCommandOptions(host.OtherDirectives()))
+
+	// Metadata inputs
+	setupMetadataInputs(inputs, host.Metadata)
+
+	// Group input
+	inputs[groupInput] = textinput.New()
+	inputs[groupInput].Placeholder = "prod/eu/db"
+	inputs[groupInput].CharLimit = 200
+	inputs[groupInput].Width = 50
+	inputs[groupInput].SetValue(host.Group)
 
 	// Tags input
 	inputs[tagsInput] = textinput.New()
@@ -100,17 +134,21 @@ func NewEditForm(hostName string, styles Styles, width, height int, configFile s
 	if len(host.Tags) > 0 {
 		inputs[tagsInput].SetValue(strings.Join(host.Tags, ", "))
 	}
+	knownTags := loadKnownTags(configFile)
+	setupTagInput(&inputs[tagsInput], knownTags)
 
 	return &editFormModel{
 		inputs:       inputs,
+		notes:        newNotesArea(host.Metadata.Notes),
 		focused:      nameInput,
 		originalName: hostName,
 		host:         host,
 		configFile:   configFile,
+		knownTags:    knownTags,
 		styles:       styles,
 		width:        width,
 		height:       height,
-	}, nil
+	}
 }
 
 // Messages for communication with parent model
@@ -147,6 +185,11 @@ func (m *editFormModel) Update(msg tea.Msg) (*editFormModel, tea.Cmd) {
 		case "tab", "shift+tab", "enter", "up", "down":
 			s := msg.String()
 
+			// Enter and arrows move inside the notes, only Tab leaves them
+			if m.focused == notesInput && s != "tab" && s != "shift+tab" {
+				break
+			}
+
 			// Handle form submission
 			if s == "enter" && m.focused == len(m.inputs)-1 {
 				return m, m.submitEditForm()
@@ -165,14 +208,7 @@ func (m *editFormModel) Update(msg tea.Msg) (*editFormModel, tea.Cmd) {
 				m.focused = len(m.inputs) - 1
 			}
 
-			for i := range m.inputs {
-				if i == m.focused {
-					cmds = append(cmds, m.inputs[i].Focus())
-					continue
-				}
-				m.inputs[i].Blur()
-			}
-
+			cmds = append(cmds, focusFormInput(m.inputs, &m.notes, m.focused))
 			return m, tea.Batch(cmds...)
 		}
 
@@ -188,11 +224,8 @@ func (m *editFormModel) Update(msg tea.Msg) (*editFormModel, tea.Cmd) {
 	}
 
 	// Update inputs
-	cmd := make([]tea.Cmd, len(m.inputs))
-	for i := range m.inputs {
-		m.inputs[i], cmd[i] = m.inputs[i].Update(msg)
-	}
-	cmds = append(cmds, cmd...)
+	cmds = append(cmds, updateFormInputs(m.inputs, &m.notes, msg)...)
+	updateTagSuggestions(&m.inputs[tagsInput], m.knownTags)
 
 	return m, tea.Batch(cmds...)
 }
@@ -204,7 +237,11 @@ func (m *editFormModel) View() string {
 
 	var b strings.Builder
 
-	b.WriteString(m.styles.FormTitle.Render("Edit SSH Host Configuration"))
+	title := "Edit SSH Host Configuration"
+	if m.pattern {
+		title = "Edit Host Pattern"
+	}
+	b.WriteString(m.styles.FormTitle.Render(title))
 	b.WriteString("\n")
 
 	// Show source file information
@@ -225,21 +262,12 @@ func (m *editFormModel) View() string {
 	}
 	b.WriteString("\n\n")
 
-	fields := []string{
-		"Host Name *",
-		"Hostname/IP *",
-		"User",
-		"Port",
-		"Identity File",
-		"ProxyJump",
-		"SSH Options",
-		"Tags (comma-separated)",
-	}
+	fields := formFieldLabels(m.pattern)
 
 	for i, field := range fields {
 		b.WriteString(m.styles.FormField.Render(field))
 		b.WriteString("\n")
-		b.WriteString(m.inputs[i].View())
+		b.WriteString(formInputView(m.inputs, m.notes, i))
 		b.WriteString("\n\n")
 	}
 
@@ -304,15 +332,22 @@ func (m *editFormModel) submitEditForm() tea.Cmd {
 		proxyJump := strings.TrimSpace(m.inputs[proxyJumpInput].Value())
 		options := strings.TrimSpace(m.inputs[optionsInput].Value())
 
-		// Set defaults
-		if port == "" {
-			port = "22"
-		}
-		// Do not auto-fill identity with placeholder if left empty; keep it empty so it's optional
+		if m.pattern {
+			// Patterns only set what is typed, the rest is left to other blocks
+			if err := validation.ValidatePattern(name, hostname, port, identity); err != nil {
+				return editFormSubmitMsg{err: err}
+			}
+		} else {
+			// Set defaults
+			if port == "" {
+				port = "22"
+			}
+			// Do not auto-fill identity with placeholder if left empty; keep it empty so it's optional
 
-		// Validate all fields
-		if err := validation.ValidateHost(name, hostname, port, identity); err != nil {
-			return editFormSubmitMsg{err: err}
+			// Validate all fields
+			if err := validation.ValidateHost(name, hostname, port, identity); err != nil {
+				return editFormSubmitMsg{err: err}
+			}
 		}
 
 		// Parse tags
@@ -327,21 +362,24 @@ func (m *editFormModel) submitEditForm() tea.Cmd {
 			}
 		}
 
-		// Create updated host configuration
-		host := config.SSHHost{
-			Name:      name,
-			Hostname:  hostname,
-			User:      user,
-			Port:      port,
-			Identity:  identity,
-			ProxyJump: proxyJump,
-			Options:   config.ParseSSHOptionsFromCommand(options),
-			Tags:      tags,
+		// Update the host read from the config, keeping the order of its
+		// directives and the ones the form doesn't show
+		host := *m.host
+		host.Directives = append([]config.Directive(nil), m.host.Directives...)
+		host.Name = name
+		host.Tags = tags
+		host.Group = config.NormalizeGroupPath(m.inputs[groupInput].Value())
+		host.Metadata = formMetadata(m.inputs, m.notes, m.host.Metadata)
+		if err := setFormDirectives(&host, hostname, user, port, identity, proxyJump, options); err != nil {
+			return editFormSubmitMsg{err: err}
 		}
 
 		// Update the configuration
 		var err error
-		if m.configFile != "" {
+		if m.pattern {
+			// Patterns are updated in the file they were read from
+			err = config.UpdatePatternBlockInFile(m.originalName, host, m.host.SourceFile)
+		} else if m.configFile != "" {
 			err = config.UpdateSSHHostInFile(m.originalName, host, m.configFile)
 		} else {
 			err = config.UpdateSSHHost(m.originalName, host)
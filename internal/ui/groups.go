@@ -0,0 +1,331 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/connectivity"
+
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// GroupMode defines how hosts are organised in the host list
+type GroupMode int
+
+const (
+	GroupNone   GroupMode = iota
+	GroupByPath           // "# Group:" comment, nested on "/"
+	GroupByFile           // Config file the host is defined in
+	GroupByTag            // First tag of the host
+)
+
+func (g GroupMode) String() string {
+	switch g {
+	case GroupByPath:
+		return "group"
+	case GroupByFile:
+		return "config file"
+	case GroupByTag:
+		return "first tag"
+	default:
+		return "none"
+	}
+}
+
+// ungroupedLabel names the group collecting the hosts that have no group path
+// or tag
+func (g GroupMode) ungroupedLabel() string {
+	if g == GroupByTag {
+		return "(untagged)"
+	}
+	return "(no group)"
+}
+
+// hostGroup is a node of the host tree
+type hostGroup struct {
+	path     string // Unique key of the group, e.g. "prod/eu"
+	name     string // Label shown in the list
+	depth    int
+	hosts    []config.SSHHost // Hosts directly in the group
+	children []*hostGroup
+}
+
+// allHosts returns the hosts of the group and its subgroups
+func (g *hostGroup) allHosts() []config.SSHHost {
+	hosts := append([]config.SSHHost(nil), g.hosts...)
+	for _, child := range g.children {
+		hosts = append(hosts, child.allHosts()...)
+	}
+	return hosts
+}
+
+// listRow is a row of the host table, either a group heading or a host
+type listRow struct {
+	group *hostGroup      // Set for group rows
+	host  *config.SSHHost // Set for host rows
+	depth int
+}
+
+// groupSegments returns the path of the group a host belongs to, or nil
+func groupSegments(host config.SSHHost, mode GroupMode) []string {
+	switch mode {
+	case GroupByPath:
+		if group := config.NormalizeGroupPath(host.Group); group != "" {
+			return strings.Split(group, "/")
+		}
+	case GroupByFile:
+		if host.SourceFile != "" {
+			return []string{host.SourceFile}
+		}
+	case GroupByTag:
+		if len(host.Tags) > 0 {
+			return []string{host.Tags[0]}
+		}
+	}
+	return nil
+}
+
+// buildGroupTree organises hosts into groups, keeping the order of the hosts
+// inside each group. Groups are sorted by name, with the hosts that have no
+// group in a last group of their own.
+func buildGroupTree(hosts []config.SSHHost, mode GroupMode) []*hostGroup {
+	root := &hostGroup{depth: -1}
+	index := make(map[string]*hostGroup)
+	var ungrouped *hostGroup
+
+	for _, host := range hosts {
+		segments := groupSegments(host, mode)
+		if len(segments) == 0 {
+			if ungrouped == nil {
+				ungrouped = &hostGroup{name: mode.ungroupedLabel()}
+			}
+			ungrouped.hosts = append(ungrouped.hosts, host)
+			continue
+		}
+
+		parent := root
+		for i, segment := range segments {
+			path := strings.Join(segments[:i+1], "/")
+			group, ok := index[path]
+			if !ok {
+				group = &hostGroup{path: path, name: segment, depth: i}
+				if mode == GroupByFile {
+					group.name = formatConfigFile(segment)
+				}
+				index[path] = group
+				parent.children = append(parent.children, group)
+			}
+			parent = group
+		}
+		parent.hosts = append(parent.hosts, host)
+	}
+
+	sortGroups(root.children)
+	if ungrouped != nil {
+		root.children = append(root.children, ungrouped)
+	}
+	return root.children
+}
+
+// sortGroups sorts groups and their subgroups by name
+func sortGroups(groups []*hostGroup) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		return strings.ToLower(groups[i].name) < strings.ToLower(groups[j].name)
+	})
+	for _, group := range groups {
+		sortGroups(group.children)
+	}
+}
+
+// buildListRows lists the rows of the host table. Without grouping every
+// host has a row; otherwise groups are followed by their subgroups and hosts
+// unless they are collapsed. Collapsed groups are ignored while searching so
+// that every match is visible.
+func (m *Model) buildListRows(hosts []config.SSHHost) []listRow {
+	rows := make([]listRow, 0, len(hosts))
+	if m.groupMode == GroupNone {
+		for i := range hosts {
+			rows = append(rows, listRow{host: &hosts[i]})
+		}
+		return rows
+	}
+
+	searching := m.searchInput.Value() != ""
+	var walk func(groups []*hostGroup)
+	walk = func(groups []*hostGroup) {
+		for _, group := range groups {
+			rows = append(rows, listRow{group: group, depth: group.depth})
+			if m.collapsedGroups[group.path] && !searching {
+				continue
+			}
+			walk(group.children)
+			for i := range group.hosts {
+				rows = append(rows, listRow{host: &group.hosts[i], depth: group.depth + 1})
+			}
+		}
+	}
+	walk(buildGroupTree(hosts, m.groupMode))
+	return rows
+}
+
+// isGroupExpanded reports whether the rows of a group are shown
+func (m *Model) isGroupExpanded(group *hostGroup) bool {
+	return !m.collapsedGroups[group.path] || m.searchInput.Value() != ""
+}
+
+// groupTableRow renders the heading of a group: its name, the number of
+// hosts it holds and their aggregate ping status
+func (m *Model) groupTableRow(group *hostGroup) table.Row {
+	hosts := group.allHosts()
+
+	arrow := "▸"
+	if m.isGroupExpanded(group) {
+		arrow = "▾"
+	}
+	name := fmt.Sprintf("%s%s %s %s (%d)", strings.Repeat("  ", group.depth), arrow, m.getGroupPingIndicator(hosts), group.name, len(hosts))
+
+	var lastLogin time.Time
+	if m.historyManager != nil {
+		for _, host := range hosts {
+			if connected, ok := m.historyManager.GetLastConnectionTime(host.Name); ok && connected.After(lastLogin) {
+				lastLogin = connected
+			}
+		}
+	}
+	var lastLoginStr string
+	if !lastLogin.IsZero() {
+		lastLoginStr = formatTimeAgo(lastLogin)
+	}
+
+	return table.Row{name, m.groupPingSummary(hosts), "", lastLoginStr}
+}
+
+// getGroupPingIndicator returns a colored circle summing up the ping status
+// of the hosts of a group: green when all are online, red when all are
+// offline, orange when some are, yellow while checking
+func (m *Model) getGroupPingIndicator(hosts []config.SSHHost) string {
+	online, offline, connecting := m.countPingStatuses(hosts)
+	switch {
+	case connecting > 0:
+		return "🟡"
+	case online > 0 && online == len(hosts):
+		return "🟢"
+	case offline > 0 && offline == len(hosts):
+		return "🔴"
+	case offline > 0:
+		return "🟠"
+	case online > 0:
+		return "🟢"
+	default:
+		return "⚫"
+	}
+}
+
+// groupPingSummary describes how many hosts of a group are online, once
+// some of them were pinged
+func (m *Model) groupPingSummary(hosts []config.SSHHost) string {
+	online, offline, connecting := m.countPingStatuses(hosts)
+	if online+offline+connecting == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d online", online, len(hosts))
+}
+
+// countPingStatuses counts the hosts in each known ping status
+func (m *Model) countPingStatuses(hosts []config.SSHHost) (online, offline, connecting int) {
+	if m.pingManager == nil {
+		return 0, 0, 0
+	}
+	for _, host := range hosts {
+		switch m.pingManager.GetStatus(host.Name) {
+		case connectivity.StatusOnline:
+			online++
+		case connectivity.StatusOffline:
+			offline++
+		case connectivity.StatusConnecting:
+			connecting++
+		}
+	}
+	return online, offline, connecting
+}
+
+// selectedGroup returns the group whose heading is under the cursor, or nil
+func (m Model) selectedGroup() *hostGroup {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.rows) {
+		return nil
+	}
+	return m.rows[cursor].group
+}
+
+// setGroupCollapsed collapses or expands a group, keeping the cursor on its
+// heading
+func (m *Model) setGroupCollapsed(group *hostGroup, collapsed bool) {
+	if m.collapsedGroups == nil {
+		m.collapsedGroups = make(map[string]bool)
+	}
+	if collapsed {
+		m.collapsedGroups[group.path] = true
+	} else {
+		delete(m.collapsedGroups, group.path)
+	}
+	m.updateTableRows()
+	m.moveCursorToGroup(group.path)
+}
+
+// moveCursorToGroup puts the cursor on the heading of a group
+func (m *Model) moveCursorToGroup(path string) {
+	for i, row := range m.rows {
+		if row.group != nil && row.group.path == path {
+			m.table.SetCursor(i)
+			return
+		}
+	}
+}
+
+// moveCursorToParentGroup puts the cursor on the heading of the group
+// containing the row under the cursor
+func (m *Model) moveCursorToParentGroup() {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.rows) {
+		return
+	}
+	depth := m.rows[cursor].depth
+	for i := cursor - 1; i >= 0; i-- {
+		if m.rows[i].group != nil && m.rows[i].depth < depth {
+			m.table.SetCursor(i)
+			return
+		}
+	}
+}
+
+// moveCursorToHost puts the cursor on a host row, if the host is visible
+func (m *Model) moveCursorToHost(name string) bool {
+	for i, row := range m.rows {
+		if row.host != nil && row.host.Name == name {
+			m.table.SetCursor(i)
+			return true
+		}
+	}
+	return false
+}
+
+// cycleGroupMode switches to the next way of grouping hosts, keeping the
+// selected host under the cursor when it is visible
+func (m *Model) cycleGroupMode() {
+	selected := m.selectedHost()
+	var selectedName string
+	if selected != nil {
+		selectedName = selected.Name
+	}
+
+	m.groupMode = (m.groupMode + 1) % 4
+	m.collapsedGroups = nil
+	m.updateTableRows()
+	if selectedName == "" || !m.moveCursorToHost(selectedName) {
+		m.table.SetCursor(0)
+	}
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// createGroupedTestModel creates a model whose hosts are spread over nested groups
+func createGroupedTestModel() Model {
+	hosts := []config.SSHHost{
+		{Name: "db-eu", Group: "prod/eu", Tags: []string{"db"}},
+		{Name: "web-eu", Group: "prod/eu", Tags: []string{"web"}},
+		{Name: "web-us", Group: "prod/us", Tags: []string{"web"}},
+		{Name: "dev", Group: "dev"},
+		{Name: "laptop"},
+	}
+
+	m := Model{
+		hosts:         hosts,
+		filteredHosts: hosts,
+		searchInput:   textinput.New(),
+		table:         table.New(),
+		ready:         true,
+		width:         80,
+		height:        24,
+		styles:        NewStyles(80),
+		groupMode:     GroupByPath,
+	}
+	m.updateTableColumns()
+	m.updateTableRows()
+	return m
+}
+
+// rowLabels describes the rows of the host table, groups as "+path"
+func rowLabels(m Model) []string {
+	var labels []string
+	for _, row := range m.rows {
+		if row.group != nil {
+			labels = append(labels, "+"+row.group.name)
+		} else {
+			labels = append(labels, row.host.Name)
+		}
+	}
+	return labels
+}
+
+func TestBuildGroupTree(t *testing.T) {
+	m := createGroupedTestModel()
+
+	want := []string{"+dev", "dev", "+prod", "+eu", "db-eu", "web-eu", "+us", "web-us", "+(no group)", "laptop"}
+	got := rowLabels(m)
+	if len(got) != len(want) {
+		t.Fatalf("rows = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("rows = %v, want %v", got, want)
+		}
+	}
+
+	if prod := m.rows[2].group; len(prod.allHosts()) != 3 {
+		t.Errorf("expected prod to hold 3 hosts, got %d", len(prod.allHosts()))
+	}
+}
+
+func TestCollapseAndExpandGroup(t *testing.T) {
+	m := createGroupedTestModel()
+
+	// Fold "prod" from its heading
+	m.table.SetCursor(2)
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	m = newModel.(Model)
+	if got := len(m.rows); got != 5 {
+		t.Fatalf("expected prod's rows to be hidden, got %v", rowLabels(m))
+	}
+	if m.selectedGroup() == nil || m.selectedGroup().path != "prod" {
+		t.Error("expected the cursor to stay on the prod heading")
+	}
+	if m.selectedHost() != nil {
+		t.Error("expected no host to be selected on a group heading")
+	}
+
+	// Searching shows every match, even in folded groups
+	m.searchInput.SetValue("web")
+	m.filteredHosts = m.filterHosts("web")
+	m.updateTableRows()
+	if !m.moveCursorToHost("web-us") {
+		t.Errorf("expected matches in folded groups to be shown, got %v", rowLabels(m))
+	}
+	m.searchInput.SetValue("")
+	m.filteredHosts = m.hosts
+	m.updateTableRows()
+
+	// Unfold it again
+	m.moveCursorToGroup("prod")
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	m = newModel.(Model)
+	if got := len(m.rows); got != 10 {
+		t.Errorf("expected prod's rows to be shown, got %v", rowLabels(m))
+	}
+}
+
+func TestCycleGroupModeKeepsSelection(t *testing.T) {
+	m := createGroupedTestModel()
+	m.moveCursorToHost("web-us")
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	m = newModel.(Model)
+	if m.groupMode != GroupByFile {
+		t.Errorf("groupMode = %v, want %v", m.groupMode, GroupByFile)
+	}
+	if host := m.selectedHost(); host == nil || host.Name != "web-us" {
+		t.Errorf("expected web-us to stay selected, got %v", host)
+	}
+}
@@ -73,6 +73,27 @@ func (m *helpModel) View() string {
 		lipgloss.JoinHorizontal(lipgloss.Left,
 			m.styles.FocusedLabel.Render("d  "),
 			m.styles.HelpText.Render("delete selected host")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.styles.FocusedLabel.Render("u  "),
+			m.styles.HelpText.Render("undo last change")),
+		"",
+		m.styles.FocusedLabel.Render("Selection"),
+		"",
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.styles.FocusedLabel.Render("Space "),
+			m.styles.HelpText.Render("select host or group")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.styles.FocusedLabel.Render("^A "),
+			m.styles.HelpText.Render("select all matching hosts")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.styles.FocusedLabel.Render("t  "),
+			m.styles.HelpText.Render("add/remove/replace tags")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.styles.FocusedLabel.Render("x  "),
+			m.styles.HelpText.Render("export hosts to a file")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.styles.FocusedLabel.Render("m/d/p "),
+			m.styles.HelpText.Render("move/delete/ping selection")),
 	)
 
 	rightColumn := lipgloss.JoinVertical(lipgloss.Left,
@@ -80,7 +101,19 @@ func (m *helpModel) View() string {
 		"",
 		lipgloss.JoinHorizontal(lipgloss.Left,
 			m.styles.FocusedLabel.Render("p  "),
-			m.styles.HelpText.Render("ping all hosts")),
+			m.styles.HelpText.Render("ping all hosts, or the selected group")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.styles.FocusedLabel.Render("g  "),
+			m.styles.HelpText.Render("group by # Group:, file, tag or none")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.styles.FocusedLabel.Render("T  "),
+			m.styles.HelpText.Render("browse tags, filter by tag")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.styles.FocusedLabel.Render("S  "),
+			m.styles.HelpText.Render("save host as a template")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.styles.FocusedLabel.Render("←/→ "),
+			m.styles.HelpText.Render("collapse/expand group")),
 		lipgloss.JoinHorizontal(lipgloss.Left,
 			m.styles.FocusedLabel.Render("f  "),
 			m.styles.HelpText.Render("setup port forwarding")),
@@ -93,6 +126,15 @@ func (m *helpModel) View() string {
 		lipgloss.JoinHorizontal(lipgloss.Left,
 			m.styles.FocusedLabel.Render("r  "),
 			m.styles.HelpText.Render("sort by recent connection")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.styles.FocusedLabel.Render("D  "),
+			m.styles.HelpText.Render("edit host patterns (Host *)")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.styles.FocusedLabel.Render("M  "),
+			m.styles.HelpText.Render("show Match blocks")),
+		lipgloss.JoinHorizontal(lipgloss.Left,
+			m.styles.FocusedLabel.Render("W  "),
+			m.styles.HelpText.Render("show config problems")),
 		"",
 		m.styles.FocusedLabel.Render("System"),
 		"",
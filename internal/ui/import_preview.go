@@ -0,0 +1,267 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/importer"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// importPreviewModel lists the hosts about to be imported and lets the user
+// choose what happens to each, conflicting ones in particular
+type importPreviewModel struct {
+	candidates []importer.Candidate
+	existing   []config.SSHHost
+	target     string
+	selected   int
+	renaming   bool
+	input      textinput.Model
+	err        string
+	confirmed  bool
+	styles     Styles
+	width      int
+	height     int
+}
+
+// newImportPreview creates the preview of an import into a config file
+func newImportPreview(candidates []importer.Candidate, existing []config.SSHHost, target string, styles Styles, width, height int) *importPreviewModel {
+	input := textinput.New()
+	input.CharLimit = 50
+	input.Width = 30
+	return &importPreviewModel{
+		candidates: candidates,
+		existing:   existing,
+		target:     target,
+		input:      input,
+		styles:     styles,
+		width:      width,
+		height:     height,
+	}
+}
+
+func (m *importPreviewModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *importPreviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.styles = NewStyles(m.width)
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.renaming {
+			return m.updateRename(msg)
+		}
+
+		m.err = ""
+		c := &m.candidates[m.selected]
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			return m, tea.Quit
+		case "enter":
+			m.confirmed = true
+			return m, tea.Quit
+		case "up", "k":
+			if m.selected > 0 {
+				m.selected--
+			}
+		case "down", "j":
+			if m.selected < len(m.candidates)-1 {
+				m.selected++
+			}
+		case " ":
+			m.cycleAction(c)
+		case "s":
+			m.setAction(c, importer.ActionSkip)
+		case "o":
+			m.setAction(c, importer.ActionOverwrite)
+		case "u":
+			m.setAction(c, importer.ActionUpdate)
+		case "i":
+			m.setAction(c, importer.ActionImport)
+		case "r":
+			name := c.Name
+			if importer.NameTaken(name, m.existing, m.candidates, m.selected) {
+				name = importer.FreeName(c.Host.Name, func(n string) bool {
+					return importer.NameTaken(n, m.existing, m.candidates, m.selected)
+				})
+			}
+			m.input.SetValue(name)
+			m.input.CursorEnd()
+			m.input.Focus()
+			m.renaming = true
+			return m, textinput.Blink
+		}
+	}
+
+	return m, nil
+}
+
+// updateRename handles keys while a new name is typed
+func (m *importPreviewModel) updateRename(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		m.renaming = false
+		m.err = ""
+		m.input.Blur()
+		return m, nil
+	case "enter":
+		name := strings.TrimSpace(m.input.Value())
+		if err := importer.ValidateRename(name, m.existing, m.candidates, m.selected); err != nil {
+			m.err = err.Error()
+			return m, nil
+		}
+		c := &m.candidates[m.selected]
+		c.Name = name
+		c.Action = importer.ActionRename
+		if name == c.Host.Name && !c.Conflict() {
+			c.Action = importer.ActionImport
+		}
+		m.renaming = false
+		m.err = ""
+		m.input.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// setAction changes what happens to a host, when the action suits it
+func (m *importPreviewModel) setAction(c *importer.Candidate, action importer.Action) {
+	switch action {
+	case importer.ActionImport:
+		if c.Conflict() {
+			m.err = fmt.Sprintf("'%s' already exists: skip (s), rename (r), update (u) or overwrite (o) it", c.Host.Name)
+			return
+		}
+		c.Name = c.Host.Name
+	case importer.ActionOverwrite, importer.ActionUpdate:
+		if !c.CanOverwrite() {
+			if c.Conflict() {
+				m.err = fmt.Sprintf("'%s' is defined in the system-wide SSH config and is read-only", c.Host.Name)
+			} else {
+				m.err = fmt.Sprintf("'%s' is a new host, there is nothing to %s", c.Host.Name, action)
+			}
+			return
+		}
+		c.Name = c.Host.Name
+	}
+	c.Action = action
+}
+
+// cycleAction switches a new host between import and skip, and cycles a
+// conflicting one through skip, update and overwrite
+func (m *importPreviewModel) cycleAction(c *importer.Candidate) {
+	if !c.Conflict() {
+		if c.Action == importer.ActionSkip {
+			m.setAction(c, importer.ActionImport)
+		} else {
+			m.setAction(c, importer.ActionSkip)
+		}
+		return
+	}
+
+	switch c.Action {
+	case importer.ActionSkip:
+		if c.CanOverwrite() {
+			m.setAction(c, importer.ActionUpdate)
+		}
+	case importer.ActionUpdate:
+		m.setAction(c, importer.ActionOverwrite)
+	default:
+		m.setAction(c, importer.ActionSkip)
+	}
+}
+
+func (m *importPreviewModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render(fmt.Sprintf("Import %d host(s) into %s", len(m.candidates), m.target)))
+	b.WriteString("\n\n")
+
+	// Keep room for the title, summary, hint lines and container borders
+	visible := m.height - 12
+	if visible < 5 {
+		visible = 5
+	}
+	start := 0
+	if m.selected >= visible {
+		start = m.selected - visible + 1
+	}
+	end := start + visible
+	if end > len(m.candidates) {
+		end = len(m.candidates)
+	}
+
+	conflictStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(ErrorColor))
+	skipStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(SecondaryColor))
+	for i := start; i < end; i++ {
+		c := m.candidates[i]
+		line := fmt.Sprintf("%-10s %-24s %s", "["+c.Action.String()+"]", importPreviewName(c), importer.Endpoint(c.Host))
+		if c.Conflict() {
+			line += conflictStyle.Render(fmt.Sprintf("  exists in %s", c.Existing.SourceFile))
+		}
+
+		switch {
+		case i == m.selected:
+			b.WriteString(m.styles.Selected.Render("▶ " + line))
+		case c.Action == importer.ActionSkip:
+			b.WriteString("  " + skipStyle.Render(line))
+		default:
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(importer.Summary(m.candidates))
+	b.WriteString("\n\n")
+
+	if m.renaming {
+		b.WriteString(m.styles.FocusedLabel.Render("New name: "))
+		b.WriteString(m.input.View())
+		b.WriteString("\n\n")
+	}
+	if m.err != "" {
+		b.WriteString(m.styles.Error.Render(m.err))
+		b.WriteString("\n\n")
+	}
+
+	if m.renaming {
+		b.WriteString(m.styles.HelpText.Render("Enter: rename • ESC: cancel"))
+	} else {
+		b.WriteString(m.styles.HelpText.Render("↑/↓: navigate • Space: toggle • s: skip • r: rename • u: update • o: overwrite • i: import • Enter: write • ESC/q: cancel"))
+	}
+
+	return m.styles.FormContainer.Render(b.String())
+}
+
+// importPreviewName shows the name a host is written under
+func importPreviewName(c importer.Candidate) string {
+	if c.Action == importer.ActionRename && c.Name != c.Host.Name {
+		return c.Host.Name + " → " + c.Name
+	}
+	return c.Host.Name
+}
+
+// RunImportPreview shows the hosts about to be imported into a config file
+// and lets the user skip, rename, update or overwrite each. It returns the candidates
+// as resolved, and whether the import was confirmed.
+func RunImportPreview(candidates []importer.Candidate, existing []config.SSHHost, target string) ([]importer.Candidate, bool, error) {
+	m := newImportPreview(candidates, existing, target, NewStyles(80), 80, 24)
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return nil, false, err
+	}
+	return m.candidates, m.confirmed, nil
+}
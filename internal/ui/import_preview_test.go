@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/Gu1llaum-3/sshm/internal/importer"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestImportPreviewActions(t *testing.T) {
+	existing := []config.SSHHost{{Name: "web", Aliases: []string{"web"}, SourceFile: "/tmp/config"}}
+	hosts := []config.SSHHost{
+		{Name: "web", Aliases: []string{"web"}, Hostname: "10.0.0.1"},
+		{Name: "api", Aliases: []string{"api"}, Hostname: "10.0.0.2"},
+	}
+	m := newImportPreview(importer.Resolve(hosts, existing, importer.ActionSkip), existing, "/tmp/config", NewStyles(80), 80, 24)
+
+	press := func(keys ...string) {
+		for _, k := range keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			switch k {
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case " ":
+				msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+			}
+			m.Update(msg)
+		}
+	}
+
+	// A conflicting host can't simply be imported
+	press("i")
+	if m.candidates[0].Action != importer.ActionSkip || m.err == "" {
+		t.Errorf("web action = %s, err = %q, want it skipped with an error", m.candidates[0].Action, m.err)
+	}
+	press(" ")
+	if m.candidates[0].Action != importer.ActionUpdate {
+		t.Errorf("web action = %s, want update", m.candidates[0].Action)
+	}
+	press(" ")
+	if m.candidates[0].Action != importer.ActionOverwrite {
+		t.Errorf("web action = %s, want overwrite", m.candidates[0].Action)
+	}
+
+	// Renaming suggests a free name
+	press("r")
+	if !m.renaming || m.input.Value() != "web-2" {
+		t.Fatalf("renaming = %v, input = %q, want web-2 suggested", m.renaming, m.input.Value())
+	}
+	press("enter")
+	if m.candidates[0].Action != importer.ActionRename || m.candidates[0].Name != "web-2" {
+		t.Errorf("web = %+v, want renamed to web-2", m.candidates[0])
+	}
+
+	press("j", " ")
+	if m.candidates[1].Action != importer.ActionSkip {
+		t.Errorf("api action = %s, want skip", m.candidates[1].Action)
+	}
+	press("enter")
+	if !m.confirmed {
+		t.Error("Enter should confirm the import")
+	}
+}
@@ -11,6 +11,8 @@ import (
 
 type infoFormModel struct {
 	host       *config.SSHHost
+	matches    []config.AppliedMatch   // Match blocks that apply, or may apply, to the host
+	effective  *config.EffectiveConfig // Settings ssh would use, nil if they can't be resolved
 	styles     Styles
 	width      int
 	height     int
@@ -27,22 +29,24 @@ type infoFormCancelMsg struct{}
 
 // NewInfoForm creates a new info form model for displaying host details in read-only mode
 func NewInfoForm(hostName string, styles Styles, width, height int, configFile string) (*infoFormModel, error) {
-	// Get the existing host configuration
-	var host *config.SSHHost
-	var err error
-
-	if configFile != "" {
-		host, err = config.GetSSHHostFromFile(hostName, configFile)
-	} else {
-		host, err = config.GetSSHHost(hostName)
+	// Get the existing host configuration, from the system-wide config too
+	parsed, err := config.ParseConfigLayers(configFile)
+	if err != nil {
+		return nil, err
 	}
-
+	host, err := parsed.FindHost(hostName)
 	if err != nil {
 		return nil, err
 	}
 
+	// Match blocks are informational and don't fail the view
+	matches := parsed.MatchBlocksFor(*host)
+	effective, _ := config.ResolveEffectiveConfig(hostName, configFile)
+
 	return &infoFormModel{
 		host:       host,
+		matches:    matches,
+		effective:  effective,
 		hostName:   hostName,
 		configFile: configFile,
 		styles:     styles,
@@ -69,7 +73,10 @@ func (m *infoFormModel) Update(msg tea.Msg) (*infoFormModel, tea.Cmd) {
 			return m, func() tea.Msg { return infoFormCancelMsg{} }
 
 		case "e", "enter":
-			// Switch to edit mode
+			// Switch to edit mode, system hosts are read-only
+			if m.host.System {
+				return m, nil
+			}
 			return m, func() tea.Msg { return infoFormEditMsg{hostName: m.hostName} }
 		}
 	}
@@ -86,19 +93,29 @@ func (m *infoFormModel) View() string {
 	b.WriteString("\n\n")
 
 	// Create info sections with consistent formatting
-	sections := []struct {
+	type infoSection struct {
 		label string
 		value string
-	}{
+	}
+	sections := []infoSection{
 		{"Host Name", m.host.Name},
-		{"Config File", formatConfigFile(m.host.SourceFile)},
+		{"Aliases", formatHostAliases(m.host)},
+		{"Config File", formatHostSource(*m.host)},
 		{"Hostname/IP", m.host.Hostname},
 		{"User", formatOptionalValue(m.host.User)},
 		{"Port", formatOptionalValue(m.host.Port)},
-		{"Identity File", formatOptionalValue(m.host.Identity)},
+		{"Identity File", formatOptionalValue(strings.Join(m.host.GetAll("IdentityFile"), "\n"))},
 		{"ProxyJump", formatOptionalValue(m.host.ProxyJump)},
-		{"SSH Options", formatSSHOptions(m.host.Options)},
+		{"SSH Options", formatSSHOptions(m.host.OtherDirectives())},
 		{"Tags", formatTags(m.host.Tags)},
+		{"Description", formatOptionalValue(m.host.Metadata.Description)},
+		{"Owner", formatOptionalValue(m.host.Metadata.Owner)},
+		{"Environment", formatOptionalValue(m.host.Metadata.Environment)},
+		{"Links", formatOptionalValue(strings.Join(m.host.Metadata.Links, "\n"))},
+	}
+	for _, entry := range m.host.Metadata.Extra {
+		// Other "# sshm:" entries are shown as written
+		sections = append(sections, infoSection{entry.Key, entry.Value})
 	}
 
 	// Render each section
@@ -129,6 +146,24 @@ func (m *infoFormModel) View() string {
 		b.WriteString("\n")
 	}
 
+	if m.host.Metadata.Notes != "" {
+		b.WriteString("\n")
+		b.WriteString(renderNotes(m.host.Metadata.Notes))
+		b.WriteString("\n")
+	}
+
+	if m.effective != nil {
+		b.WriteString("\n")
+		b.WriteString(renderEffectiveConfig(m.effective))
+		b.WriteString("\n")
+	}
+
+	if len(m.matches) > 0 {
+		b.WriteString("\n")
+		b.WriteString(renderAppliedMatches(m.matches))
+		b.WriteString("\n")
+	}
+
 	b.WriteString("\n")
 
 	// Action instructions
@@ -143,10 +178,16 @@ func (m *infoFormModel) View() string {
 		Foreground(lipgloss.Color("120")). // Green
 		Bold(true)
 
-	b.WriteString("  ")
-	b.WriteString(actionStyle.Render("e/Enter"))
-	b.WriteString(helpStyle.Render(" - Switch to edit mode"))
-	b.WriteString("\n")
+	if m.host.System {
+		b.WriteString("  ")
+		b.WriteString(helpStyle.Render("Read-only: defined in the system-wide SSH config"))
+		b.WriteString("\n")
+	} else {
+		b.WriteString("  ")
+		b.WriteString(actionStyle.Render("e/Enter"))
+		b.WriteString(helpStyle.Render(" - Switch to edit mode"))
+		b.WriteString("\n")
+	}
 
 	b.WriteString("  ")
 	b.WriteString(actionStyle.Render("q/Esc"))
@@ -171,6 +212,55 @@ func (m *infoFormModel) View() string {
 	)
 }
 
+// renderEffectiveConfig lists the settings ssh would use and where each one is set
+func renderEffectiveConfig(effective *config.EffectiveConfig) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+	keyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("39")).
+		Width(22)
+	valueStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("255"))
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("243"))
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Effective Configuration:"))
+	for _, value := range effective.Values {
+		source := value.Source
+		if value.SourceFile != "" {
+			source = fmt.Sprintf("%s, %s:%d", value.Source, formatConfigFile(value.SourceFile), value.Line)
+		}
+		b.WriteString("\n  ")
+		b.WriteString(keyStyle.Render(value.Key))
+		b.WriteString(valueStyle.Render(value.Value))
+		b.WriteString(mutedStyle.Render("  (" + source + ")"))
+	}
+	return b.String()
+}
+
+// renderAppliedMatches lists the Match blocks that apply to the host
+func renderAppliedMatches(matches []config.AppliedMatch) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+	conditionStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("255"))
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("243"))
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Match Blocks:"))
+	for _, match := range matches {
+		b.WriteString("\n  ")
+		b.WriteString(conditionStyle.Render("Match " + match.Block.Condition))
+		b.WriteString(mutedStyle.Render(fmt.Sprintf(" (%s, %s:%d)",
+			match.Status, formatConfigFile(match.Block.SourceFile), match.Block.Line)))
+	}
+	return b.String()
+}
+
 // Helper functions for formatting values
 
 func formatOptionalValue(value string) string {
@@ -180,11 +270,60 @@ func formatOptionalValue(value string) string {
 	return value
 }
 
-func formatSSHOptions(options string) string {
-	if options == "" {
+func formatSSHOptions(directives []config.Directive) string {
+	var lines []string
+	for _, d := range directives {
+		// Extra identity files are listed with the first one
+		if strings.EqualFold(d.Key, "IdentityFile") {
+			continue
+		}
+		lines = append(lines, config.LookupKeyword(d.Key).Name+" "+d.Value)
+	}
+	if len(lines) == 0 {
+		return "Not set"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatHostAliases lists the other names and patterns declared on the Host line
+func formatHostAliases(host *config.SSHHost) string {
+	var others []string
+	for _, pattern := range host.Patterns {
+		if pattern != host.Name {
+			others = append(others, pattern)
+		}
+	}
+	if len(others) == 0 {
 		return "Not set"
 	}
-	return options
+	return strings.Join(others, " ")
+}
+
+// renderNotes renders the Markdown notes of a host, with headings and list
+// markers highlighted
+func renderNotes(notes string) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+	headingStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("255"))
+	textStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252"))
+
+	lines := []string{titleStyle.Render("Notes:")}
+	for _, line := range strings.Split(notes, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			lines = append(lines, "  "+headingStyle.Render(strings.TrimSpace(strings.TrimLeft(trimmed, "#"))))
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "):
+			lines = append(lines, "  "+textStyle.Render("• "+trimmed[2:]))
+		default:
+			lines = append(lines, "  "+textStyle.Render(line))
+		}
+	}
+	return strings.Join(lines, "\n")
 }
 
 func formatTags(tags []string) string {
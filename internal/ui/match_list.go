@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// matchListModel displays the Match blocks of the configuration in read-only mode
+type matchListModel struct {
+	blocks []config.MatchBlock
+	offset int
+	styles Styles
+	width  int
+	height int
+}
+
+// matchListCloseMsg is sent when the Match block list is closed
+type matchListCloseMsg struct{}
+
+// NewMatchList creates a new read-only Match block list
+func NewMatchList(styles Styles, width, height int, configFile string) (*matchListModel, error) {
+	parsed, err := config.ParseConfigLayers(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &matchListModel{
+		blocks: parsed.MatchBlocks,
+		styles: styles,
+		width:  width,
+		height: height,
+	}, nil
+}
+
+func (m *matchListModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *matchListModel) Update(msg tea.Msg) (*matchListModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.styles = NewStyles(m.width)
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q", "M":
+			return m, func() tea.Msg { return matchListCloseMsg{} }
+		case "up", "k":
+			if m.offset > 0 {
+				m.offset--
+			}
+		case "down", "j":
+			if m.offset < len(m.contentLines())-1 {
+				m.offset++
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// contentLines renders every Match block as a list of lines
+func (m *matchListModel) contentLines() []string {
+	conditionStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+	directiveStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("255"))
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("243"))
+
+	if len(m.blocks) == 0 {
+		return []string{mutedStyle.Render("No Match blocks found in the configuration")}
+	}
+
+	var lines []string
+	for i, block := range m.blocks {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, conditionStyle.Render("Match "+block.Condition)+
+			mutedStyle.Render(fmt.Sprintf("  %s:%d", formatConfigFile(block.SourceFile), block.Line)))
+		if len(block.Directives) == 0 {
+			lines = append(lines, mutedStyle.Render("    (no directives)"))
+		}
+		for _, directive := range block.Directives {
+			lines = append(lines, directiveStyle.Render("    "+directive.Key+" "+directive.Value))
+		}
+	}
+	return lines
+}
+
+func (m *matchListModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render(fmt.Sprintf("Match Blocks (%d)", len(m.blocks))))
+	b.WriteString("\n")
+	b.WriteString(m.styles.HelpText.Render("Match blocks are read-only in sshm, edit them in your config file"))
+	b.WriteString("\n\n")
+
+	lines := m.contentLines()
+
+	// Keep room for the title, hint lines and container borders
+	visible := m.height - 10
+	if visible < 5 {
+		visible = 5
+	}
+	end := m.offset + visible
+	if end > len(lines) {
+		end = len(lines)
+	}
+	b.WriteString(strings.Join(lines[m.offset:end], "\n"))
+	b.WriteString("\n\n")
+
+	b.WriteString(m.styles.HelpText.Render("↑/↓: scroll • ESC/q: back"))
+
+	return m.styles.FormContainer.Render(b.String())
+}
@@ -42,6 +42,12 @@ const (
 	ViewPortForward
 	ViewHelp
 	ViewFileSelector
+	ViewMatchList
+	ViewPatterns
+	ViewDiagnostics
+	ViewBulk
+	ViewTags
+	ViewTemplate
 )
 
 // PortForwardType defines the type of port forwarding
@@ -80,6 +86,17 @@ type Model struct {
 	sortMode       SortMode
 	configFile     string // Path to the SSH config file
 
+	// Host tree: how hosts are grouped, the groups folded by the user and
+	// what each table row shows
+	groupMode       GroupMode
+	collapsedGroups map[string]bool
+	rows            []listRow
+
+	// Multi-selection: names of the hosts bulk actions apply to, and the
+	// hosts awaiting a bulk delete confirmation
+	selectedHosts map[string]bool
+	deleteHosts   []config.SSHHost
+
 	// Application configuration
 	appConfig      *config.AppConfig
 
@@ -96,6 +113,12 @@ type Model struct {
 	portForwardForm  *portForwardModel
 	helpForm         *helpModel
 	fileSelectorForm *fileSelectorModel
+	matchListForm    *matchListModel
+	patternList      *patternListModel
+	diagnosticsPanel *diagnosticsModel
+	bulkForm         *bulkFormModel
+	tagBrowser       *tagBrowserModel
+	templateForm     *templateFormModel
 
 	// Terminal size and styles
 	width  int
@@ -106,6 +129,16 @@ type Model struct {
 	// Error handling
 	errorMessage string
 	showingError bool
+
+	// Transient confirmation shown above the search bar
+	statusMessage string
+
+	// Problems met while reading the config, such as unreadable includes
+	diagnostics []config.Diagnostic
+
+	// State of the config files on disk, polled to reload outside edits
+	configSnapshot   configSnapshot
+	configGeneration int
 }
 
 // updateTableStyles updates the table header border color based on focus state
@@ -11,11 +11,13 @@ import (
 type moveFormModel struct {
 	fileSelector *fileSelectorModel
 	hostName     string
+	hosts        []config.SSHHost // Hosts moved together, when several are selected
 	configFile   string
 	width        int
 	height       int
 	styles       Styles
 	state        moveFormState
+	err          string
 }
 
 type moveFormState int
@@ -68,6 +70,39 @@ func NewMoveForm(hostName string, styles Styles, width, height int, configFile s
 	}, nil
 }
 
+// NewBulkMoveForm creates a move form for moving several hosts to a config file
+func NewBulkMoveForm(hosts []config.SSHHost, styles Styles, width, height int, configFile string) (*moveFormModel, error) {
+	files, err := config.GetAllConfigFilesFromBase(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config files: %v", err)
+	}
+
+	if len(files) <= 1 {
+		return nil, fmt.Errorf("no includes found in SSH config file - move operation requires multiple config files")
+	}
+
+	fileSelector, err := newFileSelectorFromFiles(
+		fmt.Sprintf("Select destination config file for %d hosts:", len(hosts)),
+		styles,
+		width,
+		height,
+		files,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file selector: %v", err)
+	}
+
+	return &moveFormModel{
+		fileSelector: fileSelector,
+		hosts:        hosts,
+		configFile:   configFile,
+		width:        width,
+		height:       height,
+		styles:       styles,
+		state:        moveFormSelectingFile,
+	}, nil
+}
+
 func (m *moveFormModel) Init() tea.Cmd {
 	return m.fileSelector.Init()
 }
@@ -93,6 +128,7 @@ func (m *moveFormModel) Update(msg tea.Msg) (*moveFormModel, tea.Cmd) {
 				if m.fileSelector != nil && len(m.fileSelector.files) > 0 {
 					selectedFile := m.fileSelector.files[m.fileSelector.selected]
 					m.state = moveFormProcessing
+					m.err = ""
 					return m, m.submitMove(selectedFile)
 				}
 			case "esc", "q":
@@ -121,12 +157,19 @@ func (m *moveFormModel) Update(msg tea.Msg) (*moveFormModel, tea.Cmd) {
 func (m *moveFormModel) View() string {
 	switch m.state {
 	case moveFormSelectingFile:
-		if m.fileSelector != nil {
-			return m.fileSelector.View()
+		if m.fileSelector == nil {
+			return "Loading..."
+		}
+		if m.err != "" {
+			return m.fileSelector.View() + "\n" + m.styles.Error.Render("Error: "+m.err)
 		}
-		return "Loading..."
+		return m.fileSelector.View()
 
 	case moveFormProcessing:
+		if len(m.hosts) > 0 {
+			return m.styles.FormTitle.Render("Moving hosts...") + "\n\n" +
+				m.styles.HelpText.Render(fmt.Sprintf("Moving %d hosts to selected config file...", len(m.hosts)))
+		}
 		return m.styles.FormTitle.Render("Moving host...") + "\n\n" +
 			m.styles.HelpText.Render(fmt.Sprintf("Moving host '%s' to selected config file...", m.hostName))
 
@@ -137,6 +180,13 @@ func (m *moveFormModel) View() string {
 
 func (m *moveFormModel) submitMove(targetFile string) tea.Cmd {
 	return func() tea.Msg {
+		if len(m.hosts) > 0 {
+			return moveFormSubmitMsg{
+				targetFile: targetFile,
+				err:        config.MoveHostsToFile(m.hosts, targetFile),
+			}
+		}
+
 		err := config.MoveHostToFile(m.hostName, targetFile)
 		return moveFormSubmitMsg{
 			hostName:   m.hostName,
@@ -149,6 +199,7 @@ func (m *moveFormModel) submitMove(targetFile string) tea.Cmd {
 // Standalone move form for CLI usage
 type standaloneMoveForm struct {
 	moveFormModel *moveFormModel
+	err           error
 }
 
 func (m standaloneMoveForm) Init() tea.Cmd {
@@ -156,11 +207,12 @@ func (m standaloneMoveForm) Init() tea.Cmd {
 }
 
 func (m standaloneMoveForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg.(type) {
+	switch msg := msg.(type) {
 	case moveFormCancelMsg:
 		return m, tea.Quit
 	case moveFormSubmitMsg:
 		// En mode standalone, on quitte après le déplacement (succès ou erreur)
+		m.err = msg.err
 		return m, tea.Quit
 	}
 
@@ -180,9 +232,15 @@ func RunMoveForm(hostName string, configFile string) error {
 	if err != nil {
 		return err
 	}
-	m := standaloneMoveForm{moveForm}
+	m := standaloneMoveForm{moveFormModel: moveForm}
 
 	p := tea.NewProgram(m, tea.WithAltScreen())
-	_, err = p.Run()
-	return err
+	final, err := p.Run()
+	if err != nil {
+		return err
+	}
+	if result, ok := final.(standaloneMoveForm); ok && result.err != nil {
+		return result.err
+	}
+	return nil
 }
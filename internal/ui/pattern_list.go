@@ -0,0 +1,219 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// patternListModel lists wildcard Host blocks, the defaults shared by many hosts
+type patternListModel struct {
+	patterns   []config.SSHHost
+	cursor     int
+	deleting   bool // Waiting for the delete confirmation
+	err        string
+	styles     Styles
+	width      int
+	height     int
+	configFile string
+}
+
+// Messages for communication with parent model
+type patternListAddMsg struct{}
+
+type patternListEditMsg struct {
+	pattern string
+}
+
+type patternListCloseMsg struct{}
+
+// NewPatternList creates a new list of the wildcard Host blocks
+func NewPatternList(styles Styles, width, height int, configFile string) (*patternListModel, error) {
+	m := &patternListModel{
+		styles:     styles,
+		width:      width,
+		height:     height,
+		configFile: configFile,
+	}
+	if err := m.reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// reload reads the pattern blocks again, keeping the cursor in range
+func (m *patternListModel) reload() error {
+	parsed, err := config.ParseConfigLayers(m.configFile)
+	if err != nil {
+		return err
+	}
+
+	m.patterns = parsed.Patterns
+	if m.cursor >= len(m.patterns) {
+		m.cursor = len(m.patterns) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	return nil
+}
+
+// selected returns the pattern block under the cursor, or nil if the list is empty
+func (m *patternListModel) selected() *config.SSHHost {
+	if len(m.patterns) == 0 {
+		return nil
+	}
+	return &m.patterns[m.cursor]
+}
+
+func (m *patternListModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *patternListModel) Update(msg tea.Msg) (*patternListModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.styles = NewStyles(m.width)
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.deleting {
+			switch msg.String() {
+			case "y", "Y":
+				m.deleting = false
+				if pattern := m.selected(); pattern != nil {
+					if err := config.DeletePatternBlockFromFile(pattern.Name, pattern.SourceFile); err != nil {
+						m.err = err.Error()
+						return m, nil
+					}
+					m.err = ""
+					if err := m.reload(); err != nil {
+						m.err = err.Error()
+					}
+				}
+			default:
+				m.deleting = false
+			}
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			return m, func() tea.Msg { return patternListCloseMsg{} }
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.patterns)-1 {
+				m.cursor++
+			}
+		case "a":
+			return m, func() tea.Msg { return patternListAddMsg{} }
+		case "e", "enter":
+			if pattern := m.selected(); pattern != nil {
+				if pattern.System {
+					m.err = readOnlyPatternError(pattern)
+					return m, nil
+				}
+				name := pattern.Name
+				return m, func() tea.Msg { return patternListEditMsg{pattern: name} }
+			}
+		case "d":
+			if pattern := m.selected(); pattern != nil {
+				if pattern.System {
+					m.err = readOnlyPatternError(pattern)
+					return m, nil
+				}
+				m.deleting = true
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m *patternListModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render(fmt.Sprintf("Host Patterns (%d)", len(m.patterns))))
+	b.WriteString("\n")
+	b.WriteString(m.styles.HelpText.Render("Wildcard Host blocks provide defaults for every host they match"))
+	b.WriteString("\n\n")
+
+	patternStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("255"))
+	selectedStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("243"))
+
+	if len(m.patterns) == 0 {
+		b.WriteString(mutedStyle.Render("No pattern blocks found, press 'a' to add one (e.g. Host *)"))
+		b.WriteString("\n")
+	}
+
+	for i, pattern := range m.patterns {
+		prefix, style := "  ", patternStyle
+		if i == m.cursor {
+			prefix, style = "▶ ", selectedStyle
+		}
+		b.WriteString(style.Render(prefix + "Host " + pattern.Name))
+		b.WriteString(mutedStyle.Render("  " + formatHostSource(pattern)))
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("    " + summarizePattern(pattern)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if m.deleting {
+		if pattern := m.selected(); pattern != nil {
+			b.WriteString(m.styles.Error.Render(fmt.Sprintf("Delete 'Host %s'? (y/N)", pattern.Name)))
+			b.WriteString("\n\n")
+		}
+	}
+
+	if m.err != "" {
+		b.WriteString(m.styles.Error.Render("Error: " + m.err))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(m.styles.HelpText.Render("↑/↓: navigate • a: add • e/Enter: edit • d: delete • ESC/q: back"))
+
+	return m.styles.FormContainer.Render(b.String())
+}
+
+// readOnlyPatternError explains that a pattern block from the system-wide
+// config can't be changed
+func readOnlyPatternError(pattern *config.SSHHost) string {
+	return fmt.Sprintf("'Host %s' is defined in the system-wide SSH config and is read-only", pattern.Name)
+}
+
+// summarizePattern lists the settings of a pattern block on one line
+func summarizePattern(pattern config.SSHHost) string {
+	var parts []string
+	for _, field := range []struct{ key, value string }{
+		{"HostName", pattern.Hostname},
+		{"User", pattern.User},
+		{"Port", pattern.Port},
+		{"IdentityFile", pattern.Identity},
+		{"ProxyJump", pattern.ProxyJump},
+	} {
+		if field.value != "" {
+			parts = append(parts, field.key+" "+field.value)
+		}
+	}
+	if pattern.Options != "" {
+		parts = append(parts, strings.Split(pattern.Options, "\n")...)
+	}
+	if len(parts) == 0 {
+		return "(no settings)"
+	}
+	return strings.Join(parts, " • ")
+}
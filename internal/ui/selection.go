@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"github.com/Gu1llaum-3/sshm/internal/config"
+)
+
+// isHostSelected reports whether a host is part of the multi-selection
+func (m Model) isHostSelected(name string) bool {
+	return m.selectedHosts[name]
+}
+
+// setHostsSelected adds hosts to the multi-selection or removes them from it
+func (m *Model) setHostsSelected(hosts []config.SSHHost, selected bool) {
+	if m.selectedHosts == nil {
+		m.selectedHosts = make(map[string]bool)
+	}
+	for _, host := range hosts {
+		if selected {
+			m.selectedHosts[host.Name] = true
+		} else {
+			delete(m.selectedHosts, host.Name)
+		}
+	}
+}
+
+// allHostsSelected reports whether every host of a list is selected
+func (m Model) allHostsSelected(hosts []config.SSHHost) bool {
+	for _, host := range hosts {
+		if !m.isHostSelected(host.Name) {
+			return false
+		}
+	}
+	return len(hosts) > 0
+}
+
+// toggleSelection selects the host under the cursor, or every host of the
+// group under it, or unselects them when they were all selected
+func (m *Model) toggleSelection() {
+	var hosts []config.SSHHost
+	if group := m.selectedGroup(); group != nil {
+		hosts = group.allHosts()
+	} else if host := m.selectedHost(); host != nil {
+		hosts = []config.SSHHost{*host}
+	}
+	if len(hosts) == 0 {
+		return
+	}
+
+	m.setHostsSelected(hosts, !m.allHostsSelected(hosts))
+	m.updateTableRows()
+	m.table.MoveDown(1)
+}
+
+// toggleSelectAll selects every host matching the current filter, or clears
+// the selection when they were all selected
+func (m *Model) toggleSelectAll() {
+	if m.allHostsSelected(m.filteredHosts) {
+		m.clearSelection()
+		return
+	}
+	m.setHostsSelected(m.filteredHosts, true)
+	m.updateTableRows()
+}
+
+// clearSelection empties the multi-selection
+func (m *Model) clearSelection() {
+	m.selectedHosts = nil
+	m.updateTableRows()
+}
+
+// pruneSelection drops the selected hosts that no longer exist
+func (m *Model) pruneSelection() {
+	if len(m.selectedHosts) == 0 {
+		return
+	}
+	existing := make(map[string]bool, len(m.hosts))
+	for _, host := range m.hosts {
+		existing[host.Name] = true
+	}
+	for name := range m.selectedHosts {
+		if !existing[name] {
+			delete(m.selectedHosts, name)
+		}
+	}
+}
+
+// selectionHosts returns the selected hosts in list order
+func (m Model) selectionHosts() []config.SSHHost {
+	var hosts []config.SSHHost
+	for _, host := range m.hosts {
+		if m.isHostSelected(host.Name) {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// actionHosts returns the hosts a bulk action applies to: the selection, or
+// the host under the cursor when nothing is selected
+func (m Model) actionHosts() []config.SSHHost {
+	if hosts := m.selectionHosts(); len(hosts) > 0 {
+		return hosts
+	}
+	if host := m.selectedHost(); host != nil {
+		return []config.SSHHost{*host}
+	}
+	return nil
+}
+
+// firstSystemHost returns the first host read from the system-wide config,
+// which can't be changed, or nil
+func firstSystemHost(hosts []config.SSHHost) *config.SSHHost {
+	for i := range hosts {
+		if hosts[i].System {
+			return &hosts[i]
+		}
+	}
+	return nil
+}
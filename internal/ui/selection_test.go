@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestToggleSelection(t *testing.T) {
+	m := createTestModel()
+
+	// Space selects the host under the cursor and moves down
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m = newModel.(Model)
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	m = newModel.(Model)
+	if got := len(m.selectionHosts()); got != 2 {
+		t.Fatalf("expected 2 selected hosts, got %d", got)
+	}
+
+	// Esc clears the selection rather than quitting
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(Model)
+	if len(m.selectedHosts) != 0 || cmd != nil {
+		t.Error("expected Esc to clear the selection")
+	}
+}
+
+func TestSelectAllMatchingFilter(t *testing.T) {
+	m := createTestModel()
+	m.searchInput.SetValue("-server")
+	m.filteredHosts = m.filterHosts("-server")
+	m.updateTableRows()
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlA})
+	m = newModel.(Model)
+	if got, want := len(m.selectionHosts()), len(m.filteredHosts); got != want || got == len(m.hosts) {
+		t.Errorf("expected the %d matching hosts to be selected, got %d", want, got)
+	}
+
+	// Bulk actions apply to the selection, not to the host under the cursor
+	if got := len(m.actionHosts()); got != len(m.filteredHosts) {
+		t.Errorf("expected bulk actions to apply to %d hosts, got %d", len(m.filteredHosts), got)
+	}
+
+	// Selecting all again clears the selection
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlA})
+	m = newModel.(Model)
+	if len(m.selectedHosts) != 0 {
+		t.Error("expected a second Ctrl+A to clear the selection")
+	}
+}
@@ -35,18 +35,39 @@ func sortHostsByName(hosts []config.SSHHost) []config.SSHHost {
 	return sorted
 }
 
-// filterHosts filters hosts according to the search query (name or tags)
+// filterHosts filters hosts according to the search query (name, tags or
+// metadata), by the value of a metadata entry or directive for queries such
+// as "owner=platform" or "user=deploy", or by tag for queries such as "#prod"
 func (m Model) filterHosts(query string) []config.SSHHost {
 	var filtered []config.SSHHost
 
 	if query == "" {
 		filtered = m.hosts
+	} else if tag := activeTagFilter(query); tag != "" {
+		for _, host := range m.hosts {
+			if hasTag(host, tag) {
+				filtered = append(filtered, host)
+			}
+		}
 	} else {
 		query = strings.ToLower(query)
 
 		for _, host := range m.hosts {
-			// Check the hostname
-			if strings.Contains(strings.ToLower(host.Name), query) {
+			if matched, ok := host.MatchMetadataQuery(query); ok {
+				if matched {
+					filtered = append(filtered, host)
+				}
+				continue
+			}
+			if matched, ok := host.MatchDirectiveQuery(query); ok {
+				if matched {
+					filtered = append(filtered, host)
+				}
+				continue
+			}
+
+			// Check the host name and its aliases
+			if strings.Contains(strings.ToLower(host.Name), query) || matchesAlias(host, query) {
 				filtered = append(filtered, host)
 				continue
 			}
@@ -64,14 +85,28 @@ func (m Model) filterHosts(query string) []config.SSHHost {
 			}
 
 			// Check the tags
+			tagged := false
 			for _, tag := range host.Tags {
 				if strings.Contains(strings.ToLower(tag), query) {
-					filtered = append(filtered, host)
+					tagged = true
 					break
 				}
 			}
+			if tagged || host.Metadata.Matches(query) {
+				filtered = append(filtered, host)
+			}
 		}
 	}
 
 	return m.sortHosts(filtered)
 }
+
+// matchesAlias reports whether any alias of the host contains the query
+func matchesAlias(host config.SSHHost, query string) bool {
+	for _, alias := range host.Aliases {
+		if strings.Contains(strings.ToLower(alias), query) {
+			return true
+		}
+	}
+	return false
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/Gu1llaum-3/sshm/internal/history"
 
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
 )
 
 // calculateDynamicColumnWidths calculates optimal column widths based on terminal width
@@ -23,6 +24,16 @@ func (m *Model) calculateDynamicColumnWidths(hosts []config.SSHHost) (int, int,
 	maxTagsLength := 8       // Minimum for "Tags" header
 	maxLastLoginLength := 12 // Minimum for "Last Login" header
 
+	// Group headings, indented hosts and selection marks need a wider name
+	// column
+	if m.groupMode != GroupNone || len(m.selectedHosts) > 0 {
+		for _, row := range m.table.Rows() {
+			if width := lipgloss.Width(row[0]); width > maxNameLength {
+				maxNameLength = width
+			}
+		}
+	}
+
 	for _, host := range hosts {
 		// Name column includes status indicator (2 chars) + space (1 char) + name
 		nameLength := 3 + len(host.Name)
@@ -43,6 +54,9 @@ func (m *Model) calculateDynamicColumnWidths(hosts []config.SSHHost) (int, int,
 			}
 			tagsStr = strings.Join(formattedTags, " ")
 		}
+		if host.System {
+			tagsStr = strings.TrimSpace(systemBadge + " " + tagsStr)
+		}
 		if len(tagsStr) > maxTagsLength {
 			maxTagsLength = len(tagsStr)
 		}
@@ -121,7 +135,8 @@ func (m *Model) calculateDynamicColumnWidths(hosts []config.SSHHost) (int, int,
 	return nameWidth, hostnameWidth, tagsWidth, lastLoginWidth
 }
 
-// updateTableRows updates the table with filtered hosts
+// updateTableRows updates the table with filtered hosts, organised in groups
+// when a group mode is selected
 func (m *Model) updateTableRows() {
 	var rows []table.Row
 	hostsToShow := m.filteredHosts
@@ -129,9 +144,16 @@ func (m *Model) updateTableRows() {
 		hostsToShow = m.hosts
 	}
 
-	for _, host := range hostsToShow {
-		// Get ping status indicator
-		statusIndicator := m.getPingStatusIndicator(host.Name)
+	m.rows = m.buildListRows(hostsToShow)
+	for _, row := range m.rows {
+		if row.group != nil {
+			rows = append(rows, m.groupTableRow(row.group))
+			continue
+		}
+		host := *row.host
+
+		// Get ping status indicator, indented under its group
+		statusIndicator := strings.Repeat("  ", row.depth) + m.getPingStatusIndicator(host.Name)
 
 		// Format tags for display
 		var tagsStr string
@@ -143,6 +165,9 @@ func (m *Model) updateTableRows() {
 			}
 			tagsStr = strings.Join(formattedTags, " ")
 		}
+		if host.System {
+			tagsStr = strings.TrimSpace(systemBadge + " " + tagsStr)
+		}
 
 		// Format last login information
 		var lastLoginStr string
@@ -152,8 +177,17 @@ func (m *Model) updateTableRows() {
 			}
 		}
 
+		// Mark the hosts of the multi-selection
+		var selectionMark string
+		if len(m.selectedHosts) > 0 {
+			selectionMark = "  "
+			if m.isHostSelected(host.Name) {
+				selectionMark = "✔ "
+			}
+		}
+
 		rows = append(rows, table.Row{
-			statusIndicator + " " + host.Name,
+			statusIndicator + " " + selectionMark + host.Name,
 			host.Hostname,
 			// host.User,      // Commented to save space
 			// host.Port,      // Commented to save space
@@ -185,6 +219,9 @@ func (m *Model) updateTableHeight() {
 	// - Safety margin: 3 lines (to ensure UI elements are always visible)
 	// Total reserved: 14 lines minimum to preserve essential UI elements
 	reservedHeight := 14
+	if len(m.diagnostics) > 0 {
+		reservedHeight++ // Config problems warning
+	}
 	availableHeight := m.height - reservedHeight
 	hostCount := len(m.table.Rows())
 
@@ -302,6 +339,9 @@ func calculateTagsColumnWidth(hosts []config.SSHHost) int {
 			}
 			tagsStr = strings.Join(formattedTags, " ")
 		}
+		if host.System {
+			tagsStr = strings.TrimSpace(systemBadge + " " + tagsStr)
+		}
 
 		if len(tagsStr) > maxLength {
 			maxLength = len(tagsStr)
@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tagQueryPrefix starts search queries matching hosts carrying a tag, such as
+// "#prod", the way tags are shown in the host table
+const tagQueryPrefix = "#"
+
+// tagBrowserModel lists the tags of the hosts with their counts. Selecting a
+// tag filters the host table on it.
+type tagBrowserModel struct {
+	tags     []config.TagCount
+	total    int    // Number of hosts
+	active   string // Tag the host table is currently filtered on
+	selected int    // 0 is the entry showing every host
+	styles   Styles
+	width    int
+	height   int
+}
+
+// tagBrowserSelectMsg is sent when a tag is chosen, or "" to show every host
+type tagBrowserSelectMsg struct {
+	tag string
+}
+
+type tagBrowserCloseMsg struct{}
+
+// NewTagBrowser creates a tag browser for the given hosts
+func NewTagBrowser(hosts []config.SSHHost, active string, styles Styles, width, height int) *tagBrowserModel {
+	m := &tagBrowserModel{
+		tags:   config.CountTags(hosts),
+		total:  len(hosts),
+		active: active,
+		styles: styles,
+		width:  width,
+		height: height,
+	}
+	for i, tag := range m.tags {
+		if strings.EqualFold(tag.Tag, active) {
+			m.selected = i + 1
+		}
+	}
+	return m
+}
+
+func (m *tagBrowserModel) Update(msg tea.Msg) (*tagBrowserModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.styles = NewStyles(m.width)
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q", "T":
+			return m, func() tea.Msg { return tagBrowserCloseMsg{} }
+		case "up", "k":
+			if m.selected > 0 {
+				m.selected--
+			}
+		case "down", "j":
+			if m.selected < len(m.tags) {
+				m.selected++
+			}
+		case "enter":
+			var tag string
+			if m.selected > 0 {
+				tag = m.tags[m.selected-1].Tag
+			}
+			return m, func() tea.Msg { return tagBrowserSelectMsg{tag: tag} }
+		}
+	}
+
+	return m, nil
+}
+
+func (m *tagBrowserModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.FormTitle.Render(fmt.Sprintf("Tags (%d)", len(m.tags))))
+	b.WriteString("\n\n")
+
+	entries := []string{fmt.Sprintf("(all hosts) %d", m.total)}
+	for _, tag := range m.tags {
+		entries = append(entries, fmt.Sprintf("#%s %d", tag.Tag, tag.Count))
+	}
+
+	// Keep room for the title, hint lines and container borders
+	visible := m.height - 8
+	if visible < 5 {
+		visible = 5
+	}
+	start := 0
+	if m.selected >= visible {
+		start = m.selected - visible + 1
+	}
+	end := start + visible
+	if end > len(entries) {
+		end = len(entries)
+	}
+
+	activeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(PrimaryColor))
+	for i := start; i < end; i++ {
+		line := entries[i]
+		if i > 0 && strings.EqualFold(m.tags[i-1].Tag, m.active) {
+			line = activeStyle.Render(line + " ✔")
+		}
+		if i == m.selected {
+			b.WriteString(m.styles.Selected.Render("▶ " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(m.styles.HelpText.Render("↑/↓: navigate • Enter: filter hosts • ESC/q: back"))
+
+	return m.styles.FormContainer.Render(b.String())
+}
+
+// activeTagFilter returns the tag the search query filters on, if any
+func activeTagFilter(query string) string {
+	if !strings.HasPrefix(query, tagQueryPrefix) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(query, tagQueryPrefix))
+}
+
+// hasTag reports whether a host carries a tag, ignoring case
+func hasTag(host config.SSHHost, tag string) bool {
+	for _, t := range host.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadKnownTags returns the tags used in the config, most used first, to
+// complete tag inputs
+func loadKnownTags(configFile string) []string {
+	parsed, err := config.ParseConfigLayers(configFile)
+	if err != nil {
+		return nil
+	}
+	var tags []string
+	for _, tag := range config.CountTags(parsed.Hosts) {
+		tags = append(tags, tag.Tag)
+	}
+	return tags
+}
+
+// setupTagInput enables the completion of known tags in a tags input.
+// Suggestions are accepted with the right arrow, tab moving between fields.
+func setupTagInput(input *textinput.Model, knownTags []string) {
+	input.ShowSuggestions = true
+	input.KeyMap.AcceptSuggestion = key.NewBinding(key.WithKeys("right"))
+	updateTagSuggestions(input, knownTags)
+}
+
+// updateTagSuggestions suggests the known tags completing the last tag typed
+// in a comma-separated tags input, leaving out the tags already listed
+func updateTagSuggestions(input *textinput.Model, knownTags []string) {
+	value := input.Value()
+	prefix, last := "", value
+	if i := strings.LastIndex(value, ","); i >= 0 {
+		prefix, last = value[:i+1], value[i+1:]
+	}
+	if strings.TrimSpace(last) == "" {
+		input.SetSuggestions(nil)
+		return
+	}
+
+	listed := make(map[string]bool)
+	for _, tag := range strings.Split(prefix, ",") {
+		listed[strings.TrimSpace(tag)] = true
+	}
+	// Keep the spacing typed after the comma
+	lead := last[:len(last)-len(strings.TrimLeft(last, " "))]
+
+	var suggestions []string
+	for _, tag := range knownTags {
+		if !listed[tag] {
+			suggestions = append(suggestions, prefix+lead+tag)
+		}
+	}
+	input.SetSuggestions(suggestions)
+}
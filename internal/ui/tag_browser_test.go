@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+func TestFilterHostsByTag(t *testing.T) {
+	m := createTestModel()
+	m.hosts = []config.SSHHost{
+		{Name: "web1", Tags: []string{"prod", "web"}},
+		{Name: "web2", Tags: []string{"production"}},
+		{Name: "db1", Tags: []string{"Prod"}},
+		{Name: "prod-box"},
+	}
+
+	got := make(map[string]bool)
+	for _, host := range m.filterHosts("#prod") {
+		got[host.Name] = true
+	}
+	if len(got) != 2 || !got["web1"] || !got["db1"] {
+		t.Errorf("filterHosts(#prod) = %v, want web1 and db1", got)
+	}
+}
+
+func TestUpdateTagSuggestions(t *testing.T) {
+	input := textinput.New()
+	known := []string{"prod", "web", "staging"}
+	setupTagInput(&input, known)
+
+	input.SetValue("prod, w")
+	updateTagSuggestions(&input, known)
+	suggestions := input.AvailableSuggestions()
+
+	want := map[string]bool{"prod, web": true, "prod, staging": true}
+	if len(suggestions) != len(want) {
+		t.Fatalf("suggestions = %v, want %v", suggestions, want)
+	}
+	for _, s := range suggestions {
+		if !want[s] {
+			t.Errorf("unexpected suggestion %q", s)
+		}
+	}
+
+	input.SetValue("prod, ")
+	updateTagSuggestions(&input, known)
+	if got := input.AvailableSuggestions(); len(got) != 0 {
+		t.Errorf("suggestions for an empty tag = %v, want none", got)
+	}
+}
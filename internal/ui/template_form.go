@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// templateFormModel asks for the name of a host template made from an
+// existing host
+type templateFormModel struct {
+	host     config.SSHHost
+	template config.HostTemplate
+	input    textinput.Model
+	styles   Styles
+	width    int
+	height   int
+	err      string
+}
+
+// templateFormSubmitMsg is sent once the template was saved
+type templateFormSubmitMsg struct {
+	name string
+	err  error
+}
+
+type templateFormCancelMsg struct{}
+
+// NewTemplateForm creates a form saving a host as a new template
+func NewTemplateForm(host config.SSHHost, styles Styles, width, height int) *templateFormModel {
+	input := textinput.New()
+	input.Placeholder = "prod-db"
+	input.CharLimit = 50
+	input.Width = 30
+	input.Focus()
+
+	return &templateFormModel{
+		host:     host,
+		template: config.TemplateFromHost(host),
+		input:    input,
+		styles:   styles,
+		width:    width,
+		height:   height,
+	}
+}
+
+func (m *templateFormModel) Update(msg tea.Msg) (*templateFormModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			return m, func() tea.Msg { return templateFormCancelMsg{} }
+		case "enter":
+			name := strings.TrimSpace(m.input.Value())
+			template := m.template
+			return m, func() tea.Msg {
+				return templateFormSubmitMsg{name: name, err: config.SaveHostTemplate(name, template)}
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *templateFormModel) View() string {
+	var sections []string
+	sections = append(sections, m.styles.Header.Render(fmt.Sprintf("📋 Save '%s' as a template", m.host.Name)))
+
+	// Show what new hosts will start from
+	var summary []string
+	add := func(label, value string) {
+		if value != "" {
+			summary = append(summary, fmt.Sprintf("%-12s %s", label+":", value))
+		}
+	}
+	add("Hostname", m.template.HostName)
+	add("User", m.template.User)
+	add("Port", m.template.Port)
+	add("Identity", m.template.IdentityFile)
+	add("ProxyJump", m.template.ProxyJump)
+	add("Options", m.template.Options)
+	add("Environment", m.template.Environment)
+	add("Group", m.template.Group)
+	add("Tags", strings.Join(m.template.Tags, ", "))
+	if len(summary) == 0 {
+		summary = append(summary, "No value to keep")
+	}
+	sections = append(sections, m.styles.HelpText.Render(strings.Join(summary, "\n")))
+
+	if m.err != "" {
+		sections = append(sections, m.styles.Error.Render("Error: "+m.err))
+	}
+
+	sections = append(sections, lipgloss.JoinVertical(lipgloss.Left,
+		m.styles.FocusedLabel.Render("Template name:"),
+		m.input.View(),
+	))
+	sections = append(sections, m.styles.HelpText.Render(" Enter: save • Esc: cancel"))
+
+	content := lipgloss.JoinVertical(lipgloss.Left, sections...)
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		m.styles.FormContainer.Render(content),
+	)
+}
@@ -2,7 +2,6 @@ package ui
 
 import (
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/Gu1llaum-3/sshm/internal/config"
@@ -39,6 +38,7 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 
 	// Initialize ping manager with 5 second timeout
 	pingManager := connectivity.NewPingManager(5 * time.Second)
+	pingManager.SetConfigFile(configFile)
 
 	// Create the model with default sorting by name
 	m := Model{
@@ -56,6 +56,12 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 		viewMode:       ViewList,
 	}
 
+	// Collect the problems met while reading the config, shown as a warning
+	if parsed, err := config.ParseConfigLayers(configFile); err == nil {
+		m.diagnostics = parsed.Diagnostics
+	}
+	m.configSnapshot = takeConfigSnapshot(configFile)
+
 	// Sort hosts according to the default sort mode
 	sortedHosts := m.sortHosts(hosts)
 
@@ -78,45 +84,9 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 		{Title: "Last Login", Width: lastLoginWidth},
 	}
 
-	// Convert hosts to table rows
-	var rows []table.Row
-	for _, host := range sortedHosts {
-		// Get ping status indicator
-		statusIndicator := m.getPingStatusIndicator(host.Name)
-
-		// Format tags for display
-		var tagsStr string
-		if len(host.Tags) > 0 {
-			// Add the # prefix to each tag and join them with spaces
-			var formattedTags []string
-			for _, tag := range host.Tags {
-				formattedTags = append(formattedTags, "#"+tag)
-			}
-			tagsStr = strings.Join(formattedTags, " ")
-		}
-
-		// Format last login information
-		var lastLoginStr string
-		if historyManager != nil {
-			if lastConnect, exists := historyManager.GetLastConnectionTime(host.Name); exists {
-				lastLoginStr = formatTimeAgo(lastConnect)
-			}
-		}
-
-		rows = append(rows, table.Row{
-			statusIndicator + " " + host.Name,
-			host.Hostname,
-			// host.User,        // Commented to save space
-			// host.Port,        // Commented to save space
-			tagsStr,
-			lastLoginStr,
-		})
-	}
-
 	// Create the table with initial height (will be updated on first WindowSizeMsg)
 	t := table.New(
 		table.WithColumns(columns),
-		table.WithRows(rows),
 		table.WithFocused(true),
 		table.WithHeight(10), // Initial height, will be recalculated dynamically
 	)
@@ -137,6 +107,9 @@ func NewModel(hosts []config.SSHHost, configFile, currentVersion string) Model {
 	m.searchInput = ti
 	m.filteredHosts = sortedHosts
 
+	// Convert hosts to table rows
+	m.updateTableRows()
+
 	// Initialize table styles based on initial focus state
 	m.updateTableStyles()
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"strings"
 	"time"
 
 	"github.com/Gu1llaum-3/sshm/internal/config"
@@ -40,6 +41,19 @@ func (m Model) startPingAllCmd() tea.Cmd {
 	)
 }
 
+// startPingHostsCmd pings the given hosts, such as the hosts of a group
+func (m Model) startPingHostsCmd(hosts []config.SSHHost) tea.Cmd {
+	if m.pingManager == nil {
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	for _, host := range hosts {
+		cmds = append(cmds, pingSingleHostCmd(m.pingManager, host))
+	}
+	return tea.Batch(cmds...)
+}
+
 // listenForPingResultsCmd is no longer needed since we use individual ping commands
 
 // pingSingleHostCmd creates a command to ping a single host
@@ -74,6 +88,9 @@ func (m Model) Init() tea.Cmd {
 	// Basic initialization commands
 	cmds = append(cmds, textinput.Blink)
 
+	// Watch the config files for changes made outside sshm
+	cmds = append(cmds, watchConfigCmd(m.configFile, m.configGeneration))
+
 	// Check for version updates if we have a current version
 	if m.currentVersion != "" {
 		cmds = append(cmds, checkVersionCmd(m.currentVersion))
@@ -135,6 +152,36 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.fileSelectorForm.height = m.height
 			m.fileSelectorForm.styles = m.styles
 		}
+		if m.matchListForm != nil {
+			m.matchListForm.width = m.width
+			m.matchListForm.height = m.height
+			m.matchListForm.styles = m.styles
+		}
+		if m.diagnosticsPanel != nil {
+			m.diagnosticsPanel.width = m.width
+			m.diagnosticsPanel.height = m.height
+			m.diagnosticsPanel.styles = m.styles
+		}
+		if m.patternList != nil {
+			m.patternList.width = m.width
+			m.patternList.height = m.height
+			m.patternList.styles = m.styles
+		}
+		if m.bulkForm != nil {
+			m.bulkForm.width = m.width
+			m.bulkForm.height = m.height
+			m.bulkForm.styles = m.styles
+		}
+		if m.tagBrowser != nil {
+			m.tagBrowser.width = m.width
+			m.tagBrowser.height = m.height
+			m.tagBrowser.styles = m.styles
+		}
+		if m.templateForm != nil {
+			m.templateForm.width = m.width
+			m.templateForm.height = m.height
+			m.templateForm.styles = m.styles
+		}
 		return m, nil
 
 	case pingResultMsg:
@@ -145,6 +192,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case configWatchMsg:
+		return m.handleConfigWatch(msg)
+
 	case versionCheckMsg:
 		// Handle version check result
 		if msg != nil {
@@ -163,6 +213,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if string(msg) == "clear" {
 			m.showingError = false
 			m.errorMessage = ""
+			m.statusMessage = ""
 		}
 		return m, nil
 
@@ -173,30 +224,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.addForm.err = msg.err.Error()
 			}
 			return m, nil
+		} else if m.addForm != nil && m.addForm.pattern {
+			// Success: return to the pattern list
+			m.returnToPatternList()
+			return m, nil
 		} else {
 			// Success: refresh hosts and return to list view
-			var hosts []config.SSHHost
-			var err error
-
-			if m.configFile != "" {
-				hosts, err = config.ParseSSHConfigFile(m.configFile)
-			} else {
-				hosts, err = config.ParseSSHConfig()
-			}
-
-			if err != nil {
+			if err := m.reloadHosts(); err != nil {
 				return m, tea.Quit
 			}
-			m.hosts = m.sortHosts(hosts)
-
-			// Reapply search filter if there is one active
-			if m.searchInput.Value() != "" {
-				m.filteredHosts = m.filterHosts(m.searchInput.Value())
-			} else {
-				m.filteredHosts = m.hosts
-			}
-
-			m.updateTableRows()
 			m.viewMode = ViewList
 			m.addForm = nil
 			m.table.Focus()
@@ -204,6 +240,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case addFormCancelMsg:
+		if m.addForm != nil && m.addForm.pattern {
+			m.returnToPatternList()
+			return m, nil
+		}
 		// Cancel: return to list view
 		m.viewMode = ViewList
 		m.addForm = nil
@@ -217,30 +257,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.editForm.err = msg.err.Error()
 			}
 			return m, nil
+		} else if m.editForm != nil && m.editForm.pattern {
+			// Success: return to the pattern list
+			m.returnToPatternList()
+			return m, nil
 		} else {
 			// Success: refresh hosts and return to list view
-			var hosts []config.SSHHost
-			var err error
-
-			if m.configFile != "" {
-				hosts, err = config.ParseSSHConfigFile(m.configFile)
-			} else {
-				hosts, err = config.ParseSSHConfig()
-			}
-
-			if err != nil {
+			if err := m.reloadHosts(); err != nil {
 				return m, tea.Quit
 			}
-			m.hosts = m.sortHosts(hosts)
-
-			// Reapply search filter if there is one active
-			if m.searchInput.Value() != "" {
-				m.filteredHosts = m.filterHosts(m.searchInput.Value())
-			} else {
-				m.filteredHosts = m.hosts
-			}
-
-			m.updateTableRows()
 			m.viewMode = ViewList
 			m.editForm = nil
 			m.table.Focus()
@@ -248,6 +273,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case editFormCancelMsg:
+		if m.editForm != nil && m.editForm.pattern {
+			m.returnToPatternList()
+			return m, nil
+		}
 		// Cancel: return to list view
 		m.viewMode = ViewList
 		m.editForm = nil
@@ -256,42 +285,101 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case moveFormSubmitMsg:
 		if msg.err != nil {
-			// En cas d'erreur, on pourrait afficher une notification ou retourner à la liste
-			// Pour l'instant, on retourne simplement à la liste
-			m.viewMode = ViewList
-			m.moveForm = nil
-			m.table.Focus()
+			// Keep the form open with the error, nothing was changed on disk
+			if m.moveForm != nil {
+				m.moveForm.err = msg.err.Error()
+				m.moveForm.state = moveFormSelectingFile
+			}
 			return m, nil
 		} else {
 			// Success: refresh hosts and return to list view
-			var hosts []config.SSHHost
-			var err error
-
-			if m.configFile != "" {
-				hosts, err = config.ParseSSHConfigFile(m.configFile)
-			} else {
-				hosts, err = config.ParseSSHConfig()
-			}
-
-			if err != nil {
+			if err := m.reloadHosts(); err != nil {
 				return m, tea.Quit
 			}
-			m.hosts = m.sortHosts(hosts)
-
-			// Reapply search filter if there is one active
-			if m.searchInput.Value() != "" {
-				m.filteredHosts = m.filterHosts(m.searchInput.Value())
-			} else {
-				m.filteredHosts = m.hosts
+			if m.moveForm != nil && len(m.moveForm.hosts) > 0 {
+				m.clearSelection()
 			}
-
-			m.updateTableRows()
 			m.viewMode = ViewList
 			m.moveForm = nil
 			m.table.Focus()
 			return m, nil
 		}
 
+	case bulkFormSubmitMsg:
+		if msg.err != nil {
+			// Keep the form open with the error, nothing was changed on disk
+			if m.bulkForm != nil {
+				m.bulkForm.err = msg.err.Error()
+			}
+			return m, nil
+		}
+		// Success: refresh hosts and return to list view
+		if err := m.reloadHosts(); err != nil {
+			return m, tea.Quit
+		}
+		m.viewMode = ViewList
+		m.bulkForm = nil
+		m.table.Focus()
+		m.statusMessage = msg.status
+		return m, func() tea.Msg {
+			time.Sleep(3 * time.Second) // Show message for 3 seconds
+			return errorMsg("clear")
+		}
+
+	case tagBrowserSelectMsg:
+		// Filter the host table on the chosen tag, or show every host
+		m.viewMode = ViewList
+		m.tagBrowser = nil
+		if msg.tag != "" {
+			m.searchInput.SetValue(tagQueryPrefix + msg.tag)
+			m.filteredHosts = m.filterHosts(m.searchInput.Value())
+		} else {
+			m.searchInput.SetValue("")
+			m.filteredHosts = m.sortHosts(m.hosts)
+		}
+		m.updateTableRows()
+		m.table.SetCursor(0)
+		m.table.Focus()
+		return m, nil
+
+	case tagBrowserCloseMsg:
+		// Close the tag browser and return to list view
+		m.viewMode = ViewList
+		m.tagBrowser = nil
+		m.table.Focus()
+		return m, nil
+
+	case templateFormSubmitMsg:
+		if msg.err != nil {
+			// Keep the form open with the error, nothing was saved
+			if m.templateForm != nil {
+				m.templateForm.err = msg.err.Error()
+			}
+			return m, nil
+		}
+		m.viewMode = ViewList
+		m.templateForm = nil
+		m.table.Focus()
+		m.statusMessage = fmt.Sprintf("Saved template '%s'", msg.name)
+		return m, func() tea.Msg {
+			time.Sleep(3 * time.Second) // Show message for 3 seconds
+			return errorMsg("clear")
+		}
+
+	case templateFormCancelMsg:
+		// Cancel: return to list view
+		m.viewMode = ViewList
+		m.templateForm = nil
+		m.table.Focus()
+		return m, nil
+
+	case bulkFormCancelMsg:
+		// Cancel: return to list view
+		m.viewMode = ViewList
+		m.bulkForm = nil
+		m.table.Focus()
+		return m, nil
+
 	case moveFormCancelMsg:
 		// Cancel: return to list view
 		m.viewMode = ViewList
@@ -306,6 +394,44 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.table.Focus()
 		return m, nil
 
+	case patternListAddMsg:
+		m.addForm = NewPatternAddForm(m.styles, m.width, m.height, m.configFile)
+		m.viewMode = ViewAdd
+		return m, textinput.Blink
+
+	case patternListEditMsg:
+		editForm, err := NewPatternEditForm(msg.pattern, m.styles, m.width, m.height, m.configFile)
+		if err != nil {
+			if m.patternList != nil {
+				m.patternList.err = err.Error()
+			}
+			return m, nil
+		}
+		m.editForm = editForm
+		m.viewMode = ViewEdit
+		return m, textinput.Blink
+
+	case patternListCloseMsg:
+		// Close the pattern list and return to list view
+		m.viewMode = ViewList
+		m.patternList = nil
+		m.table.Focus()
+		return m, nil
+
+	case diagnosticsCloseMsg:
+		// Close the diagnostics panel and return to list view
+		m.viewMode = ViewList
+		m.diagnosticsPanel = nil
+		m.table.Focus()
+		return m, nil
+
+	case matchListCloseMsg:
+		// Close the Match block list and return to list view
+		m.viewMode = ViewList
+		m.matchListForm = nil
+		m.table.Focus()
+		return m, nil
+
 	case fileSelectorMsg:
 		if msg.cancelled {
 			// Cancel: return to list view
@@ -434,6 +560,48 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.fileSelectorForm = newForm
 				return m, cmd
 			}
+		case ViewPatterns:
+			if m.patternList != nil {
+				var newList *patternListModel
+				newList, cmd = m.patternList.Update(msg)
+				m.patternList = newList
+				return m, cmd
+			}
+		case ViewMatchList:
+			if m.matchListForm != nil {
+				var newForm *matchListModel
+				newForm, cmd = m.matchListForm.Update(msg)
+				m.matchListForm = newForm
+				return m, cmd
+			}
+		case ViewDiagnostics:
+			if m.diagnosticsPanel != nil {
+				var newPanel *diagnosticsModel
+				newPanel, cmd = m.diagnosticsPanel.Update(msg)
+				m.diagnosticsPanel = newPanel
+				return m, cmd
+			}
+		case ViewTags:
+			if m.tagBrowser != nil {
+				var newBrowser *tagBrowserModel
+				newBrowser, cmd = m.tagBrowser.Update(msg)
+				m.tagBrowser = newBrowser
+				return m, cmd
+			}
+		case ViewTemplate:
+			if m.templateForm != nil {
+				var newForm *templateFormModel
+				newForm, cmd = m.templateForm.Update(msg)
+				m.templateForm = newForm
+				return m, cmd
+			}
+		case ViewBulk:
+			if m.bulkForm != nil {
+				var newForm *bulkFormModel
+				newForm, cmd = m.bulkForm.Update(msg)
+				m.bulkForm = newForm
+				return m, cmd
+			}
 		case ViewList:
 			// Handle list view keys
 			return m.handleListViewKeys(msg)
@@ -453,9 +621,15 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			// Exit delete mode
 			m.deleteMode = false
 			m.deleteHost = ""
+			m.deleteHosts = nil
 			m.table.Focus()
 			return m, nil
 		}
+		if key == "esc" && !m.searchMode && len(m.selectedHosts) > 0 {
+			// Clear the multi-selection
+			m.clearSelection()
+			return m, nil
+		}
 		// Use configurable key bindings for quit
 		if m.appConfig != nil && m.appConfig.KeyBindings.ShouldQuitOnKey(key) {
 			return m, tea.Quit
@@ -505,6 +679,27 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.searchInput.Blur()
 			m.table.Focus()
 			return m, nil
+		} else if m.deleteMode && len(m.deleteHosts) > 0 {
+			// Confirm the deletion of the selected hosts, written as one change
+			count := len(m.deleteHosts)
+			err := config.DeleteHosts(m.deleteHosts)
+			m.deleteMode = false
+			m.deleteHosts = nil
+			m.table.Focus()
+			if err == nil {
+				err = m.reloadHosts()
+			}
+			if err != nil {
+				m.errorMessage = "Delete failed: " + err.Error()
+				m.showingError = true
+			} else {
+				m.clearSelection()
+				m.statusMessage = fmt.Sprintf("Deleted %d hosts", count)
+			}
+			return m, func() tea.Msg {
+				time.Sleep(3 * time.Second) // Show message for 3 seconds
+				return errorMsg("clear")
+			}
 		} else if m.deleteMode {
 			// Confirm deletion
 			var err error
@@ -513,49 +708,30 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			} else {
 				err = config.DeleteSSHHost(m.deleteHost)
 			}
-			if err != nil {
-				// Could display an error message here
-				m.deleteMode = false
-				m.deleteHost = ""
-				m.table.Focus()
-				return m, nil
-			}
-			// Refresh the hosts list
-			var hosts []config.SSHHost
-			var parseErr error
-
-			if m.configFile != "" {
-				hosts, parseErr = config.ParseSSHConfigFile(m.configFile)
-			} else {
-				hosts, parseErr = config.ParseSSHConfig()
+			m.deleteMode = false
+			m.deleteHost = ""
+			m.table.Focus()
+			if err == nil {
+				// Refresh the hosts list
+				err = m.reloadHosts()
 			}
-
-			if parseErr != nil {
-				// Could display an error message here
-				m.deleteMode = false
-				m.deleteHost = ""
-				m.table.Focus()
+			if err == nil {
 				return m, nil
 			}
-			m.hosts = m.sortHosts(hosts)
-
-			// Reapply search filter if there is one active
-			if m.searchInput.Value() != "" {
-				m.filteredHosts = m.filterHosts(m.searchInput.Value())
-			} else {
-				m.filteredHosts = m.hosts
+			m.errorMessage = "Delete failed: " + err.Error()
+			m.showingError = true
+			return m, func() tea.Msg {
+				time.Sleep(3 * time.Second) // Show error for 3 seconds
+				return errorMsg("clear")
 			}
-
-			m.updateTableRows()
-			m.deleteMode = false
-			m.deleteHost = ""
-			m.table.Focus()
+		} else if group := m.selectedGroup(); group != nil {
+			// Fold or unfold the group under the cursor
+			m.setGroupCollapsed(group, m.isGroupExpanded(group))
 			return m, nil
 		} else {
 			// Connect to the selected host
-			selected := m.table.SelectedRow()
-			if len(selected) > 0 {
-				hostName := extractHostNameFromTableRow(selected[0]) // Extract hostname from first column
+			if host := m.selectedHost(); host != nil {
+				hostName := host.Name
 
 				// Record the connection in history
 				if m.historyManager != nil {
@@ -582,9 +758,11 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "e":
 		if !m.searchMode && !m.deleteMode {
 			// Edit the selected host
-			selected := m.table.SelectedRow()
-			if len(selected) > 0 {
-				hostName := extractHostNameFromTableRow(selected[0]) // Extract hostname from first column
+			if host := m.selectedHost(); host != nil && host.System {
+				return m.showReadOnlyError(host)
+			}
+			if host := m.selectedHost(); host != nil {
+				hostName := host.Name
 				editForm, err := NewEditForm(hostName, m.styles, m.width, m.height, m.configFile)
 				if err != nil {
 					// Handle error - could show in UI
@@ -596,11 +774,32 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 	case "m":
+		if !m.searchMode && !m.deleteMode && len(m.selectedHosts) > 0 {
+			// Move the selected hosts to another config file
+			hosts := m.selectionHosts()
+			if host := firstSystemHost(hosts); host != nil {
+				return m.showReadOnlyError(host)
+			}
+			moveForm, err := NewBulkMoveForm(hosts, m.styles, m.width, m.height, m.configFile)
+			if err != nil {
+				m.errorMessage = err.Error()
+				m.showingError = true
+				return m, func() tea.Msg {
+					time.Sleep(3 * time.Second) // Show error for 3 seconds
+					return errorMsg("clear")
+				}
+			}
+			m.moveForm = moveForm
+			m.viewMode = ViewMove
+			return m, textinput.Blink
+		}
 		if !m.searchMode && !m.deleteMode {
 			// Move the selected host to another config file
-			selected := m.table.SelectedRow()
-			if len(selected) > 0 {
-				hostName := extractHostNameFromTableRow(selected[0]) // Extract hostname from first column
+			if host := m.selectedHost(); host != nil && host.System {
+				return m.showReadOnlyError(host)
+			}
+			if host := m.selectedHost(); host != nil {
+				hostName := host.Name
 				moveForm, err := NewMoveForm(hostName, m.styles, m.width, m.height, m.configFile)
 				if err != nil {
 					// Show error message to user
@@ -619,9 +818,8 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "i":
 		if !m.searchMode && !m.deleteMode {
 			// Show info for the selected host
-			selected := m.table.SelectedRow()
-			if len(selected) > 0 {
-				hostName := extractHostNameFromTableRow(selected[0]) // Extract hostname from first column
+			if host := m.selectedHost(); host != nil {
+				hostName := host.Name
 				infoForm, err := NewInfoForm(hostName, m.styles, m.width, m.height, m.configFile)
 				if err != nil {
 					// Handle error - could show in UI
@@ -671,11 +869,24 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, textinput.Blink
 		}
 	case "d":
+		if !m.searchMode && !m.deleteMode && len(m.selectedHosts) > 0 {
+			// Delete the selected hosts after a single confirmation
+			hosts := m.selectionHosts()
+			if host := firstSystemHost(hosts); host != nil {
+				return m.showReadOnlyError(host)
+			}
+			m.deleteMode = true
+			m.deleteHosts = hosts
+			m.table.Blur()
+			return m, nil
+		}
 		if !m.searchMode && !m.deleteMode {
 			// Delete the selected host
-			selected := m.table.SelectedRow()
-			if len(selected) > 0 {
-				hostName := extractHostNameFromTableRow(selected[0]) // Extract hostname from first column
+			if host := m.selectedHost(); host != nil && host.System {
+				return m.showReadOnlyError(host)
+			}
+			if host := m.selectedHost(); host != nil {
+				hostName := host.Name
 				m.deleteMode = true
 				m.deleteHost = hostName
 				m.table.Blur()
@@ -684,20 +895,169 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	case "p":
 		if !m.searchMode && !m.deleteMode {
-			// Ping all hosts
+			// Ping the selected hosts, the hosts of the group under the
+			// cursor, or all hosts
+			if hosts := m.selectionHosts(); len(hosts) > 0 {
+				return m, m.startPingHostsCmd(hosts)
+			}
+			if group := m.selectedGroup(); group != nil {
+				return m, m.startPingHostsCmd(group.allHosts())
+			}
 			return m, m.startPingAllCmd()
 		}
+	case "g":
+		if !m.searchMode && !m.deleteMode {
+			// Cycle through the ways of grouping hosts
+			m.cycleGroupMode()
+			m.statusMessage = "Hosts grouped by " + m.groupMode.String()
+			if m.groupMode == GroupNone {
+				m.statusMessage = "Hosts no longer grouped"
+			}
+			return m, func() tea.Msg {
+				time.Sleep(3 * time.Second) // Show message for 3 seconds
+				return errorMsg("clear")
+			}
+		}
+	case "left":
+		if !m.searchMode && !m.deleteMode && m.groupMode != GroupNone {
+			// Fold the group under the cursor, or go up to the parent group
+			if group := m.selectedGroup(); group != nil && m.isGroupExpanded(group) {
+				m.setGroupCollapsed(group, true)
+			} else {
+				m.moveCursorToParentGroup()
+			}
+			return m, nil
+		}
+	case "right":
+		if !m.searchMode && !m.deleteMode && m.groupMode != GroupNone {
+			// Unfold the group under the cursor
+			if group := m.selectedGroup(); group != nil && !m.isGroupExpanded(group) {
+				m.setGroupCollapsed(group, false)
+			}
+			return m, nil
+		}
+	case " ":
+		if !m.searchMode && !m.deleteMode {
+			// Add the host or group under the cursor to the selection
+			m.toggleSelection()
+			return m, nil
+		}
+	case "ctrl+a":
+		if !m.searchMode && !m.deleteMode {
+			// Select every host matching the current filter
+			m.toggleSelectAll()
+			return m, nil
+		}
+	case "t":
+		if !m.searchMode && !m.deleteMode {
+			// Add, remove or replace the tags of the selected hosts
+			hosts := m.actionHosts()
+			if host := firstSystemHost(hosts); host != nil {
+				return m.showReadOnlyError(host)
+			}
+			if len(hosts) > 0 {
+				m.bulkForm = NewBulkForm(bulkTags, hosts, loadKnownTags(m.configFile), m.styles, m.width, m.height)
+				m.viewMode = ViewBulk
+				return m, textinput.Blink
+			}
+		}
+	case "x":
+		if !m.searchMode && !m.deleteMode {
+			// Export the selected hosts to a standalone config file
+			if hosts := m.actionHosts(); len(hosts) > 0 {
+				m.bulkForm = NewBulkForm(bulkExport, hosts, nil, m.styles, m.width, m.height)
+				m.viewMode = ViewBulk
+				return m, textinput.Blink
+			}
+		}
 	case "f":
 		if !m.searchMode && !m.deleteMode {
 			// Port forwarding for the selected host
-			selected := m.table.SelectedRow()
-			if len(selected) > 0 {
-				hostName := extractHostNameFromTableRow(selected[0]) // Extract hostname from first column
+			if host := m.selectedHost(); host != nil {
+				hostName := host.Name
 				m.portForwardForm = NewPortForwardForm(hostName, m.styles, m.width, m.height, m.configFile, m.historyManager)
 				m.viewMode = ViewPortForward
 				return m, textinput.Blink
 			}
 		}
+	case "D":
+		if !m.searchMode && !m.deleteMode {
+			// Show the wildcard Host blocks holding shared defaults
+			patternList, err := NewPatternList(m.styles, m.width, m.height, m.configFile)
+			if err != nil {
+				m.errorMessage = err.Error()
+				m.showingError = true
+				return m, func() tea.Msg {
+					time.Sleep(3 * time.Second) // Show error for 3 seconds
+					return errorMsg("clear")
+				}
+			}
+			m.patternList = patternList
+			m.viewMode = ViewPatterns
+			return m, nil
+		}
+	case "M":
+		if !m.searchMode && !m.deleteMode {
+			// Show the Match blocks of the configuration
+			matchListForm, err := NewMatchList(m.styles, m.width, m.height, m.configFile)
+			if err != nil {
+				m.errorMessage = err.Error()
+				m.showingError = true
+				return m, func() tea.Msg {
+					time.Sleep(3 * time.Second) // Show error for 3 seconds
+					return errorMsg("clear")
+				}
+			}
+			m.matchListForm = matchListForm
+			m.viewMode = ViewMatchList
+			return m, nil
+		}
+	case "T":
+		if !m.searchMode && !m.deleteMode {
+			// Browse the tags and filter the hosts on one of them
+			m.tagBrowser = NewTagBrowser(m.hosts, activeTagFilter(m.searchInput.Value()), m.styles, m.width, m.height)
+			m.viewMode = ViewTags
+			return m, nil
+		}
+	case "S":
+		if !m.searchMode && !m.deleteMode {
+			// Save the selected host as a template for new hosts
+			if host := m.selectedHost(); host != nil {
+				m.templateForm = NewTemplateForm(*host, m.styles, m.width, m.height)
+				m.viewMode = ViewTemplate
+				return m, textinput.Blink
+			}
+		}
+	case "W":
+		if !m.searchMode && !m.deleteMode {
+			// Show the problems met while reading the config
+			m.diagnosticsPanel = NewDiagnosticsPanel(m.diagnostics, m.styles, m.width, m.height)
+			m.viewMode = ViewDiagnostics
+			return m, nil
+		}
+	case "u":
+		if !m.searchMode && !m.deleteMode {
+			// Undo the last change made to the config files
+			restored, err := config.UndoLastChange()
+			if err != nil {
+				m.errorMessage = "Undo failed: " + err.Error()
+				m.showingError = true
+			} else {
+				var files []string
+				for _, backup := range restored {
+					files = append(files, formatConfigFile(backup.SourceFile))
+				}
+				m.statusMessage = "Undid last change to " + strings.Join(files, ", ")
+				if err := m.reloadHosts(); err != nil {
+					m.errorMessage = err.Error()
+					m.showingError = true
+				}
+			}
+			return m, func() tea.Msg {
+				time.Sleep(3 * time.Second) // Show message for 3 seconds
+				return errorMsg("clear")
+			}
+		}
 	case "h":
 		if !m.searchMode && !m.deleteMode {
 			// Show help
@@ -770,3 +1130,60 @@ func (m Model) handleListViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	return m, cmd
 }
+
+// returnToPatternList closes a pattern form and shows the refreshed pattern list
+func (m *Model) returnToPatternList() {
+	m.addForm = nil
+	m.editForm = nil
+	m.viewMode = ViewPatterns
+	m.refreshConfigSnapshot()
+	if m.patternList == nil {
+		return
+	}
+	if err := m.patternList.reload(); err != nil {
+		m.patternList.err = err.Error()
+	}
+}
+
+// selectedHost returns the host under the cursor, or nil when the cursor is
+// on a group heading
+func (m Model) selectedHost() *config.SSHHost {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.rows) {
+		return nil
+	}
+	return m.rows[cursor].host
+}
+
+// showReadOnlyError explains that a host from the system-wide config can't
+// be changed
+func (m Model) showReadOnlyError(host *config.SSHHost) (tea.Model, tea.Cmd) {
+	m.errorMessage = fmt.Sprintf("'%s' is defined in the system-wide SSH config (%s) and is read-only", host.Name, host.SourceFile)
+	m.showingError = true
+	return m, func() tea.Msg {
+		time.Sleep(3 * time.Second) // Show error for 3 seconds
+		return errorMsg("clear")
+	}
+}
+
+// reloadHosts reads the config again and refreshes the host table
+func (m *Model) reloadHosts() error {
+	parsed, err := config.ParseConfigLayers(m.configFile)
+	if err != nil {
+		return err
+	}
+	m.hosts = m.sortHosts(parsed.Hosts)
+	m.diagnostics = parsed.Diagnostics
+	m.pruneSelection()
+	m.refreshConfigSnapshot()
+
+	// Reapply search filter if there is one active
+	if m.searchInput.Value() != "" {
+		m.filteredHosts = m.filterHosts(m.searchInput.Value())
+	} else {
+		m.filteredHosts = m.hosts
+	}
+
+	m.updateTableRows()
+	return nil
+}
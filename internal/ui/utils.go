@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"github.com/Gu1llaum-3/sshm/internal/config"
 	"github.com/Gu1llaum-3/sshm/internal/connectivity"
 	"strings"
 	"time"
@@ -71,6 +72,18 @@ func formatConfigFile(filePath string) string {
 	return filePath
 }
 
+// systemBadge marks hosts and patterns read from the system-wide SSH config
+const systemBadge = "[system]"
+
+// formatHostSource formats the file a host is defined in, marking the
+// read-only system config
+func formatHostSource(host config.SSHHost) string {
+	if host.System {
+		return systemBadge + " " + host.SourceFile + " (read-only)"
+	}
+	return formatConfigFile(host.SourceFile)
+}
+
 // getPingStatusIndicator returns a colored circle indicator based on ping status
 func (m *Model) getPingStatusIndicator(hostName string) string {
 	if m.pingManager == nil {
@@ -89,17 +102,3 @@ func (m *Model) getPingStatusIndicator(hostName string) string {
 		return "⚫" // Gray circle for unknown
 	}
 }
-
-// extractHostNameFromTableRow extracts the host name from the first column,
-// removing the ping status indicator
-func extractHostNameFromTableRow(firstColumn string) string {
-	// The first column format is: "🟢 hostname" or "⚫ hostname" etc.
-	// We need to remove the emoji and space to get just the hostname
-	parts := strings.Fields(firstColumn)
-	if len(parts) >= 2 {
-		// Return everything after the first part (the emoji)
-		return strings.Join(parts[1:], " ")
-	}
-	// Fallback: if there's no space, return the whole string
-	return firstColumn
-}
@@ -43,6 +43,30 @@ func (m Model) View() string {
 		if m.fileSelectorForm != nil {
 			return m.fileSelectorForm.View()
 		}
+	case ViewPatterns:
+		if m.patternList != nil {
+			return m.patternList.View()
+		}
+	case ViewMatchList:
+		if m.matchListForm != nil {
+			return m.matchListForm.View()
+		}
+	case ViewDiagnostics:
+		if m.diagnosticsPanel != nil {
+			return m.diagnosticsPanel.View()
+		}
+	case ViewBulk:
+		if m.bulkForm != nil {
+			return m.bulkForm.View()
+		}
+	case ViewTags:
+		if m.tagBrowser != nil {
+			return m.tagBrowser.View()
+		}
+	case ViewTemplate:
+		if m.templateForm != nil {
+			return m.templateForm.View()
+		}
 	case ViewList:
 		return m.renderListView()
 	}
@@ -86,6 +110,29 @@ func (m Model) renderListView() string {
 		components = append(components, errorStyle.Render("❌ "+m.errorMessage))
 	}
 
+	// Add status message if there's one to show
+	if m.statusMessage != "" {
+		statusStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("10")). // Green color
+			Bold(true).
+			Padding(0, 1).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("10")).
+			Align(lipgloss.Center)
+
+		components = append(components, statusStyle.Render("✔ "+m.statusMessage))
+	}
+
+	// Warn about problems met while reading the config
+	if len(m.diagnostics) > 0 {
+		warningStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")). // Orange color
+			Bold(true)
+
+		components = append(components, warningStyle.Render(
+			fmt.Sprintf("⚠ %d problem(s) reading the config, some hosts may be missing (W: details)", len(m.diagnostics))))
+	}
+
 	// Add the search bar with the appropriate style based on focus
 	searchPrompt := "Search (/ to focus): "
 	if m.searchMode {
@@ -105,7 +152,9 @@ func (m Model) renderListView() string {
 
 	// Add the help text
 	var helpText string
-	if !m.searchMode {
+	if n := len(m.selectedHosts); n > 0 && !m.searchMode {
+		helpText = fmt.Sprintf(" %d selected • Space: toggle • t: tags • m: move • d: delete • p: ping • x: export • Esc: clear", n)
+	} else if !m.searchMode {
 		helpText = " ↑/↓: navigate • Enter: connect • p: ping all • i: info • h: help • q: quit"
 	} else {
 		helpText = " Type to filter • Enter: validate • Tab: switch • ESC: quit"
@@ -145,6 +194,10 @@ func (m Model) renderDeleteConfirmation() string {
 	// Remove emojis (uncertain width depending on terminal) to stabilize the frame
 	title := "DELETE SSH HOST"
 	question := fmt.Sprintf("Are you sure you want to delete host '%s'?", m.deleteHost)
+	if len(m.deleteHosts) > 0 {
+		title = "DELETE SSH HOSTS"
+		question = fmt.Sprintf("Are you sure you want to delete %d hosts?\n%s", len(m.deleteHosts), hostNamesSummary(m.deleteHosts, 5))
+	}
 	action := "This action cannot be undone."
 	help := "Enter: confirm • Esc: cancel"
 
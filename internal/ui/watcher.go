@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// configWatchInterval is how often the config files are checked for changes
+const configWatchInterval = 2 * time.Second
+
+// fileStamp identifies a version of a file on disk
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// configSnapshot records the state of every file of the config tree. Files
+// that don't exist are recorded with a zero stamp.
+type configSnapshot map[string]fileStamp
+
+// configWatchMsg carries the state of the config files after a poll
+type configWatchMsg struct {
+	generation int // Generation of the snapshot the poll was started against
+	snapshot   configSnapshot
+}
+
+// watchedConfigFiles returns the files the host list is read from: the config
+// tree and, when it is shown, the system-wide config
+func watchedConfigFiles(configFile string) []string {
+	files, _ := config.GetAllConfigFilesFromBase(configFile)
+	if configFile == "" && config.IsSystemConfigEnabled() {
+		files = append(files, config.SystemSSHConfigPath)
+	}
+	return files
+}
+
+// takeConfigSnapshot records the state of the files of the config tree
+func takeConfigSnapshot(configFile string) configSnapshot {
+	snapshot := make(configSnapshot)
+	for _, file := range watchedConfigFiles(configFile) {
+		var stamp fileStamp
+		if info, err := os.Stat(file); err == nil {
+			stamp = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+		snapshot[file] = stamp
+	}
+	return snapshot
+}
+
+// changedFiles lists the files added, removed or modified since a previous
+// snapshot, sorted
+func (s configSnapshot) changedFiles(previous configSnapshot) []string {
+	var changed []string
+	for file, stamp := range s {
+		if old, ok := previous[file]; !ok || !old.modTime.Equal(stamp.modTime) || old.size != stamp.size {
+			changed = append(changed, file)
+		}
+	}
+	for file := range previous {
+		if _, ok := s[file]; !ok {
+			changed = append(changed, file)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// watchConfigCmd polls the config files after configWatchInterval
+func watchConfigCmd(configFile string, generation int) tea.Cmd {
+	return tea.Tick(configWatchInterval, func(time.Time) tea.Msg {
+		return configWatchMsg{generation: generation, snapshot: takeConfigSnapshot(configFile)}
+	})
+}
+
+// refreshConfigSnapshot records the current state of the config files, so
+// that changes made by sshm itself aren't reported as outside edits. Polls
+// started before are ignored.
+func (m *Model) refreshConfigSnapshot() {
+	m.configSnapshot = takeConfigSnapshot(m.configFile)
+	m.configGeneration++
+}
+
+// handleConfigWatch reloads the hosts when files of the config tree changed
+// on disk, keeping the selection, search and sort, and polls again. Changes
+// are only picked up while the host list is shown.
+func (m Model) handleConfigWatch(msg configWatchMsg) (tea.Model, tea.Cmd) {
+	if msg.generation != m.configGeneration {
+		// The snapshot was refreshed while polling
+		return m, watchConfigCmd(m.configFile, m.configGeneration)
+	}
+
+	changed := msg.snapshot.changedFiles(m.configSnapshot)
+	if len(changed) == 0 {
+		return m, watchConfigCmd(m.configFile, m.configGeneration)
+	}
+	if m.viewMode != ViewList {
+		// Reloading would record the new versions of the files, and a form
+		// being filled in would then overwrite the outside edit on submit.
+		// The snapshot is kept, so the first poll back in the list reloads.
+		return m, watchConfigCmd(m.configFile, m.configGeneration)
+	}
+	m.configSnapshot = msg.snapshot
+
+	var selectedHost, selectedGroup string
+	if host := m.selectedHost(); host != nil {
+		selectedHost = host.Name
+	} else if group := m.selectedGroup(); group != nil {
+		selectedGroup = group.path
+	}
+	cursor := m.table.Cursor()
+
+	if err := m.reloadHosts(); err != nil {
+		m.errorMessage = "Could not reload config: " + err.Error()
+		m.showingError = true
+		return m, tea.Batch(watchConfigCmd(m.configFile, m.configGeneration), func() tea.Msg {
+			time.Sleep(3 * time.Second) // Show error for 3 seconds
+			return errorMsg("clear")
+		})
+	}
+	if m.patternList != nil {
+		if err := m.patternList.reload(); err != nil {
+			m.patternList.err = err.Error()
+		}
+	}
+
+	switch {
+	case selectedHost != "" && m.moveCursorToHost(selectedHost):
+	case selectedGroup != "":
+		m.moveCursorToGroup(selectedGroup)
+	default:
+		// The selected host is gone; stay at the same place in the list
+		if cursor >= len(m.rows) {
+			cursor = len(m.rows) - 1
+		}
+		m.table.SetCursor(max(cursor, 0))
+	}
+
+	names := make([]string, len(changed))
+	for i, file := range changed {
+		names[i] = formatConfigFile(file)
+	}
+	m.statusMessage = "Reloaded after changes to " + strings.Join(names, ", ")
+	return m, tea.Batch(watchConfigCmd(m.configFile, m.configGeneration), func() tea.Msg {
+		time.Sleep(3 * time.Second) // Show message for 3 seconds
+		return errorMsg("clear")
+	})
+}
@@ -0,0 +1,204 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Gu1llaum-3/sshm/internal/config"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestConfigWatchReloadsHosts(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("Host alpha\n\nHost beta\n\nHost gamma\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	hosts, err := config.ParseSSHConfigFile(path)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	m := Model{
+		hosts:         hosts,
+		filteredHosts: hosts,
+		configFile:    path,
+		searchInput:   textinput.New(),
+		table:         table.New(),
+		ready:         true,
+		width:         80,
+		height:        24,
+		styles:        NewStyles(80),
+	}
+	m.updateTableColumns()
+	m.updateTableRows()
+	m.configSnapshot = takeConfigSnapshot(path)
+	m.moveCursorToHost("beta")
+
+	// Nothing changed
+	newModel, _ := m.handleConfigWatch(configWatchMsg{generation: m.configGeneration, snapshot: takeConfigSnapshot(path)})
+	m = newModel.(Model)
+	if m.statusMessage != "" {
+		t.Errorf("unexpected reload: %s", m.statusMessage)
+	}
+
+	if err := os.WriteFile(path, []byte("Host aardvark\n\nHost alpha\n\nHost beta\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// A poll started before sshm refreshed the snapshot is ignored
+	stale := configWatchMsg{generation: m.configGeneration - 1, snapshot: takeConfigSnapshot(path)}
+	newModel, _ = m.handleConfigWatch(stale)
+	if newModel.(Model).statusMessage != "" {
+		t.Error("expected a stale poll to be ignored")
+	}
+
+	newModel, _ = m.handleConfigWatch(configWatchMsg{generation: m.configGeneration, snapshot: takeConfigSnapshot(path)})
+	m = newModel.(Model)
+	if len(m.hosts) != 3 || m.hosts[0].Name != "aardvark" {
+		t.Fatalf("expected the hosts to be reloaded, got %v", m.hosts)
+	}
+	if host := m.selectedHost(); host == nil || host.Name != "beta" {
+		t.Errorf("expected beta to stay selected, got %v", host)
+	}
+	if !strings.Contains(m.statusMessage, "config") {
+		t.Errorf("expected the notice to name the changed file, got %q", m.statusMessage)
+	}
+}
+
+func TestConfigSnapshotKeepsChangeDetection(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("Host a\n    HostName a\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	host, err := config.GetSSHHostFromFile("a", path)
+	if err != nil {
+		t.Fatalf("GetSSHHostFromFile() error = %v", err)
+	}
+
+	// Another program edits the file, then the watcher polls while a form
+	// is still open
+	external := "Host a\n    HostName a\n\nHost b\n    HostName b\n"
+	if err := os.WriteFile(path, []byte(external), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	takeConfigSnapshot(path)
+
+	host.User = "admin"
+	if err := config.UpdateSSHHostInFile("a", *host, path); !errors.Is(err, config.ErrConfigChanged) {
+		t.Fatalf("UpdateSSHHostInFile() error = %v, want ErrConfigChanged", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != external {
+		t.Errorf("file was overwritten:\n%s", data)
+	}
+}
+
+func TestConfigWatchDuringFormKeepsChangeDetection(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("Host a\n    HostName a\n    User bob\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	hosts, err := config.ParseSSHConfigFile(path)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	m := Model{
+		hosts:         hosts,
+		filteredHosts: hosts,
+		configFile:    path,
+		searchInput:   textinput.New(),
+		table:         table.New(),
+		ready:         true,
+		width:         80,
+		height:        24,
+		styles:        NewStyles(80),
+	}
+	m.updateTableColumns()
+	m.updateTableRows()
+	m.configSnapshot = takeConfigSnapshot(path)
+
+	form, err := NewEditForm("a", m.styles, m.width, m.height, path)
+	if err != nil {
+		t.Fatalf("NewEditForm() error = %v", err)
+	}
+	m.editForm = form
+	m.viewMode = ViewEdit
+
+	// Another program edits the file while the form is open, and the
+	// watcher polls before the form is submitted
+	external := "Host a\n    HostName a\n    User alice\n"
+	if err := os.WriteFile(path, []byte(external), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	newModel, _ := m.Update(configWatchMsg{generation: m.configGeneration, snapshot: takeConfigSnapshot(path)})
+	m = newModel.(Model)
+	if m.statusMessage != "" {
+		t.Errorf("unexpected reload while the form is open: %s", m.statusMessage)
+	}
+
+	msg, ok := m.editForm.submitEditForm()().(editFormSubmitMsg)
+	if !ok || !errors.Is(msg.err, config.ErrConfigChanged) {
+		t.Fatalf("submit = %+v, want ErrConfigChanged", msg)
+	}
+	if data, _ := os.ReadFile(path); string(data) != external {
+		t.Errorf("file was overwritten:\n%s", data)
+	}
+
+	// Back in the list, the next poll picks up the change
+	m.viewMode = ViewList
+	newModel, _ = m.Update(configWatchMsg{generation: m.configGeneration, snapshot: takeConfigSnapshot(path)})
+	m = newModel.(Model)
+	if m.statusMessage == "" || m.hosts[0].User != "alice" {
+		t.Errorf("expected the hosts to be reloaded, got %v", m.hosts)
+	}
+}
+
+func TestDeleteShowsConfigChangedError(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("Host a\n    HostName a\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	hosts, err := config.ParseSSHConfigFile(path)
+	if err != nil {
+		t.Fatalf("ParseSSHConfigFile() error = %v", err)
+	}
+	m := Model{
+		hosts:         hosts,
+		filteredHosts: hosts,
+		configFile:    path,
+		searchInput:   textinput.New(),
+		table:         table.New(),
+		ready:         true,
+		width:         80,
+		height:        24,
+		styles:        NewStyles(80),
+		deleteMode:    true,
+		deleteHost:    "a",
+	}
+	m.updateTableColumns()
+	m.updateTableRows()
+
+	external := "Host a\n    HostName a\n    User alice\n"
+	if err := os.WriteFile(path, []byte(external), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+	if !m.showingError || !strings.Contains(m.errorMessage, config.ErrConfigChanged.Error()) {
+		t.Errorf("expected the delete error to be shown, got %q", m.errorMessage)
+	}
+	if m.deleteMode {
+		t.Error("expected the delete confirmation to be closed")
+	}
+	if data, _ := os.ReadFile(path); string(data) != external {
+		t.Errorf("file was overwritten:\n%s", data)
+	}
+}
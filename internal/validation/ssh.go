@@ -94,3 +94,35 @@ func ValidateHost(name, hostname, port, identity string) error {
 
 	return nil
 }
+
+// ValidatePattern validates the fields of a wildcard Host block such as "Host *.internal".
+// Unlike hosts, a hostname is optional and values may contain %-tokens expanded by ssh.
+func ValidatePattern(pattern, hostname, port, identity string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("pattern is required")
+	}
+
+	if strings.ContainsAny(pattern, "#\n\r") {
+		return fmt.Errorf("invalid pattern: cannot contain special characters")
+	}
+
+	for _, field := range strings.FieldsFunc(pattern, func(r rune) bool { return r == ' ' || r == '\t' || r == ',' }) {
+		if !strings.ContainsAny(field, "*?") && !strings.HasPrefix(field, "!") {
+			return fmt.Errorf("'%s' is a host name, patterns must use wildcards or negations", field)
+		}
+	}
+
+	if hostname != "" && !strings.Contains(hostname, "%") && !ValidateHostname(hostname) && !ValidateIP(hostname) {
+		return fmt.Errorf("invalid hostname or IP address format")
+	}
+
+	if !ValidatePort(port) {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+
+	if identity != "" && !strings.Contains(identity, "%") && !ValidateIdentityFile(identity) {
+		return fmt.Errorf("identity file does not exist: %s", identity)
+	}
+
+	return nil
+}
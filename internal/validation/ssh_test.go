@@ -185,3 +185,32 @@ func TestValidateHost(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		hostname string
+		port     string
+		identity string
+		wantErr  bool
+	}{
+		{"match all", "*", "", "", "", false},
+		{"domain with negation", "*.internal !bastion.internal", "", "2222", "", false},
+		{"hostname token", "*.internal", "%h.example.com", "", "~/.ssh/%h", false},
+		{"empty pattern", "", "", "", "", true},
+		{"concrete host", "web", "", "", "", true},
+		{"mixed with concrete host", "*.internal web", "", "", "", true},
+		{"invalid hostname", "*", "invalid..hostname", "", "", true},
+		{"invalid port", "*", "", "99999", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePattern(tt.pattern, tt.hostname, tt.port, tt.identity)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePattern() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}